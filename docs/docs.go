@@ -18,9 +18,80 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/account/change_password": {
+            "post": {
+                "description": "This endpoint replaces an account's password after checking the current one. Every other session of the account is logged out; the session making the request stays valid.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "account"
+                ],
+                "summary": "Change an account's password",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "password change request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/account.ChangePasswordArgs"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully changed password!",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/account/create_account": {
             "post": {
-                "description": "This endpoint creates a new multiplayer account, protected by a password.",
+                "description": "This endpoint creates a new multiplayer account, protected by a password. The account starts unverified and a verification token is emailed to it.",
                 "consumes": [
                     "application/json"
                 ],
@@ -44,22 +115,34 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "201": {
-                        "description": "Account successfully created",
+                        "description": "Account successfully created; the body contains a session for the new account",
                         "schema": {
-                            "type": "integer"
+                            "$ref": "#/definitions/auth.Session"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
                     },
                     "403": {
                         "description": "Forbidden",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Email already in use",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
                     }
                 }
             }
@@ -78,6 +161,12 @@ const docTemplate = `{
                 ],
                 "summary": "Deletes an account",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
                     {
                         "description": "account deletion request body",
                         "name": "body",
@@ -92,27 +181,39 @@ const docTemplate = `{
                     "200": {
                         "description": "Successfully deleted account!",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httpapi.MessageResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
                     },
                     "403": {
                         "description": "Forbidden",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
                     }
                 }
             }
         },
         "/account/get_account": {
             "get": {
-                "description": "This endpoint gets a multiplayer account's info.",
+                "description": "This endpoint gets a multiplayer account's info. Only the account itself can get it; other players use /account/get_profile.",
                 "consumes": [
                     "application/json"
                 ],
@@ -131,12 +232,17 @@ const docTemplate = `{
                         "in": "query",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
                     {
                         "type": "integer",
-                        "description": "session ID",
+                        "description": "Deprecated numeric session ID",
                         "name": "session_id",
-                        "in": "query",
-                        "required": true
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -148,134 +254,128 @@ const docTemplate = `{
                     },
                     "400": {
                         "description": "Bad Request",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
                     },
                     "403": {
                         "description": "Forbidden",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/account/update_account": {
-            "put": {
-                "description": "This endpoint updates an account's info.",
-                "consumes": [
-                    "application/json"
-                ],
+        "/account/get_profile": {
+            "get": {
+                "description": "This endpoint gets what other players can see of an account: its name, experience level, stats and join date, plus its info and location when the player has made them public. The email is never included. Players use /account/my_account to see their own account.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "account"
                 ],
-                "summary": "Updates an account",
+                "summary": "Gets a player's public profile",
                 "parameters": [
                     {
-                        "description": "account update request body",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/account.UpdateAccountArgs"
-                        }
+                        "type": "integer",
+                        "description": "account ID",
+                        "name": "account_id",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully updated account!"
+                        "description": "Profile successfully retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/account.PublicProfile"
+                        }
                     },
                     "400": {
-                        "description": "account_id must be specified",
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "an error occurred while decoding the request body: \u003cerror message\u003e",
+                        "description": "Internal Server Error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/game/create_game": {
-            "post": {
-                "description": "This endpoint creates a new multiplayer game, optionally protected by a password.",
-                "consumes": [
-                    "application/json"
-                ],
+        "/account/my_account": {
+            "get": {
+                "description": "This endpoint gets everything about the session's own account, including its email, whether the email is verified and its privacy settings.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "game"
+                    "account"
                 ],
-                "summary": "Create a new game",
+                "summary": "Gets your own account",
                 "parameters": [
                     {
-                        "description": "Game creation request body",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/game.CreateGameArgs"
-                        }
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Game successfully created",
+                    "200": {
+                        "description": "Account successfully retrieved",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/account.AccountDetails"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {}
-                    }
-                }
-            }
-        },
-        "/health": {
-            "get": {
-                "description": "Returns the status of the service.",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "health"
-                ],
-                "summary": "Health check endpoint",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/health.Response"
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/lobby/create_lobby": {
+        "/account/resend_verification": {
             "post": {
-                "description": "This endpoint creates a new multiplayer lobby, protected by a password.",
+                "description": "This endpoint emails a new verification token to the account's current email. The previous token stops working. Only one email is sent per minute.",
                 "consumes": [
                     "application/json"
                 ],
@@ -283,45 +383,70 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "lobby"
+                    "account"
                 ],
-                "summary": "Create a new lobby",
+                "summary": "Re-send the verification email",
                 "parameters": [
                     {
-                        "description": "lobby creation request body",
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "verification re-send request body",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/lobby.CreateLobbyArgs"
+                            "$ref": "#/definitions/account.ResendVerificationArgs"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Lobby successfully created",
+                    "202": {
+                        "description": "A new verification email has been sent.",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httpapi.MessageResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
                     },
                     "403": {
                         "description": "Forbidden",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/lobby/delete_lobby": {
-            "delete": {
-                "description": "This endpoint deletes a multiplayer lobby.",
+        "/account/update_account": {
+            "put": {
+                "description": "This endpoint updates an account's info.",
                 "consumes": [
                     "application/json"
                 ],
@@ -329,45 +454,78 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "lobby"
+                    "account"
                 ],
-                "summary": "Deletes a lobby",
+                "summary": "Updates an account",
                 "parameters": [
                     {
-                        "description": "lobby deletion request body",
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "description": "account update request body",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/lobby.DeleteLobbyArgs"
+                            "$ref": "#/definitions/account.UpdateAccountArgs"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully deleted lobby!",
+                        "description": "Successfully updated account!"
+                    },
+                    "400": {
+                        "description": "account_id must be specified",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {}
+                    "401": {
+                        "description": "a session token must be provided, or the current password is incorrect",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
                     },
                     "403": {
-                        "description": "Forbidden",
-                        "schema": {}
+                        "description": "the session does not belong to the account being acted on",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "no account exists with the ID \u003caccount_id\u003e",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "an account already uses the provided email",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "too many failed password attempts; please try again later",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
                     },
                     "500": {
-                        "description": "Internal Server Error",
-                        "schema": {}
+                        "description": "an error occurred while decoding the request body: \u003cerror message\u003e",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/lobby/get_lobby": {
-            "get": {
-                "description": "This endpoint gets a multiplayer lobby's info.",
+        "/account/update_privacy": {
+            "put": {
+                "description": "This endpoint sets whether the account's location and info appear on its public profile. Unlike /account/update_account, it does not need the password, since it can only hide or reveal what the player already wrote.",
                 "consumes": [
                     "application/json"
                 ],
@@ -375,45 +533,64 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "lobby"
+                    "account"
                 ],
-                "summary": "Gets a lobby",
+                "summary": "Updates an account's privacy settings",
                 "parameters": [
                     {
-                        "description": "lobby acquisition request body",
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "privacy settings update request body",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/lobby.GetLobbyArgs"
+                            "$ref": "#/definitions/account.UpdatePrivacyArgs"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Lobby successfully retrieved",
+                        "description": "Successfully updated privacy settings!",
                         "schema": {
-                            "$ref": "#/definitions/lobby.Lobby"
+                            "$ref": "#/definitions/httpapi.MessageResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
                     },
                     "403": {
                         "description": "Forbidden",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/lobby/update_lobby": {
-            "put": {
-                "description": "This endpoint updates a lobby's info.",
+        "/account/verify_email": {
+            "post": {
+                "description": "This endpoint verifies an account's email using the token sent to it when the account was created (or by /account/resend_verification). Each token can only be used once.",
                 "consumes": [
                     "application/json"
                 ],
@@ -421,45 +598,108 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "lobby"
+                    "account"
                 ],
-                "summary": "Updates a lobby",
+                "summary": "Verify an account's email",
                 "parameters": [
                     {
-                        "description": "lobby update request body",
+                        "description": "email verification request body",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/lobby.UpdateLobbyArgs"
+                            "$ref": "#/definitions/account.VerifyEmailArgs"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully updated lobby!",
+                        "description": "Successfully verified email!",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httpapi.MessageResponse"
                         }
                     },
                     "400": {
-                        "description": "lobby_id must be specified",
+                        "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "an error occurred while decoding the request body: \u003cerror message\u003e",
+                        "description": "Internal Server Error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/sessions": {
+        "/admin/login_failures": {
+            "get": {
+                "description": "This endpoint lets the server's administrator see the failed password attempts for an account, newest first, including the IP address each came from. It requires the ADMIN_TOKEN configured on the server.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List failed password attempts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "The ID of the account",
+                        "name": "account_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "How many attempts to return (default 50, at most 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The failed attempts",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/auth.LoginFailure"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/unlock_account": {
             "post": {
-                "description": "Create a new session for a user. Expires 12 hours from last interaction.",
+                "description": "This endpoint lets the server's administrator lift a lockout from an account after failed password attempts, for example once its owner has been in touch. It requires the ADMIN_TOKEN configured on the server. Lockouts of IP addresses are not lifted.",
                 "consumes": [
                     "application/json"
                 ],
@@ -467,37 +707,58 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "sessions"
+                    "admin"
                 ],
-                "summary": "Create a new session",
+                "summary": "Unlock an account",
                 "parameters": [
                     {
-                        "description": "User ID",
-                        "name": "account_id",
+                        "type": "string",
+                        "description": "Bearer admin token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "account unlock request body",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "integer"
+                            "$ref": "#/definitions/auth.UnlockAccountArgs"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Successfully unlocked account!",
                         "schema": {
-                            "$ref": "#/definitions/auth.Session"
+                            "$ref": "#/definitions/httpapi.MessageResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/sessions/{id}": {
-            "get": {
-                "description": "Get a session by its ID",
+        "/auth/forgot_password": {
+            "post": {
+                "description": "This endpoint emails a password reset token to the account with the given email. The token can be used once with /auth/reset_password and expires after an hour. Requesting a new token invalidates the previous one.",
                 "consumes": [
                     "application/json"
                 ],
@@ -505,33 +766,45 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "sessions"
+                    "auth"
                 ],
-                "summary": "Get a session",
+                "summary": "Request a password reset",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Session ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "forgot password request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.ForgotPasswordArgs"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "If an account uses that email, a password reset token has been sent to it.",
                         "schema": {
-                            "$ref": "#/definitions/auth.Session"
+                            "$ref": "#/definitions/httpapi.MessageResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {}
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete a session by its ID",
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "This endpoint verifies the email and password for an account and returns a new session. Sessions expire 12 hours from last interaction. After repeated failed attempts the account and the caller's IP address are locked out for a time, given by the Retry-After header.",
                 "consumes": [
                     "application/json"
                 ],
@@ -539,243 +812,4801 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "sessions"
+                    "auth"
                 ],
-                "summary": "Delete a session",
+                "summary": "Log in to an account",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Session ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "login request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.LoginArgs"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "Successfully logged in",
+                        "schema": {
+                            "$ref": "#/definitions/auth.Session"
+                        }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {}
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "account.Account": {
-            "description": "Structure for representing a player account.",
-            "type": "object",
-            "properties": {
-                "email": {
-                    "description": "Email is the email address of the player.",
-                    "type": "string"
-                },
-                "experience_level": {
-                    "description": "ExperienceLevel represents the player's experience level (0=beginner, 1=easy, 2=medium, 3=hard, 4=very hard, 5=impossible)",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/account.ExperienceLevel"
+        },
+        "/auth/logout": {
+            "post": {
+                "description": "This endpoint invalidates the given session so that it can no longer be used. Numeric session IDs are only accepted until the legacy session ID cutoff.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Log out of an account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "description": "logout request body",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/auth.LogoutArgs"
                         }
-                    ]
-                },
-                "info": {
-                    "description": "Info contains additional information about the player.",
-                    "type": "string"
-                },
-                "location": {
-                    "description": "Location indicates the player's real-life location.",
-                    "type": "string"
-                },
-                "name": {
-                    "description": "Name is the name of the player.",
-                    "type": "string"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully logged out!",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
                 }
             }
         },
-        "account.AccountParam": {
-            "description": "Structure for representing a player account with non-required fields.",
-            "type": "object",
-            "properties": {
-                "email": {
-                    "description": "Email is the email address of the player.",
-                    "type": "string"
-                },
-                "experience_level": {
-                    "description": "ExperienceLevel represents the player's experience level (0=beginner, 1=easy, 2=medium, 3=hard, 4=very hard, 5=impossible)",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/account.ExperienceLevel"
-                        }
+        "/auth/reset_password": {
+            "post": {
+                "description": "This endpoint sets a new password using a password reset token. The token can only be used once, every session of the account is logged out and a lockout from failed password attempts is lifted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Reset a forgotten password",
+                "parameters": [
+                    {
+                        "description": "password reset request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.ResetPasswordArgs"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully reset password!",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/game/create_game": {
+            "post": {
+                "description": "This endpoint creates a new multiplayer game hosted by the caller, optionally protected by a password.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "game"
+                ],
+                "summary": "Create a new game",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Game creation request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/game.CreateGameArgs"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Game successfully created",
+                        "schema": {
+                            "$ref": "#/definitions/game.Game"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/game/delete_game": {
+            "delete": {
+                "description": "This endpoint deletes a hosted multiplayer game.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "game"
+                ],
+                "summary": "Deletes a game",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "game deletion request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/game.DeleteGameArgs"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully deleted game!",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/game/get_game": {
+            "get": {
+                "description": "This endpoint gets a hosted multiplayer game's info. The game password is never returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "game"
+                ],
+                "summary": "Gets a game",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "game ID",
+                        "name": "game_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Game successfully retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/game.Game"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/game/list_games": {
+            "get": {
+                "description": "This endpoint lists hosted multiplayer games so that they can be shown in the launcher. By default only open games are listed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "game"
+                ],
+                "summary": "Lists games",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "open",
+                        "description": "game status to filter by (open, in_progress or finished)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "maximum number of games to return (at most 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "number of games to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Games successfully retrieved",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/game.Game"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Returns the status of the service.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Health check endpoint",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/health.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/lobby/create_lobby": {
+            "post": {
+                "description": "This endpoint creates a new multiplayer lobby, protected by a password. The caller becomes the owner; the owner name is taken from their account.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Create a new lobby",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "lobby creation request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/lobby.CreateLobbyArgs"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created lobby!",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/lobby/delete_lobby": {
+            "delete": {
+                "description": "This endpoint deletes a multiplayer lobby.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Deletes a lobby",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "lobby deletion request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/lobby.DeleteLobbyArgs"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully deleted lobby!",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/lobby/events": {
+            "get": {
+                "description": "This endpoint upgrades to a WebSocket which pushes lobby.Event messages (members joining, leaving or being kicked, ready state changes, settings changes, mutes, chat messages and the lobby being deleted) to members of the lobby. Members can send chat messages by sending {\"type\": \"chat_message\", \"message\": \"...\"} over the WebSocket. The WebSocket is closed when the member leaves or is removed from the lobby, after the event saying so, and when the session ends.",
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Streams lobby events",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "lobby ID",
+                        "name": "lobby_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ctp-lobby-events, bearer.\u003csession token\u003e, for clients which cannot set the Authorization header",
+                        "name": "Sec-WebSocket-Protocol",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching protocols; events are sent as JSON messages",
+                        "schema": {
+                            "$ref": "#/definitions/lobby.Event"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/lobby/get_lobby": {
+            "get": {
+                "description": "This endpoint gets a multiplayer lobby's info. The lobby password is never returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Gets a lobby",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "lobby ID",
+                        "name": "lobby_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Lobby successfully retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/lobby.Lobby"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/lobby/join_lobby": {
+            "post": {
+                "description": "This endpoint adds the caller to a multiplayer lobby. Closed and full lobbies cannot be joined, and private lobbies require the lobby password. The lobby owner can always join a closed or private lobby.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Joins a lobby",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "lobby join request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/lobby.JoinLobbyArgs"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully joined lobby!",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/lobby/kick_member": {
+            "post": {
+                "description": "This endpoint lets the lobby owner remove a member from the lobby, and optionally ban them from rejoining it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Kicks a lobby member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "lobby kick request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/lobby.KickMemberArgs"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully kicked member!",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/lobby/leave_lobby": {
+            "post": {
+                "description": "This endpoint removes the caller from a multiplayer lobby they have joined. If the caller owns the lobby, ownership passes to the member who joined earliest, or the lobby is deleted if it is empty.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Leaves a lobby",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "lobby leave request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/lobby.LeaveLobbyArgs"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully left lobby!",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/lobby/list_lobbies": {
+            "get": {
+                "description": "This endpoint lists public lobbies which are not closed, one page at a time. Pass the returned next_cursor as the cursor parameter to get the next page.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Lists lobbies",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "only include lobbies whose name contains this text (case-insensitive)",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only include lobbies which have room for more members",
+                        "name": "has_free_slots",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only include lobbies which are (or are not) muted",
+                        "name": "is_muted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "only include lobbies whose owner has this experience level",
+                        "name": "owner_experience_level",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "newest",
+                        "description": "sort order (newest, oldest, name or most_members)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "maximum number of lobbies to return (at most 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor returned by the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Lobbies successfully retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/lobby.ListLobbiesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/lobby/list_members": {
+            "get": {
+                "description": "This endpoint lists the members of a multiplayer lobby along with their experience levels.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Lists lobby members",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "lobby ID",
+                        "name": "lobby_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Members successfully retrieved",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/lobby.Member"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/lobby/list_messages": {
+            "get": {
+                "description": "This endpoint lists the chat history of a lobby the caller is a member of, newest first. Pass the ID of the oldest message received as before to get the previous page.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Lists lobby chat messages",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "lobby ID",
+                        "name": "lobby_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "only include messages older than the message with this ID",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "maximum number of messages to return (at most 200)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Messages successfully retrieved",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/lobby.Message"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/lobby/mute_member": {
+            "post": {
+                "description": "This endpoint lets the lobby owner stop (or allow again) a single member sending chat messages.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Mutes a lobby member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "lobby member mute request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/lobby.MuteMemberArgs"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully muted member!",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/lobby/send_message": {
+            "post": {
+                "description": "This endpoint sends a chat message to a lobby the caller is a member of. Members cannot chat while the lobby or they are muted, and can send at most 5 messages every 10 seconds.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Sends a lobby chat message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "lobby chat message request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/lobby.SendMessageArgs"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Message successfully sent",
+                        "schema": {
+                            "$ref": "#/definitions/lobby.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/lobby/set_ready": {
+            "post": {
+                "description": "This endpoint marks the caller as ready (or not ready) in a lobby they have joined.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Sets the caller's ready state",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "lobby ready state request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/lobby.SetReadyArgs"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully changed ready state!",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/lobby/transfer_ownership": {
+            "post": {
+                "description": "This endpoint lets the lobby owner make another member of the lobby its owner.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Transfers lobby ownership",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "lobby ownership transfer request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/lobby.TransferOwnershipArgs"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully transferred ownership!",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/lobby/update_lobby": {
+            "put": {
+                "description": "This endpoint updates a lobby's info. Only the lobby owner can update it, and the owner cannot be changed here.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Updates a lobby",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "lobby update request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/lobby.UpdateLobbyArgs"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully updated lobby!",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "lobby_id must be specified",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "a session token must be provided in the Authorization header",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "the session does not belong to the account being acted on",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "no lobby exists with the ID \u003cid\u003e",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "an error occurred while decoding the request body: \u003cerror message\u003e",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions": {
+            "post": {
+                "description": "Create a new session for a user. Expires 12 hours from last interaction.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Create a new session",
+                "parameters": [
+                    {
+                        "description": "User ID",
+                        "name": "account_id",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth.Session"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/sessions/{id}": {
+            "get": {
+                "description": "Get a session by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Get a session",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth.Session"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/sessions/{token}": {
+            "delete": {
+                "description": "Delete a session by its token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Delete a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/v2/accounts": {
+            "post": {
+                "description": "This endpoint creates a new multiplayer account, protected by a password. The account starts unverified and a verification token is emailed to it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "account"
+                ],
+                "summary": "Create a new account",
+                "parameters": [
+                    {
+                        "description": "account creation request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/account.CreateAccountArgs"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Account successfully created; the body contains a session for the new account",
+                        "schema": {
+                            "$ref": "#/definitions/auth.Session"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Email already in use",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/accounts/me": {
+            "get": {
+                "description": "This endpoint gets everything about the session's own account, including its email, whether the email is verified and its privacy settings.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "account"
+                ],
+                "summary": "Gets your own account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Account successfully retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/account.AccountDetails"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/accounts/{id}": {
+            "get": {
+                "description": "This endpoint gets a multiplayer account's info. Only the account itself can get it; other players use /v2/accounts/{id}/profile.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "account"
+                ],
+                "summary": "Gets an account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Account successfully retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/account.Account"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "This endpoint deletes a player account.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "account"
+                ],
+                "summary": "Deletes an account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully deleted account!",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "This endpoint updates an account's info. The account's password has to be given.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "account"
+                ],
+                "summary": "Updates an account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "account update request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/account.UpdateAccountBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully updated account!",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/accounts/{id}/password": {
+            "put": {
+                "description": "This endpoint replaces an account's password after checking the current one. Every other session of the account is logged out; the session making the request stays valid.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "account"
+                ],
+                "summary": "Change an account's password",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "password change request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/account.ChangePasswordBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully changed password!",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/accounts/{id}/privacy": {
+            "patch": {
+                "description": "This endpoint sets whether the account's location and info appear on its public profile. Unlike updating the account, it does not need the password, since it can only hide or reveal what the player already wrote.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "account"
+                ],
+                "summary": "Updates an account's privacy settings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "the privacy settings to change",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/account.PrivacySettingsParam"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully updated privacy settings!",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/accounts/{id}/profile": {
+            "get": {
+                "description": "This endpoint gets what other players can see of an account: its name, experience level, stats and join date, plus its info and location when the player has made them public. The email is never included. Players use /v2/accounts/me to see their own account.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "account"
+                ],
+                "summary": "Gets a player's public profile",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Profile successfully retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/account.PublicProfile"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/accounts/{id}/verification-emails": {
+            "post": {
+                "description": "This endpoint emails a new verification token to the account's current email. The previous token stops working. Only one email is sent per minute.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "account"
+                ],
+                "summary": "Re-send the verification email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "A new verification email has been sent.",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/admin/accounts/{id}/lockout": {
+            "delete": {
+                "description": "This endpoint lets the server's administrator lift a lockout from an account after failed password attempts, for example once its owner has been in touch. It requires the ADMIN_TOKEN configured on the server. Lockouts of IP addresses are not lifted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Unlock an account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "The ID of the account",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully unlocked account!",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/admin/accounts/{id}/login-failures": {
+            "get": {
+                "description": "This endpoint lets the server's administrator see the failed password attempts for an account, newest first, including the IP address each came from. It requires the ADMIN_TOKEN configured on the server.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List failed password attempts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "The ID of the account",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "How many attempts to return (default 50, at most 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The failed attempts",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/auth.LoginFailure"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/email-verifications": {
+            "post": {
+                "description": "This endpoint verifies an account's email using the token sent to it when the account was created (or by /account/resend_verification). Each token can only be used once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "account"
+                ],
+                "summary": "Verify an account's email",
+                "parameters": [
+                    {
+                        "description": "email verification request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/account.VerifyEmailArgs"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully verified email!",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/games": {
+            "get": {
+                "description": "This endpoint lists hosted multiplayer games so that they can be shown in the launcher. By default only open games are listed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "game"
+                ],
+                "summary": "Lists games",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "open",
+                        "description": "game status to filter by (open, in_progress or finished)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "maximum number of games to return (at most 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "number of games to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Games successfully retrieved",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/game.Game"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "This endpoint creates a new multiplayer game hosted by the caller, optionally protected by a password.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "game"
+                ],
+                "summary": "Create a new game",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Game creation request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/game.CreateGameArgs"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Game successfully created",
+                        "schema": {
+                            "$ref": "#/definitions/game.Game"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/games/{id}": {
+            "get": {
+                "description": "This endpoint gets a hosted multiplayer game's info. The game password is never returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "game"
+                ],
+                "summary": "Gets a game",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "game ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Game successfully retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/game.Game"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "This endpoint deletes a hosted multiplayer game.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "game"
+                ],
+                "summary": "Deletes a game",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "game ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully deleted game!",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/lobbies": {
+            "get": {
+                "description": "This endpoint lists public lobbies which are not closed, one page at a time. Pass the returned next_cursor as the cursor parameter to get the next page.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Lists lobbies",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "only include lobbies whose name contains this text (case-insensitive)",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only include lobbies which have room for more members",
+                        "name": "has_free_slots",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only include lobbies which are (or are not) muted",
+                        "name": "is_muted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "only include lobbies whose owner has this experience level",
+                        "name": "owner_experience_level",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "newest",
+                        "description": "sort order (newest, oldest, name or most_members)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "maximum number of lobbies to return (at most 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor returned by the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Lobbies successfully retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/lobby.ListLobbiesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "This endpoint creates a new multiplayer lobby, protected by a password. The caller becomes the owner; the owner name is taken from their account. The new lobby's URL is in the Location header.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Create a new lobby",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "lobby creation request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/lobby.CreateLobbyArgs"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Lobby successfully created",
+                        "schema": {
+                            "$ref": "#/definitions/lobby.Lobby"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "/v2/lobbies/{id}"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/lobbies/{id}": {
+            "get": {
+                "description": "This endpoint gets a multiplayer lobby's info. The lobby password is never returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Gets a lobby",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "lobby ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Lobby successfully retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/lobby.Lobby"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "This endpoint deletes a multiplayer lobby.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Deletes a lobby",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "lobby ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully deleted lobby!",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "This endpoint updates a lobby's info. Only the lobby owner can update it, and the owner cannot be changed here.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Updates a lobby",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "lobby ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "lobby update request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/lobby.UpdateLobbyBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully updated lobby!",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/lobbies/{id}/events": {
+            "get": {
+                "description": "This endpoint upgrades to a WebSocket which pushes lobby.Event messages (members joining, leaving or being kicked, ready state changes, settings changes, mutes, chat messages and the lobby being deleted) to members of the lobby. Members can send chat messages by sending {\"type\": \"chat_message\", \"message\": \"...\"} over the WebSocket. The WebSocket is closed when the member leaves or is removed from the lobby, after the event saying so, and when the session ends.",
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Streams lobby events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ctp-lobby-events, bearer.\u003csession token\u003e, for clients which cannot set the Authorization header",
+                        "name": "Sec-WebSocket-Protocol",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "lobby ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching protocols; events are sent as JSON messages",
+                        "schema": {
+                            "$ref": "#/definitions/lobby.Event"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/lobbies/{id}/members": {
+            "get": {
+                "description": "This endpoint lists the members of a multiplayer lobby along with their experience levels.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Lists lobby members",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "lobby ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Members successfully retrieved",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/lobby.Member"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "This endpoint adds the caller to a multiplayer lobby. Closed and full lobbies cannot be joined, and private lobbies require the lobby password. The lobby owner can always join a closed or private lobby.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Joins a lobby",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "lobby ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "lobby join request body",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/lobby.JoinLobbyBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully joined lobby!",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/lobbies/{id}/members/me": {
+            "delete": {
+                "description": "This endpoint removes the caller from a multiplayer lobby they have joined. If the caller owns the lobby, ownership passes to the member who joined earliest, or the lobby is deleted if it is empty.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Leaves a lobby",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "lobby ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully left lobby!",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/lobbies/{id}/members/me/ready": {
+            "put": {
+                "description": "This endpoint marks the caller as ready (or not ready) in a lobby they have joined.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Sets the caller's ready state",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "lobby ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "ready state request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/lobby.SetReadyBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully changed ready state!",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/lobbies/{id}/members/{account_id}": {
+            "delete": {
+                "description": "This endpoint lets the lobby owner remove a member from the lobby, and optionally ban them from rejoining it. Pass ban=true to ban them.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Kicks a lobby member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "lobby ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "account ID of the member",
+                        "name": "account_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "also ban the account from the lobby",
+                        "name": "ban",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully kicked member!",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/lobbies/{id}/members/{account_id}/muted": {
+            "put": {
+                "description": "This endpoint lets the lobby owner stop (or allow again) a single member sending chat messages.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Mutes or unmutes a lobby member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "lobby ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "account ID of the member",
+                        "name": "account_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "member mute request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/lobby.MuteMemberBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully muted member!",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/lobbies/{id}/messages": {
+            "get": {
+                "description": "This endpoint lists the chat history of a lobby the caller is a member of, newest first. Pass the ID of the oldest message received as before to get the previous page.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Lists lobby chat messages",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "lobby ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "only include messages older than the message with this ID",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "maximum number of messages to return (at most 200)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Messages successfully retrieved",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/lobby.Message"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "This endpoint sends a chat message to a lobby the caller is a member of. Members cannot chat while the lobby or they are muted, and can send at most 5 messages every 10 seconds.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Sends a lobby chat message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "lobby ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "chat message request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/lobby.SendMessageBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Message successfully sent",
+                        "schema": {
+                            "$ref": "#/definitions/lobby.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/lobbies/{id}/owner": {
+            "put": {
+                "description": "This endpoint lets the lobby owner make another member of the lobby its owner.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lobby"
+                ],
+                "summary": "Transfers lobby ownership",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "lobby ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "ownership transfer request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/lobby.TransferOwnershipBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully transferred ownership!",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/password-reset-requests": {
+            "post": {
+                "description": "This endpoint emails a password reset token to the account with the given email. The token can be used once with /auth/reset_password and expires after an hour. Requesting a new token invalidates the previous one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Request a password reset",
+                "parameters": [
+                    {
+                        "description": "forgot password request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.ForgotPasswordArgs"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "If an account uses that email, a password reset token has been sent to it.",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/password-resets": {
+            "post": {
+                "description": "This endpoint sets a new password using a password reset token. The token can only be used once, every session of the account is logged out and a lockout from failed password attempts is lifted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Reset a forgotten password",
+                "parameters": [
+                    {
+                        "description": "password reset request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.ResetPasswordArgs"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully reset password!",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/sessions": {
+            "post": {
+                "description": "This endpoint verifies the email and password for an account and returns a new session. Sessions expire 12 hours from last interaction. After repeated failed attempts the account and the caller's IP address are locked out for a time, given by the Retry-After header.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Log in to an account",
+                "parameters": [
+                    {
+                        "description": "login request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.LoginArgs"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully logged in",
+                        "schema": {
+                            "$ref": "#/definitions/auth.Session"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/sessions/current": {
+            "delete": {
+                "description": "This endpoint invalidates the session making the request so that it can no longer be used.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Log out of an account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer session token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully logged out!",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpapi.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "account.Account": {
+            "description": "Structure for representing a player account.",
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "description": "Email is the email address of the player.",
+                    "type": "string"
+                },
+                "experience_level": {
+                    "description": "ExperienceLevel represents the player's experience level (0=beginner, 1=easy, 2=medium, 3=hard, 4=very hard, 5=impossible)",
+                    "maximum": 5,
+                    "minimum": 0,
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/account.ExperienceLevel"
+                        }
+                    ]
+                },
+                "info": {
+                    "description": "Info contains additional information about the player.",
+                    "type": "string"
+                },
+                "location": {
+                    "description": "Location indicates the player's real-life location.",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Name is the name of the player.",
+                    "type": "string"
+                }
+            }
+        },
+        "account.AccountDetails": {
+            "description": "A player's own account, including its private fields.",
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "description": "Email is the email address of the player.",
+                    "type": "string"
+                },
+                "email_verified": {
+                    "description": "EmailVerified is whether the email has been verified.",
+                    "type": "boolean"
+                },
+                "experience_level": {
+                    "description": "ExperienceLevel represents the player's experience level (0=beginner, 1=easy, 2=medium, 3=hard, 4=very hard, 5=impossible)",
+                    "maximum": 5,
+                    "minimum": 0,
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/account.ExperienceLevel"
+                        }
+                    ]
+                },
+                "id": {
+                    "description": "ID is the ID of the account.",
+                    "type": "integer"
+                },
+                "info": {
+                    "description": "Info contains additional information about the player.",
+                    "type": "string"
+                },
+                "joined_at": {
+                    "description": "JoinedAt is when the account was created.",
+                    "type": "string"
+                },
+                "location": {
+                    "description": "Location indicates the player's real-life location.",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Name is the name of the player.",
+                    "type": "string"
+                },
+                "privacy": {
+                    "description": "Privacy controls which fields appear on the public profile.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/account.PrivacySettings"
+                        }
+                    ]
+                },
+                "stats": {
+                    "description": "Stats summarises the player's activity.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/account.ProfileStats"
+                        }
+                    ]
+                }
+            }
+        },
+        "account.AccountParam": {
+            "description": "Structure for representing a player account with non-required fields.",
+            "type": "object",
+            "properties": {
+                "email": {
+                    "description": "Email is the email address of the player.",
+                    "type": "string"
+                },
+                "experience_level": {
+                    "description": "ExperienceLevel represents the player's experience level (0=beginner, 1=easy, 2=medium, 3=hard, 4=very hard, 5=impossible)",
+                    "maximum": 5,
+                    "minimum": 0,
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/account.ExperienceLevel"
+                        }
+                    ]
+                },
+                "info": {
+                    "description": "Info contains additional information about the player.",
+                    "type": "string"
+                },
+                "location": {
+                    "description": "Location indicates the player's real-life location.",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Name is the name of the player.",
+                    "type": "string"
+                }
+            }
+        },
+        "account.ChangePasswordArgs": {
+            "description": "Structure for the password change request payload.",
+            "type": "object",
+            "required": [
+                "account_id",
+                "current_password",
+                "new_password"
+            ],
+            "properties": {
+                "account_id": {
+                    "description": "The account ID for the account whose password will be changed.",
+                    "type": "integer"
+                },
+                "current_password": {
+                    "description": "The account's current password.",
+                    "type": "string"
+                },
+                "new_password": {
+                    "description": "The password to replace it with.",
+                    "type": "string"
+                }
+            }
+        },
+        "account.ChangePasswordBody": {
+            "description": "Structure for the v2 password change request payload.",
+            "type": "object",
+            "properties": {
+                "current_password": {
+                    "description": "The account's current password.",
+                    "type": "string"
+                },
+                "new_password": {
+                    "description": "The password to replace it with.",
+                    "type": "string"
+                }
+            }
+        },
+        "account.CreateAccountArgs": {
+            "description": "Structure for the account creation request payload.",
+            "type": "object",
+            "required": [
+                "password"
+            ],
+            "properties": {
+                "account": {
+                    "description": "The account to create.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/account.Account"
+                        }
+                    ]
+                },
+                "password": {
+                    "description": "The password for the account to be created",
+                    "type": "string"
+                }
+            }
+        },
+        "account.DeleteAccountArgs": {
+            "description": "Structure for the account deletion request payload.",
+            "type": "object",
+            "required": [
+                "account_id"
+            ],
+            "properties": {
+                "account_id": {
+                    "description": "The account ID for the account that will be deleted.",
+                    "type": "integer"
+                },
+                "session_id": {
+                    "description": "Deprecated: a valid numeric session ID for the account. Send the session token in the Authorization header instead.",
+                    "type": "integer"
+                }
+            }
+        },
+        "account.ExperienceLevel": {
+            "type": "integer",
+            "enum": [
+                0,
+                1,
+                2,
+                3,
+                4,
+                5
+            ],
+            "x-enum-varnames": [
+                "Beginner",
+                "Easy",
+                "Medium",
+                "Hard",
+                "Very_Hard",
+                "Impossible"
+            ]
+        },
+        "account.PrivacySettings": {
+            "description": "Who can see the optional fields of a player's public profile.",
+            "type": "object",
+            "properties": {
+                "info_visibility": {
+                    "description": "InfoVisibility is \"public\" or \"private\".",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/account.Visibility"
+                        }
+                    ]
+                },
+                "location_visibility": {
+                    "description": "LocationVisibility is \"public\" or \"private\".",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/account.Visibility"
+                        }
+                    ]
+                }
+            }
+        },
+        "account.PrivacySettingsParam": {
+            "description": "Privacy settings with non-required fields.",
+            "type": "object",
+            "properties": {
+                "info_visibility": {
+                    "description": "InfoVisibility is \"public\" or \"private\".",
+                    "enum": [
+                        "public",
+                        "private"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/account.Visibility"
+                        }
+                    ]
+                },
+                "location_visibility": {
+                    "description": "LocationVisibility is \"public\" or \"private\".",
+                    "enum": [
+                        "public",
+                        "private"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/account.Visibility"
+                        }
+                    ]
+                }
+            }
+        },
+        "account.ProfileStats": {
+            "description": "Statistics about a player's activity.",
+            "type": "object",
+            "properties": {
+                "games_hosted": {
+                    "description": "GamesHosted is how many games the player is hosting or has hosted.",
+                    "type": "integer"
+                }
+            }
+        },
+        "account.PublicProfile": {
+            "description": "A player's public profile.",
+            "type": "object",
+            "properties": {
+                "experience_level": {
+                    "description": "ExperienceLevel represents the player's experience level (0=beginner, 1=easy, 2=medium, 3=hard, 4=very hard, 5=impossible)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/account.ExperienceLevel"
+                        }
+                    ]
+                },
+                "id": {
+                    "description": "ID is the ID of the account.",
+                    "type": "integer"
+                },
+                "info": {
+                    "description": "Info contains additional information about the player. It is left out when the player keeps it private.",
+                    "type": "string"
+                },
+                "joined_at": {
+                    "description": "JoinedAt is when the account was created.",
+                    "type": "string"
+                },
+                "location": {
+                    "description": "Location indicates the player's real-life location. It is left out when the player keeps it private.",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Name is the name of the player.",
+                    "type": "string"
+                },
+                "stats": {
+                    "description": "Stats summarises the player's activity.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/account.ProfileStats"
+                        }
+                    ]
+                }
+            }
+        },
+        "account.ResendVerificationArgs": {
+            "description": "Structure for the verification re-send request payload.",
+            "type": "object",
+            "required": [
+                "account_id"
+            ],
+            "properties": {
+                "account_id": {
+                    "description": "The account ID for the account whose email should be verified.",
+                    "type": "integer"
+                }
+            }
+        },
+        "account.UpdateAccountArgs": {
+            "description": "Structure for the account update request payload.",
+            "type": "object",
+            "required": [
+                "account",
+                "account_id",
+                "password"
+            ],
+            "properties": {
+                "account": {
+                    "description": "The account to create.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/account.AccountParam"
+                        }
+                    ]
+                },
+                "account_id": {
+                    "description": "The account ID for the account that will be updated.",
+                    "type": "integer"
+                },
+                "password": {
+                    "description": "The password for the account to be created",
+                    "type": "string"
+                },
+                "session_id": {
+                    "description": "Deprecated: a valid numeric session ID for the account. Send the session token in the Authorization header instead.",
+                    "type": "integer"
+                }
+            }
+        },
+        "account.UpdateAccountBody": {
+            "description": "Structure for the v2 account update request payload.",
+            "type": "object",
+            "properties": {
+                "account": {
+                    "description": "The fields of the account to change. Fields which are left out keep their current value.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/account.AccountParam"
+                        }
+                    ]
+                },
+                "password": {
+                    "description": "The account's password.",
+                    "type": "string"
+                }
+            }
+        },
+        "account.UpdatePrivacyArgs": {
+            "description": "Structure for the privacy settings update request payload.",
+            "type": "object",
+            "required": [
+                "account_id",
+                "privacy"
+            ],
+            "properties": {
+                "account_id": {
+                    "description": "The account ID for the account whose privacy settings will be updated.",
+                    "type": "integer"
+                },
+                "privacy": {
+                    "description": "The privacy settings to change. Settings which are left out keep their current value.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/account.PrivacySettingsParam"
+                        }
                     ]
+                }
+            }
+        },
+        "account.VerifyEmailArgs": {
+            "description": "Structure for the email verification request payload.",
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "description": "The verification token which was emailed to the account.",
+                    "type": "string"
+                }
+            }
+        },
+        "account.Visibility": {
+            "type": "string",
+            "enum": [
+                "public",
+                "private"
+            ],
+            "x-enum-varnames": [
+                "VisibilityPublic",
+                "VisibilityPrivate"
+            ]
+        },
+        "auth.ForgotPasswordArgs": {
+            "description": "Structure for the forgot password request payload.",
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "description": "The email address of the account whose password was forgotten.",
+                    "type": "string"
+                }
+            }
+        },
+        "auth.LoginArgs": {
+            "description": "Structure for the login request payload.",
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "description": "The email address of the account to log in to.",
+                    "type": "string"
                 },
-                "info": {
-                    "description": "Info contains additional information about the player.",
+                "password": {
+                    "description": "The password for the account.",
+                    "type": "string"
+                }
+            }
+        },
+        "auth.LoginFailure": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "description": "AccountID is nil when the attempt was for an email with no account.",
+                    "type": "integer"
+                },
+                "created_at": {
                     "type": "string"
                 },
-                "location": {
-                    "description": "Location indicates the player's real-life location.",
+                "id": {
+                    "type": "integer"
+                },
+                "ip": {
                     "type": "string"
                 },
-                "name": {
-                    "description": "Name is the name of the player.",
+                "reason": {
                     "type": "string"
                 }
             }
         },
-        "account.CreateAccountArgs": {
-            "description": "Structure for the account creation request payload.",
+        "auth.LogoutArgs": {
+            "description": "Structure for the logout request payload. The body may be omitted when the session token is sent in the Authorization header.",
             "type": "object",
             "properties": {
-                "account": {
-                    "description": "The account to create.",
+                "session_id": {
+                    "description": "Deprecated: the numeric session ID to invalidate. Send the session token in the Authorization header instead.",
+                    "type": "integer"
+                }
+            }
+        },
+        "auth.ResetPasswordArgs": {
+            "description": "Structure for the password reset request payload.",
+            "type": "object",
+            "required": [
+                "new_password",
+                "token"
+            ],
+            "properties": {
+                "new_password": {
+                    "description": "The new password for the account.",
+                    "type": "string"
+                },
+                "token": {
+                    "description": "The password reset token which was emailed to the account.",
+                    "type": "string"
+                }
+            }
+        },
+        "auth.Session": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID is the numeric session ID.\n\nDeprecated: numeric session IDs are only accepted until the store's LegacyIDCutoff. Use Token instead.",
+                    "type": "integer"
+                },
+                "token": {
+                    "description": "Token is the opaque bearer token for the session. It is only known when the session is created,\nsince only its hash is stored.",
+                    "type": "string"
+                }
+            }
+        },
+        "auth.UnlockAccountArgs": {
+            "description": "Structure for the account unlock request payload.",
+            "type": "object",
+            "required": [
+                "account_id"
+            ],
+            "properties": {
+                "account_id": {
+                    "description": "The ID of the account to unlock.",
+                    "type": "integer"
+                }
+            }
+        },
+        "game.CreateGameArgs": {
+            "description": "Structure for the game creation request payload.",
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "map_size": {
+                    "description": "MapSize is the size of the map (\"small\", \"medium\", \"large\" or \"gigantic\"). Defaults to \"medium\".",
+                    "enum": [
+                        "small",
+                        "medium",
+                        "large",
+                        "gigantic"
+                    ],
                     "allOf": [
                         {
-                            "$ref": "#/definitions/account.Account"
+                            "$ref": "#/definitions/game.MapSize"
                         }
                     ]
                 },
+                "max_players": {
+                    "description": "MaxPlayers is the maximum number of players, between 2 and 8. Defaults to 8.",
+                    "type": "integer",
+                    "maximum": 8,
+                    "minimum": 2
+                },
+                "name": {
+                    "description": "Name is the name of the game shown in the launcher.",
+                    "type": "string"
+                },
                 "password": {
-                    "description": "The password for the account to be created",
+                    "description": "Password is the password for the game.\nThis field is required if PasswordProtected is true.\nIt must satisfy the server's password policy.",
                     "type": "string"
+                },
+                "password_protected": {
+                    "description": "PasswordProtected indicates whether the game is password-protected.\nIf true, a password must be provided.",
+                    "type": "boolean"
+                },
+                "ruleset": {
+                    "description": "Ruleset is the version of the rules the game is played with (\"ctp2\" or \"ctp1\"). Defaults to \"ctp2\".",
+                    "enum": [
+                        "ctp2",
+                        "ctp1"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/game.Ruleset"
+                        }
+                    ]
                 }
             }
         },
-        "account.DeleteAccountArgs": {
-            "description": "Structure for the account deletion request payload.",
+        "game.DeleteGameArgs": {
+            "description": "Structure for the game deletion request payload.",
             "type": "object",
+            "required": [
+                "game_id"
+            ],
             "properties": {
-                "account_id": {
-                    "description": "The account ID for the account that will be deleted.",
+                "game_id": {
+                    "description": "The game ID for the game that will be deleted.",
                     "type": "integer"
+                }
+            }
+        },
+        "game.Game": {
+            "description": "Structure for representing a hosted multiplayer game.",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "CreatedAt is when the game was created.",
+                    "type": "string"
                 },
-                "session_id": {
-                    "description": "A valid session ID for the account (so we know they are signed in)",
+                "host_account_id": {
+                    "description": "HostAccountId is the account ID of the player hosting the game.",
+                    "type": "integer"
+                },
+                "id": {
+                    "description": "ID is the unique identifier for the game.",
+                    "type": "integer"
+                },
+                "map_size": {
+                    "description": "MapSize is the size of the generated map.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/game.MapSize"
+                        }
+                    ]
+                },
+                "max_players": {
+                    "description": "MaxPlayers is the maximum number of players who can join the game.",
                     "type": "integer"
+                },
+                "name": {
+                    "description": "Name is the name of the game.",
+                    "type": "string"
+                },
+                "password_protected": {
+                    "description": "PasswordProtected indicates whether a password is needed to join the game.",
+                    "type": "boolean"
+                },
+                "ruleset": {
+                    "description": "Ruleset is the version of the rules the game is played with.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/game.Ruleset"
+                        }
+                    ]
+                },
+                "status": {
+                    "description": "Status is the lifecycle state of the game.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/game.GameStatus"
+                        }
+                    ]
                 }
             }
         },
-        "account.ExperienceLevel": {
-            "type": "integer",
+        "game.GameStatus": {
+            "type": "string",
             "enum": [
-                0,
-                1,
-                2,
-                3,
-                4,
-                5
+                "open",
+                "in_progress",
+                "finished"
             ],
             "x-enum-varnames": [
-                "Beginner",
-                "Easy",
-                "Medium",
-                "Hard",
-                "Very_Hard",
-                "Impossible"
+                "GameStatusOpen",
+                "GameStatusInProgress",
+                "GameStatusFinished"
             ]
         },
-        "account.UpdateAccountArgs": {
-            "description": "Structure for the account update request payload.",
+        "game.MapSize": {
+            "type": "string",
+            "enum": [
+                "small",
+                "medium",
+                "large",
+                "gigantic"
+            ],
+            "x-enum-varnames": [
+                "MapSizeSmall",
+                "MapSizeMedium",
+                "MapSizeLarge",
+                "MapSizeGigantic"
+            ]
+        },
+        "game.Ruleset": {
+            "type": "string",
+            "enum": [
+                "ctp2",
+                "ctp1"
+            ],
+            "x-enum-varnames": [
+                "RulesetCTP2",
+                "RulesetCTP1"
+            ]
+        },
+        "health.Response": {
             "type": "object",
             "properties": {
-                "account": {
-                    "description": "The account to create.",
+                "status": {
+                    "type": "string",
+                    "example": "OK"
+                }
+            }
+        },
+        "httpapi.Code": {
+            "type": "string",
+            "enum": [
+                "validation_error",
+                "unauthorized",
+                "forbidden",
+                "not_found",
+                "method_not_allowed",
+                "conflict",
+                "too_many_requests",
+                "internal_error"
+            ],
+            "x-enum-varnames": [
+                "CodeValidation",
+                "CodeUnauthorized",
+                "CodeForbidden",
+                "CodeNotFound",
+                "CodeMethodNotAllowed",
+                "CodeConflict",
+                "CodeTooManyRequests",
+                "CodeInternal"
+            ]
+        },
+        "httpapi.ErrorBody": {
+            "description": "What went wrong with a request.",
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code is a machine-readable error code, such as \"not_found\" or \"invalid_credentials\".",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/account.AccountParam"
+                            "$ref": "#/definitions/httpapi.Code"
                         }
-                    ]
+                    ],
+                    "example": "validation_error"
                 },
-                "account_id": {
-                    "description": "The account ID for the account that will be updated.",
-                    "type": "integer"
+                "fields": {
+                    "description": "Fields lists what is wrong with each invalid field, for validation errors.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/httpapi.FieldError"
+                    }
+                },
+                "message": {
+                    "description": "Message explains what went wrong to a person.",
+                    "type": "string",
+                    "example": "account_id must be specified"
+                }
+            }
+        },
+        "httpapi.ErrorResponse": {
+            "description": "The body of every error response.",
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/httpapi.ErrorBody"
+                }
+            }
+        },
+        "httpapi.FieldError": {
+            "description": "What is wrong with one field of a request.",
+            "type": "object",
+            "properties": {
+                "field": {
+                    "description": "Field is the JSON path of the field, such as \"account.experience_level\".",
+                    "type": "string",
+                    "example": "max_players"
+                },
+                "message": {
+                    "description": "Message explains what is wrong with the field to a person.",
+                    "type": "string",
+                    "example": "max_players must be between 2 and 8"
+                }
+            }
+        },
+        "httpapi.MessageResponse": {
+            "description": "The body of a successful response which only carries a message.",
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Successfully updated account!"
+                }
+            }
+        },
+        "lobby.CreateLobbyArgs": {
+            "description": "Structure for the lobby creation request payload.",
+            "type": "object",
+            "required": [
+                "password"
+            ],
+            "properties": {
+                "lobby": {
+                    "description": "The lobby to create.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/lobby.Lobby"
+                        }
+                    ]
                 },
                 "password": {
-                    "description": "The password for the account to be created",
+                    "description": "The password for the lobby to be created. It is required to join the lobby while it is private.",
                     "type": "string"
-                },
-                "session_id": {
-                    "description": "A valid session ID for the account (so we know they are signed in)",
+                }
+            }
+        },
+        "lobby.DeleteLobbyArgs": {
+            "description": "Structure for the lobby deletion request payload.",
+            "type": "object",
+            "required": [
+                "lobby_id"
+            ],
+            "properties": {
+                "lobby_id": {
+                    "description": "The lobby ID for the lobby that will be deleted.",
                     "type": "integer"
                 }
             }
         },
-        "auth.Session": {
+        "lobby.Event": {
+            "description": "Structure for representing a realtime lobby event.",
             "type": "object",
             "properties": {
                 "account_id": {
+                    "description": "AccountId is the account the event is about, for member events, or the new owner, for owner_changed events.",
                     "type": "integer"
                 },
-                "created_at": {
+                "error": {
+                    "description": "Error explains why the client's chat message was rejected, for error events.",
                     "type": "string"
                 },
-                "expires_at": {
-                    "type": "string"
+                "error_code": {
+                    "description": "ErrorCode is the machine-readable code of the error, for error events.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/httpapi.Code"
+                        }
+                    ]
                 },
-                "id": {
+                "lobby": {
+                    "description": "Lobby holds the settings which changed, for lobby_updated events.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/lobby.LobbyParam"
+                        }
+                    ]
+                },
+                "lobby_id": {
+                    "description": "LobbyId is the lobby the event happened in.",
                     "type": "integer"
+                },
+                "message": {
+                    "description": "Message is the chat message which was sent, for chat_message events.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/lobby.Message"
+                        }
+                    ]
+                },
+                "muted": {
+                    "description": "Muted is the member's new mute state, for member_muted events.",
+                    "type": "boolean"
+                },
+                "ready": {
+                    "description": "Ready is the member's new ready state, for ready_changed events.",
+                    "type": "boolean"
+                },
+                "time": {
+                    "description": "Time is when the event happened.",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Type is what happened to the lobby.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/lobby.EventType"
+                        }
+                    ]
                 }
             }
         },
-        "game.CreateGameArgs": {
-            "description": "Structure for the game creation request payload.",
+        "lobby.EventType": {
+            "type": "string",
+            "enum": [
+                "member_joined",
+                "member_left",
+                "member_kicked",
+                "member_banned",
+                "ready_changed",
+                "lobby_updated",
+                "lobby_deleted",
+                "member_muted",
+                "chat_message",
+                "owner_changed",
+                "error"
+            ],
+            "x-enum-varnames": [
+                "EventMemberJoined",
+                "EventMemberLeft",
+                "EventMemberKicked",
+                "EventMemberBanned",
+                "EventReadyChanged",
+                "EventLobbyUpdated",
+                "EventLobbyDeleted",
+                "EventMemberMuted",
+                "EventChatMessage",
+                "EventOwnerChanged",
+                "EventError"
+            ]
+        },
+        "lobby.JoinLobbyArgs": {
+            "description": "Structure for the lobby join request payload.",
             "type": "object",
+            "required": [
+                "lobby_id"
+            ],
             "properties": {
+                "lobby_id": {
+                    "description": "The lobby ID for the lobby that will be joined.",
+                    "type": "integer"
+                },
                 "password": {
-                    "description": "Password is the password for the game.\nThis field is required if PasswordProtected is true.\nIt must be longer than 6 characters.",
+                    "description": "The lobby password. Only required for private lobbies.",
                     "type": "string"
-                },
-                "password_protected": {
-                    "description": "PasswordProtected indicates whether the game is password-protected.\nIf true, a password must be provided.",
-                    "type": "boolean"
                 }
             }
         },
-        "health.Response": {
+        "lobby.JoinLobbyBody": {
+            "description": "Structure for the v2 lobby join request payload.",
             "type": "object",
             "properties": {
-                "status": {
-                    "type": "string",
-                    "example": "OK"
+                "password": {
+                    "description": "The lobby password. Only required for private lobbies.",
+                    "type": "string"
                 }
             }
         },
-        "lobby.CreateLobbyArgs": {
-            "description": "Structure for the lobby creation request payload.",
+        "lobby.KickMemberArgs": {
+            "description": "Structure for the lobby kick request payload.",
             "type": "object",
+            "required": [
+                "account_id",
+                "lobby_id"
+            ],
             "properties": {
-                "lobby": {
-                    "description": "The lobby to create.",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/lobby.Lobby"
-                        }
-                    ]
+                "account_id": {
+                    "description": "The account ID of the member who will be kicked.",
+                    "type": "integer"
                 },
-                "password": {
-                    "description": "FIXME make passwords actually get stored with lobbies\nThe password for the lobby to be created",
-                    "type": "string"
+                "ban": {
+                    "description": "Whether the member should also be banned from rejoining the lobby.",
+                    "type": "boolean"
+                },
+                "lobby_id": {
+                    "description": "The lobby ID for the lobby the member will be kicked from.",
+                    "type": "integer"
                 }
             }
         },
-        "lobby.DeleteLobbyArgs": {
-            "description": "Structure for the lobby deletion request payload.",
+        "lobby.LeaveLobbyArgs": {
+            "description": "Structure for the lobby leave request payload.",
             "type": "object",
+            "required": [
+                "lobby_id"
+            ],
             "properties": {
                 "lobby_id": {
-                    "description": "The lobby ID for the lobby that will be deleted.",
+                    "description": "The lobby ID for the lobby that will be left.",
                     "type": "integer"
                 }
             }
         },
-        "lobby.GetLobbyArgs": {
-            "description": "Structure for the lobby acquisition request payload.",
+        "lobby.ListLobbiesResponse": {
+            "description": "Structure for the lobby browser response.",
             "type": "object",
             "properties": {
-                "lobby_id": {
-                    "description": "The lobby ID for the lobby that will be retrieved.",
-                    "type": "integer"
+                "lobbies": {
+                    "description": "Lobbies is the current page of lobbies.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/lobby.LobbySummary"
+                    }
+                },
+                "next_cursor": {
+                    "description": "NextCursor is passed as the cursor parameter to fetch the next page. It is empty on the last page.",
+                    "type": "string"
                 }
             }
         },
@@ -792,7 +5623,7 @@ const docTemplate = `{
                     "type": "boolean"
                 },
                 "is_muted": {
-                    "description": "IsMuted indicates if the lobby is muted.",
+                    "description": "IsMuted indicates if the lobby is muted; only the owner can chat in a muted lobby.",
                     "type": "boolean"
                 },
                 "is_public": {
@@ -804,11 +5635,11 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "owner_account_id": {
-                    "description": "OwnerAccountId is the account ID of the lobby owner.",
+                    "description": "OwnerAccountId is the account ID of the lobby owner. It is set by the server from the caller's session.",
                     "type": "string"
                 },
                 "owner_name": {
-                    "description": "OwnerName is the name of the lobby owner.",
+                    "description": "OwnerName is the account name of the lobby owner. It is set by the server.",
                     "type": "string"
                 }
             }
@@ -826,30 +5657,263 @@ const docTemplate = `{
                     "type": "boolean"
                 },
                 "is_muted": {
-                    "description": "IsMuted indicates if the lobby is muted.",
+                    "description": "IsMuted indicates if the lobby is muted; only the owner can chat in a muted lobby.",
+                    "type": "boolean"
+                },
+                "is_public": {
+                    "description": "IsPublic indicates if the lobby is public.",
+                    "type": "boolean"
+                },
+                "name": {
+                    "description": "Name is the name of the lobby.",
+                    "type": "string"
+                },
+                "owner_account_id": {
+                    "description": "OwnerAccountId is the account ID of the lobby owner. It cannot be updated directly; use /lobby/transfer_ownership.",
+                    "type": "string"
+                },
+                "owner_name": {
+                    "description": "OwnerName is the account name of the lobby owner. It cannot be updated directly.",
+                    "type": "string"
+                }
+            }
+        },
+        "lobby.LobbySummary": {
+            "description": "Structure for representing a player lobby in the lobby browser.",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "CreatedAt is when the lobby was created.",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID is the unique identifier for the lobby.",
+                    "type": "integer"
+                },
+                "is_closed": {
+                    "description": "IsClosed indicates if the lobby is closed.",
+                    "type": "boolean"
+                },
+                "is_muted": {
+                    "description": "IsMuted indicates if the lobby is muted; only the owner can chat in a muted lobby.",
                     "type": "boolean"
                 },
                 "is_public": {
                     "description": "IsPublic indicates if the lobby is public.",
                     "type": "boolean"
                 },
+                "max_members": {
+                    "description": "MaxMembers is the number of members the lobby has room for.",
+                    "type": "integer"
+                },
+                "member_count": {
+                    "description": "MemberCount is the number of members currently in the lobby.",
+                    "type": "integer"
+                },
                 "name": {
                     "description": "Name is the name of the lobby.",
                     "type": "string"
                 },
                 "owner_account_id": {
-                    "description": "OwnerAccountId is the account ID of the lobby owner.",
+                    "description": "OwnerAccountId is the account ID of the lobby owner. It is set by the server from the caller's session.",
                     "type": "string"
                 },
+                "owner_experience_level": {
+                    "description": "OwnerExperienceLevel is the experience level of the lobby owner.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/account.ExperienceLevel"
+                        }
+                    ]
+                },
                 "owner_name": {
-                    "description": "OwnerName is the name of the lobby owner.",
+                    "description": "OwnerName is the account name of the lobby owner. It is set by the server.",
+                    "type": "string"
+                }
+            }
+        },
+        "lobby.Member": {
+            "description": "Structure for representing a member of a player lobby.",
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "description": "AccountId is the ID of the member's account.",
+                    "type": "integer"
+                },
+                "experience_level": {
+                    "description": "ExperienceLevel is the member's experience level (0=beginner, 1=easy, 2=medium, 3=hard, 4=very hard, 5=impossible).",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/account.ExperienceLevel"
+                        }
+                    ]
+                },
+                "is_muted": {
+                    "description": "IsMuted indicates whether the lobby owner has stopped the member sending chat messages.",
+                    "type": "boolean"
+                },
+                "is_ready": {
+                    "description": "IsReady indicates whether the member is ready for the game to start.",
+                    "type": "boolean"
+                },
+                "joined_at": {
+                    "description": "JoinedAt is when the member joined the lobby.",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Name is the member's account name.",
+                    "type": "string"
+                }
+            }
+        },
+        "lobby.Message": {
+            "description": "Structure for representing a lobby chat message.",
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "description": "AccountId is the account which sent the message.",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "description": "CreatedAt is when the message was sent.",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID is the unique identifier for the message.",
+                    "type": "integer"
+                },
+                "lobby_id": {
+                    "description": "LobbyId is the lobby the message was sent in.",
+                    "type": "integer"
+                },
+                "message": {
+                    "description": "Message is the text of the message.",
+                    "type": "string"
+                }
+            }
+        },
+        "lobby.MuteMemberArgs": {
+            "description": "Structure for the lobby member mute request payload.",
+            "type": "object",
+            "required": [
+                "account_id",
+                "lobby_id",
+                "muted"
+            ],
+            "properties": {
+                "account_id": {
+                    "description": "The account ID of the member who will be muted or unmuted.",
+                    "type": "integer"
+                },
+                "lobby_id": {
+                    "description": "The lobby ID for the lobby the member is in.",
+                    "type": "integer"
+                },
+                "muted": {
+                    "description": "Whether the member should be muted.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "lobby.MuteMemberBody": {
+            "description": "Structure for the v2 member mute request payload.",
+            "type": "object",
+            "properties": {
+                "muted": {
+                    "description": "Whether the member should be muted.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "lobby.SendMessageArgs": {
+            "description": "Structure for the lobby chat message request payload.",
+            "type": "object",
+            "required": [
+                "lobby_id"
+            ],
+            "properties": {
+                "lobby_id": {
+                    "description": "The lobby ID for the lobby the message will be sent to.",
+                    "type": "integer"
+                },
+                "message": {
+                    "description": "The text of the message, at most 500 characters.",
+                    "type": "string"
+                }
+            }
+        },
+        "lobby.SendMessageBody": {
+            "description": "Structure for the v2 chat message request payload.",
+            "type": "object",
+            "properties": {
+                "message": {
+                    "description": "The text of the message, at most 500 characters.",
                     "type": "string"
                 }
             }
         },
+        "lobby.SetReadyArgs": {
+            "description": "Structure for the lobby ready state request payload.",
+            "type": "object",
+            "required": [
+                "lobby_id",
+                "ready"
+            ],
+            "properties": {
+                "lobby_id": {
+                    "description": "The lobby ID for the lobby the caller is a member of.",
+                    "type": "integer"
+                },
+                "ready": {
+                    "description": "Whether the caller is ready for the game to start.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "lobby.SetReadyBody": {
+            "description": "Structure for the v2 ready state request payload.",
+            "type": "object",
+            "properties": {
+                "ready": {
+                    "description": "Whether the caller is ready for the game to start.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "lobby.TransferOwnershipArgs": {
+            "description": "Structure for the lobby ownership transfer request payload.",
+            "type": "object",
+            "required": [
+                "account_id",
+                "lobby_id"
+            ],
+            "properties": {
+                "account_id": {
+                    "description": "The account ID of the member who will become the owner.",
+                    "type": "integer"
+                },
+                "lobby_id": {
+                    "description": "The lobby ID for the lobby whose ownership will be transferred.",
+                    "type": "integer"
+                }
+            }
+        },
+        "lobby.TransferOwnershipBody": {
+            "description": "Structure for the v2 ownership transfer request payload.",
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "description": "The account ID of the member who will become the owner.",
+                    "type": "integer"
+                }
+            }
+        },
         "lobby.UpdateLobbyArgs": {
             "description": "Structure for the lobby update request payload.",
             "type": "object",
+            "required": [
+                "lobby_id"
+            ],
             "properties": {
                 "lobby": {
                     "description": "The lobby to update.",
@@ -862,6 +5926,28 @@ const docTemplate = `{
                 "lobby_id": {
                     "description": "The lobby ID for the lobby that will be updated.",
                     "type": "integer"
+                },
+                "password": {
+                    "description": "A new password for the lobby. Only the lobby owner can change it.",
+                    "type": "string"
+                }
+            }
+        },
+        "lobby.UpdateLobbyBody": {
+            "description": "Structure for the v2 lobby update request payload.",
+            "type": "object",
+            "properties": {
+                "lobby": {
+                    "description": "The fields of the lobby to change. Fields which are left out keep their current value.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/lobby.LobbyParam"
+                        }
+                    ]
+                },
+                "password": {
+                    "description": "A new password for the lobby.",
+                    "type": "string"
                 }
             }
         }
@@ -25,10 +25,10 @@ type Account struct {
 	Location string `json:"location"`
 
 	// Email is the email address of the player.
-	Email string `json:"email"`
+	Email string `json:"email" validate:"required,email"`
 
 	// ExperienceLevel represents the player's experience level (0=beginner, 1=easy, 2=medium, 3=hard, 4=very hard, 5=impossible)
-	ExperienceLevel ExperienceLevel `json:"experience_level"`
+	ExperienceLevel ExperienceLevel `json:"experience_level" validate:"min=0,max=5"`
 }
 
 // AccountParam represents a player account with non-required fields.
@@ -45,8 +45,8 @@ type AccountParam struct {
 	Location *string `json:"location,omitempty"`
 
 	// Email is the email address of the player.
-	Email *string `json:"email,omitempty"`
+	Email *string `json:"email,omitempty" validate:"email"`
 
 	// ExperienceLevel represents the player's experience level (0=beginner, 1=easy, 2=medium, 3=hard, 4=very hard, 5=impossible)
-	ExperienceLevel *ExperienceLevel `json:"experience_level,omitempty"`
+	ExperienceLevel *ExperienceLevel `json:"experience_level,omitempty" validate:"min=0,max=5"`
 }
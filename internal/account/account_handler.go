@@ -3,39 +3,126 @@ package account
 import (
 	"net/http"
 
-	"github.com/jmoiron/sqlx"
-
 	auth "github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+	"github.com/justinfarrelldev/open-ctp-server/internal/mail"
 )
 
-func CreateAccountHandler(w http.ResponseWriter, r *http.Request, db *sqlx.DB, store *auth.SessionStore) {
-	if _, err := CreateAccount(w, r, db, store); err != nil {
-		// Handle the error, e.g., log it and send an appropriate response to the client
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+func CreateAccountHandler(w http.ResponseWriter, r *http.Request, accounts AccountRepository, store *auth.SessionStore, sender mail.Sender) {
+	if _, err := CreateAccount(w, r, accounts, store, sender); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func GetAccountHandler(w http.ResponseWriter, r *http.Request, accounts AccountRepository, store *auth.SessionStore) {
+	if err := GetAccount(w, r, accounts, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func UpdateAccountHandler(w http.ResponseWriter, r *http.Request, accounts AccountRepository, store *auth.SessionStore, lockout *auth.Lockout) {
+	if err := UpdateAccount(w, r, accounts, store, lockout); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func DeleteAccountHandler(w http.ResponseWriter, r *http.Request, accounts AccountRepository, store *auth.SessionStore) {
+	if err := DeleteAccount(w, r, accounts, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func ChangePasswordHandler(w http.ResponseWriter, r *http.Request, accounts AccountRepository, store *auth.SessionStore, lockout *auth.Lockout) {
+	if err := ChangePassword(w, r, accounts, store, lockout); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func VerifyEmailHandler(w http.ResponseWriter, r *http.Request, accounts AccountRepository) {
+	if err := VerifyEmail(w, r, accounts); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func ResendVerificationHandler(w http.ResponseWriter, r *http.Request, accounts AccountRepository, store *auth.SessionStore, sender mail.Sender) {
+	if err := ResendVerification(w, r, accounts, store, sender); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func GetProfileHandler(w http.ResponseWriter, r *http.Request, accounts AccountRepository) {
+	if err := GetProfile(w, r, accounts); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func MyAccountHandler(w http.ResponseWriter, r *http.Request, accounts AccountRepository, store *auth.SessionStore) {
+	if err := MyAccount(w, r, accounts, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func UpdatePrivacyHandler(w http.ResponseWriter, r *http.Request, accounts AccountRepository, store *auth.SessionStore) {
+	if err := UpdatePrivacy(w, r, accounts, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func ChangePasswordV2Handler(w http.ResponseWriter, r *http.Request, accounts AccountRepository, store *auth.SessionStore, lockout *auth.Lockout) {
+	if err := ChangePasswordV2(w, r, accounts, store, lockout); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func DeleteAccountV2Handler(w http.ResponseWriter, r *http.Request, accounts AccountRepository, store *auth.SessionStore) {
+	if err := DeleteAccountV2(w, r, accounts, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func GetAccountV2Handler(w http.ResponseWriter, r *http.Request, accounts AccountRepository, store *auth.SessionStore) {
+	if err := GetAccountV2(w, r, accounts, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func GetProfileV2Handler(w http.ResponseWriter, r *http.Request, accounts AccountRepository) {
+	if err := GetProfileV2(w, r, accounts); err != nil {
+		httpapi.WriteError(w, err)
 		return
 	}
 }
 
-func GetAccountHandler(w http.ResponseWriter, r *http.Request, db *sqlx.DB, store *auth.SessionStore) {
-	if err := GetAccount(w, r, db, store); err != nil {
-		// Handle the error, e.g., log it and send an appropriate response to the client
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+func ResendVerificationV2Handler(w http.ResponseWriter, r *http.Request, accounts AccountRepository, store *auth.SessionStore, sender mail.Sender) {
+	if err := ResendVerificationV2(w, r, accounts, store, sender); err != nil {
+		httpapi.WriteError(w, err)
 		return
 	}
 }
 
-func UpdateAccountHandler(w http.ResponseWriter, r *http.Request, db *sqlx.DB, store *auth.SessionStore) {
-	if err := UpdateAccount(w, r, db, store); err != nil {
-		// Handle the error, e.g., log it and send an appropriate response to the client
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+func UpdateAccountV2Handler(w http.ResponseWriter, r *http.Request, accounts AccountRepository, store *auth.SessionStore, lockout *auth.Lockout) {
+	if err := UpdateAccountV2(w, r, accounts, store, lockout); err != nil {
+		httpapi.WriteError(w, err)
 		return
 	}
 }
 
-func DeleteAccountHandler(w http.ResponseWriter, r *http.Request, db *sqlx.DB, store *auth.SessionStore) {
-	if err := DeleteAccount(w, r, db, store); err != nil {
-		// Handle the error, e.g., log it and send an appropriate response to the client
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+func UpdatePrivacyV2Handler(w http.ResponseWriter, r *http.Request, accounts AccountRepository, store *auth.SessionStore) {
+	if err := UpdatePrivacyV2(w, r, accounts, store); err != nil {
+		httpapi.WriteError(w, err)
 		return
 	}
 }
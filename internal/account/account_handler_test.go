@@ -10,6 +10,9 @@ import (
 	"github.com/jmoiron/sqlx"
 
 	auth "github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi/httpapitest"
+	"github.com/justinfarrelldev/open-ctp-server/internal/mail"
 )
 
 // func TestCreateAccountHandler_Success(t *testing.T) {
@@ -65,18 +68,18 @@ func TestCreateAccountHandler_InvalidMethod(t *testing.T) {
 	sqlxDB := sqlx.NewDb(db, "sqlmock")
 	store := auth.NewSessionStore(sqlxDB)
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		CreateAccountHandler(w, r, sqlxDB, store)
+		CreateAccountHandler(w, r, NewPostgresAccountRepository(sqlxDB), store, mail.LogSender{})
 	})
 
 	handler.ServeHTTP(rr, req)
 
-	if status := rr.Code; status != http.StatusBadRequest {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+	if status := rr.Code; status != http.StatusMethodNotAllowed {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusMethodNotAllowed)
 	}
 
 	expectedError := "invalid request; request must be a POST request"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
 
@@ -96,18 +99,18 @@ func TestCreateAccountHandler_DecodeError(t *testing.T) {
 	sqlxDB := sqlx.NewDb(db, "sqlmock")
 	store := auth.NewSessionStore(sqlxDB)
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		CreateAccountHandler(w, r, sqlxDB, store)
+		CreateAccountHandler(w, r, NewPostgresAccountRepository(sqlxDB), store, mail.LogSender{})
 	})
 
 	handler.ServeHTTP(rr, req)
 
-	if status := rr.Code; status != http.StatusInternalServerError {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
 	}
 
-	expectedError := "an error occurred while decoding the request body:json: cannot unmarshal string into Go struct field Account.account.experience_level of type account.ExperienceLevel"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	// The message comes from encoding/json, whose wording changes between Go versions
+	if code := httpapitest.Code(rr); code != httpapi.CodeValidation {
+		t.Errorf("handler returned unexpected code: got %v want %v", code, httpapi.CodeValidation)
 	}
 }
 
@@ -127,7 +130,7 @@ func TestCreateAccountHandler_EmailRequired(t *testing.T) {
 	sqlxDB := sqlx.NewDb(db, "sqlmock")
 	store := auth.NewSessionStore(sqlxDB)
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		CreateAccountHandler(w, r, sqlxDB, store)
+		CreateAccountHandler(w, r, NewPostgresAccountRepository(sqlxDB), store, mail.LogSender{})
 	})
 
 	handler.ServeHTTP(rr, req)
@@ -136,8 +139,8 @@ func TestCreateAccountHandler_EmailRequired(t *testing.T) {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
 	}
 
-	expectedError := "an error occurred while checking whether the email for the account is valid: mail: no address"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	expectedError := "account.email must be specified"
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
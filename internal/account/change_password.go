@@ -0,0 +1,160 @@
+package account
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	auth "github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+)
+
+// ChangePasswordArgs represents the expected structure of the request body for changing an account's password.
+//
+// @Description Structure for the password change request payload.
+type ChangePasswordArgs struct {
+	// The account ID for the account whose password will be changed.
+	AccountId *int64 `json:"account_id" validate:"required"`
+	// The account's current password.
+	CurrentPassword string `json:"current_password" validate:"required"`
+	// The password to replace it with.
+	NewPassword string `json:"new_password" validate:"required,password"`
+}
+
+// ChangePasswordBody represents the expected structure of the request body for changing a password through
+// /v2, which takes the account ID from the path.
+//
+// @Description Structure for the v2 password change request payload.
+type ChangePasswordBody struct {
+	// The account's current password.
+	CurrentPassword string `json:"current_password"`
+	// The password to replace it with.
+	NewPassword string `json:"new_password"`
+}
+
+const ERROR_CURRENT_PASSWORD_INCORRECT = "the current password is incorrect"
+
+// CodeCurrentPasswordIncorrect is the code of the error returned when the current password is wrong.
+const CodeCurrentPasswordIncorrect httpapi.Code = "current_password_incorrect"
+
+// ChangePassword sets a new password for an account.
+//
+// @Summary Change an account's password
+// @Description This endpoint replaces an account's password after checking the current one. Every other session of the account is logged out; the session making the request stays valid.
+// @Tags account
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Param body body ChangePasswordArgs true "password change request body"
+// @Success 200 {object} httpapi.MessageResponse "Successfully changed password!"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 429 {object} httpapi.ErrorResponse "Too Many Requests"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /account/change_password [post]
+func ChangePassword(w http.ResponseWriter, r *http.Request, accounts AccountRepository, store *auth.SessionStore, lockout *auth.Lockout) error {
+
+	if r.Method != http.MethodPost {
+		return httpapi.MethodNotAllowed(http.MethodPost)
+	}
+
+	args := ChangePasswordArgs{}
+	err := httpapi.DecodeJSON(r, &args)
+	if err != nil {
+		return err
+	}
+
+	return changePassword(w, r, args, accounts, store, lockout)
+}
+
+// ChangePasswordV2 sets a new password for the account with the ID in the path.
+//
+// @Summary Change an account's password
+// @Description This endpoint replaces an account's password after checking the current one. Every other session of the account is logged out; the session making the request stays valid.
+// @Tags account
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Param id path int true "account ID"
+// @Param body body ChangePasswordBody true "password change request body"
+// @Success 200 {object} httpapi.MessageResponse "Successfully changed password!"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 429 {object} httpapi.ErrorResponse "Too Many Requests"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /v2/accounts/{id}/password [put]
+func ChangePasswordV2(w http.ResponseWriter, r *http.Request, accounts AccountRepository, store *auth.SessionStore, lockout *auth.Lockout) error {
+	accountId, err := httpapi.PathID(r, "id")
+	if err != nil {
+		return err
+	}
+
+	body := ChangePasswordBody{}
+	if err := httpapi.DecodeJSON(r, &body); err != nil {
+		return err
+	}
+
+	return changePassword(w, r, ChangePasswordArgs{AccountId: &accountId, CurrentPassword: body.CurrentPassword, NewPassword: body.NewPassword}, accounts, store, lockout)
+}
+
+// changePassword replaces the password of the account in args after checking the current one.
+func changePassword(w http.ResponseWriter, r *http.Request, args ChangePasswordArgs, accounts AccountRepository, store *auth.SessionStore, lockout *auth.Lockout) error {
+	if err := httpapi.Validate(&args); err != nil {
+		return err
+	}
+
+	session, err := store.Authenticate(r, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := auth.RequireAccount(session, *args.AccountId); err != nil {
+		return err
+	}
+
+	if err := checkCurrentPassword(r, accounts, lockout, *args.AccountId, args.CurrentPassword); err != nil {
+		return err
+	}
+
+	hash, err := auth.HashPassword(args.NewPassword)
+	if err != nil {
+		log.Println("error hashing a password: ", err.Error())
+		return errors.New("an error occurred while saving the password. Please try again later")
+	}
+
+	if err := accounts.UpdateCredentials(*args.AccountId, hash); err != nil {
+		log.Println("error saving a password: ", err.Error())
+		return errors.New("an error occurred while saving the password. Please try again at a later time")
+	}
+
+	// Anyone else who knew the old password may still be logged in
+	if err := store.DeleteAccountSessions(*args.AccountId, session); err != nil {
+		return errors.New("the password was changed, but an error occurred while logging out the account's other sessions")
+	}
+
+	httpapi.WriteMessage(w, http.StatusOK, "Successfully changed password!")
+	return nil
+}
+
+// checkCurrentPassword checks the password the caller gave for the account. A wrong password, or an
+// account without one, is reported as ERROR_CURRENT_PASSWORD_INCORRECT.
+func checkCurrentPassword(r *http.Request, accounts AccountRepository, lockout *auth.Lockout, accountId int64, password string) error {
+	credentials, err := accounts.CredentialsByAccountID(accountId)
+	if errors.Is(err, auth.ErrCredentialsNotFound) {
+		return httpapi.Unauthorized(ERROR_CURRENT_PASSWORD_INCORRECT).WithCode(CodeCurrentPasswordIncorrect)
+	}
+	if err != nil {
+		return fmt.Errorf("error retrieving account credentials: %v", err)
+	}
+
+	if err := lockout.ComparePassword(r, credentials, password); err != nil {
+		if errors.Is(err, auth.ErrPasswordMismatch) {
+			return httpapi.Unauthorized(ERROR_CURRENT_PASSWORD_INCORRECT).WithCode(CodeCurrentPasswordIncorrect)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package account
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	auth "github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi/httpapitest"
+)
+
+func TestChangePassword(t *testing.T) {
+	accounts := NewMemoryAccountRepository()
+	store := auth.NewSessionStoreWithRepository(auth.NewMemorySessionRepository())
+	lockout := auth.NewLockout(auth.NewMemoryLoginAttemptRepository())
+
+	hash, err := auth.HashPassword("password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, err := accounts.RegisterAccount(&Account{Name: "Player", Email: "player@example.com"}, hash, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := store.CreateSession(current.AccountID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "wrong current password",
+			body:           `{"account_id": 1, "current_password": "wrong-password", "new_password": "new-password"}`,
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   ERROR_CURRENT_PASSWORD_INCORRECT,
+		},
+		{
+			name:           "new password too short",
+			body:           `{"account_id": 1, "current_password": "password123", "new_password": "short"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "new_password must be at least 6 characters",
+		},
+		{
+			name:           "another account",
+			body:           `{"account_id": 2, "current_password": "password123", "new_password": "new-password"}`,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "success",
+			body:           `{"account_id": 1, "current_password": "password123", "new_password": "new-password"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "Successfully changed password!",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/account/change_password", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+current.Token)
+			rr := httptest.NewRecorder()
+
+			ChangePasswordHandler(rr, req, accounts, store, lockout)
+
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v (%s)", rr.Code, tt.expectedStatus, rr.Body.String())
+			}
+			if tt.expectedBody != "" && httpapitest.Message(rr) != tt.expectedBody {
+				t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), tt.expectedBody)
+			}
+		})
+	}
+
+	credentials, err := accounts.CredentialsByAccountID(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := auth.ComparePassword(credentials.Hash, credentials.Salt, "new-password"); err != nil {
+		t.Errorf("expected the new password to be stored: %v", err)
+	}
+
+	if session, _ := store.GetSessionByToken(current.Token); session == nil {
+		t.Error("expected the session which changed the password to stay valid")
+	}
+	if session, _ := store.GetSessionByToken(other.Token); session != nil {
+		t.Error("expected the account's other sessions to be logged out")
+	}
+}
+
+func TestChangePassword_LockedOut(t *testing.T) {
+	accounts := NewMemoryAccountRepository()
+	store := auth.NewSessionStoreWithRepository(auth.NewMemorySessionRepository())
+	lockout := auth.NewLockout(auth.NewMemoryLoginAttemptRepository())
+	lockout.AccountThreshold = 2
+
+	hash, err := auth.HashPassword("password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, err := accounts.RegisterAccount(&Account{Name: "Player", Email: "player@example.com"}, hash, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changePassword := func(currentPassword string) *httptest.ResponseRecorder {
+		body := `{"account_id": 1, "current_password": "` + currentPassword + `", "new_password": "new-password"}`
+		req := httptest.NewRequest(http.MethodPost, "/account/change_password", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+session.Token)
+		rr := httptest.NewRecorder()
+		ChangePasswordHandler(rr, req, accounts, store, lockout)
+		return rr
+	}
+
+	for i := 0; i < 2; i++ {
+		if rr := changePassword("wrong-password"); rr.Code != http.StatusUnauthorized {
+			t.Fatalf("expected a wrong password to be refused, got %d: %s", rr.Code, rr.Body.String())
+		}
+	}
+
+	rr := changePassword("password123")
+	if rr.Code != http.StatusTooManyRequests || rr.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected the account to be locked out, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if err := lockout.Unlock(1); err != nil {
+		t.Fatal(err)
+	}
+
+	if rr := changePassword("password123"); rr.Code != http.StatusOK {
+		t.Errorf("expected the unlocked account to change its password, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
@@ -1,15 +1,13 @@
 package account
 
 import (
-	"encoding/json"
 	"errors"
-	"fmt"
 	"log"
 	"net/http"
-	"net/mail"
 
-	"github.com/jmoiron/sqlx"
 	auth "github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+	"github.com/justinfarrelldev/open-ctp-server/internal/mail"
 )
 
 // CreateAccountArgs represents the expected structure of the request body for creating an account for use within the server.
@@ -19,141 +17,85 @@ type CreateAccountArgs struct {
 	// The account to create.
 	Account Account `json:"account"`
 	// The password for the account to be created
-	Password string `json:"password"`
+	Password string `json:"password" validate:"required,password"`
 }
 
-const ERROR_PASSWORD_TOO_SHORT = "password must be longer than 6 characters"
-const ERROR_PASSWORD_REQUIRED_BUT_NO_PASSWORD = "password is required"
+const ERROR_EMAIL_IN_USE = "an account already uses the provided email"
 
-func isEmailValid(email string, db *sqlx.DB) (bool, error) {
-	_, err := mail.ParseAddress(email)
-	if err != nil {
-		return false, errors.New("an error occurred while checking whether the email for the account is valid: " + err.Error())
-	}
-
-	result, err := db.Query("SELECT * from account WHERE email = $1", email)
+// CodeEmailInUse is the code of the error returned when an account already uses the email.
+const CodeEmailInUse httpapi.Code = "email_in_use"
 
+func isEmailAvailable(email string, accounts AccountRepository) (bool, error) {
+	exists, err := accounts.EmailExists(email)
 	if err != nil {
 		return false, errors.New("an error occurred while checking whether the email for the account is unique: " + err.Error())
 	}
 
-	defer result.Close()
-
-	if result.Next() {
-		// If result.Next() returns true, there is at least one row, so the email is not unique.
-		return false, nil
-	}
-
-	// If no rows were found, the email is unique (or not in the database).
-	return true, nil
+	return !exists, nil
 }
 
 // CreateAccount handles the creation of a new account.
 //
 // @Summary Create a new account
-// @Description This endpoint creates a new multiplayer account, protected by a password.
+// @Description This endpoint creates a new multiplayer account, protected by a password. The account starts unverified and a verification token is emailed to it.
 // @Tags account
 // @Accept json
 // @Produce json
 // @Param body body CreateAccountArgs true "account creation request body"
-// @Success 201 {integer} int64 "Account successfully created"
-// @Failure 400 {object} error "Bad Request"
-// @Failure 403 {object} error "Forbidden"
-// @Failure 500 {object} error "Internal Server Error"
+// @Success 201 {object} auth.Session "Account successfully created; the body contains a session for the new account"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 409 {object} httpapi.ErrorResponse "Email already in use"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
 // @Router /account/create_account [post]
-func CreateAccount(w http.ResponseWriter, r *http.Request, db *sqlx.DB, store *auth.SessionStore) (*int64, error) {
+// @Router /v2/accounts [post]
+func CreateAccount(w http.ResponseWriter, r *http.Request, accounts AccountRepository, store *auth.SessionStore, sender mail.Sender) (*auth.Session, error) {
 
 	if r.Method != "POST" {
-		w.WriteHeader(http.StatusBadRequest)
-
-		return nil, errors.New("invalid request; request must be a POST request")
+		return nil, httpapi.MethodNotAllowed(http.MethodPost)
 	}
 
-	decoder := json.NewDecoder(r.Body)
-	decoder.DisallowUnknownFields()
-
 	account := CreateAccountArgs{}
-	err := decoder.Decode(&account)
-
+	err := httpapi.DecodeJSON(r, &account)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-
-		return nil, errors.New("an error occurred while decoding the request body:" + err.Error())
-	}
-
-	if account.Account.ExperienceLevel < 0 || account.Account.ExperienceLevel > 5 {
-		w.WriteHeader(http.StatusBadRequest)
-
-		return nil, errors.New("experience_level must be between 0 and 5 (0=easy, 5=impossible)")
-	}
-
-	if account.Password == "" {
-		w.WriteHeader(http.StatusBadRequest)
-
-		return nil, errors.New(ERROR_PASSWORD_REQUIRED_BUT_NO_PASSWORD)
+		return nil, err
 	}
 
-	if len(account.Password) < 6 {
-		w.WriteHeader(http.StatusBadRequest)
-
-		return nil, errors.New(ERROR_PASSWORD_TOO_SHORT)
+	if err := httpapi.Validate(&account); err != nil {
+		return nil, err
 	}
 
-	isValidEmail, err := isEmailValid(account.Account.Email, db)
+	isAvailable, err := isEmailAvailable(account.Account.Email, accounts)
 
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
 		return nil, err
 	}
 
-	if !isValidEmail {
-		w.WriteHeader(http.StatusBadRequest)
-		return nil, errors.New("the provided email is not valid")
+	if !isAvailable {
+		return nil, httpapi.Conflict(ERROR_EMAIL_IN_USE).WithCode(CodeEmailInUse)
 	}
 
-	hashSalt, err := auth.Hasher.GenerateHash([]byte(account.Password), nil)
+	hash, err := auth.HashPassword(account.Password)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
 		log.Println("error saving a password: ", err.Error())
 		return nil, errors.New("an error occurred while saving the password. Please try again later")
 	}
 
-	accountId, err := storeAccount(&account.Account, db)
+	// The account, its password and its first session are stored together, so a failure
+	// part of the way through does not leave the email taken
+	session, err := accounts.RegisterAccount(&account.Account, hash, store)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
 		log.Println("error saving an account: ", err.Error())
 		// Different from the one above for debugging purposes
 		return nil, errors.New("an error occurred while creating the account. Please try again at a later time")
 	}
 
-	err = auth.StoreHashAndSalt(hashSalt, account.Account.Email, db)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-
-		log.Println("error saving a password: ", err.Error())
-		// Different from the one above for debugging purposes
-		return nil, errors.New("an error occurred while saving the password. Please try again at a later time")
-	}
-
-	session, err := store.CreateSession(*accountId)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-
-		log.Println("error creating a session: ", err.Error())
-		return nil, errors.New("an error occurred while creating a session. Please try again at a later time")
-	}
-
-	w.WriteHeader(http.StatusCreated)
-	fmt.Println("Successfully created account!")
-	return &session.ID, nil
-}
-
-func storeAccount(account *Account, db *sqlx.DB) (accountId *int, err error) {
-	var id int
-	err = db.QueryRow("INSERT INTO account (name, info, location, email, experience_level) VALUES ($1, $2, $3, $4, $5) RETURNING id", account.Name, account.Info, account.Location, account.Email, account.ExperienceLevel).Scan(&id)
-	if err != nil {
-		return nil, errors.New("an error occurred while inserting an account into the database: " + err.Error())
+	// The account is usable without a verified email, and the email can be sent again, so a failure here
+	// should not fail the request
+	if err := sendVerificationEmail(accounts, sender, int64(session.AccountID), account.Account.Email); err != nil {
+		log.Println("error sending a verification email: ", err.Error())
 	}
 
-	return &id, nil
+	httpapi.WriteJSON(w, http.StatusCreated, session)
+	return session, nil
 }
@@ -3,6 +3,7 @@ package account
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -10,6 +11,8 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
 	auth "github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi/httpapitest"
+	"github.com/justinfarrelldev/open-ctp-server/internal/mail"
 )
 
 func TestCreateAccount_InvalidMethod(t *testing.T) {
@@ -19,18 +22,18 @@ func TestCreateAccount_InvalidMethod(t *testing.T) {
 	}
 
 	rr := httptest.NewRecorder()
-	var mockDB *sqlx.DB = nil
+	var mockDB AccountRepository = nil
 	var mockStore *auth.SessionStore = nil
 
-	_, err = CreateAccount(rr, req, mockDB, mockStore)
+	_, err = CreateAccount(rr, req, mockDB, mockStore, mail.LogSender{})
 
 	expectedError := "invalid request; request must be a POST request"
 	if err == nil || err.Error() != expectedError {
 		t.Errorf("CreateAccount() error = %v, wantErr %v", err, expectedError)
 	}
 
-	if status := rr.Code; status != http.StatusBadRequest {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+	if status := httpapitest.Status(err); status != http.StatusMethodNotAllowed {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusMethodNotAllowed)
 	}
 }
 
@@ -42,18 +45,18 @@ func TestCreateAccount_DecodeError(t *testing.T) {
 	}
 
 	rr := httptest.NewRecorder()
-	var mockDB *sqlx.DB = nil
+	var mockDB AccountRepository = nil
 	var mockStore *auth.SessionStore = nil
 
-	_, err = CreateAccount(rr, req, mockDB, mockStore)
+	_, err = CreateAccount(rr, req, mockDB, mockStore, mail.LogSender{})
 
-	expectedError := "an error occurred while decoding the request body:json: cannot unmarshal number into Go struct field CreateAccountArgs.password of type string"
+	expectedError := "an error occurred while decoding the request body: json: cannot unmarshal number into Go struct field CreateAccountArgs.password of type string"
 	if err == nil || err.Error() != expectedError {
 		t.Errorf("CreateAccount() error = %v, wantErr %v", err, expectedError)
 	}
 
-	if status := rr.Code; status != http.StatusInternalServerError {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+	if status := httpapitest.Status(err); status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
 	}
 }
 
@@ -76,17 +79,17 @@ func TestCreateAccount_PasswordTooShort(t *testing.T) {
 	}
 
 	rr := httptest.NewRecorder()
-	var mockDB *sqlx.DB = nil
+	var mockDB AccountRepository = nil
 	var mockStore *auth.SessionStore = nil
 
-	_, err = CreateAccount(rr, req, mockDB, mockStore)
+	_, err = CreateAccount(rr, req, mockDB, mockStore, mail.LogSender{})
 
-	expectedError := ERROR_PASSWORD_TOO_SHORT
+	expectedError := "password must be at least 6 characters"
 	if err == nil || err.Error() != expectedError {
 		t.Errorf("CreateAccount() error = %v, wantErr %v", err, expectedError)
 	}
 
-	if status := rr.Code; status != http.StatusBadRequest {
+	if status := httpapitest.Status(err); status != http.StatusBadRequest {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
 	}
 }
@@ -109,17 +112,17 @@ func TestCreateAccount_PasswordRequired(t *testing.T) {
 	}
 
 	rr := httptest.NewRecorder()
-	var mockDB *sqlx.DB = nil
+	var mockDB AccountRepository = nil
 	var mockStore *auth.SessionStore = nil
 
-	_, err = CreateAccount(rr, req, mockDB, mockStore)
+	_, err = CreateAccount(rr, req, mockDB, mockStore, mail.LogSender{})
 
-	expectedError := ERROR_PASSWORD_REQUIRED_BUT_NO_PASSWORD
+	expectedError := "password must be specified"
 	if err == nil || err.Error() != expectedError {
 		t.Errorf("CreateAccount() error = %v, wantErr %v", err, expectedError)
 	}
 
-	if status := rr.Code; status != http.StatusBadRequest {
+	if status := httpapitest.Status(err); status != http.StatusBadRequest {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
 	}
 }
@@ -155,23 +158,41 @@ func TestCreateAccount_Success(t *testing.T) {
 		WithArgs(account.Account.Email).
 		WillReturnRows(sqlmock.NewRows(nil))
 
+	mock.ExpectBegin()
+
 	mock.ExpectQuery("INSERT INTO account \\(name, info, location, email, experience_level\\) VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5\\)").
 		WithArgs(account.Account.Name, account.Account.Info, account.Account.Location, account.Account.Email, account.Account.ExperienceLevel).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 
-	mock.ExpectQuery("INSERT INTO passwords \\(account_email, hash, salt\\) VALUES \\(\\$1, \\$2, \\$3\\)").
-		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+	mock.ExpectQuery("INSERT INTO passwords \\(account_email, hash\\) VALUES \\(\\$1, \\$2\\)").
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg()).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 
-	mock.ExpectExec("INSERT INTO sessions \\(id, account_id, created_at, expires_at\\) VALUES \\(\\?, \\?, \\?, \\?\\)").
-		WithArgs(sqlmock.AnyArg(), 1, sqlmock.AnyArg(), sqlmock.AnyArg()).
+	mock.ExpectExec("INSERT INTO sessions \\(id, token_hash, account_id, created_at, expires_at\\) VALUES \\(\\?, \\?, \\?, \\?, \\?\\)").
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 1, sqlmock.AnyArg(), sqlmock.AnyArg()).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
+	mock.ExpectCommit()
+
+	mock.ExpectBegin()
+
+	mock.ExpectExec("DELETE FROM email_verifications WHERE account_id = \\$1").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectExec("INSERT INTO email_verifications \\(token_hash, account_id, email, created_at, expires_at\\)").
+		WithArgs(sqlmock.AnyArg(), 1, account.Account.Email, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	mock.ExpectCommit()
+
 	mockStore := &auth.SessionStore{
 		DB: sqlxDB,
 	}
 
-	_, err = CreateAccount(rr, req, sqlxDB, mockStore)
+	sender := &recordingSender{}
+
+	session, err := CreateAccount(rr, req, NewPostgresAccountRepository(sqlxDB), mockStore, sender)
 	if err != nil {
 		t.Errorf("CreateAccount() error = %v, wantErr %v", err, nil)
 	}
@@ -180,6 +201,68 @@ func TestCreateAccount_Success(t *testing.T) {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusCreated)
 	}
 
+	if session == nil || session.Token == "" {
+		t.Errorf("expected a session token to be issued, got %v", session)
+	}
+
+	if len(sender.messages) != 1 || sender.messages[0].To != account.Account.Email {
+		t.Errorf("expected a verification email to the account, got %+v", sender.messages)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCreateAccount_RollsBackWhenPasswordFails(t *testing.T) {
+	account := CreateAccountArgs{
+		Account: Account{
+			Name:  "Test User",
+			Email: "test@example.com",
+		},
+		Password: "password123",
+	}
+
+	jsonBody, _ := json.Marshal(account)
+	req, err := http.NewRequest("POST", "/account/create_account", bytes.NewBuffer(jsonBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	mock.ExpectQuery("SELECT \\* from account WHERE email = \\$1").
+		WithArgs(account.Account.Email).
+		WillReturnRows(sqlmock.NewRows(nil))
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("INSERT INTO account \\(name, info, location, email, experience_level\\) VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	mock.ExpectQuery("INSERT INTO passwords \\(account_email, hash\\) VALUES \\(\\$1, \\$2\\)").
+		WillReturnError(errors.New("connection reset"))
+
+	// The account must not be kept without its password
+	mock.ExpectRollback()
+
+	_, err = CreateAccount(rr, req, NewPostgresAccountRepository(sqlxDB), &auth.SessionStore{DB: sqlxDB}, mail.LogSender{})
+	if err == nil {
+		t.Error("expected an error when the password could not be stored")
+	}
+
+	if status := httpapitest.Status(err); status != http.StatusInternalServerError {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+	}
+
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("there were unfulfilled expectations: %s", err)
 	}
@@ -204,17 +287,17 @@ func TestCreateAccount_ExperienceLevelTooLow(t *testing.T) {
 	}
 
 	rr := httptest.NewRecorder()
-	var mockDB *sqlx.DB = nil
+	var mockDB AccountRepository = nil
 	var mockStore *auth.SessionStore = nil
 
-	_, err = CreateAccount(rr, req, mockDB, mockStore)
+	_, err = CreateAccount(rr, req, mockDB, mockStore, mail.LogSender{})
 
-	expectedError := "experience_level must be between 0 and 5 (0=easy, 5=impossible)"
+	expectedError := "account.experience_level must be between 0 and 5"
 	if err == nil || err.Error() != expectedError {
 		t.Errorf("CreateAccount() error = %v, wantErr %v", err, expectedError)
 	}
 
-	if status := rr.Code; status != http.StatusBadRequest {
+	if status := httpapitest.Status(err); status != http.StatusBadRequest {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
 	}
 }
@@ -238,17 +321,17 @@ func TestCreateAccount_ExperienceLevelTooHigh(t *testing.T) {
 	}
 
 	rr := httptest.NewRecorder()
-	var mockDB *sqlx.DB = nil
+	var mockDB AccountRepository = nil
 	var mockStore *auth.SessionStore = nil
 
-	_, err = CreateAccount(rr, req, mockDB, mockStore)
+	_, err = CreateAccount(rr, req, mockDB, mockStore, mail.LogSender{})
 
-	expectedError := "experience_level must be between 0 and 5 (0=easy, 5=impossible)"
+	expectedError := "account.experience_level must be between 0 and 5"
 	if err == nil || err.Error() != expectedError {
 		t.Errorf("CreateAccount() error = %v, wantErr %v", err, expectedError)
 	}
 
-	if status := rr.Code; status != http.StatusBadRequest {
+	if status := httpapitest.Status(err); status != http.StatusBadRequest {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
 	}
 }
@@ -272,17 +355,17 @@ func TestCreateAccount_InvalidEmail(t *testing.T) {
 	}
 
 	rr := httptest.NewRecorder()
-	var mockDB *sqlx.DB = nil
+	var mockDB AccountRepository = nil
 	var mockStore *auth.SessionStore = nil
 
-	_, err = CreateAccount(rr, req, mockDB, mockStore)
+	_, err = CreateAccount(rr, req, mockDB, mockStore, mail.LogSender{})
 
-	expectedError := "an error occurred while checking whether the email for the account is valid: mail: missing '@' or angle-addr"
+	expectedError := "account.email must be a valid email address: mail: missing '@' or angle-addr"
 	if err == nil || err.Error() != expectedError {
 		t.Errorf("CreateAccount() error = %v, wantErr %v", err, expectedError)
 	}
 
-	if status := rr.Code; status != http.StatusBadRequest {
+	if status := httpapitest.Status(err); status != http.StatusBadRequest {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
 	}
 }
@@ -1,13 +1,11 @@
 package account
 
 import (
-	"encoding/json"
-	"errors"
 	"fmt"
 	"net/http"
 
-	"github.com/jmoiron/sqlx"
 	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
 )
 
 // DeleteAccountArgs represents the expected structure of the request body for deleting an account.
@@ -15,8 +13,8 @@ import (
 // @Description Structure for the account deletion request payload.
 type DeleteAccountArgs struct {
 	// The account ID for the account that will be deleted.
-	AccountId *int64 `json:"account_id,omitempty"`
-	// A valid session ID for the account (so we know they are signed in)
+	AccountId *int64 `json:"account_id,omitempty" validate:"required"`
+	// Deprecated: a valid numeric session ID for the account. Send the session token in the Authorization header instead.
 	SessionId *int64 `json:"session_id,omitempty"`
 }
 
@@ -27,74 +25,75 @@ type DeleteAccountArgs struct {
 // @Tags account
 // @Accept json
 // @Produce json
+// @Param Authorization header string false "Bearer session token"
 // @Param body body DeleteAccountArgs true "account deletion request body"
-// @Success 200 {string} string "Successfully deleted account!"
-// @Failure 400 {object} error "Bad Request"
-// @Failure 403 {object} error "Forbidden"
-// @Failure 500 {object} error "Internal Server Error"
+// @Success 200 {object} httpapi.MessageResponse "Successfully deleted account!"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
 // @Router /account/delete_account [delete]
-func DeleteAccount(w http.ResponseWriter, r *http.Request, db *sqlx.DB, store *auth.SessionStore) error {
+func DeleteAccount(w http.ResponseWriter, r *http.Request, accounts AccountRepository, store *auth.SessionStore) error {
 
 	if r.Method != http.MethodDelete {
-		return errors.New("invalid request; request must be a DELETE request")
+		return httpapi.MethodNotAllowed(http.MethodDelete)
 	}
 
-	decoder := json.NewDecoder(r.Body)
-	decoder.DisallowUnknownFields()
-
 	args := DeleteAccountArgs{}
-	err := decoder.Decode(&args)
-
+	err := httpapi.DecodeJSON(r, &args)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		return errors.New("an error occurred while decoding the request body: " + err.Error())
+		return err
 	}
 
-	if args.AccountId == nil {
-		w.WriteHeader(http.StatusBadRequest)
-		return errors.New("account_id must be specified")
-	}
-
-	if args.SessionId == nil {
-		w.WriteHeader(http.StatusBadRequest)
-		return errors.New("a valid session_id must be specified")
-	}
-
-	fmt.Println("args: ", *args.AccountId)
-
-	session, err := store.GetSession(*args.SessionId)
+	return deleteAccount(w, r, args, accounts, store)
+}
 
+// DeleteAccountV2 deletes the account with the ID in the path.
+//
+// @Summary Deletes an account
+// @Description This endpoint deletes a player account.
+// @Tags account
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Param id path int true "account ID"
+// @Success 200 {object} httpapi.MessageResponse "Successfully deleted account!"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /v2/accounts/{id} [delete]
+func DeleteAccountV2(w http.ResponseWriter, r *http.Request, accounts AccountRepository, store *auth.SessionStore) error {
+	accountId, err := httpapi.PathID(r, "id")
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		return errors.New("an error occurred while retrieving the session: " + err.Error())
+		return err
 	}
 
-	if session == nil {
-		w.WriteHeader(http.StatusBadRequest)
-		return errors.New("session not found")
-	}
+	return deleteAccount(w, r, DeleteAccountArgs{AccountId: &accountId}, accounts, store)
+}
 
-	if session.IsExpired() {
-		w.WriteHeader(http.StatusForbidden)
-		return errors.New("session has expired")
+// deleteAccount deletes the account in args, if the caller's session belongs to it.
+func deleteAccount(w http.ResponseWriter, r *http.Request, args DeleteAccountArgs, accounts AccountRepository, store *auth.SessionStore) error {
+	if err := httpapi.Validate(&args); err != nil {
+		return err
 	}
 
-	query := "DELETE FROM account WHERE id = $1"
-	result, err := db.Exec(query, args.AccountId)
+	session, err := store.Authenticate(r, args.SessionId)
 	if err != nil {
-		return fmt.Errorf("an error occurred while deleting the account with the ID %d: %v", args.AccountId, err)
+		return err
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("an error occurred while checking the affected rows: %v", err)
+	if err := auth.RequireAccount(session, *args.AccountId); err != nil {
+		return err
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("no rows were affected when the DELETE query ran for the account with ID %d", *args.AccountId)
+	err = accounts.DeleteAccount(*args.AccountId)
+	if err == ErrAccountNotFound {
+		return httpapi.NotFound(fmt.Sprintf("no rows were affected when the DELETE query ran for the account with ID %d", *args.AccountId))
+	}
+	if err != nil {
+		return fmt.Errorf("an error occurred while deleting the account with the ID %d: %v", *args.AccountId, err)
 	}
 
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("Successfully deleted account!"))
+	httpapi.WriteMessage(w, http.StatusOK, "Successfully deleted account!")
 	return nil
 }
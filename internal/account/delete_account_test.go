@@ -15,6 +15,8 @@ import (
 	"github.com/jmoiron/sqlx"
 
 	auth "github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi/httpapitest"
 )
 
 func TestDeleteAccount_InvalidMethod(t *testing.T) {
@@ -37,21 +39,21 @@ func TestDeleteAccount_InvalidMethod(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := DeleteAccount(w, r, sqlxDB, mockStore)
+		err := DeleteAccount(w, r, NewPostgresAccountRepository(sqlxDB), mockStore)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
 	handler.ServeHTTP(rr, req)
 
-	if status := rr.Code; status != http.StatusInternalServerError {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+	if status := rr.Code; status != http.StatusMethodNotAllowed {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusMethodNotAllowed)
 	}
 
 	expectedError := "invalid request; request must be a DELETE request"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
 
@@ -75,21 +77,21 @@ func TestDeleteAccount_DecodeError(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := DeleteAccount(w, r, sqlxDB, mockStore)
+		err := DeleteAccount(w, r, NewPostgresAccountRepository(sqlxDB), mockStore)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
 	handler.ServeHTTP(rr, req)
 
-	if status := rr.Code; status != http.StatusInternalServerError {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
 	}
 
 	expectedError := "an error occurred while decoding the request body: invalid character 'i' looking for beginning of value"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
 
@@ -119,9 +121,9 @@ func TestDeleteAccount_MissingAccountID(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := DeleteAccount(w, r, sqlxDB, mockStore)
+		err := DeleteAccount(w, r, NewPostgresAccountRepository(sqlxDB), mockStore)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
@@ -132,8 +134,8 @@ func TestDeleteAccount_MissingAccountID(t *testing.T) {
 	}
 
 	expectedError := "account_id must be specified"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
 
@@ -164,21 +166,21 @@ func TestDeleteAccount_MissingSessionID(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := DeleteAccount(w, r, sqlxDB, mockStore)
+		err := DeleteAccount(w, r, NewPostgresAccountRepository(sqlxDB), mockStore)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
 	handler.ServeHTTP(rr, req)
 
-	if status := rr.Code; status != http.StatusBadRequest {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+	if status := rr.Code; status != http.StatusUnauthorized {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusUnauthorized)
 	}
 
-	expectedError := "a valid session_id must be specified"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	expectedError := auth.ERROR_SESSION_REQUIRED
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
 
@@ -216,21 +218,21 @@ func TestDeleteAccount_SessionNotFound(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := DeleteAccount(w, r, sqlxDB, mockStore)
+		err := DeleteAccount(w, r, NewPostgresAccountRepository(sqlxDB), mockStore)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
 	handler.ServeHTTP(rr, req)
 
-	if status := rr.Code; status != http.StatusBadRequest {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+	if status := rr.Code; status != http.StatusForbidden {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusForbidden)
 	}
 
 	expectedError := "session not found"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
 
@@ -272,9 +274,9 @@ func TestDeleteAccount_SessionExpired(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := DeleteAccount(w, r, sqlxDB, mockStore)
+		err := DeleteAccount(w, r, NewPostgresAccountRepository(sqlxDB), mockStore)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
@@ -285,8 +287,68 @@ func TestDeleteAccount_SessionExpired(t *testing.T) {
 	}
 
 	expectedError := "session has expired"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
+	}
+}
+
+func TestDeleteAccount_SessionForAnotherAccount(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	var acctId int64 = 2
+	sessionID := int64(1)
+	deleteArgs := DeleteAccountArgs{
+		AccountId: &acctId,
+		SessionId: &sessionID,
+	}
+
+	body, _ := json.Marshal(deleteArgs)
+	req, err := http.NewRequest("DELETE", "/account/delete_account", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	createdAt := time.Now()
+	expiresAt := time.Now().Add(6 * time.Hour)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM sessions WHERE id = $1")).
+		WithArgs(sessionID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "account_id", "created_at", "expires_at"}).
+			AddRow(sessionID, 1, createdAt, expiresAt))
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE sessions SET expires_at = $1 WHERE id = $2")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mockStore := &auth.SessionStore{
+		DB: sqlxDB,
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := DeleteAccount(w, r, NewPostgresAccountRepository(sqlxDB), mockStore)
+		if err != nil {
+			httpapi.WriteError(w, err)
+		}
+	})
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusForbidden {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusForbidden)
+	}
+
+	if httpapitest.Message(rr) != auth.ErrForbidden.Error() {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), auth.ErrForbidden.Error())
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
 	}
 }
 
@@ -323,18 +385,27 @@ func TestDeleteAccount_Success(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"id", "account_id", "created_at", "expires_at"}).
 			AddRow(sessionID, accountID, createdAt, expiresAt))
 
+	mock.ExpectBegin()
+
 	mock.ExpectExec("DELETE FROM account WHERE id = \\$1").
 		WithArgs(accountID).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
+	// Sessions are not removed by the account table's cascade
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM sessions WHERE account_id = $1")).
+		WithArgs(accountID).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	mock.ExpectCommit()
+
 	mockStore := &auth.SessionStore{
 		DB: sqlxDB,
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := DeleteAccount(w, r, sqlxDB, mockStore)
+		err := DeleteAccount(w, r, NewPostgresAccountRepository(sqlxDB), mockStore)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
@@ -345,8 +416,8 @@ func TestDeleteAccount_Success(t *testing.T) {
 	}
 
 	expectedResponse := "Successfully deleted account!"
-	if strings.TrimSpace(rr.Body.String()) != expectedResponse {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedResponse)
+	if httpapitest.Message(rr) != expectedResponse {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedResponse)
 	}
 
 	if err := mock.ExpectationsWereMet(); err != nil {
@@ -388,29 +459,33 @@ func TestDeleteAccount_NoAccountExists(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"id", "account_id", "created_at", "expires_at"}).
 			AddRow(sessionID, accountID, createdAt, expiresAt))
 
+	mock.ExpectBegin()
+
 	mock.ExpectExec("DELETE FROM account WHERE id = \\$1").
 		WithArgs(accountID).
 		WillReturnResult(sqlmock.NewResult(0, 0))
 
+	mock.ExpectRollback()
+
 	mockStore := &auth.SessionStore{
 		DB: sqlxDB,
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := DeleteAccount(w, r, sqlxDB, mockStore)
+		err := DeleteAccount(w, r, NewPostgresAccountRepository(sqlxDB), mockStore)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
 	handler.ServeHTTP(rr, req)
 
-	if status := rr.Code; status != http.StatusInternalServerError {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusNotFound)
 	}
 
 	expectedError := "no rows were affected when the DELETE query ran for the account with ID 1"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
@@ -0,0 +1,99 @@
+package account
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	auth "github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+	"github.com/justinfarrelldev/open-ctp-server/internal/mail"
+)
+
+// ErrEmailVerificationNotFound is returned when an email verification token is unknown, already used, expired
+// or was sent to an email the account no longer uses.
+var ErrEmailVerificationNotFound = errors.New("the verification token is invalid or has expired")
+
+// DefaultEmailVerificationLifetime is how long an email verification token can be used for.
+const DefaultEmailVerificationLifetime = 48 * time.Hour
+
+// VerificationResendInterval is how long an account has to wait before another verification email is sent.
+const VerificationResendInterval = time.Minute
+
+const ERROR_EMAIL_NOT_VERIFIED = "the account's email must be verified first"
+const ERROR_EMAIL_ALREADY_VERIFIED = "the account's email is already verified"
+const ERROR_VERIFICATION_RECENTLY_SENT = "a verification email was sent recently; please wait a minute before asking for another"
+
+// Codes of the errors clients need to tell apart when verifying emails.
+const (
+	CodeEmailNotVerified         httpapi.Code = "email_not_verified"
+	CodeEmailAlreadyVerified     httpapi.Code = "email_already_verified"
+	CodeVerificationRecentlySent httpapi.Code = "verification_recently_sent"
+	CodeVerificationTokenInvalid httpapi.Code = "verification_token_invalid"
+)
+
+// EmailVerification is an outstanding verification token for an account's email.
+type EmailVerification struct {
+	// TokenHash is the SHA-256 hash of the token. The token itself is only ever sent to the email.
+	TokenHash string    `db:"token_hash"`
+	AccountID int64     `db:"account_id"`
+	Email     string    `db:"email"`
+	CreatedAt time.Time `db:"created_at"`
+	ExpiresAt time.Time `db:"expires_at"`
+}
+
+// sendVerificationEmail emails a new verification token to the account, replacing any earlier token.
+func sendVerificationEmail(accounts AccountRepository, sender mail.Sender, accountID int64, email string) error {
+	token, tokenHash, err := auth.NewSecretToken()
+	if err != nil {
+		return fmt.Errorf("an error occurred while generating a verification token: %v", err)
+	}
+
+	now := time.Now()
+	verification := &EmailVerification{
+		TokenHash: tokenHash,
+		AccountID: accountID,
+		Email:     email,
+		CreatedAt: now,
+		ExpiresAt: now.Add(DefaultEmailVerificationLifetime),
+	}
+
+	if err := accounts.CreateEmailVerification(verification); err != nil {
+		return fmt.Errorf("an error occurred while saving the verification token: %v", err)
+	}
+
+	return sender.Send(mail.Message{
+		To:      email,
+		Subject: "Verify your Open CTP Server email",
+		Body: fmt.Sprintf(
+			"Thanks for creating an account! To verify your email, send this token to /account/verify_email:\n\n%s\n\nThe token expires in %s. If you did not create an account, you can ignore this email.",
+			token, DefaultEmailVerificationLifetime,
+		),
+	})
+}
+
+// RequireVerifiedEmail refuses requests from accounts which have not verified their email. It must be
+// wrapped in SessionStore.Middleware, which provides the caller's session.
+func RequireVerifiedEmail(accounts AccountRepository, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accountID, ok := auth.AccountIDFromContext(r.Context())
+		if !ok {
+			httpapi.WriteError(w, auth.ErrSessionRequired)
+			return
+		}
+
+		verified, err := accounts.IsEmailVerified(accountID)
+		if err != nil {
+			httpapi.WriteError(w, fmt.Errorf("an error occurred while checking whether the account's email is verified: %v", err))
+			return
+		}
+
+		if !verified {
+			httpapi.WriteError(w, httpapi.Forbidden(ERROR_EMAIL_NOT_VERIFIED).WithCode(CodeEmailNotVerified))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
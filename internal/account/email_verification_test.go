@@ -0,0 +1,163 @@
+package account
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	auth "github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi/httpapitest"
+	"github.com/justinfarrelldev/open-ctp-server/internal/mail"
+)
+
+// recordingSender keeps the emails it is asked to send.
+type recordingSender struct {
+	messages []mail.Message
+}
+
+func (r *recordingSender) Send(message mail.Message) error {
+	r.messages = append(r.messages, message)
+	return nil
+}
+
+var verificationTokenPattern = regexp.MustCompile(`(?m)^[A-Za-z0-9_-]{43}$`)
+
+func TestEmailVerificationFlow(t *testing.T) {
+	accounts := NewMemoryAccountRepository()
+	store := auth.NewSessionStoreWithRepository(auth.NewMemorySessionRepository())
+	sender := &recordingSender{}
+
+	req := httptest.NewRequest(http.MethodPost, "/account/create_account", strings.NewReader(`{"account": {"name": "Player", "email": "player@example.com"}, "password": "password123"}`))
+	session, err := CreateAccount(httptest.NewRecorder(), req, accounts, store, sender)
+	if err != nil {
+		t.Fatalf("CreateAccount() error = %v", err)
+	}
+
+	if verified, _ := accounts.IsEmailVerified(1); verified {
+		t.Fatal("expected a new account to start unverified")
+	}
+	if len(sender.messages) != 1 || sender.messages[0].To != "player@example.com" {
+		t.Fatalf("expected one verification email to the account, got %+v", sender.messages)
+	}
+	token := verificationTokenPattern.FindString(sender.messages[0].Body)
+	if token == "" {
+		t.Fatalf("no token in the email: %s", sender.messages[0].Body)
+	}
+
+	resend := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/account/resend_verification", strings.NewReader(`{"account_id": 1}`))
+		req.Header.Set("Authorization", "Bearer "+session.Token)
+		rr := httptest.NewRecorder()
+		ResendVerificationHandler(rr, req, accounts, store, sender)
+		return rr
+	}
+
+	verify := func(token string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		VerifyEmailHandler(rr, httptest.NewRequest(http.MethodPost, "/account/verify_email", strings.NewReader(`{"token": "`+token+`"}`)), accounts)
+		return rr
+	}
+
+	if rr := resend(); rr.Code != http.StatusTooManyRequests {
+		t.Errorf("expected an immediate re-send to be refused, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if rr := verify("not-a-token"); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected an unknown token to be refused, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if rr := verify(token); rr.Code != http.StatusOK {
+		t.Fatalf("VerifyEmailHandler returned %d: %s", rr.Code, rr.Body.String())
+	}
+	if verified, _ := accounts.IsEmailVerified(1); !verified {
+		t.Error("expected the account to be verified")
+	}
+
+	if rr := verify(token); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected the token to only work once, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if rr := resend(); rr.Code != http.StatusBadRequest || httpapitest.Message(rr) != ERROR_EMAIL_ALREADY_VERIFIED {
+		t.Errorf("expected a verified account to be refused, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestMemoryAccountRepository_EmailChangeUnverifies(t *testing.T) {
+	accounts := NewMemoryAccountRepository()
+
+	id, err := accounts.CreateAccount(&Account{Name: "Player", Email: "old@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	verification := &EmailVerification{TokenHash: "old", AccountID: id, Email: "old@example.com", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	if err := accounts.CreateEmailVerification(verification); err != nil {
+		t.Fatal(err)
+	}
+	verification.TokenHash = "stale"
+	if err := accounts.CreateEmailVerification(verification); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := accounts.VerifyEmail("old", now); err != ErrEmailVerificationNotFound {
+		t.Errorf("expected a newer verification to replace the old one, got %v", err)
+	}
+
+	email := "new@example.com"
+	if err := accounts.UpdateAccount(id, &AccountParam{Email: &email}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := accounts.VerifyEmail("stale", now); err != ErrEmailVerificationNotFound {
+		t.Errorf("expected a token sent to the old email to be refused, got %v", err)
+	}
+	if verified, _ := accounts.IsEmailVerified(id); verified {
+		t.Error("expected the account to be unverified")
+	}
+}
+
+func TestRequireVerifiedEmail(t *testing.T) {
+	accounts := NewMemoryAccountRepository()
+	store := auth.NewSessionStoreWithRepository(auth.NewMemorySessionRepository())
+
+	id, err := accounts.CreateAccount(&Account{Name: "Player", Email: "player@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, err := store.CreateSession(int(id))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := store.Middleware(RequireVerifiedEmail(accounts, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})))
+
+	request := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/lobby/create_lobby", nil)
+		req.Header.Set("Authorization", "Bearer "+session.Token)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := request(); rr.Code != http.StatusForbidden || httpapitest.Message(rr) != ERROR_EMAIL_NOT_VERIFIED {
+		t.Errorf("expected an unverified account to be refused, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	now := time.Now()
+	if err := accounts.CreateEmailVerification(&EmailVerification{TokenHash: "hash", AccountID: id, Email: "player@example.com", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := accounts.VerifyEmail("hash", now); err != nil {
+		t.Fatal(err)
+	}
+
+	if rr := request(); rr.Code != http.StatusCreated {
+		t.Errorf("expected a verified account to be let through, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
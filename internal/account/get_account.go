@@ -1,15 +1,12 @@
 package account
 
 import (
-	"database/sql"
-	"encoding/json"
-	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 
-	"github.com/jmoiron/sqlx"
 	auth "github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
 )
 
 // GetAccountArgs represents the expected structure of the request body for getting an account.
@@ -18,102 +15,99 @@ import (
 type GetAccountArgs struct {
 	// The account ID for the account that will be retrieved.
 	AccountId int64 `json:"account_id"`
-	// A valid session ID for the account (so we know they are signed in)
+	// Deprecated: a valid numeric session ID for the account. Send the session token in the Authorization header instead.
 	SessionId int64 `json:"session_id"`
 }
 
 // GetAccount gets an account by the account ID.
 //
 // @Summary Gets an account
-// @Description This endpoint gets a multiplayer account's info.
+// @Description This endpoint gets a multiplayer account's info. Only the account itself can get it; other players use /account/get_profile.
 // @Tags account
 // @Accept json
 // @Produce json
 // @Param account_id query int true "account ID"
-// @Param session_id query int true "session ID"
+// @Param Authorization header string false "Bearer session token"
+// @Param session_id query int false "Deprecated numeric session ID"
 //
 // @Success 200 {object} account.Account "Account successfully retrieved"
 //
-// @Failure 400 {object} error "Bad Request"
-// @Failure 403 {object} error "Forbidden"
-// @Failure 500 {object} error "Internal Server Error"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
 // @Router /account/get_account [get]
-func GetAccount(w http.ResponseWriter, r *http.Request, db *sqlx.DB, store *auth.SessionStore) error {
+func GetAccount(w http.ResponseWriter, r *http.Request, accounts AccountRepository, store *auth.SessionStore) error {
 	if r.Method != "GET" {
-		return errors.New("invalid request; request must be a GET request")
+		return httpapi.MethodNotAllowed(http.MethodGet)
 	}
 
 	queryParams := r.URL.Query()
 	accountIdStr := queryParams.Get("account_id")
 	if accountIdStr == "" {
-		return errors.New("account_id is required")
+		return httpapi.Validation("account_id is required")
 	}
 
 	accountId, err := strconv.ParseInt(accountIdStr, 10, 64)
 	if err != nil {
-		return errors.New("invalid account_id")
+		return httpapi.Validation("invalid account_id")
 	}
 
-	sessionIdStr := queryParams.Get("session_id")
-	if sessionIdStr == "" {
-		return errors.New("session_id is required")
+	var legacySessionId *int64
+	if sessionIdStr := queryParams.Get("session_id"); sessionIdStr != "" {
+		sessionId, err := strconv.ParseInt(sessionIdStr, 10, 64)
+		if err != nil {
+			return httpapi.Validation("invalid session_id")
+		}
+		legacySessionId = &sessionId
 	}
 
-	sessionId, err := strconv.ParseInt(sessionIdStr, 10, 64)
-	if err != nil {
-		return errors.New("invalid session_id")
-	}
+	return getAccount(w, r, accountId, legacySessionId, accounts, store)
+}
 
-	session, err := store.GetSession(sessionId)
+// GetAccountV2 gets the account with the ID in the path.
+//
+// @Summary Gets an account
+// @Description This endpoint gets a multiplayer account's info. Only the account itself can get it; other players use /v2/accounts/{id}/profile.
+// @Tags account
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Param id path int true "account ID"
+// @Success 200 {object} account.Account "Account successfully retrieved"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 404 {object} httpapi.ErrorResponse "Not Found"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /v2/accounts/{id} [get]
+func GetAccountV2(w http.ResponseWriter, r *http.Request, accounts AccountRepository, store *auth.SessionStore) error {
+	accountId, err := httpapi.PathID(r, "id")
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		return errors.New("an error occurred while retrieving the session: " + err.Error())
+		return err
 	}
 
-	if session == nil {
-		w.WriteHeader(http.StatusForbidden)
-		return errors.New("session not found")
-	}
+	return getAccount(w, r, accountId, nil, accounts, store)
+}
 
-	if session.IsExpired() {
-		w.WriteHeader(http.StatusForbidden)
-		return errors.New("session has expired")
+// getAccount writes the account with the given ID, if the caller's session belongs to it.
+func getAccount(w http.ResponseWriter, r *http.Request, accountId int64, legacySessionId *int64, accounts AccountRepository, store *auth.SessionStore) error {
+	session, err := store.Authenticate(r, legacySessionId)
+	if err != nil {
+		return err
 	}
 
-	var (
-		name            string
-		info            string
-		location        string
-		email           string
-		experienceLevel int
-	)
-
-	fmt.Println("account id is", accountId)
-
-	if err := db.QueryRow("SELECT name, info, location, email, experience_level FROM account WHERE id = $1", accountId).
-		Scan(&name, &info, &location, &email, &experienceLevel); err != nil {
-		if err == sql.ErrNoRows {
-			return fmt.Errorf("no account exists with the ID %d", accountId)
-		}
-		return fmt.Errorf("an error occurred while getting the account with the ID %d: %v", accountId, err)
+	if err := auth.RequireAccount(session, accountId); err != nil {
+		return err
 	}
 
-	// Now assemble the variables into the Account struct
-	account := Account{
-		Name:            name,
-		Info:            info,
-		Location:        location,
-		Email:           email,
-		ExperienceLevel: ExperienceLevel(experienceLevel),
+	account, err := accounts.GetAccount(accountId)
+	if err == ErrAccountNotFound {
+		return httpapi.NotFound(fmt.Sprintf("no account exists with the ID %d", accountId))
 	}
-
-	accountBytes, err := json.Marshal(account)
 	if err != nil {
-		return fmt.Errorf("Error marshalling struct: %v", err)
+		return fmt.Errorf("an error occurred while getting the account with the ID %d: %v", accountId, err)
 	}
 
-	w.Write(accountBytes)
-
-	fmt.Println("Successfully got account!")
+	httpapi.WriteJSON(w, http.StatusOK, account)
 	return nil
 }
@@ -13,6 +13,8 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	auth "github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi/httpapitest"
 )
 
 func TestGetAccount_Success(t *testing.T) {
@@ -58,9 +60,9 @@ func TestGetAccount_Success(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := GetAccount(w, r, sqlxDB, mockStore)
+		err := GetAccount(w, r, NewPostgresAccountRepository(sqlxDB), mockStore)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
@@ -76,6 +78,78 @@ func TestGetAccount_Success(t *testing.T) {
 	}
 }
 
+func TestGetAccount_BearerToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	accountID := int64(1)
+	createdAt := time.Now()
+	expiresAt := time.Now().Add(6 * time.Hour)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM sessions WHERE token_hash = $1")).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "account_id", "created_at", "expires_at"}).
+			AddRow(1, accountID, createdAt, expiresAt))
+
+	mock.ExpectExec("UPDATE sessions SET expires_at").
+		WithArgs(sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectQuery("SELECT name, info, location, email, experience_level FROM account WHERE id = \\$1").
+		WithArgs(accountID).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "info", "location", "email", "experience_level"}).
+			AddRow("John Doe", "Some info", "Some location", "john.doe@example.com", 5))
+
+	req, err := http.NewRequest("GET", "/account/get_account?account_id=1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer some-session-token")
+
+	rr := httptest.NewRecorder()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	mockStore := &auth.SessionStore{
+		DB: sqlxDB,
+	}
+
+	err = GetAccount(rr, req, NewPostgresAccountRepository(sqlxDB), mockStore)
+	if err != nil {
+		t.Errorf("GetAccount() error = %v, wantErr %v", err, nil)
+	}
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetAccount_MissingSession(t *testing.T) {
+	req, err := http.NewRequest("GET", "/account/get_account?account_id=1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	var mockDB AccountRepository = nil
+	var mockStore *auth.SessionStore = nil
+
+	err = GetAccount(rr, req, mockDB, mockStore)
+
+	if err == nil || err.Error() != auth.ERROR_SESSION_REQUIRED {
+		t.Errorf("GetAccount() error = %v, wantErr %v", err, auth.ERROR_SESSION_REQUIRED)
+	}
+
+	if status := httpapitest.Status(err); status != http.StatusUnauthorized {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusUnauthorized)
+	}
+}
+
 func TestGetAccount_NotFound(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -110,21 +184,21 @@ func TestGetAccount_NotFound(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := GetAccount(w, r, sqlxDB, mockStore)
+		err := GetAccount(w, r, NewPostgresAccountRepository(sqlxDB), mockStore)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
 	handler.ServeHTTP(rr, req)
 
-	if status := rr.Code; status != http.StatusInternalServerError {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusNotFound)
 	}
 
 	expectedError := "no account exists with the ID 1"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
 
@@ -147,21 +221,21 @@ func TestGetAccount_InvalidMethod(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := GetAccount(w, r, sqlxDB, mockStore)
+		err := GetAccount(w, r, NewPostgresAccountRepository(sqlxDB), mockStore)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
 	handler.ServeHTTP(rr, req)
 
-	if status := rr.Code; status != http.StatusInternalServerError {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+	if status := rr.Code; status != http.StatusMethodNotAllowed {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusMethodNotAllowed)
 	}
 
 	expectedError := "invalid request; request must be a GET request"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
 
@@ -184,21 +258,21 @@ func TestGetAccount_DecodeError(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := GetAccount(w, r, sqlxDB, mockStore)
+		err := GetAccount(w, r, NewPostgresAccountRepository(sqlxDB), mockStore)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
 	handler.ServeHTTP(rr, req)
 
-	if status := rr.Code; status != http.StatusInternalServerError {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
 	}
 
 	expectedError := "invalid account_id"
-	if strings.ReplaceAll(strings.TrimSpace(rr.Body.String()), "\n", "") != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.ReplaceAll(strings.TrimSpace(rr.Body.String()), "\n", ""), expectedError)
+	if strings.ReplaceAll(httpapitest.Message(rr), "\n", "") != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", strings.ReplaceAll(httpapitest.Message(rr), "\n", ""), expectedError)
 	}
 }
 
@@ -221,20 +295,31 @@ func TestGetAccount_MissingAccountID(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := GetAccount(w, r, sqlxDB, mockStore)
+		err := GetAccount(w, r, NewPostgresAccountRepository(sqlxDB), mockStore)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
 	handler.ServeHTTP(rr, req)
 
-	if status := rr.Code; status != http.StatusInternalServerError {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
 	}
 
 	expectedError := "account_id is required"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
+	}
+}
+
+func TestGetAccount_InvalidSessionID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/account/get_account?account_id=1&session_id=invalid", nil)
+	rr := httptest.NewRecorder()
+
+	GetAccountHandler(rr, req, NewMemoryAccountRepository(), auth.NewSessionStoreWithRepository(auth.NewMemorySessionRepository()))
+
+	if rr.Code != http.StatusBadRequest || httpapitest.Code(rr) != httpapi.CodeValidation || httpapitest.Message(rr) != "invalid session_id" {
+		t.Errorf("got %d: %s", rr.Code, rr.Body.String())
 	}
 }
@@ -0,0 +1,75 @@
+package account
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+)
+
+// GetProfile gets the public profile of an account.
+//
+// @Summary Gets a player's public profile
+// @Description This endpoint gets what other players can see of an account: its name, experience level, stats and join date, plus its info and location when the player has made them public. The email is never included. Players use /account/my_account to see their own account.
+// @Tags account
+// @Produce json
+// @Param account_id query int true "account ID"
+//
+// @Success 200 {object} account.PublicProfile "Profile successfully retrieved"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 404 {object} httpapi.ErrorResponse "Not Found"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /account/get_profile [get]
+func GetProfile(w http.ResponseWriter, r *http.Request, accounts AccountRepository) error {
+	if r.Method != http.MethodGet {
+		return httpapi.MethodNotAllowed(http.MethodGet)
+	}
+
+	accountIdStr := r.URL.Query().Get("account_id")
+	if accountIdStr == "" {
+		return httpapi.Validation("account_id is required")
+	}
+
+	accountId, err := strconv.ParseInt(accountIdStr, 10, 64)
+	if err != nil {
+		return httpapi.Validation("invalid account_id")
+	}
+
+	return getProfile(w, accountId, accounts)
+}
+
+// GetProfileV2 gets the public profile of the account with the ID in the path.
+//
+// @Summary Gets a player's public profile
+// @Description This endpoint gets what other players can see of an account: its name, experience level, stats and join date, plus its info and location when the player has made them public. The email is never included. Players use /v2/accounts/me to see their own account.
+// @Tags account
+// @Produce json
+// @Param id path int true "account ID"
+// @Success 200 {object} account.PublicProfile "Profile successfully retrieved"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 404 {object} httpapi.ErrorResponse "Not Found"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /v2/accounts/{id}/profile [get]
+func GetProfileV2(w http.ResponseWriter, r *http.Request, accounts AccountRepository) error {
+	accountId, err := httpapi.PathID(r, "id")
+	if err != nil {
+		return err
+	}
+
+	return getProfile(w, accountId, accounts)
+}
+
+// getProfile writes the public profile of the account with the given ID.
+func getProfile(w http.ResponseWriter, accountId int64, accounts AccountRepository) error {
+	details, err := accounts.GetAccountDetails(accountId)
+	if err == ErrAccountNotFound {
+		return httpapi.NotFound(fmt.Sprintf("no account exists with the ID %d", accountId))
+	}
+	if err != nil {
+		return fmt.Errorf("an error occurred while getting the account with the ID %d: %v", accountId, err)
+	}
+
+	httpapi.WriteJSON(w, http.StatusOK, details.PublicProfile())
+	return nil
+}
@@ -0,0 +1,43 @@
+package account
+
+import (
+	"fmt"
+	"net/http"
+
+	auth "github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+)
+
+// MyAccount gets the account the session belongs to, including its private fields.
+//
+// @Summary Gets your own account
+// @Description This endpoint gets everything about the session's own account, including its email, whether the email is verified and its privacy settings.
+// @Tags account
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+//
+// @Success 200 {object} account.AccountDetails "Account successfully retrieved"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /account/my_account [get]
+// @Router /v2/accounts/me [get]
+func MyAccount(w http.ResponseWriter, r *http.Request, accounts AccountRepository, store *auth.SessionStore) error {
+	if r.Method != http.MethodGet {
+		return httpapi.MethodNotAllowed(http.MethodGet)
+	}
+
+	session, err := store.Authenticate(r, nil)
+	if err != nil {
+		return err
+	}
+
+	details, err := accounts.GetAccountDetails(int64(session.AccountID))
+	if err != nil {
+		// The account of a valid session only disappears when it is deleted mid-request
+		return fmt.Errorf("an error occurred while getting the account with the ID %d: %v", session.AccountID, err)
+	}
+
+	httpapi.WriteJSON(w, http.StatusOK, details)
+	return nil
+}
@@ -0,0 +1,121 @@
+package account
+
+import (
+	"time"
+)
+
+// Visibility controls who can see a part of an account's public profile.
+type Visibility string
+
+const (
+	// VisibilityPublic shows the field to everyone who looks up the profile.
+	VisibilityPublic Visibility = "public"
+	// VisibilityPrivate shows the field only to the account itself.
+	VisibilityPrivate Visibility = "private"
+)
+
+// DefaultPrivacySettings are the privacy settings of new accounts.
+var DefaultPrivacySettings = PrivacySettings{
+	LocationVisibility: VisibilityPrivate,
+	InfoVisibility:     VisibilityPublic,
+}
+
+// PrivacySettings controls which optional fields appear on an account's public profile.
+//
+// @Description Who can see the optional fields of a player's public profile.
+type PrivacySettings struct {
+	// LocationVisibility is "public" or "private".
+	LocationVisibility Visibility `json:"location_visibility" db:"location_visibility"`
+
+	// InfoVisibility is "public" or "private".
+	InfoVisibility Visibility `json:"info_visibility" db:"info_visibility"`
+}
+
+// PrivacySettingsParam represents privacy settings with non-required fields.
+//
+// @Description Privacy settings with non-required fields.
+type PrivacySettingsParam struct {
+	// LocationVisibility is "public" or "private".
+	LocationVisibility *Visibility `json:"location_visibility,omitempty" validate:"oneof=public private"`
+
+	// InfoVisibility is "public" or "private".
+	InfoVisibility *Visibility `json:"info_visibility,omitempty" validate:"oneof=public private"`
+}
+
+// ProfileStats summarises an account's activity on the server.
+//
+// @Description Statistics about a player's activity.
+type ProfileStats struct {
+	// GamesHosted is how many games the player is hosting or has hosted.
+	GamesHosted int `json:"games_hosted"`
+}
+
+// AccountDetails is everything about an account which the account itself may see.
+//
+// @Description A player's own account, including its private fields.
+type AccountDetails struct {
+	// ID is the ID of the account.
+	ID int64 `json:"id"`
+
+	Account
+
+	// EmailVerified is whether the email has been verified.
+	EmailVerified bool `json:"email_verified"`
+
+	// Privacy controls which fields appear on the public profile.
+	Privacy PrivacySettings `json:"privacy"`
+
+	// Stats summarises the player's activity.
+	Stats ProfileStats `json:"stats"`
+
+	// JoinedAt is when the account was created.
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// PublicProfile is what other players can see of an account. It never includes the email.
+//
+// @Description A player's public profile.
+type PublicProfile struct {
+	// ID is the ID of the account.
+	ID int64 `json:"id"`
+
+	// Name is the name of the player.
+	Name string `json:"name"`
+
+	// Info contains additional information about the player. It is left out when the player keeps it private.
+	Info *string `json:"info,omitempty"`
+
+	// Location indicates the player's real-life location. It is left out when the player keeps it private.
+	Location *string `json:"location,omitempty"`
+
+	// ExperienceLevel represents the player's experience level (0=beginner, 1=easy, 2=medium, 3=hard, 4=very hard, 5=impossible)
+	ExperienceLevel ExperienceLevel `json:"experience_level"`
+
+	// Stats summarises the player's activity.
+	Stats ProfileStats `json:"stats"`
+
+	// JoinedAt is when the account was created.
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// PublicProfile returns the parts of the account its privacy settings let other players see.
+func (d *AccountDetails) PublicProfile() *PublicProfile {
+	profile := &PublicProfile{
+		ID:              d.ID,
+		Name:            d.Name,
+		ExperienceLevel: d.ExperienceLevel,
+		Stats:           d.Stats,
+		JoinedAt:        d.JoinedAt,
+	}
+
+	if d.Privacy.InfoVisibility == VisibilityPublic {
+		info := d.Info
+		profile.Info = &info
+	}
+	if d.Privacy.LocationVisibility == VisibilityPublic {
+		location := d.Location
+		profile.Location = &location
+	}
+
+	return profile
+}
@@ -0,0 +1,144 @@
+package account
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	auth "github.com/justinfarrelldev/open-ctp-server/internal/auth"
+)
+
+func TestProfilePrivacy(t *testing.T) {
+	accounts := NewMemoryAccountRepository()
+	accounts.HostedGames = func(accountID int64) (int, error) { return 2, nil }
+	store := auth.NewSessionStoreWithRepository(auth.NewMemorySessionRepository())
+
+	id, err := accounts.CreateAccount(&Account{Name: "Player", Info: "Plays Romans", Location: "Lisbon", Email: "player@example.com", ExperienceLevel: Hard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, err := store.CreateSession(int(id))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	getProfile := func() (*httptest.ResponseRecorder, map[string]interface{}) {
+		rr := httptest.NewRecorder()
+		GetProfileHandler(rr, httptest.NewRequest(http.MethodGet, "/account/get_profile?account_id=1", nil), accounts)
+
+		var profile map[string]interface{}
+		if err := json.Unmarshal(rr.Body.Bytes(), &profile); err != nil {
+			t.Fatalf("could not decode the profile %q: %v", rr.Body.String(), err)
+		}
+		return rr, profile
+	}
+
+	rr, profile := getProfile()
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GetProfileHandler returned %d: %s", rr.Code, rr.Body.String())
+	}
+	if _, ok := profile["email"]; ok {
+		t.Error("expected the profile to never include the email")
+	}
+	if _, ok := profile["location"]; ok {
+		t.Error("expected the location to be private by default")
+	}
+	if profile["info"] != "Plays Romans" || profile["name"] != "Player" || profile["experience_level"] != float64(Hard) {
+		t.Errorf("unexpected profile: %v", profile)
+	}
+	if stats, _ := profile["stats"].(map[string]interface{}); stats["games_hosted"] != float64(2) {
+		t.Errorf("expected the hosted games to be counted, got %v", profile["stats"])
+	}
+
+	updatePrivacy := func(body string, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/account/update_privacy", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		UpdatePrivacyHandler(rr, req, accounts, store)
+		return rr
+	}
+
+	if rr := updatePrivacy(`{"account_id": 1, "privacy": {"location_visibility": "friends"}}`, session.Token); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected an unknown visibility to be refused, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := updatePrivacy(`{"account_id": 1, "privacy": {}}`, session.Token); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected an empty update to be refused, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := updatePrivacy(`{"account_id": 1, "privacy": {"location_visibility": "public"}}`, "not-a-token"); rr.Code != http.StatusForbidden {
+		t.Errorf("expected an invalid session to be refused, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if rr := updatePrivacy(`{"account_id": 1, "privacy": {"location_visibility": "public", "info_visibility": "private"}}`, session.Token); rr.Code != http.StatusOK {
+		t.Fatalf("UpdatePrivacyHandler returned %d: %s", rr.Code, rr.Body.String())
+	}
+
+	_, profile = getProfile()
+	if _, ok := profile["info"]; ok || profile["location"] != "Lisbon" {
+		t.Errorf("expected the location to be public and the info private, got %v", profile)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/account/my_account", nil)
+	req.Header.Set("Authorization", "Bearer "+session.Token)
+	rr = httptest.NewRecorder()
+	MyAccountHandler(rr, req, accounts, store)
+
+	var details AccountDetails
+	if err := json.Unmarshal(rr.Body.Bytes(), &details); err != nil {
+		t.Fatalf("could not decode the account %q: %v", rr.Body.String(), err)
+	}
+	if details.ID != id || details.Email != "player@example.com" || details.Info != "Plays Romans" || details.Privacy.InfoVisibility != VisibilityPrivate {
+		t.Errorf("expected the owner to see every field, got %+v", details)
+	}
+}
+
+func TestGetProfile_NotFound(t *testing.T) {
+	rr := httptest.NewRecorder()
+	GetProfileHandler(rr, httptest.NewRequest(http.MethodGet, "/account/get_profile?account_id=7", nil), NewMemoryAccountRepository())
+
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestPostgresAccountRepository_GetAccountDetails(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	joinedAt := time.Now()
+	columns := []string{"id", "name", "info", "location", "email", "experience_level", "email_verified", "location_visibility", "info_visibility", "created_at", "count"}
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, name, info, location, email, experience_level, email_verified, location_visibility, info_visibility, created_at,")).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "Player", "Plays Romans", "Lisbon", "player@example.com", 3, true, "private", "public", joinedAt, 4))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, name, info, location, email, experience_level, email_verified, location_visibility, info_visibility, created_at,")).
+		WithArgs(int64(2)).
+		WillReturnError(sql.ErrNoRows)
+
+	accounts := NewPostgresAccountRepository(sqlx.NewDb(db, "sqlmock"))
+
+	details, err := accounts.GetAccountDetails(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	profile := details.PublicProfile()
+	if profile.Location != nil || profile.Info == nil || *profile.Info != "Plays Romans" || profile.Stats.GamesHosted != 4 || !profile.JoinedAt.Equal(joinedAt) {
+		t.Errorf("unexpected profile: %+v", profile)
+	}
+
+	if _, err := accounts.GetAccountDetails(2); err != ErrAccountNotFound {
+		t.Errorf("expected ErrAccountNotFound, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,714 @@
+package account
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	auth "github.com/justinfarrelldev/open-ctp-server/internal/auth"
+)
+
+// ErrAccountNotFound is returned when no account exists with the requested ID.
+var ErrAccountNotFound = errors.New("no account exists with the given ID")
+
+// ErrEmailInUse is returned when an account is given an email another account already uses.
+var ErrEmailInUse = errors.New("the email is already in use")
+
+// AccountRepository stores accounts and their passwords.
+type AccountRepository interface {
+	auth.CredentialRepository
+
+	// CreateAccount stores a new account without a password and returns its ID.
+	CreateAccount(account *Account) (int64, error)
+	// RegisterAccount stores a new account together with its password and a first session from the store.
+	// Either all of them are stored or none are, so a failure never leaves the email taken by a half-created account.
+	RegisterAccount(account *Account, hash string, store *auth.SessionStore) (*auth.Session, error)
+	// EmailExists reports whether an account already uses the given email.
+	EmailExists(email string) (bool, error)
+	// GetAccount returns ErrAccountNotFound when the account does not exist.
+	GetAccount(accountID int64) (*Account, error)
+	// UpdateAccount sets every non-nil field of the update on the account. It returns ErrEmailInUse when
+	// another account already uses the new email, and ErrAccountNotFound when the account does not exist.
+	UpdateAccount(accountID int64, update *AccountParam) error
+	// DeleteAccount deletes the account along with its sessions and anything the repository's delete hooks
+	// clean up, such as the lobbies it owns. It returns ErrAccountNotFound when the account does not exist.
+	DeleteAccount(accountID int64) error
+	// AccountName returns the display name of the account, or ErrAccountNotFound.
+	AccountName(accountID int64) (string, error)
+	// GetAccountDetails returns the account together with its privacy settings and stats, or ErrAccountNotFound.
+	GetAccountDetails(accountID int64) (*AccountDetails, error)
+	// UpdatePrivacy sets every non-nil field of the update on the account's privacy settings. It returns
+	// ErrAccountNotFound when the account does not exist.
+	UpdatePrivacy(accountID int64, update *PrivacySettingsParam) error
+
+	// IsEmailVerified returns ErrAccountNotFound when the account does not exist.
+	IsEmailVerified(accountID int64) (bool, error)
+	// CreateEmailVerification stores the verification, replacing any earlier one for the same account.
+	CreateEmailVerification(verification *EmailVerification) error
+	// GetEmailVerification returns the account's outstanding verification, or ErrEmailVerificationNotFound.
+	GetEmailVerification(accountID int64) (*EmailVerification, error)
+	// VerifyEmail uses up the verification with the token hash and marks the account's email as verified,
+	// provided the email has not changed since the token was sent. It returns the account's ID, or
+	// ErrEmailVerificationNotFound when the token is unknown, expired or was sent to an old email.
+	VerifyEmail(tokenHash string, now time.Time) (int64, error)
+}
+
+// PostgresDeleteHook cleans up data which refers to an account inside the transaction deleting it.
+// The account row is already gone when the hook runs, along with everything that cascades from it.
+type PostgresDeleteHook func(tx *sqlx.Tx, accountID int64) error
+
+// PostgresAccountRepository stores accounts in the account table and passwords in the passwords table.
+type PostgresAccountRepository struct {
+	*auth.PostgresCredentialRepository
+	DB *sqlx.DB
+
+	// DeleteHooks run in the transaction which deletes an account. They let packages which depend on
+	// accounts, such as lobby, take part in the deletion.
+	DeleteHooks []PostgresDeleteHook
+}
+
+// NewPostgresAccountRepository creates an AccountRepository backed by Postgres.
+func NewPostgresAccountRepository(db *sqlx.DB) *PostgresAccountRepository {
+	return &PostgresAccountRepository{
+		PostgresCredentialRepository: auth.NewPostgresCredentialRepository(db),
+		DB:                           db,
+	}
+}
+
+func (p *PostgresAccountRepository) CreateAccount(account *Account) (int64, error) {
+	return insertAccount(p.DB, account)
+}
+
+func insertAccount(q sqlx.Queryer, account *Account) (int64, error) {
+	var id int64
+	err := q.QueryRowx("INSERT INTO account (name, info, location, email, experience_level) VALUES ($1, $2, $3, $4, $5) RETURNING id", account.Name, account.Info, account.Location, account.Email, account.ExperienceLevel).Scan(&id)
+	if err != nil {
+		return 0, errors.New("an error occurred while inserting an account into the database: " + err.Error())
+	}
+
+	return id, nil
+}
+
+func (p *PostgresAccountRepository) RegisterAccount(account *Account, hash string, store *auth.SessionStore) (*auth.Session, error) {
+	tx, err := p.DB.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while starting a transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	accountID, err := insertAccount(tx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auth.NewPostgresCredentialRepository(tx).StoreCredentials(account.Email, hash); err != nil {
+		return nil, err
+	}
+
+	session, err := store.CreateSessionIn(auth.NewPostgresSessionRepository(tx), int(accountID))
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while creating a session for the account: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("an error occurred while committing the account: %v", err)
+	}
+
+	return session, nil
+}
+
+func (p *PostgresAccountRepository) EmailExists(email string) (bool, error) {
+	result, err := p.DB.Query("SELECT * from account WHERE email = $1", email)
+	if err != nil {
+		return false, err
+	}
+
+	defer result.Close()
+
+	// If result.Next() returns true, there is at least one row, so the email is taken.
+	return result.Next(), nil
+}
+
+func (p *PostgresAccountRepository) GetAccount(accountID int64) (*Account, error) {
+	var (
+		account         Account
+		experienceLevel int
+	)
+
+	if err := p.DB.QueryRow("SELECT name, info, location, email, experience_level FROM account WHERE id = $1", accountID).
+		Scan(&account.Name, &account.Info, &account.Location, &account.Email, &experienceLevel); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrAccountNotFound
+		}
+		return nil, err
+	}
+
+	account.ExperienceLevel = ExperienceLevel(experienceLevel)
+	return &account, nil
+}
+
+func (p *PostgresAccountRepository) UpdateAccount(accountID int64, update *AccountParam) error {
+	query := "UPDATE account SET "
+	params := []interface{}{}
+	paramIndex := 1
+
+	if update.Name != nil {
+		query += fmt.Sprintf("name = $%d, ", paramIndex)
+		params = append(params, update.Name)
+		paramIndex++
+	}
+	if update.Info != nil {
+		query += fmt.Sprintf("info = $%d, ", paramIndex)
+		params = append(params, update.Info)
+		paramIndex++
+	}
+	if update.Location != nil {
+		query += fmt.Sprintf("location = $%d, ", paramIndex)
+		params = append(params, update.Location)
+		paramIndex++
+	}
+	if update.Email != nil {
+		// A new email has to be verified again
+		query += fmt.Sprintf("email = $%d, email_verified = email_verified AND email = $%d, ", paramIndex, paramIndex)
+		params = append(params, update.Email)
+		paramIndex++
+	}
+	if update.ExperienceLevel != nil {
+		query += fmt.Sprintf("experience_level = $%d, ", paramIndex)
+		params = append(params, update.ExperienceLevel)
+		paramIndex++
+	}
+
+	if len(params) == 0 {
+		return nil
+	}
+
+	// Remove the trailing comma and space
+	query = query[:len(query)-2]
+	query += fmt.Sprintf(" WHERE id = $%d", paramIndex)
+	params = append(params, accountID)
+
+	result, err := p.DB.Exec(query, params...)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" && pqErr.Constraint == "account_email_key" {
+		return ErrEmailInUse
+	}
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("an error occurred while checking the affected rows: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrAccountNotFound
+	}
+
+	return nil
+}
+
+func (p *PostgresAccountRepository) DeleteAccount(accountID int64) error {
+	tx, err := p.DB.Beginx()
+	if err != nil {
+		return fmt.Errorf("an error occurred while starting a transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("DELETE FROM account WHERE id = $1", accountID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("an error occurred while checking the affected rows: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrAccountNotFound
+	}
+
+	// Sessions do not reference the account table, so they are not removed by the cascade
+	if err := auth.NewPostgresSessionRepository(tx).DeleteAccountSessions(accountID); err != nil {
+		return fmt.Errorf("an error occurred while deleting the sessions of the account: %v", err)
+	}
+
+	for _, hook := range p.DeleteHooks {
+		if err := hook(tx, accountID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (p *PostgresAccountRepository) AccountName(accountID int64) (string, error) {
+	var name string
+	if err := p.DB.QueryRow("SELECT name FROM account WHERE id = $1", accountID).Scan(&name); err != nil {
+		if err == sql.ErrNoRows {
+			return "", ErrAccountNotFound
+		}
+		return "", err
+	}
+	return name, nil
+}
+
+func (p *PostgresAccountRepository) GetAccountDetails(accountID int64) (*AccountDetails, error) {
+	var (
+		details         AccountDetails
+		experienceLevel int
+	)
+
+	query := `SELECT id, name, info, location, email, experience_level, email_verified, location_visibility, info_visibility, created_at,
+	(SELECT count(*) FROM game WHERE game.host_account_id = account.id)
+FROM account WHERE id = $1`
+	if err := p.DB.QueryRow(query, accountID).Scan(
+		&details.ID, &details.Name, &details.Info, &details.Location, &details.Email, &experienceLevel, &details.EmailVerified,
+		&details.Privacy.LocationVisibility, &details.Privacy.InfoVisibility, &details.JoinedAt, &details.Stats.GamesHosted,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrAccountNotFound
+		}
+		return nil, err
+	}
+
+	details.ExperienceLevel = ExperienceLevel(experienceLevel)
+	return &details, nil
+}
+
+func (p *PostgresAccountRepository) UpdatePrivacy(accountID int64, update *PrivacySettingsParam) error {
+	query := "UPDATE account SET location_visibility = COALESCE($1, location_visibility), info_visibility = COALESCE($2, info_visibility) WHERE id = $3"
+	result, err := p.DB.Exec(query, update.LocationVisibility, update.InfoVisibility, accountID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("an error occurred while checking the affected rows: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrAccountNotFound
+	}
+
+	return nil
+}
+
+func (p *PostgresAccountRepository) IsEmailVerified(accountID int64) (bool, error) {
+	var verified bool
+	if err := p.DB.QueryRow("SELECT email_verified FROM account WHERE id = $1", accountID).Scan(&verified); err != nil {
+		if err == sql.ErrNoRows {
+			return false, ErrAccountNotFound
+		}
+		return false, err
+	}
+	return verified, nil
+}
+
+func (p *PostgresAccountRepository) CreateEmailVerification(verification *EmailVerification) error {
+	tx, err := p.DB.Beginx()
+	if err != nil {
+		return fmt.Errorf("an error occurred while starting a transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM email_verifications WHERE account_id = $1", verification.AccountID); err != nil {
+		return err
+	}
+
+	query := "INSERT INTO email_verifications (token_hash, account_id, email, created_at, expires_at) VALUES (:token_hash, :account_id, :email, :created_at, :expires_at)"
+	if _, err := tx.NamedExec(query, verification); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (p *PostgresAccountRepository) GetEmailVerification(accountID int64) (*EmailVerification, error) {
+	var verification EmailVerification
+	query := "SELECT token_hash, account_id, email, created_at, expires_at FROM email_verifications WHERE account_id = $1"
+	if err := p.DB.Get(&verification, query, accountID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrEmailVerificationNotFound
+		}
+		return nil, err
+	}
+	return &verification, nil
+}
+
+func (p *PostgresAccountRepository) VerifyEmail(tokenHash string, now time.Time) (int64, error) {
+	// The token is used up even when the email has since changed, since it can never be used again
+	query := `WITH used AS (DELETE FROM email_verifications WHERE token_hash = $1 AND expires_at > $2 RETURNING account_id, email)
+UPDATE account SET email_verified = true FROM used WHERE account.id = used.account_id AND account.email = used.email RETURNING account.id`
+
+	var accountID int64
+	if err := p.DB.QueryRow(query, tokenHash, now).Scan(&accountID); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, ErrEmailVerificationNotFound
+		}
+		return 0, err
+	}
+	return accountID, nil
+}
+
+// memoryAccount is an account held by MemoryAccountRepository together with its password.
+type memoryAccount struct {
+	account       Account
+	credentials   *auth.Credentials
+	emailVerified bool
+	privacy       PrivacySettings
+	createdAt     time.Time
+}
+
+// MemoryDeleteHook prepares the clean up of data which refers to an account being deleted. Memory has no
+// transactions to roll back, so a hook must change nothing when it fails, and otherwise return a commit
+// function which cannot fail. Commits only run once every hook has been prepared.
+type MemoryDeleteHook func(accountID int64) (commit func(), err error)
+
+// MemoryAccountRepository keeps accounts in memory. It is meant for tests and for running the server without a database.
+type MemoryAccountRepository struct {
+	// DeleteHooks are prepared before an account is deleted and stop the deletion when they fail, leaving
+	// everything as it was. Unlike Postgres, sessions are not kept with the accounts, so a hook deleting
+	// them belongs here too.
+	DeleteHooks []MemoryDeleteHook
+
+	// HostedGames counts the games an account hosts for its profile stats. Games are kept by another
+	// repository, so without it the count is always 0.
+	HostedGames func(accountID int64) (int, error)
+
+	mu            sync.RWMutex
+	nextID        int64
+	accounts      map[int64]*memoryAccount
+	verifications map[string]EmailVerification
+}
+
+// NewMemoryAccountRepository creates an empty in-memory AccountRepository.
+func NewMemoryAccountRepository() *MemoryAccountRepository {
+	return &MemoryAccountRepository{accounts: map[int64]*memoryAccount{}, verifications: map[string]EmailVerification{}}
+}
+
+func (m *MemoryAccountRepository) CreateAccount(account *Account) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.createAccount(account)
+}
+
+// createAccount stores a new account. The caller must hold the lock.
+func (m *MemoryAccountRepository) createAccount(account *Account) (int64, error) {
+	if m.findByEmail(account.Email) != 0 {
+		return 0, fmt.Errorf("an error occurred while inserting an account into the database: %w", ErrEmailInUse)
+	}
+
+	m.nextID++
+	m.accounts[m.nextID] = &memoryAccount{account: *account, privacy: DefaultPrivacySettings, createdAt: time.Now()}
+	return m.nextID, nil
+}
+
+func (m *MemoryAccountRepository) RegisterAccount(account *Account, hash string, store *auth.SessionStore) (*auth.Session, error) {
+	m.mu.Lock()
+	accountID, err := m.createAccount(account)
+	if err == nil {
+		m.accounts[accountID].credentials = &auth.Credentials{AccountID: int(accountID), Hash: hash}
+	}
+	m.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := store.CreateSession(int(accountID))
+	if err != nil {
+		// Undo the account so that the email can be used again
+		m.mu.Lock()
+		delete(m.accounts, accountID)
+		m.mu.Unlock()
+		return nil, fmt.Errorf("an error occurred while creating a session for the account: %v", err)
+	}
+
+	return session, nil
+}
+
+func (m *MemoryAccountRepository) EmailExists(email string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.findByEmail(email) != 0, nil
+}
+
+func (m *MemoryAccountRepository) GetAccount(accountID int64) (*Account, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stored, ok := m.accounts[accountID]
+	if !ok {
+		return nil, ErrAccountNotFound
+	}
+
+	account := stored.account
+	return &account, nil
+}
+
+func (m *MemoryAccountRepository) UpdateAccount(accountID int64, update *AccountParam) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.accounts[accountID]
+	if !ok {
+		return ErrAccountNotFound
+	}
+
+	if update.Email != nil {
+		if owner := m.findByEmail(*update.Email); owner != 0 && owner != accountID {
+			return ErrEmailInUse
+		}
+	}
+
+	if update.Name != nil {
+		stored.account.Name = *update.Name
+	}
+	if update.Info != nil {
+		stored.account.Info = *update.Info
+	}
+	if update.Location != nil {
+		stored.account.Location = *update.Location
+	}
+	if update.Email != nil {
+		if *update.Email != stored.account.Email {
+			stored.emailVerified = false
+		}
+		stored.account.Email = *update.Email
+	}
+	if update.ExperienceLevel != nil {
+		stored.account.ExperienceLevel = *update.ExperienceLevel
+	}
+
+	return nil
+}
+
+func (m *MemoryAccountRepository) DeleteAccount(accountID int64) error {
+	m.mu.RLock()
+	_, ok := m.accounts[accountID]
+	m.mu.RUnlock()
+	if !ok {
+		return ErrAccountNotFound
+	}
+
+	// The hooks may look accounts up, so they run without the lock
+	commits := make([]func(), 0, len(m.DeleteHooks))
+	for _, hook := range m.DeleteHooks {
+		commit, err := hook(accountID)
+		if err != nil {
+			return err
+		}
+		commits = append(commits, commit)
+	}
+
+	if err := m.deleteAccount(accountID); err != nil {
+		return err
+	}
+
+	for _, commit := range commits {
+		commit()
+	}
+	return nil
+}
+
+// deleteAccount removes the account and its email verifications.
+func (m *MemoryAccountRepository) deleteAccount(accountID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// The account may have been deleted while the hooks were prepared
+	if _, ok := m.accounts[accountID]; !ok {
+		return ErrAccountNotFound
+	}
+
+	delete(m.accounts, accountID)
+	for tokenHash, verification := range m.verifications {
+		if verification.AccountID == accountID {
+			delete(m.verifications, tokenHash)
+		}
+	}
+	return nil
+}
+
+func (m *MemoryAccountRepository) AccountName(accountID int64) (string, error) {
+	account, err := m.GetAccount(accountID)
+	if err != nil {
+		return "", err
+	}
+	return account.Name, nil
+}
+
+func (m *MemoryAccountRepository) GetAccountDetails(accountID int64) (*AccountDetails, error) {
+	m.mu.RLock()
+	stored, ok := m.accounts[accountID]
+	var details AccountDetails
+	if ok {
+		details = AccountDetails{
+			ID:            accountID,
+			Account:       stored.account,
+			EmailVerified: stored.emailVerified,
+			Privacy:       stored.privacy,
+			JoinedAt:      stored.createdAt,
+		}
+	}
+	m.mu.RUnlock()
+	if !ok {
+		return nil, ErrAccountNotFound
+	}
+
+	// The hook may belong to a repository which looks accounts up, so it runs without the lock
+	if m.HostedGames != nil {
+		gamesHosted, err := m.HostedGames(accountID)
+		if err != nil {
+			return nil, err
+		}
+		details.Stats.GamesHosted = gamesHosted
+	}
+
+	return &details, nil
+}
+
+func (m *MemoryAccountRepository) UpdatePrivacy(accountID int64, update *PrivacySettingsParam) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.accounts[accountID]
+	if !ok {
+		return ErrAccountNotFound
+	}
+
+	if update.LocationVisibility != nil {
+		stored.privacy.LocationVisibility = *update.LocationVisibility
+	}
+	if update.InfoVisibility != nil {
+		stored.privacy.InfoVisibility = *update.InfoVisibility
+	}
+
+	return nil
+}
+
+func (m *MemoryAccountRepository) StoreCredentials(accountEmail string, hash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := m.findByEmail(accountEmail)
+	if id == 0 {
+		return errors.New("an error occurred while inserting a password hash into the database: no account uses the email")
+	}
+
+	m.accounts[id].credentials = &auth.Credentials{AccountID: int(id), Hash: hash}
+	return nil
+}
+
+func (m *MemoryAccountRepository) UpdateCredentials(accountID int64, hash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.accounts[accountID]
+	if !ok || stored.credentials == nil {
+		return auth.ErrCredentialsNotFound
+	}
+
+	stored.credentials = &auth.Credentials{AccountID: int(accountID), Hash: hash}
+	return nil
+}
+
+func (m *MemoryAccountRepository) CredentialsByEmail(email string) (*auth.Credentials, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.credentials(m.findByEmail(email))
+}
+
+func (m *MemoryAccountRepository) CredentialsByAccountID(accountID int64) (*auth.Credentials, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.credentials(accountID)
+}
+
+func (m *MemoryAccountRepository) credentials(accountID int64) (*auth.Credentials, error) {
+	stored, ok := m.accounts[accountID]
+	if !ok || stored.credentials == nil {
+		return nil, auth.ErrCredentialsNotFound
+	}
+
+	credentials := *stored.credentials
+	return &credentials, nil
+}
+
+func (m *MemoryAccountRepository) IsEmailVerified(accountID int64) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stored, ok := m.accounts[accountID]
+	if !ok {
+		return false, ErrAccountNotFound
+	}
+	return stored.emailVerified, nil
+}
+
+func (m *MemoryAccountRepository) CreateEmailVerification(verification *EmailVerification) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for tokenHash, existing := range m.verifications {
+		if existing.AccountID == verification.AccountID {
+			delete(m.verifications, tokenHash)
+		}
+	}
+
+	m.verifications[verification.TokenHash] = *verification
+	return nil
+}
+
+func (m *MemoryAccountRepository) GetEmailVerification(accountID int64) (*EmailVerification, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, verification := range m.verifications {
+		if verification.AccountID == accountID {
+			return &verification, nil
+		}
+	}
+	return nil, ErrEmailVerificationNotFound
+}
+
+func (m *MemoryAccountRepository) VerifyEmail(tokenHash string, now time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	verification, ok := m.verifications[tokenHash]
+	if !ok || !verification.ExpiresAt.After(now) {
+		return 0, ErrEmailVerificationNotFound
+	}
+	delete(m.verifications, tokenHash)
+
+	stored, ok := m.accounts[verification.AccountID]
+	if !ok || stored.account.Email != verification.Email {
+		return 0, ErrEmailVerificationNotFound
+	}
+
+	stored.emailVerified = true
+	return verification.AccountID, nil
+}
+
+// findByEmail returns the ID of the account using the email, or 0. The caller must hold the lock.
+func (m *MemoryAccountRepository) findByEmail(email string) int64 {
+	for id, stored := range m.accounts {
+		if stored.account.Email == email {
+			return id
+		}
+	}
+	return 0
+}
@@ -0,0 +1,248 @@
+package account
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	auth "github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/mail"
+)
+
+func TestMemoryAccountRepository_AccountLifecycle(t *testing.T) {
+	accounts := NewMemoryAccountRepository()
+	sessions := auth.NewMemorySessionRepository()
+	store := auth.NewSessionStoreWithRepository(sessions)
+	accounts.DeleteHooks = append(accounts.DeleteHooks, func(accountID int64) (func(), error) {
+		return func() { sessions.DeleteAccountSessions(accountID) }, nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/account/create_account", strings.NewReader(`{"account": {"name": "Player", "info": "", "location": "", "email": "player@example.com", "experience_level": 2}, "password": "password123"}`))
+	rr := httptest.NewRecorder()
+
+	session, err := CreateAccount(rr, req, accounts, store, mail.LogSender{})
+	if err != nil {
+		t.Fatalf("CreateAccount() error = %v", err)
+	}
+
+	bearer := "Bearer " + session.Token
+
+	req = httptest.NewRequest(http.MethodPut, "/account/update_account", strings.NewReader(`{"account": {"name": "Renamed"}, "password": "password123", "account_id": 1}`))
+	req.Header.Set("Authorization", bearer)
+	rr = httptest.NewRecorder()
+
+	if err := UpdateAccount(rr, req, accounts, store, auth.NewLockout(auth.NewMemoryLoginAttemptRepository())); err != nil {
+		t.Fatalf("UpdateAccount() error = %v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/account/get_account?account_id=1", nil)
+	req.Header.Set("Authorization", bearer)
+	rr = httptest.NewRecorder()
+
+	if err := GetAccount(rr, req, accounts, store); err != nil {
+		t.Fatalf("GetAccount() error = %v", err)
+	}
+
+	var account Account
+	if err := json.Unmarshal(rr.Body.Bytes(), &account); err != nil {
+		t.Fatal(err)
+	}
+
+	if account.Name != "Renamed" || account.Email != "player@example.com" || account.ExperienceLevel != Medium {
+		t.Errorf("unexpected account: %+v", account)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/account/delete_account", strings.NewReader(`{"account_id": 1}`))
+	req.Header.Set("Authorization", bearer)
+	rr = httptest.NewRecorder()
+
+	if err := DeleteAccount(rr, req, accounts, store); err != nil {
+		t.Fatalf("DeleteAccount() error = %v", err)
+	}
+
+	if _, err := accounts.GetAccount(1); err != ErrAccountNotFound {
+		t.Errorf("expected ErrAccountNotFound, got %v", err)
+	}
+
+	if session, err := store.GetSessionByToken(session.Token); session != nil || err != nil {
+		t.Errorf("expected the account's sessions to be deleted with it, got %+v, %v", session, err)
+	}
+}
+
+// failingSessionRepository is a session repository which cannot store sessions.
+type failingSessionRepository struct {
+	*auth.MemorySessionRepository
+}
+
+func (failingSessionRepository) CreateSession(session *auth.Session) error {
+	return errors.New("the session could not be stored")
+}
+
+func TestMemoryAccountRepository_RegisterAccountIsUndoneWhenSessionFails(t *testing.T) {
+	accounts := NewMemoryAccountRepository()
+	store := auth.NewSessionStoreWithRepository(failingSessionRepository{auth.NewMemorySessionRepository()})
+
+	if _, err := accounts.RegisterAccount(&Account{Name: "Player", Email: "player@example.com"}, "hash", store); err == nil {
+		t.Fatal("expected an error when the session could not be stored")
+	}
+
+	if exists, _ := accounts.EmailExists("player@example.com"); exists {
+		t.Error("expected the email to be free for another attempt")
+	}
+}
+
+func TestMemoryAccountRepository_FailingDeleteHookKeepsAccount(t *testing.T) {
+	accounts := NewMemoryAccountRepository()
+	committed := false
+	accounts.DeleteHooks = append(accounts.DeleteHooks, func(accountID int64) (func(), error) {
+		return func() { committed = true }, nil
+	}, func(accountID int64) (func(), error) {
+		return nil, errors.New("the account's lobbies could not be handed over")
+	})
+
+	id, err := accounts.CreateAccount(&Account{Name: "Player", Email: "player@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := accounts.DeleteAccount(id); err == nil {
+		t.Fatal("expected the hook's error")
+	}
+
+	if _, err := accounts.GetAccount(id); err != nil {
+		t.Errorf("expected the account to be kept, got %v", err)
+	}
+	if committed {
+		t.Error("expected the hooks which were ready not to be committed")
+	}
+}
+
+func TestMemoryAccountRepository_CredentialsFollowEmail(t *testing.T) {
+	accounts := NewMemoryAccountRepository()
+
+	id, err := accounts.CreateAccount(&Account{Name: "Player", Email: "old@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := accounts.StoreCredentials("old@example.com", "hash"); err != nil {
+		t.Fatal(err)
+	}
+
+	email := "new@example.com"
+	if err := accounts.UpdateAccount(id, &AccountParam{Email: &email}); err != nil {
+		t.Fatal(err)
+	}
+
+	credentials, err := accounts.CredentialsByEmail(email)
+	if err != nil || credentials.AccountID != int(id) || credentials.Hash != "hash" {
+		t.Errorf("expected the credentials to follow the new email, got %+v, %v", credentials, err)
+	}
+
+	if _, err := accounts.CredentialsByEmail("old@example.com"); err != auth.ErrCredentialsNotFound {
+		t.Errorf("expected ErrCredentialsNotFound, got %v", err)
+	}
+
+	if exists, _ := accounts.EmailExists(email); !exists {
+		t.Error("expected the new email to be in use")
+	}
+}
+
+func TestMemoryAccountRepository_EmailsAreUnique(t *testing.T) {
+	accounts := NewMemoryAccountRepository()
+
+	first, err := accounts.CreateAccount(&Account{Name: "First", Email: "first@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := accounts.CreateAccount(&Account{Name: "Second", Email: "second@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := accounts.CreateAccount(&Account{Name: "Third", Email: "first@example.com"}); !errors.Is(err, ErrEmailInUse) {
+		t.Errorf("expected ErrEmailInUse creating an account, got %v", err)
+	}
+
+	email := "first@example.com"
+	name := "Renamed"
+	if err := accounts.UpdateAccount(second, &AccountParam{Name: &name, Email: &email}); !errors.Is(err, ErrEmailInUse) {
+		t.Errorf("expected ErrEmailInUse updating an account, got %v", err)
+	}
+	if account, _ := accounts.GetAccount(second); account.Email != "second@example.com" || account.Name != "Second" {
+		t.Errorf("expected the refused update to change nothing, got %+v", account)
+	}
+
+	// An account can keep its own email
+	if err := accounts.UpdateAccount(first, &AccountParam{Name: &name, Email: &email}); err != nil {
+		t.Errorf("UpdateAccount() error = %v", err)
+	}
+}
+
+func TestMemoryAccountRepository_V2Routes(t *testing.T) {
+	accounts := NewMemoryAccountRepository()
+	store := auth.NewSessionStoreWithRepository(auth.NewMemorySessionRepository())
+	lockout := auth.NewLockout(auth.NewMemoryLoginAttemptRepository())
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v2/accounts", func(w http.ResponseWriter, r *http.Request) {
+		CreateAccountHandler(w, r, accounts, store, mail.LogSender{})
+	})
+	mux.HandleFunc("GET /v2/accounts/{id}", func(w http.ResponseWriter, r *http.Request) {
+		GetAccountV2Handler(w, r, accounts, store)
+	})
+	mux.HandleFunc("PATCH /v2/accounts/{id}", func(w http.ResponseWriter, r *http.Request) {
+		UpdateAccountV2Handler(w, r, accounts, store, lockout)
+	})
+	mux.HandleFunc("PUT /v2/accounts/{id}/password", func(w http.ResponseWriter, r *http.Request) {
+		ChangePasswordV2Handler(w, r, accounts, store, lockout)
+	})
+	mux.HandleFunc("DELETE /v2/accounts/{id}", func(w http.ResponseWriter, r *http.Request) {
+		DeleteAccountV2Handler(w, r, accounts, store)
+	})
+
+	call := func(method string, url string, body string, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := call(http.MethodPost, "/v2/accounts", `{"account": {"name": "Player", "email": "player@example.com", "experience_level": 2}, "password": "password123"}`, "")
+	var session auth.Session
+	if err := json.Unmarshal(rr.Body.Bytes(), &session); err != nil || session.Token == "" {
+		t.Fatalf("POST /v2/accounts returned %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if rr := call(http.MethodPatch, "/v2/accounts/1", `{"account": {"name": "Renamed"}, "password": "password123"}`, session.Token); rr.Code != http.StatusOK {
+		t.Fatalf("PATCH /v2/accounts/1 returned %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if rr := call(http.MethodPut, "/v2/accounts/1/password", `{"current_password": "password123", "new_password": "new-password"}`, session.Token); rr.Code != http.StatusOK {
+		t.Fatalf("PUT /v2/accounts/1/password returned %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = call(http.MethodGet, "/v2/accounts/1", "", session.Token)
+	var account Account
+	if err := json.Unmarshal(rr.Body.Bytes(), &account); err != nil || account.Name != "Renamed" {
+		t.Fatalf("GET /v2/accounts/1 returned %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if rr := call(http.MethodDelete, "/v2/accounts/2", "", session.Token); rr.Code != http.StatusForbidden {
+		t.Errorf("expected deleting another account to be refused, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if rr := call(http.MethodDelete, "/v2/accounts/1", "", session.Token); rr.Code != http.StatusOK {
+		t.Fatalf("DELETE /v2/accounts/1 returned %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if _, err := accounts.GetAccount(1); err != ErrAccountNotFound {
+		t.Errorf("expected ErrAccountNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package account
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	auth "github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+	"github.com/justinfarrelldev/open-ctp-server/internal/mail"
+)
+
+// ResendVerificationArgs represents the expected structure of the request body for re-sending a verification email.
+//
+// @Description Structure for the verification re-send request payload.
+type ResendVerificationArgs struct {
+	// The account ID for the account whose email should be verified.
+	AccountId *int64 `json:"account_id" validate:"required"`
+}
+
+// ResendVerification emails a new verification token to an account, replacing the previous one.
+//
+// @Summary Re-send the verification email
+// @Description This endpoint emails a new verification token to the account's current email. The previous token stops working. Only one email is sent per minute.
+// @Tags account
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Param body body ResendVerificationArgs true "verification re-send request body"
+// @Success 202 {object} httpapi.MessageResponse "A new verification email has been sent."
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 429 {object} httpapi.ErrorResponse "Too Many Requests"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /account/resend_verification [post]
+func ResendVerification(w http.ResponseWriter, r *http.Request, accounts AccountRepository, store *auth.SessionStore, sender mail.Sender) error {
+
+	if r.Method != http.MethodPost {
+		return httpapi.MethodNotAllowed(http.MethodPost)
+	}
+
+	args := ResendVerificationArgs{}
+	err := httpapi.DecodeJSON(r, &args)
+	if err != nil {
+		return err
+	}
+
+	return resendVerification(w, r, args, accounts, store, sender)
+}
+
+// ResendVerificationV2 emails a new verification token to the account with the ID in the path.
+//
+// @Summary Re-send the verification email
+// @Description This endpoint emails a new verification token to the account's current email. The previous token stops working. Only one email is sent per minute.
+// @Tags account
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Param id path int true "account ID"
+// @Success 202 {object} httpapi.MessageResponse "A new verification email has been sent."
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 429 {object} httpapi.ErrorResponse "Too Many Requests"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /v2/accounts/{id}/verification-emails [post]
+func ResendVerificationV2(w http.ResponseWriter, r *http.Request, accounts AccountRepository, store *auth.SessionStore, sender mail.Sender) error {
+	accountId, err := httpapi.PathID(r, "id")
+	if err != nil {
+		return err
+	}
+
+	return resendVerification(w, r, ResendVerificationArgs{AccountId: &accountId}, accounts, store, sender)
+}
+
+// resendVerification emails a new verification token to the account in args.
+func resendVerification(w http.ResponseWriter, r *http.Request, args ResendVerificationArgs, accounts AccountRepository, store *auth.SessionStore, sender mail.Sender) error {
+	if err := httpapi.Validate(&args); err != nil {
+		return err
+	}
+
+	session, err := store.Authenticate(r, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := auth.RequireAccount(session, *args.AccountId); err != nil {
+		return err
+	}
+
+	verified, err := accounts.IsEmailVerified(*args.AccountId)
+	if err != nil {
+		return fmt.Errorf("an error occurred while checking whether the account's email is verified: %v", err)
+	}
+
+	if verified {
+		return httpapi.Validation(ERROR_EMAIL_ALREADY_VERIFIED).WithCode(CodeEmailAlreadyVerified)
+	}
+
+	// Keep the account from being used to flood an inbox
+	previous, err := accounts.GetEmailVerification(*args.AccountId)
+	if err != nil && !errors.Is(err, ErrEmailVerificationNotFound) {
+		return fmt.Errorf("an error occurred while getting the previous verification: %v", err)
+	}
+	if previous != nil && time.Since(previous.CreatedAt) < VerificationResendInterval {
+		retryAfter := VerificationResendInterval - time.Since(previous.CreatedAt)
+		return httpapi.TooManyRequests(ERROR_VERIFICATION_RECENTLY_SENT, retryAfter).WithCode(CodeVerificationRecentlySent)
+	}
+
+	account, err := accounts.GetAccount(*args.AccountId)
+	if err != nil {
+		return fmt.Errorf("an error occurred while getting the account with the ID %d: %v", *args.AccountId, err)
+	}
+
+	if err := sendVerificationEmail(accounts, sender, *args.AccountId, account.Email); err != nil {
+		log.Println("error sending a verification email: ", err.Error())
+		return errors.New("an error occurred while sending the verification email. Please try again at a later time")
+	}
+
+	httpapi.WriteMessage(w, http.StatusAccepted, "A new verification email has been sent.")
+	return nil
+}
@@ -1,15 +1,12 @@
 package account
 
 import (
-	"encoding/base64"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
-	"reflect"
 
-	"github.com/jmoiron/sqlx"
 	auth "github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
 )
 
 // UpdateAccountArgs represents the expected structure of the request body for updating an account.
@@ -17,15 +14,26 @@ import (
 // @Description Structure for the account update request payload.
 type UpdateAccountArgs struct {
 	// The account to create.
-	Account *AccountParam `json:"account"`
+	Account *AccountParam `json:"account" validate:"required,nonempty"`
 	// The password for the account to be created
-	Password *string `json:"password"`
+	Password *string `json:"password" validate:"required"`
 	// The account ID for the account that will be updated.
-	AccountId *int64 `json:"account_id"`
-	// A valid session ID for the account (so we know they are signed in)
+	AccountId *int64 `json:"account_id" validate:"required"`
+	// Deprecated: a valid numeric session ID for the account. Send the session token in the Authorization header instead.
 	SessionId *int64 `json:"session_id"`
 }
 
+// UpdateAccountBody represents the expected structure of the request body for updating an account through
+// /v2, which takes the account ID from the path.
+//
+// @Description Structure for the v2 account update request payload.
+type UpdateAccountBody struct {
+	// The fields of the account to change. Fields which are left out keep their current value.
+	Account *AccountParam `json:"account"`
+	// The account's password.
+	Password *string `json:"password"`
+}
+
 // UpdateAccount updates an account by the account ID.
 //
 // @Summary Updates an account
@@ -33,150 +41,114 @@ type UpdateAccountArgs struct {
 // @Tags account
 // @Accept json
 // @Produce json
+// @Param Authorization header string false "Bearer session token"
 // @Param body body UpdateAccountArgs true "account update request body"
 // @Success 200 {object} nil "Successfully updated account!"
-// @Failure 400 {string} string "account_id must be specified"
-// @Failure 500 {string} string "an error occurred while decoding the request body: <error message>"
+// @Failure 400 {object} httpapi.ErrorResponse "account_id must be specified"
+// @Failure 401 {object} httpapi.ErrorResponse "a session token must be provided, or the current password is incorrect"
+// @Failure 403 {object} httpapi.ErrorResponse "the session does not belong to the account being acted on"
+// @Failure 404 {object} httpapi.ErrorResponse "no account exists with the ID <account_id>"
+// @Failure 409 {object} httpapi.ErrorResponse "an account already uses the provided email"
+// @Failure 429 {object} httpapi.ErrorResponse "too many failed password attempts; please try again later"
+// @Failure 500 {object} httpapi.ErrorResponse "an error occurred while decoding the request body: <error message>"
 // @Router /account/update_account [put]
-func UpdateAccount(w http.ResponseWriter, r *http.Request, db *sqlx.DB, store *auth.SessionStore) error {
+func UpdateAccount(w http.ResponseWriter, r *http.Request, accounts AccountRepository, store *auth.SessionStore, lockout *auth.Lockout) error {
 
 	if r.Method != http.MethodPut {
-		return errors.New("invalid request; request must be a PUT request")
+		return httpapi.MethodNotAllowed(http.MethodPut)
 	}
 
-	decoder := json.NewDecoder(r.Body)
-	decoder.DisallowUnknownFields()
-
 	args := UpdateAccountArgs{}
-	err := decoder.Decode(&args)
-
+	err := httpapi.DecodeJSON(r, &args)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		return errors.New("an error occurred while decoding the request body:" + err.Error())
-	}
-
-	if args.AccountId == nil {
-		w.WriteHeader(http.StatusBadRequest)
-		return errors.New("account_id must be specified")
-	}
-
-	if args.SessionId == nil {
-		w.WriteHeader(http.StatusBadRequest)
-		return errors.New("a valid session_id must be specified")
+		return err
 	}
 
-	session, err := store.GetSession(*args.SessionId)
+	return updateAccount(w, r, args, accounts, store, lockout)
+}
 
+// UpdateAccountV2 updates the account with the ID in the path.
+//
+// @Summary Updates an account
+// @Description This endpoint updates an account's info. The account's password has to be given.
+// @Tags account
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Param id path int true "account ID"
+// @Param body body UpdateAccountBody true "account update request body"
+// @Success 200 {object} httpapi.MessageResponse "Successfully updated account!"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 404 {object} httpapi.ErrorResponse "Not Found"
+// @Failure 409 {object} httpapi.ErrorResponse "Conflict"
+// @Failure 429 {object} httpapi.ErrorResponse "Too Many Requests"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /v2/accounts/{id} [patch]
+func UpdateAccountV2(w http.ResponseWriter, r *http.Request, accounts AccountRepository, store *auth.SessionStore, lockout *auth.Lockout) error {
+	accountId, err := httpapi.PathID(r, "id")
 	if err != nil {
-
-		w.WriteHeader(http.StatusInternalServerError)
-		return errors.New("an error occurred while retrieving the session: " + err.Error())
+		return err
 	}
 
-	if session == nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		return errors.New("session not found")
+	body := UpdateAccountBody{}
+	if err := httpapi.DecodeJSON(r, &body); err != nil {
+		return err
 	}
 
-	if session.IsExpired() {
-		w.WriteHeader(http.StatusForbidden)
-		return errors.New("session has expired")
-	}
-
-	if args.Password == nil {
-		w.WriteHeader(http.StatusBadRequest)
-		return errors.New("the password for the account must be specified")
-	}
+	return updateAccount(w, r, UpdateAccountArgs{Account: body.Account, Password: body.Password, AccountId: &accountId}, accounts, store, lockout)
+}
 
-	if args.Account == nil {
-		w.WriteHeader(http.StatusBadRequest)
-		return errors.New("account must be specified")
+// updateAccount updates the account in args after checking its password.
+func updateAccount(w http.ResponseWriter, r *http.Request, args UpdateAccountArgs, accounts AccountRepository, store *auth.SessionStore, lockout *auth.Lockout) error {
+	if err := httpapi.Validate(&args); err != nil {
+		return err
 	}
 
-	// Get the current password hash and salt from the database
-	var storedHash, storedSalt string
-	err = db.QueryRow("SELECT hash, salt FROM passwords WHERE id = $1", args.AccountId).Scan(&storedHash, &storedSalt)
+	session, err := store.Authenticate(r, args.SessionId)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		return fmt.Errorf("error retrieving account credentials: %v", err)
+		return err
 	}
 
-	storedHashBytes, err := base64.StdEncoding.DecodeString(storedHash)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		return fmt.Errorf("error decoding stored hash: %v", err)
-	}
-	storedSaltBytes, err := base64.StdEncoding.DecodeString(storedSalt)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		return fmt.Errorf("error decoding stored salt: %v", err)
+	if err := auth.RequireAccount(session, *args.AccountId); err != nil {
+		return err
 	}
 
-	err = auth.Hasher.Compare(storedHashBytes, storedSaltBytes, []byte(*args.Password))
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		return fmt.Errorf("error comparing passwords: %v", err)
+	if err := checkCurrentPassword(r, accounts, lockout, *args.AccountId, *args.Password); err != nil {
+		return err
 	}
 
-	// Use reflection to check if at least one field other than AccountId is set
-	v := reflect.ValueOf(args)
-	numFields := v.NumField()
-	anyFieldSet := false
-
-	for i := 0; i < numFields; i++ {
-		field := v.Field(i)
-		if field.Kind() == reflect.Ptr && !field.IsNil() && v.Type().Field(i).Name != "AccountId" {
-			anyFieldSet = true
-			break
+	if args.Account.Email != nil {
+		current, err := accounts.GetAccount(*args.AccountId)
+		if err != nil {
+			return fmt.Errorf("an error occurred while getting the account with the ID %d: %v", *args.AccountId, err)
 		}
-	}
 
-	if !anyFieldSet {
-		w.WriteHeader(http.StatusBadRequest)
-		return errors.New("at least one field to update must be specified")
+		if *args.Account.Email != current.Email {
+			isAvailable, err := isEmailAvailable(*args.Account.Email, accounts)
+			if err != nil {
+				return err
+			}
+			if !isAvailable {
+				return httpapi.Conflict(ERROR_EMAIL_IN_USE).WithCode(CodeEmailInUse)
+			}
+		}
 	}
 
-	query := "UPDATE account SET "
-	params := []interface{}{}
-	paramIndex := 1
-
-	if args.Account.Name != nil {
-		query += fmt.Sprintf("name = $%d, ", paramIndex)
-		params = append(params, args.Account.Name)
-		paramIndex++
+	err = accounts.UpdateAccount(*args.AccountId, args.Account)
+	// Another account may have taken the email since it was checked
+	if errors.Is(err, ErrEmailInUse) {
+		return httpapi.Conflict(ERROR_EMAIL_IN_USE).WithCode(CodeEmailInUse)
 	}
-	if args.Account.Info != nil {
-		query += fmt.Sprintf("info = $%d, ", paramIndex)
-		params = append(params, args.Account.Info)
-		paramIndex++
+	// The account may have been deleted since its password was checked
+	if err == ErrAccountNotFound {
+		return httpapi.NotFound(fmt.Sprintf("no account exists with the ID %d", *args.AccountId))
 	}
-	if args.Account.Location != nil {
-		query += fmt.Sprintf("location = $%d, ", paramIndex)
-		params = append(params, args.Account.Location)
-		paramIndex++
-	}
-	if args.Account.Email != nil {
-		query += fmt.Sprintf("email = $%d, ", paramIndex)
-		params = append(params, args.Account.Email)
-		paramIndex++
-	}
-	if args.Account.ExperienceLevel != nil {
-		query += fmt.Sprintf("experience_level = $%d, ", paramIndex)
-		params = append(params, args.Account.ExperienceLevel)
-		paramIndex++
-	}
-
-	// Remove the trailing comma and space
-	query = query[:len(query)-2]
-	query += fmt.Sprintf(" WHERE id = $%d", paramIndex)
-	params = append(params, args.AccountId)
-
-	_, err = db.Exec(query, params...)
 	if err != nil {
-		return fmt.Errorf("an error occurred while updating the account with the ID %d: %v", args.AccountId, err)
+		return fmt.Errorf("an error occurred while updating the account with the ID %d: %v", *args.AccountId, err)
 	}
 
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("Successfully updated account!"))
+	httpapi.WriteMessage(w, http.StatusOK, "Successfully updated account!")
 	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"regexp"
@@ -13,8 +14,11 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 
 	auth "github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi/httpapitest"
 )
 
 func TestUpdateAccount_InvalidMethod(t *testing.T) {
@@ -37,21 +41,21 @@ func TestUpdateAccount_InvalidMethod(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := UpdateAccount(w, r, sqlxDB, mockStore)
+		err := UpdateAccount(w, r, NewPostgresAccountRepository(sqlxDB), mockStore, auth.NewLockout(auth.NewMemoryLoginAttemptRepository()))
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
 	handler.ServeHTTP(rr, req)
 
-	if status := rr.Code; status != http.StatusInternalServerError {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+	if status := rr.Code; status != http.StatusMethodNotAllowed {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusMethodNotAllowed)
 	}
 
 	expectedError := "invalid request; request must be a PUT request"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
 
@@ -75,21 +79,21 @@ func TestUpdateAccount_DecodeError(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := UpdateAccount(w, r, sqlxDB, mockStore)
+		err := UpdateAccount(w, r, NewPostgresAccountRepository(sqlxDB), mockStore, auth.NewLockout(auth.NewMemoryLoginAttemptRepository()))
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
 	handler.ServeHTTP(rr, req)
 
-	if status := rr.Code; status != http.StatusInternalServerError {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
 	}
 
-	expectedError := "an error occurred while decoding the request body:invalid character 'i' looking for beginning of value"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	expectedError := "an error occurred while decoding the request body: invalid character 'i' looking for beginning of value"
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
 
@@ -101,7 +105,9 @@ func TestUpdateAccount_MissingAccountID(t *testing.T) {
 	defer db.Close()
 
 	password := "John Doe Updated"
+	name := "John Doe"
 	updateArgs := UpdateAccountArgs{
+		Account:  &AccountParam{Name: &name},
 		Password: &password,
 	}
 
@@ -119,9 +125,9 @@ func TestUpdateAccount_MissingAccountID(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := UpdateAccount(w, r, sqlxDB, mockStore)
+		err := UpdateAccount(w, r, NewPostgresAccountRepository(sqlxDB), mockStore, auth.NewLockout(auth.NewMemoryLoginAttemptRepository()))
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
@@ -132,8 +138,8 @@ func TestUpdateAccount_MissingAccountID(t *testing.T) {
 	}
 
 	expectedError := "account_id must be specified"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
 func TestUpdateAccount_Success(t *testing.T) {
@@ -195,11 +201,20 @@ func TestUpdateAccount_Success(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"id", "account_id", "created_at", "expires_at"}).
 			AddRow(sessionID, accountID, createdAt, expiresAt))
 
-	mock.ExpectQuery("SELECT hash, salt FROM passwords WHERE id = \\$1").
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT account.id, passwords.hash, COALESCE(passwords.salt, '') FROM passwords JOIN account ON account.email = passwords.account_email WHERE account.id = $1")).
 		WithArgs(accountID).
-		WillReturnRows(sqlmock.NewRows([]string{"hash", "salt"}).AddRow(storedHash, storedSalt))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hash", "salt"}).AddRow(accountID, storedHash, storedSalt))
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name, info, location, email, experience_level FROM account WHERE id = $1")).
+		WithArgs(accountID).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "info", "location", "email", "experience_level"}).
+			AddRow("Name", "Info", "Location", "old@example.com", 1))
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * from account WHERE email = $1")).
+		WithArgs(email).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
 
-	mock.ExpectExec("UPDATE account SET name = \\$1, info = \\$2, location = \\$3, email = \\$4, experience_level = \\$5 WHERE id = \\$6").
+	mock.ExpectExec("UPDATE account SET name = \\$1, info = \\$2, location = \\$3, email = \\$4, email_verified = email_verified AND email = \\$4, experience_level = \\$5 WHERE id = \\$6").
 		WithArgs(name, info, location, email, experienceLevel, accountID).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
@@ -208,9 +223,9 @@ func TestUpdateAccount_Success(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := UpdateAccount(w, r, sqlxDB, mockStore)
+		err := UpdateAccount(w, r, NewPostgresAccountRepository(sqlxDB), mockStore, auth.NewLockout(auth.NewMemoryLoginAttemptRepository()))
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
@@ -221,8 +236,8 @@ func TestUpdateAccount_Success(t *testing.T) {
 	}
 
 	expectedResponse := "Successfully updated account!"
-	if strings.TrimSpace(rr.Body.String()) != expectedResponse {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedResponse)
+	if httpapitest.Message(rr) != expectedResponse {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedResponse)
 	}
 
 	if err := mock.ExpectationsWereMet(); err != nil {
@@ -271,9 +286,9 @@ func TestUpdateAccount_MissingPassword(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := UpdateAccount(w, r, sqlxDB, mockStore)
+		err := UpdateAccount(w, r, NewPostgresAccountRepository(sqlxDB), mockStore, auth.NewLockout(auth.NewMemoryLoginAttemptRepository()))
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
@@ -283,9 +298,9 @@ func TestUpdateAccount_MissingPassword(t *testing.T) {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
 	}
 
-	expectedError := "the password for the account must be specified"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	expectedError := "password must be specified"
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
 
@@ -328,9 +343,9 @@ func TestUpdateAccount_MissingAccount(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := UpdateAccount(w, r, sqlxDB, mockStore)
+		err := UpdateAccount(w, r, NewPostgresAccountRepository(sqlxDB), mockStore, auth.NewLockout(auth.NewMemoryLoginAttemptRepository()))
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
@@ -341,7 +356,144 @@ func TestUpdateAccount_MissingAccount(t *testing.T) {
 	}
 
 	expectedError := "account must be specified"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
+	}
+}
+
+func TestUpdateAccount_IncorrectPassword(t *testing.T) {
+	accounts := NewMemoryAccountRepository()
+	store := auth.NewSessionStoreWithRepository(auth.NewMemorySessionRepository())
+	lockout := auth.NewLockout(auth.NewMemoryLoginAttemptRepository())
+
+	hash, err := auth.HashPassword("password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	withPassword, err := accounts.RegisterAccount(&Account{Name: "Player", Email: "player@example.com"}, hash, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An account whose password was never stored
+	accountID, err := accounts.CreateAccount(&Account{Name: "Other", Email: "other@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	withoutPassword, err := store.CreateSession(int(accountID))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		session *auth.Session
+		body    string
+	}{
+		{"wrong password", withPassword, `{"account_id": 1, "password": "wrong-password", "account": {"name": "Renamed"}}`},
+		{"no password stored", withoutPassword, `{"account_id": 2, "password": "password123", "account": {"name": "Renamed"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/account/update_account", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+tt.session.Token)
+			rr := httptest.NewRecorder()
+
+			UpdateAccountHandler(rr, req, accounts, store, lockout)
+
+			if rr.Code != http.StatusUnauthorized || httpapitest.Code(rr) != CodeCurrentPasswordIncorrect || httpapitest.Message(rr) != ERROR_CURRENT_PASSWORD_INCORRECT {
+				t.Errorf("got %d: %s", rr.Code, rr.Body.String())
+			}
+		})
+	}
+
+	account, err := accounts.GetAccount(1)
+	if err != nil || account.Name != "Player" {
+		t.Errorf("expected the account to be left alone, got %+v, %v", account, err)
+	}
+}
+
+func TestUpdateAccount_EmailInUse(t *testing.T) {
+	accounts := NewMemoryAccountRepository()
+	store := auth.NewSessionStoreWithRepository(auth.NewMemorySessionRepository())
+	lockout := auth.NewLockout(auth.NewMemoryLoginAttemptRepository())
+
+	hash, err := auth.HashPassword("password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := accounts.RegisterAccount(&Account{Name: "First", Email: "first@example.com"}, hash, store); err != nil {
+		t.Fatal(err)
+	}
+	session, err := accounts.RegisterAccount(&Account{Name: "Second", Email: "second@example.com"}, hash, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		email          string
+		expectedStatus int
+	}{
+		{"another account's email", "first@example.com", http.StatusConflict},
+		{"its own email", "second@example.com", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"account_id": 2, "password": "password123", "account": {"email": "` + tt.email + `"}}`
+			req := httptest.NewRequest(http.MethodPut, "/account/update_account", strings.NewReader(body))
+			req.Header.Set("Authorization", "Bearer "+session.Token)
+			rr := httptest.NewRecorder()
+
+			UpdateAccountHandler(rr, req, accounts, store, lockout)
+
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v (%s)", rr.Code, tt.expectedStatus, rr.Body.String())
+			}
+			if tt.expectedStatus == http.StatusConflict && httpapitest.Code(rr) != CodeEmailInUse {
+				t.Errorf("expected %s, got %s", CodeEmailInUse, rr.Body.String())
+			}
+		})
+	}
+
+	if credentials, err := accounts.CredentialsByEmail("first@example.com"); err != nil || credentials.AccountID != 1 {
+		t.Errorf("expected the email to still belong to the first account, got %+v, %v", credentials, err)
+	}
+}
+
+func TestPostgresAccountRepository_UpdateAccountEmailInUse(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	email := "taken@example.com"
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE account SET email = $1")).
+		WillReturnError(&pq.Error{Code: "23505", Constraint: "account_email_key"})
+
+	err = NewPostgresAccountRepository(sqlx.NewDb(db, "sqlmock")).UpdateAccount(1, &AccountParam{Email: &email})
+	if !errors.Is(err, ErrEmailInUse) {
+		t.Errorf("expected ErrEmailInUse, got %v", err)
+	}
+}
+
+func TestPostgresAccountRepository_UpdateAccountNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	name := "Renamed"
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE account SET name = $1 WHERE id = $2")).
+		WithArgs(&name, 1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = NewPostgresAccountRepository(sqlx.NewDb(db, "sqlmock")).UpdateAccount(1, &AccountParam{Name: &name})
+	if !errors.Is(err, ErrAccountNotFound) {
+		t.Errorf("expected ErrAccountNotFound, got %v", err)
 	}
 }
@@ -0,0 +1,102 @@
+package account
+
+import (
+	"fmt"
+	"net/http"
+
+	auth "github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+)
+
+// UpdatePrivacyArgs represents the expected structure of the request body for updating an account's privacy settings.
+//
+// @Description Structure for the privacy settings update request payload.
+type UpdatePrivacyArgs struct {
+	// The account ID for the account whose privacy settings will be updated.
+	AccountId *int64 `json:"account_id" validate:"required"`
+	// The privacy settings to change. Settings which are left out keep their current value.
+	Privacy *PrivacySettingsParam `json:"privacy" validate:"required,nonempty"`
+}
+
+// UpdatePrivacy updates which fields of an account appear on its public profile.
+//
+// @Summary Updates an account's privacy settings
+// @Description This endpoint sets whether the account's location and info appear on its public profile. Unlike /account/update_account, it does not need the password, since it can only hide or reveal what the player already wrote.
+// @Tags account
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Param body body UpdatePrivacyArgs true "privacy settings update request body"
+// @Success 200 {object} httpapi.MessageResponse "Successfully updated privacy settings!"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /account/update_privacy [put]
+func UpdatePrivacy(w http.ResponseWriter, r *http.Request, accounts AccountRepository, store *auth.SessionStore) error {
+	if r.Method != http.MethodPut {
+		return httpapi.MethodNotAllowed(http.MethodPut)
+	}
+
+	args := UpdatePrivacyArgs{}
+	err := httpapi.DecodeJSON(r, &args)
+	if err != nil {
+		return err
+	}
+
+	return updatePrivacy(w, r, args, accounts, store)
+}
+
+// UpdatePrivacyV2 updates the privacy settings of the account with the ID in the path. The body holds the
+// settings to change.
+//
+// @Summary Updates an account's privacy settings
+// @Description This endpoint sets whether the account's location and info appear on its public profile. Unlike updating the account, it does not need the password, since it can only hide or reveal what the player already wrote.
+// @Tags account
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Param id path int true "account ID"
+// @Param body body PrivacySettingsParam true "the privacy settings to change"
+// @Success 200 {object} httpapi.MessageResponse "Successfully updated privacy settings!"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /v2/accounts/{id}/privacy [patch]
+func UpdatePrivacyV2(w http.ResponseWriter, r *http.Request, accounts AccountRepository, store *auth.SessionStore) error {
+	accountId, err := httpapi.PathID(r, "id")
+	if err != nil {
+		return err
+	}
+
+	privacy := PrivacySettingsParam{}
+	if err := httpapi.DecodeJSON(r, &privacy); err != nil {
+		return err
+	}
+
+	return updatePrivacy(w, r, UpdatePrivacyArgs{AccountId: &accountId, Privacy: &privacy}, accounts, store)
+}
+
+// updatePrivacy changes the privacy settings of the account in args.
+func updatePrivacy(w http.ResponseWriter, r *http.Request, args UpdatePrivacyArgs, accounts AccountRepository, store *auth.SessionStore) error {
+	if err := httpapi.Validate(&args); err != nil {
+		return err
+	}
+
+	session, err := store.Authenticate(r, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := auth.RequireAccount(session, *args.AccountId); err != nil {
+		return err
+	}
+
+	if err := accounts.UpdatePrivacy(*args.AccountId, args.Privacy); err != nil {
+		return fmt.Errorf("an error occurred while updating the privacy settings of the account with the ID %d: %v", *args.AccountId, err)
+	}
+
+	httpapi.WriteMessage(w, http.StatusOK, "Successfully updated privacy settings!")
+	return nil
+}
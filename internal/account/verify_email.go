@@ -0,0 +1,60 @@
+package account
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	auth "github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+)
+
+// VerifyEmailArgs represents the expected structure of the request body for verifying an account's email.
+//
+// @Description Structure for the email verification request payload.
+type VerifyEmailArgs struct {
+	// The verification token which was emailed to the account.
+	Token string `json:"token" validate:"required"`
+}
+
+// VerifyEmail marks an account's email as verified using the token that was emailed to it.
+//
+// @Summary Verify an account's email
+// @Description This endpoint verifies an account's email using the token sent to it when the account was created (or by /account/resend_verification). Each token can only be used once.
+// @Tags account
+// @Accept json
+// @Produce json
+// @Param body body VerifyEmailArgs true "email verification request body"
+// @Success 200 {object} httpapi.MessageResponse "Successfully verified email!"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /account/verify_email [post]
+// @Router /v2/email-verifications [post]
+func VerifyEmail(w http.ResponseWriter, r *http.Request, accounts AccountRepository) error {
+
+	if r.Method != http.MethodPost {
+		return httpapi.MethodNotAllowed(http.MethodPost)
+	}
+
+	args := VerifyEmailArgs{}
+	err := httpapi.DecodeJSON(r, &args)
+	if err != nil {
+		return err
+	}
+
+	if err := httpapi.Validate(&args); err != nil {
+		return err
+	}
+
+	_, err = accounts.VerifyEmail(auth.HashSecretToken(args.Token), time.Now())
+	if errors.Is(err, ErrEmailVerificationNotFound) {
+		return httpapi.Validation(err.Error()).WithCode(CodeVerificationTokenInvalid)
+	}
+	if err != nil {
+		return fmt.Errorf("an error occurred while verifying the email: %v", err)
+	}
+
+	httpapi.WriteMessage(w, http.StatusOK, "Successfully verified email!")
+	return nil
+}
@@ -0,0 +1,193 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+)
+
+const ERROR_ADMIN_TOKEN_REQUIRED = "the admin token must be provided in the Authorization header"
+
+// CodeAdminTokenRequired is the code of ErrAdminTokenRequired.
+const CodeAdminTokenRequired httpapi.Code = "admin_token_required"
+
+// ErrAdminTokenRequired is returned when an admin endpoint is called without the admin token.
+var ErrAdminTokenRequired = httpapi.Unauthorized(ERROR_ADMIN_TOKEN_REQUIRED).WithCode(CodeAdminTokenRequired)
+
+// DefaultListLoginFailuresLimit and MaxListLoginFailuresLimit bound how many failed attempts are listed for an account.
+const (
+	DefaultListLoginFailuresLimit = 50
+	MaxListLoginFailuresLimit     = 500
+)
+
+// requireAdmin checks that the request carries the admin token as a bearer token. The admin endpoints
+// are only registered when an admin token is configured, so adminToken is never empty.
+func requireAdmin(r *http.Request, adminToken string) error {
+	token := BearerToken(r)
+	if adminToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+		return ErrAdminTokenRequired
+	}
+	return nil
+}
+
+// UnlockAccountArgs represents the expected structure of the request body for unlocking an account.
+//
+// @Description Structure for the account unlock request payload.
+type UnlockAccountArgs struct {
+	// The ID of the account to unlock.
+	AccountId *int64 `json:"account_id" validate:"required"`
+}
+
+// UnlockAccount lifts a lockout caused by failed password attempts.
+//
+// @Summary Unlock an account
+// @Description This endpoint lets the server's administrator lift a lockout from an account after failed password attempts, for example once its owner has been in touch. It requires the ADMIN_TOKEN configured on the server. Lockouts of IP addresses are not lifted.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer admin token"
+// @Param body body UnlockAccountArgs true "account unlock request body"
+// @Success 200 {object} httpapi.MessageResponse "Successfully unlocked account!"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /admin/unlock_account [post]
+func UnlockAccount(w http.ResponseWriter, r *http.Request, lockout *Lockout, adminToken string) error {
+	if r.Method != http.MethodPost {
+		return httpapi.MethodNotAllowed(http.MethodPost)
+	}
+
+	if err := requireAdmin(r, adminToken); err != nil {
+		return err
+	}
+
+	args := UnlockAccountArgs{}
+	err := httpapi.DecodeJSON(r, &args)
+	if err != nil {
+		return err
+	}
+
+	return unlockAccount(w, args, lockout)
+}
+
+// UnlockAccountV2 lifts a lockout from the account with the ID in the path.
+//
+// @Summary Unlock an account
+// @Description This endpoint lets the server's administrator lift a lockout from an account after failed password attempts, for example once its owner has been in touch. It requires the ADMIN_TOKEN configured on the server. Lockouts of IP addresses are not lifted.
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer admin token"
+// @Param id path int true "The ID of the account"
+// @Success 200 {object} httpapi.MessageResponse "Successfully unlocked account!"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /v2/admin/accounts/{id}/lockout [delete]
+func UnlockAccountV2(w http.ResponseWriter, r *http.Request, lockout *Lockout, adminToken string) error {
+	if err := requireAdmin(r, adminToken); err != nil {
+		return err
+	}
+
+	accountId, err := httpapi.PathID(r, "id")
+	if err != nil {
+		return err
+	}
+
+	return unlockAccount(w, UnlockAccountArgs{AccountId: &accountId}, lockout)
+}
+
+// unlockAccount lifts the lockout from the account in args. The caller must have checked the admin token.
+func unlockAccount(w http.ResponseWriter, args UnlockAccountArgs, lockout *Lockout) error {
+	if err := httpapi.Validate(&args); err != nil {
+		return err
+	}
+
+	if err := lockout.Unlock(*args.AccountId); err != nil {
+		return fmt.Errorf("an error occurred while unlocking the account: %v", err)
+	}
+
+	httpapi.WriteMessage(w, http.StatusOK, "Successfully unlocked account!")
+	return nil
+}
+
+// ListLoginFailures lists the failed password attempts for an account.
+//
+// @Summary List failed password attempts
+// @Description This endpoint lets the server's administrator see the failed password attempts for an account, newest first, including the IP address each came from. It requires the ADMIN_TOKEN configured on the server.
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer admin token"
+// @Param account_id query int true "The ID of the account"
+// @Param limit query int false "How many attempts to return (default 50, at most 500)"
+// @Success 200 {array} LoginFailure "The failed attempts"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /admin/login_failures [get]
+func ListLoginFailures(w http.ResponseWriter, r *http.Request, lockout *Lockout, adminToken string) error {
+	if r.Method != http.MethodGet {
+		return httpapi.MethodNotAllowed(http.MethodGet)
+	}
+
+	if err := requireAdmin(r, adminToken); err != nil {
+		return err
+	}
+
+	accountID, err := strconv.ParseInt(r.URL.Query().Get("account_id"), 10, 64)
+	if err != nil {
+		return httpapi.Validation("account_id must be specified as a number")
+	}
+
+	return listLoginFailures(w, r, accountID, lockout)
+}
+
+// ListLoginFailuresV2 lists the failed password attempts for the account with the ID in the path.
+//
+// @Summary List failed password attempts
+// @Description This endpoint lets the server's administrator see the failed password attempts for an account, newest first, including the IP address each came from. It requires the ADMIN_TOKEN configured on the server.
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer admin token"
+// @Param id path int true "The ID of the account"
+// @Param limit query int false "How many attempts to return (default 50, at most 500)"
+// @Success 200 {array} LoginFailure "The failed attempts"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /v2/admin/accounts/{id}/login-failures [get]
+func ListLoginFailuresV2(w http.ResponseWriter, r *http.Request, lockout *Lockout, adminToken string) error {
+	if err := requireAdmin(r, adminToken); err != nil {
+		return err
+	}
+
+	accountID, err := httpapi.PathID(r, "id")
+	if err != nil {
+		return err
+	}
+
+	return listLoginFailures(w, r, accountID, lockout)
+}
+
+// listLoginFailures writes the failed password attempts for the account, as many as the limit query
+// parameter asks for. The caller must have checked the admin token.
+func listLoginFailures(w http.ResponseWriter, r *http.Request, accountID int64, lockout *Lockout) error {
+	limit := DefaultListLoginFailuresLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > MaxListLoginFailuresLimit {
+			return httpapi.Validation(fmt.Sprintf("limit must be a number between 1 and %d", MaxListLoginFailuresLimit))
+		}
+		limit = parsed
+	}
+
+	failures, err := lockout.Attempts.ListLoginFailures(accountID, limit)
+	if err != nil {
+		return fmt.Errorf("an error occurred while listing failed password attempts: %v", err)
+	}
+
+	httpapi.WriteJSON(w, http.StatusOK, failures)
+	return nil
+}
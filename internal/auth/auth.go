@@ -1,15 +1,29 @@
 package auth
 
 import (
-	"bytes"
 	"crypto/rand"
+	"crypto/subtle"
 	"encoding/base64"
 	"errors"
+	"fmt"
+	"strings"
 
-	"github.com/jmoiron/sqlx"
 	"golang.org/x/crypto/argon2"
 )
 
+// ErrPasswordMismatch is returned when a password does not match the stored hash.
+var ErrPasswordMismatch = errors.New("hash doesn't match")
+
+// The default Argon2id parameters, following the second recommendation of RFC 9106. They can be
+// changed with NewArgon2idHash; hashes made with other parameters are upgraded when the password is next used.
+const (
+	DefaultArgon2Time    uint32 = 3
+	DefaultArgon2Memory  uint32 = 64 * 1024
+	DefaultArgon2Threads uint8  = 4
+	DefaultArgon2SaltLen uint32 = 16
+	DefaultArgon2KeyLen  uint32 = 32
+)
+
 // hashSalt represents a salt and a hash in the same data type for password storage.
 //
 // @Description Structure containing both a salt and a hash for password storage.
@@ -92,23 +106,121 @@ func (a *argon2idHash) Compare(hash, salt, password []byte) error {
 		return err
 	}
 
-	// Compare the generated hash with the stored hash.
-	// If they don't match return error.
-	if !bytes.Equal(hash, hashSalt.Hash) {
-		return errors.New("hash doesn't match")
-
+	// Compare the generated hash with the stored hash in constant time, so
+	// the comparison does not reveal how much of the hash matched.
+	if subtle.ConstantTimeCompare(hash, hashSalt.Hash) != 1 {
+		return ErrPasswordMismatch
 	}
 	return nil
 }
 
-func StoreHashAndSalt(hashSalt *hashSalt, accountEmail string, db *sqlx.DB) error {
-	result, err := db.Query("INSERT INTO passwords (account_email, hash, salt) VALUES ($1, $2, $3)", accountEmail, base64.StdEncoding.EncodeToString(hashSalt.Hash), base64.StdEncoding.EncodeToString(hashSalt.Salt))
+// Encode hashes the password with a random salt and returns it in the PHC string format,
+// e.g. $argon2id$v=19$m=65536,t=3,p=4$<salt>$<hash>, so the parameters are stored with the hash.
+func (a *argon2idHash) Encode(password []byte) (string, error) {
+	hashSalt, err := a.GenerateHash(password, nil)
 	if err != nil {
-		return errors.New("an error occurred while inserting a hash-salt pair into the database: " + err.Error())
+		return "", err
 	}
 
-	defer result.Close()
-	return nil
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, a.memory, a.time, a.threads,
+		base64.RawStdEncoding.EncodeToString(hashSalt.Salt),
+		base64.RawStdEncoding.EncodeToString(hashSalt.Hash),
+	), nil
+}
+
+// decodeHash parses a hash in the PHC string format, returning the parameters it was made with.
+func decodeHash(encoded string) (*argon2idHash, *hashSalt, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != "argon2id" {
+		return nil, nil, errors.New("the hash is not an argon2id hash in the PHC string format")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return nil, nil, fmt.Errorf("error decoding the hash version: %v", err)
+	}
+	if version != argon2.Version {
+		return nil, nil, fmt.Errorf("unsupported argon2 version %d", version)
+	}
+
+	params := &argon2idHash{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.time, &params.threads); err != nil {
+		return nil, nil, fmt.Errorf("error decoding the hash parameters: %v", err)
+	}
+	if params.time == 0 || params.threads == 0 {
+		return nil, nil, errors.New("the hash parameters must be greater than 0")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, nil, errors.New("error decoding stored salt: " + err.Error())
+	}
+
+	hash, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return nil, nil, errors.New("error decoding stored hash: " + err.Error())
+	}
+
+	params.saltLen = uint32(len(salt))
+	params.keyLen = uint32(len(hash))
+
+	return params, &hashSalt{Hash: hash, Salt: salt}, nil
 }
 
-var Hasher = NewArgon2idHash(1, 32, 64*1024, 32, 256)
+// isPHCHash reports whether a stored hash is in the PHC string format rather than a legacy base64 hash.
+func isPHCHash(encodedHash string) bool {
+	return strings.HasPrefix(encodedHash, "$argon2id$")
+}
+
+// HashPassword hashes a password with Hasher and returns it in the PHC string format, which carries its own salt and parameters.
+func HashPassword(password string) (string, error) {
+	return Hasher.Encode([]byte(password))
+}
+
+// ComparePassword checks a password against a hash produced by HashPassword. Hashes stored before the
+// PHC string format was used are base64-encoded with a separate base64-encoded salt; legacySalt is only
+// used for those and is ignored otherwise.
+func ComparePassword(encodedHash string, legacySalt string, password string) error {
+	if isPHCHash(encodedHash) {
+		params, stored, err := decodeHash(encodedHash)
+		if err != nil {
+			return err
+		}
+		return params.Compare(stored.Hash, stored.Salt, []byte(password))
+	}
+
+	hash, err := base64.StdEncoding.DecodeString(encodedHash)
+	if err != nil {
+		return errors.New("error decoding stored hash: " + err.Error())
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(legacySalt)
+	if err != nil {
+		return errors.New("error decoding stored salt: " + err.Error())
+	}
+
+	return legacyHasher.Compare(hash, salt, []byte(password))
+}
+
+// PasswordNeedsRehash reports whether a stored hash was made with other parameters than Hasher's,
+// in which case it should be replaced with HashPassword once the password has been checked.
+func PasswordNeedsRehash(encodedHash string) bool {
+	if !isPHCHash(encodedHash) {
+		return true
+	}
+
+	params, _, err := decodeHash(encodedHash)
+	if err != nil {
+		return true
+	}
+
+	return *params != *Hasher
+}
+
+// Hasher hashes new passwords. main replaces it when the parameters are configured from the environment.
+var Hasher = NewArgon2idHash(DefaultArgon2Time, DefaultArgon2SaltLen, DefaultArgon2Memory, DefaultArgon2Threads, DefaultArgon2KeyLen)
+
+// legacyHasher has the parameters of hashes stored before the PHC string format was used, which did not record them.
+var legacyHasher = NewArgon2idHash(1, 32, 64*1024, 32, 256)
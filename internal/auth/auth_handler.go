@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+	"github.com/justinfarrelldev/open-ctp-server/internal/mail"
+)
+
+func LoginHandler(w http.ResponseWriter, r *http.Request, credentials CredentialRepository, store *SessionStore, lockout *Lockout) {
+	if err := Login(w, r, credentials, store, lockout); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func LogoutHandler(w http.ResponseWriter, r *http.Request, store *SessionStore) {
+	if err := Logout(w, r, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func ForgotPasswordHandler(w http.ResponseWriter, r *http.Request, credentials CredentialRepository, resets PasswordResetRepository, sender mail.Sender) {
+	if err := ForgotPassword(w, r, credentials, resets, sender); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func ResetPasswordHandler(w http.ResponseWriter, r *http.Request, resets PasswordResetRepository, store *SessionStore, lockout *Lockout) {
+	if err := ResetPassword(w, r, resets, store, lockout); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func UnlockAccountHandler(w http.ResponseWriter, r *http.Request, lockout *Lockout, adminToken string) {
+	if err := UnlockAccount(w, r, lockout, adminToken); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func ListLoginFailuresHandler(w http.ResponseWriter, r *http.Request, lockout *Lockout, adminToken string) {
+	if err := ListLoginFailures(w, r, lockout, adminToken); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func UnlockAccountV2Handler(w http.ResponseWriter, r *http.Request, lockout *Lockout, adminToken string) {
+	if err := UnlockAccountV2(w, r, lockout, adminToken); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func ListLoginFailuresV2Handler(w http.ResponseWriter, r *http.Request, lockout *Lockout, adminToken string) {
+	if err := ListLoginFailuresV2(w, r, lockout, adminToken); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func LogoutV2Handler(w http.ResponseWriter, r *http.Request, store *SessionStore) {
+	if err := LogoutV2(w, r, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
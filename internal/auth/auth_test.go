@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"strings"
 	"testing"
 
 	"encoding/base64"
@@ -49,7 +50,7 @@ func TestCompare(t *testing.T) {
 	}
 }
 
-func TestStoreHashAndSalt(t *testing.T) {
+func TestStoreCredentials(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
@@ -58,20 +59,17 @@ func TestStoreHashAndSalt(t *testing.T) {
 
 	sqlxDB := sqlx.NewDb(db, "sqlmock")
 
-	hasher := NewArgon2idHash(1, 32, 64*1024, 32, 256)
-	password := []byte("password123")
-
-	hashSalt, err := hasher.GenerateHash(password, nil)
+	hash, err := HashPassword("password123")
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
 
 	accountEmail := "test@example.com"
 	mock.ExpectQuery("INSERT INTO passwords").
-		WithArgs(accountEmail, base64.StdEncoding.EncodeToString(hashSalt.Hash), base64.StdEncoding.EncodeToString(hashSalt.Salt)).
+		WithArgs(accountEmail, hash).
 		WillReturnRows(sqlmock.NewRows([]string{"account_email"}))
 
-	err = StoreHashAndSalt(hashSalt, accountEmail, sqlxDB)
+	err = NewPostgresCredentialRepository(sqlxDB).StoreCredentials(accountEmail, hash)
 	if err != nil {
 		t.Errorf("expected no error, got %v", err)
 	}
@@ -80,3 +78,79 @@ func TestStoreHashAndSalt(t *testing.T) {
 		t.Errorf("there were unfulfilled expectations: %v", err)
 	}
 }
+
+func TestHashPassword_PHCFormat(t *testing.T) {
+	hash, err := HashPassword("password123")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=65536,t=3,p=4$") {
+		t.Errorf("unexpected hash format: %s", hash)
+	}
+
+	if err := ComparePassword(hash, "", "password123"); err != nil {
+		t.Errorf("expected hashes to match, got error %v", err)
+	}
+
+	if err := ComparePassword(hash, "", "wrongpassword"); err != ErrPasswordMismatch {
+		t.Errorf("expected ErrPasswordMismatch, got %v", err)
+	}
+
+	if PasswordNeedsRehash(hash) {
+		t.Error("expected a hash made with the current parameters to be kept")
+	}
+}
+
+func TestComparePassword_KeepsWorkingWhenParametersChange(t *testing.T) {
+	hash, err := HashPassword("password123")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	previous := Hasher
+	Hasher = NewArgon2idHash(2, DefaultArgon2SaltLen, 32*1024, 2, DefaultArgon2KeyLen)
+	defer func() { Hasher = previous }()
+
+	if err := ComparePassword(hash, "", "password123"); err != nil {
+		t.Errorf("expected the hash to be checked with its own parameters, got error %v", err)
+	}
+
+	if !PasswordNeedsRehash(hash) {
+		t.Error("expected a hash made with other parameters to need rehashing")
+	}
+}
+
+func TestComparePassword_LegacyHash(t *testing.T) {
+	hashSalt, err := legacyHasher.GenerateHash([]byte("password123"), nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	hash := base64.StdEncoding.EncodeToString(hashSalt.Hash)
+	salt := base64.StdEncoding.EncodeToString(hashSalt.Salt)
+
+	if err := ComparePassword(hash, salt, "password123"); err != nil {
+		t.Errorf("expected hashes to match, got error %v", err)
+	}
+
+	if err := ComparePassword(hash, salt, "wrongpassword"); err != ErrPasswordMismatch {
+		t.Errorf("expected ErrPasswordMismatch, got %v", err)
+	}
+
+	if !PasswordNeedsRehash(hash) {
+		t.Error("expected a legacy hash to need rehashing")
+	}
+}
+
+func TestComparePassword_MalformedHash(t *testing.T) {
+	for _, hash := range []string{
+		"$argon2id$v=19$m=65536,t=3$c2FsdA$aGFzaA",
+		"$argon2id$v=18$m=65536,t=3,p=4$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=65536,t=0,p=0$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=65536,t=3,p=4$!!!$aGFzaA",
+	} {
+		if err := ComparePassword(hash, "", "password123"); err == nil {
+			t.Errorf("expected %q to be refused", hash)
+		}
+	}
+}
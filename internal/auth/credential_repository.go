@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrCredentialsNotFound is returned when no password is stored for an account.
+var ErrCredentialsNotFound = errors.New("no credentials exist for the account")
+
+// Credentials are an account's password hash, as returned by HashPassword. Salt is only set for hashes
+// stored before the PHC string format was used, and should be passed to ComparePassword with the hash.
+type Credentials struct {
+	AccountID int    `db:"id"`
+	Hash      string `db:"hash"`
+	Salt      string `db:"salt"`
+}
+
+// CredentialRepository stores account passwords. Passwords are keyed by the account's email, so they
+// follow the account when its email changes. Lookups return ErrCredentialsNotFound when there is no password.
+type CredentialRepository interface {
+	StoreCredentials(accountEmail string, hash string) error
+	// UpdateCredentials replaces the account's password. It returns ErrCredentialsNotFound when the account has none.
+	UpdateCredentials(accountID int64, hash string) error
+	CredentialsByEmail(email string) (*Credentials, error)
+	CredentialsByAccountID(accountID int64) (*Credentials, error)
+}
+
+// PostgresCredentialRepository stores passwords in the passwords table.
+type PostgresCredentialRepository struct {
+	// DB is either the database or a transaction, so that passwords can be stored together with their account.
+	DB sqlx.Ext
+}
+
+// NewPostgresCredentialRepository creates a CredentialRepository backed by Postgres. Pass a *sqlx.Tx to
+// make the repository part of a transaction.
+func NewPostgresCredentialRepository(db sqlx.Ext) *PostgresCredentialRepository {
+	return &PostgresCredentialRepository{DB: db}
+}
+
+func (p *PostgresCredentialRepository) StoreCredentials(accountEmail string, hash string) error {
+	result, err := p.DB.Query("INSERT INTO passwords (account_email, hash) VALUES ($1, $2)", accountEmail, hash)
+	if err != nil {
+		return errors.New("an error occurred while inserting a password hash into the database: " + err.Error())
+	}
+
+	defer result.Close()
+	return nil
+}
+
+func (p *PostgresCredentialRepository) UpdateCredentials(accountID int64, hash string) error {
+	// The salt column is only used by hashes stored before the PHC string format was used
+	result, err := p.DB.Exec(
+		"UPDATE passwords SET hash = $1, salt = NULL WHERE account_email = (SELECT email FROM account WHERE id = $2)",
+		hash, accountID,
+	)
+	if err != nil {
+		return errors.New("an error occurred while updating a password hash in the database: " + err.Error())
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.New("an error occurred while checking the affected rows: " + err.Error())
+	}
+
+	if rowsAffected == 0 {
+		return ErrCredentialsNotFound
+	}
+
+	return nil
+}
+
+func (p *PostgresCredentialRepository) CredentialsByEmail(email string) (*Credentials, error) {
+	return p.getCredentials(
+		"SELECT account.id, passwords.hash, COALESCE(passwords.salt, '') FROM account JOIN passwords ON passwords.account_email = account.email WHERE account.email = $1",
+		email,
+	)
+}
+
+func (p *PostgresCredentialRepository) CredentialsByAccountID(accountID int64) (*Credentials, error) {
+	return p.getCredentials(
+		"SELECT account.id, passwords.hash, COALESCE(passwords.salt, '') FROM passwords JOIN account ON account.email = passwords.account_email WHERE account.id = $1",
+		accountID,
+	)
+}
+
+func (p *PostgresCredentialRepository) getCredentials(query string, arg interface{}) (*Credentials, error) {
+	var credentials Credentials
+	if err := p.DB.QueryRowx(query, arg).Scan(&credentials.AccountID, &credentials.Hash, &credentials.Salt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrCredentialsNotFound
+		}
+		return nil, err
+	}
+	return &credentials, nil
+}
@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+	"github.com/justinfarrelldev/open-ctp-server/internal/mail"
+)
+
+// ForgotPasswordArgs represents the expected structure of the request body for requesting a password reset.
+//
+// @Description Structure for the forgot password request payload.
+type ForgotPasswordArgs struct {
+	// The email address of the account whose password was forgotten.
+	Email string `json:"email" validate:"required"`
+}
+
+// The same response is sent whether or not the email belongs to an account, so that the
+// endpoint cannot be used to find out which emails have accounts.
+const FORGOT_PASSWORD_RESPONSE = "If an account uses that email, a password reset token has been sent to it."
+
+// ForgotPassword emails a single-use password reset token to the account with the given email.
+//
+// @Summary Request a password reset
+// @Description This endpoint emails a password reset token to the account with the given email. The token can be used once with /auth/reset_password and expires after an hour. Requesting a new token invalidates the previous one.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body ForgotPasswordArgs true "forgot password request body"
+// @Success 202 {object} httpapi.MessageResponse "If an account uses that email, a password reset token has been sent to it."
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /auth/forgot_password [post]
+// @Router /v2/password-reset-requests [post]
+func ForgotPassword(w http.ResponseWriter, r *http.Request, credentials CredentialRepository, resets PasswordResetRepository, sender mail.Sender) error {
+	if r.Method != http.MethodPost {
+		return httpapi.MethodNotAllowed(http.MethodPost)
+	}
+
+	args := ForgotPasswordArgs{}
+	err := httpapi.DecodeJSON(r, &args)
+	if err != nil {
+		return err
+	}
+
+	if err := httpapi.Validate(&args); err != nil {
+		return err
+	}
+
+	stored, err := credentials.CredentialsByEmail(args.Email)
+	if err != nil && !errors.Is(err, ErrCredentialsNotFound) {
+		return fmt.Errorf("error retrieving account credentials: %v", err)
+	}
+
+	// Storing the token and sending the email take long enough to tell known emails from unknown ones by
+	// the response time, so they are done after responding, and failures are only logged
+	if stored != nil {
+		pendingResets.Add(1)
+		go func() {
+			defer pendingResets.Done()
+			if err := sendPasswordReset(int64(stored.AccountID), args.Email, resets, sender); err != nil {
+				log.Println("error sending a password reset: ", err.Error())
+			}
+		}()
+	}
+
+	httpapi.WriteMessage(w, http.StatusAccepted, FORGOT_PASSWORD_RESPONSE)
+	return nil
+}
+
+// pendingResets counts the password resets which are still being sent.
+var pendingResets sync.WaitGroup
+
+// WaitForPasswordResets waits until the password resets which are still being sent are done, so that
+// stopping the server does not lose them.
+func WaitForPasswordResets() {
+	pendingResets.Wait()
+}
+
+// sendPasswordReset stores a new password reset for the account and emails its token to email.
+func sendPasswordReset(accountID int64, email string, resets PasswordResetRepository, sender mail.Sender) error {
+	// Reset tokens are made and stored the same way as session tokens
+	token, err := generateSessionToken()
+	if err != nil {
+		return fmt.Errorf("error generating a password reset token: %v", err)
+	}
+
+	now := time.Now()
+	reset := &PasswordReset{
+		TokenHash: hashSessionToken(token),
+		AccountID: accountID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(DefaultPasswordResetLifetime),
+	}
+
+	if err := resets.CreatePasswordReset(reset); err != nil {
+		return fmt.Errorf("error saving a password reset: %v", err)
+	}
+
+	return sender.Send(mail.Message{
+		To:      email,
+		Subject: "Reset your Open CTP Server password",
+		Body: fmt.Sprintf(
+			"Someone asked to reset the password for your account. If it was you, send this token to /auth/reset_password along with your new password:\n\n%s\n\nThe token expires in %s. If you did not ask for a reset, you can ignore this email.",
+			token, DefaultPasswordResetLifetime,
+		),
+	})
+}
@@ -0,0 +1,211 @@
+package auth
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+)
+
+const ERROR_TOO_MANY_ATTEMPTS = "too many failed password attempts; please try again later"
+
+// The default lockout policy. An account is locked for BaseDelay after AccountThreshold failures in a
+// row, and each further failure doubles the lock, up to MaxDelay.
+const (
+	DefaultAccountLockoutThreshold = 5
+	DefaultIPLockoutThreshold      = 20
+	DefaultLockoutBaseDelay        = time.Minute
+	DefaultLockoutMaxDelay         = time.Hour
+	DefaultLockoutResetAfter       = 24 * time.Hour
+)
+
+// The reasons recorded in the audit trail of failed attempts.
+const (
+	LOGIN_FAILURE_UNKNOWN_EMAIL  = "unknown_email"
+	LOGIN_FAILURE_WRONG_PASSWORD = "wrong_password"
+)
+
+// CodeTooManyAttempts is the code of the error returned while an account or IP address is locked out.
+const CodeTooManyAttempts httpapi.Code = "too_many_attempts"
+
+// ErrTooManyAttempts returns the error reported while an account or IP address is locked out after too many
+// failed password attempts. Its Retry-After header says when the caller can try again.
+func ErrTooManyAttempts(retryAfter time.Duration) *httpapi.Error {
+	return httpapi.TooManyRequests(ERROR_TOO_MANY_ATTEMPTS, retryAfter).WithCode(CodeTooManyAttempts)
+}
+
+// Lockout slows down password guessing. Failed attempts are counted per account and per IP address, so an
+// attacker can neither grind one account from many addresses nor many accounts from one address.
+type Lockout struct {
+	Attempts LoginAttemptRepository
+
+	// AccountThreshold and IPThreshold are how many failures in a row lock the account or IP address.
+	AccountThreshold int
+	IPThreshold      int
+	// BaseDelay is how long the first lock lasts. Each further failure doubles it, up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// ResetAfter is how long after the last failure the count starts over.
+	ResetAfter time.Duration
+	// ClientIPHeader names a header holding the client's IP address, such as Fly-Client-IP. It must only
+	// be set behind a proxy which overwrites the header. When empty, the connection's address is used.
+	ClientIPHeader string
+}
+
+// NewLockout creates a Lockout with the default policy.
+func NewLockout(attempts LoginAttemptRepository) *Lockout {
+	return &Lockout{
+		Attempts:         attempts,
+		AccountThreshold: DefaultAccountLockoutThreshold,
+		IPThreshold:      DefaultIPLockoutThreshold,
+		BaseDelay:        DefaultLockoutBaseDelay,
+		MaxDelay:         DefaultLockoutMaxDelay,
+		ResetAfter:       DefaultLockoutResetAfter,
+	}
+}
+
+func accountThrottleKey(accountID int64) string {
+	return fmt.Sprintf("account:%d", accountID)
+}
+
+// emailThrottleKey counts attempts for emails without an account, so that they are locked out just like
+// real accounts and the lockout does not reveal which emails have accounts.
+func emailThrottleKey(email string) string {
+	return "email:" + strings.ToLower(email)
+}
+
+func ipThrottleKey(ip string) string {
+	return "ip:" + ip
+}
+
+// subjectThrottleKey returns the key for the account when it is known, or else for the email.
+func subjectThrottleKey(accountID *int64, email string) string {
+	if accountID != nil {
+		return accountThrottleKey(*accountID)
+	}
+	return emailThrottleKey(email)
+}
+
+// ClientIP returns the IP address the request came from.
+func (l *Lockout) ClientIP(r *http.Request) string {
+	return httpapi.ClientIP(r, l.ClientIPHeader)
+}
+
+// policy returns the ThrottlePolicy of keys which lock after threshold failures.
+func (l *Lockout) policy(threshold int) ThrottlePolicy {
+	return ThrottlePolicy{Threshold: threshold, BaseDelay: l.BaseDelay, MaxDelay: l.MaxDelay, ResetAfter: l.ResetAfter}
+}
+
+// keys returns the throttle keys of an attempt from the request, with their policies: the account (or the
+// email, when it has no account) and the request's IP address.
+func (l *Lockout) keys(r *http.Request, accountID *int64, email string) ([]string, []ThrottlePolicy) {
+	return []string{subjectThrottleKey(accountID, email), ipThrottleKey(l.ClientIP(r))},
+		[]ThrottlePolicy{l.policy(l.AccountThreshold), l.policy(l.IPThreshold)}
+}
+
+// Check returns ErrTooManyAttempts while the request's IP address, or the account, is locked out, without
+// counting an attempt. accountID is nil when the email has no account.
+func (l *Lockout) Check(r *http.Request, accountID *int64, email string) error {
+	keys, policies := l.keys(r, accountID, email)
+	throttles, err := l.Attempts.GetThrottles(keys)
+	if err != nil {
+		return fmt.Errorf("an error occurred while checking for failed password attempts: %v", err)
+	}
+
+	now := time.Now()
+	var retryAfter time.Duration
+	for _, throttle := range throttles {
+		policy := policies[0]
+		if throttle.Key == keys[1] {
+			policy = policies[1]
+		}
+
+		if remaining := policy.RetryAfter(throttle, now); remaining > retryAfter {
+			retryAfter = remaining
+		}
+	}
+
+	if retryAfter > 0 {
+		return ErrTooManyAttempts(retryAfter)
+	}
+	return nil
+}
+
+// Begin counts a password attempt against the account (or the email, when it has no account) and the
+// request's IP address as failed, and returns ErrTooManyAttempts without counting it while either is
+// locked out. accountID is nil when the email has no account. It should be called before the password is
+// checked, followed by Succeed or Fail. Counting attempts before they are checked keeps concurrent
+// attempts from all getting past the lockout before any of them has failed.
+func (l *Lockout) Begin(r *http.Request, accountID *int64, email string) error {
+	keys, policies := l.keys(r, accountID, email)
+	now := time.Now()
+
+	for i, key := range keys {
+		retryAfter, err := l.Attempts.TakeAttempt(key, now, policies[i])
+		if err == nil && retryAfter == 0 {
+			continue
+		}
+
+		// The keys counted so far are given their attempt back
+		for _, counted := range keys[:i] {
+			if err := l.Attempts.ForgiveAttempt(counted); err != nil {
+				log.Println("error taking back a password attempt: ", err.Error())
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("an error occurred while counting a password attempt: %v", err)
+		}
+		return ErrTooManyAttempts(retryAfter)
+	}
+
+	return nil
+}
+
+// Succeed takes back the attempt counted by Begin once the password turned out to be right. The account's
+// failures are cleared, while those of the IP address are kept, since one correct password says nothing
+// about the other accounts tried from it.
+func (l *Lockout) Succeed(r *http.Request, accountID int64) {
+	if err := l.Attempts.ClearThrottle(accountThrottleKey(accountID)); err != nil {
+		log.Println("error clearing failed password attempts: ", err.Error())
+	}
+	if err := l.Attempts.ForgiveAttempt(ipThrottleKey(l.ClientIP(r))); err != nil {
+		log.Println("error taking back a password attempt: ", err.Error())
+	}
+}
+
+// Fail records a failed password attempt, which Begin has already counted, in the audit trail.
+func (l *Lockout) Fail(r *http.Request, accountID *int64, email string, reason string) error {
+	failure := &LoginFailure{AccountID: accountID, IP: l.ClientIP(r), Reason: reason, CreatedAt: time.Now()}
+	if err := l.Attempts.RecordLoginFailure(failure); err != nil {
+		return fmt.Errorf("an error occurred while recording a failed password attempt: %v", err)
+	}
+	return nil
+}
+
+// Unlock lifts a lockout on the account early. The audit trail is kept.
+func (l *Lockout) Unlock(accountID int64) error {
+	return l.Attempts.ClearThrottle(accountThrottleKey(accountID))
+}
+
+// ComparePassword checks the account's password like ComparePassword, counting a wrong password as a
+// failed attempt. It returns ErrTooManyAttempts without checking the password while the account or IP
+// address is locked out, and ErrPasswordMismatch when the password is wrong.
+func (l *Lockout) ComparePassword(r *http.Request, credentials *Credentials, password string) error {
+	accountID := int64(credentials.AccountID)
+	if err := l.Begin(r, &accountID, ""); err != nil {
+		return err
+	}
+
+	if err := ComparePassword(credentials.Hash, credentials.Salt, password); err != nil {
+		if err := l.Fail(r, &accountID, "", LOGIN_FAILURE_WRONG_PASSWORD); err != nil {
+			log.Println("error recording a failed password attempt: ", err.Error())
+		}
+		return err
+	}
+
+	l.Succeed(r, accountID)
+	return nil
+}
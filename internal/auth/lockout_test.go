@@ -0,0 +1,284 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi/httpapitest"
+)
+
+func TestLockout_Delay(t *testing.T) {
+	policy := NewLockout(NewMemoryLoginAttemptRepository()).policy(DefaultAccountLockoutThreshold)
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 4, want: 0},
+		{failures: 5, want: time.Minute},
+		{failures: 6, want: 2 * time.Minute},
+		{failures: 8, want: 8 * time.Minute},
+		{failures: 50, want: time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := policy.delay(tt.failures); got != tt.want {
+			t.Errorf("delay(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func loginRequest(email string, password string, ip string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(`{"email": "`+email+`", "password": "`+password+`"}`))
+	req.RemoteAddr = ip + ":1234"
+	return req
+}
+
+// failAttempt makes a wrong password attempt for the account, as Lockout.ComparePassword would.
+func failAttempt(t *testing.T, lockout *Lockout, r *http.Request, accountID int64) {
+	t.Helper()
+	if err := lockout.Begin(r, &accountID, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := lockout.Fail(r, &accountID, "", LOGIN_FAILURE_WRONG_PASSWORD); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLogin_LocksOutAfterFailedAttempts(t *testing.T) {
+	hash, err := HashPassword("password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	credentials := &memoryCredentials{}
+	credentials.StoreCredentials("player@example.com", hash)
+
+	store := NewSessionStoreWithRepository(NewMemorySessionRepository())
+	attempts := NewMemoryLoginAttemptRepository()
+	lockout := NewLockout(attempts)
+	lockout.AccountThreshold = 3
+	lockout.IPThreshold = 5
+
+	login := func(email string, password string, ip string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		LoginHandler(rr, loginRequest(email, password, ip), credentials, store, lockout)
+		return rr
+	}
+
+	// Each attempt comes from another address, like a botnet
+	for i, ip := range []string{"192.0.2.1", "192.0.2.2", "192.0.2.3"} {
+		if rr := login("player@example.com", "wrong-password", ip); rr.Code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: expected 401, got %d: %s", i, rr.Code, rr.Body.String())
+		}
+	}
+
+	rr := login("player@example.com", "password123", "192.0.2.4")
+	if rr.Code != http.StatusTooManyRequests || httpapitest.Code(rr) != CodeTooManyAttempts {
+		t.Fatalf("expected the account to be locked out, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr.Header().Get("Retry-After") != "60" {
+		t.Errorf("expected Retry-After to be 60 seconds, got %q", rr.Header().Get("Retry-After"))
+	}
+
+	// Emails without an account are locked out the same way
+	for i := 0; i < 3; i++ {
+		login("nobody@example.com", "password123", "198.51.100.1")
+	}
+	if rr := login("nobody@example.com", "password123", "198.51.100.2"); rr.Code != http.StatusTooManyRequests {
+		t.Errorf("expected the unknown email to be locked out, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	// The address which tried three emails is locked out for every account after two more failures
+	login("first@example.com", "password123", "198.51.100.1")
+	login("second@example.com", "password123", "198.51.100.1")
+	if rr := login("third@example.com", "password123", "198.51.100.1"); rr.Code != http.StatusTooManyRequests {
+		t.Errorf("expected the IP address to be locked out, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	failures, err := attempts.ListLoginFailures(1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failures) != 3 || failures[0].IP != "192.0.2.3" || failures[0].Reason != LOGIN_FAILURE_WRONG_PASSWORD {
+		t.Errorf("unexpected audit trail: %+v", failures)
+	}
+}
+
+func TestLogin_SuccessClearsFailures(t *testing.T) {
+	hash, err := HashPassword("password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	credentials := &memoryCredentials{}
+	credentials.StoreCredentials("player@example.com", hash)
+
+	store := NewSessionStoreWithRepository(NewMemorySessionRepository())
+	lockout := NewLockout(NewMemoryLoginAttemptRepository())
+	lockout.AccountThreshold = 2
+
+	for _, password := range []string{"wrong-password", "password123", "wrong-password", "password123"} {
+		rr := httptest.NewRecorder()
+		LoginHandler(rr, loginRequest("player@example.com", password, "192.0.2.1"), credentials, store, lockout)
+
+		if password == "password123" && rr.Code != http.StatusOK {
+			t.Fatalf("expected the correct password to log in, got %d: %s", rr.Code, rr.Body.String())
+		}
+	}
+}
+
+func TestAdminEndpoints(t *testing.T) {
+	attempts := NewMemoryLoginAttemptRepository()
+	lockout := NewLockout(attempts)
+	lockout.AccountThreshold = 1
+
+	accountID := int64(1)
+	failAttempt(t, lockout, loginRequest("player@example.com", "wrong-password", "192.0.2.1"), accountID)
+
+	if err := lockout.Check(loginRequest("player@example.com", "", "192.0.2.2"), &accountID, ""); httpapitest.Status(err) != http.StatusTooManyRequests {
+		t.Fatalf("expected the account to be locked out, got %v", err)
+	}
+
+	unlock := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/unlock_account", strings.NewReader(`{"account_id": 1}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		UnlockAccountHandler(rr, req, lockout, "admin-token")
+		return rr
+	}
+
+	if rr := unlock("wrong-token"); rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected a wrong admin token to be refused, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if rr := unlock("admin-token"); rr.Code != http.StatusOK {
+		t.Fatalf("UnlockAccountHandler returned %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if err := lockout.Check(loginRequest("player@example.com", "", "192.0.2.2"), &accountID, ""); err != nil {
+		t.Errorf("expected the account to be unlocked, got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/login_failures?account_id=1", nil)
+	req.Header.Set("Authorization", "Bearer admin-token")
+	rr := httptest.NewRecorder()
+	ListLoginFailuresHandler(rr, req, lockout, "admin-token")
+
+	var failures []LoginFailure
+	if err := json.Unmarshal(rr.Body.Bytes(), &failures); err != nil {
+		t.Fatalf("could not decode the response body %q: %v", rr.Body.String(), err)
+	}
+	if len(failures) != 1 || failures[0].IP != "192.0.2.1" {
+		t.Errorf("expected the audit trail to be kept, got %+v", failures)
+	}
+}
+
+func TestAdminEndpointsV2(t *testing.T) {
+	lockout := NewLockout(NewMemoryLoginAttemptRepository())
+	lockout.AccountThreshold = 1
+
+	accountID := int64(1)
+	failAttempt(t, lockout, loginRequest("player@example.com", "wrong-password", "192.0.2.1"), accountID)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("DELETE /v2/admin/accounts/{id}/lockout", func(w http.ResponseWriter, r *http.Request) {
+		UnlockAccountV2Handler(w, r, lockout, "admin-token")
+	})
+	mux.HandleFunc("GET /v2/admin/accounts/{id}/login-failures", func(w http.ResponseWriter, r *http.Request) {
+		ListLoginFailuresV2Handler(w, r, lockout, "admin-token")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v2/admin/accounts/1/login-failures?limit=5", nil)
+	req.Header.Set("Authorization", "Bearer admin-token")
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	var failures []LoginFailure
+	if err := json.Unmarshal(rr.Body.Bytes(), &failures); err != nil || len(failures) != 1 {
+		t.Fatalf("GET /v2/admin/accounts/1/login-failures returned %d: %s", rr.Code, rr.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/v2/admin/accounts/1/lockout", nil)
+	req.Header.Set("Authorization", "Bearer admin-token")
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("DELETE /v2/admin/accounts/1/lockout returned %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if err := lockout.Check(loginRequest("player@example.com", "", "192.0.2.2"), &accountID, ""); err != nil {
+		t.Errorf("expected the account to be unlocked, got %v", err)
+	}
+}
+
+func TestLockout_ConcurrentAttemptsCannotPassTheThreshold(t *testing.T) {
+	lockout := NewLockout(NewMemoryLoginAttemptRepository())
+	lockout.AccountThreshold = 3
+
+	var wg sync.WaitGroup
+	var begun atomic.Int32
+	accountID := int64(1)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if lockout.Begin(loginRequest("player@example.com", "wrong-password", "192.0.2.1"), &accountID, "") == nil {
+				begun.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := begun.Load(); got != 3 {
+		t.Errorf("expected 3 attempts to get past the lockout, got %d", got)
+	}
+}
+
+func TestPostgresLoginAttemptRepository_TakeAttempt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	now := time.Now()
+	policy := NewLockout(nil).policy(DefaultAccountLockoutThreshold)
+	expectThrottle := func(failures int) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO login_throttles (key, failures, last_failure_at) VALUES ($1, 0, $2) ON CONFLICT (key) DO NOTHING")).
+			WithArgs("account:1", now).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT key, failures, last_failure_at FROM login_throttles WHERE key = $1 FOR UPDATE")).
+			WithArgs("account:1").
+			WillReturnRows(sqlmock.NewRows([]string{"key", "failures", "last_failure_at"}).AddRow("account:1", failures, now.Add(-time.Second)))
+	}
+
+	expectThrottle(3)
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE login_throttles SET failures = $2, last_failure_at = $3 WHERE key = $1")).
+		WithArgs("account:1", 4, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	expectThrottle(DefaultAccountLockoutThreshold)
+	mock.ExpectRollback()
+
+	repository := NewPostgresLoginAttemptRepository(sqlx.NewDb(db, "sqlmock"))
+	if retryAfter, err := repository.TakeAttempt("account:1", now, policy); retryAfter != 0 || err != nil {
+		t.Errorf("expected the attempt to be counted, got %v, %v", retryAfter, err)
+	}
+	if retryAfter, err := repository.TakeAttempt("account:1", now, policy); retryAfter != DefaultLockoutBaseDelay-time.Second || err != nil {
+		t.Errorf("expected the key to be locked out, got %v, %v", retryAfter, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+)
+
+// LoginArgs represents the expected structure of the request body for logging in to an account.
+//
+// @Description Structure for the login request payload.
+type LoginArgs struct {
+	// The email address of the account to log in to.
+	Email string `json:"email" validate:"required"`
+	// The password for the account.
+	Password string `json:"password" validate:"required"`
+}
+
+const ERROR_INVALID_CREDENTIALS = "invalid email or password"
+
+// CodeInvalidCredentials is the code of the error returned when the email or password is wrong.
+const CodeInvalidCredentials httpapi.Code = "invalid_credentials"
+
+// dummyHash is a hash of no password which logins for unknown emails are compared against.
+var dummyHash struct {
+	mu   sync.Mutex
+	hash string
+}
+
+// dummyPasswordHash returns a hash made with Hasher's parameters, so that comparing against it takes as
+// long as comparing against an account's hash.
+func dummyPasswordHash() string {
+	dummyHash.mu.Lock()
+	defer dummyHash.mu.Unlock()
+
+	// Hasher can be replaced after the first login, which would make the hash out of date
+	if dummyHash.hash == "" || PasswordNeedsRehash(dummyHash.hash) {
+		hash, err := HashPassword("")
+		if err != nil {
+			log.Println("error making the dummy password hash: ", err.Error())
+			return ""
+		}
+		dummyHash.hash = hash
+	}
+
+	return dummyHash.hash
+}
+
+// Login verifies an account's password and issues a new session for it.
+//
+// @Summary Log in to an account
+// @Description This endpoint verifies the email and password for an account and returns a new session. Sessions expire 12 hours from last interaction. After repeated failed attempts the account and the caller's IP address are locked out for a time, given by the Retry-After header.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body LoginArgs true "login request body"
+// @Success 200 {object} Session "Successfully logged in"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 429 {object} httpapi.ErrorResponse "Too Many Requests"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /auth/login [post]
+// @Router /v2/sessions [post]
+func Login(w http.ResponseWriter, r *http.Request, credentials CredentialRepository, store *SessionStore, lockout *Lockout) error {
+	if r.Method != http.MethodPost {
+		return httpapi.MethodNotAllowed(http.MethodPost)
+	}
+
+	args := LoginArgs{}
+	err := httpapi.DecodeJSON(r, &args)
+	if err != nil {
+		return err
+	}
+
+	if err := httpapi.Validate(&args); err != nil {
+		return err
+	}
+
+	stored, err := credentials.CredentialsByEmail(args.Email)
+	if err != nil && !errors.Is(err, ErrCredentialsNotFound) {
+		return fmt.Errorf("error retrieving account credentials: %v", err)
+	}
+
+	if stored == nil {
+		// Emails without an account are locked out the same way, so the lockout does not reveal which emails have accounts
+		if err := lockout.Begin(r, nil, args.Email); err != nil {
+			return err
+		}
+		// Hashing the password as if the email had an account keeps the response from taking less time
+		// than for an account's wrong password
+		ComparePassword(dummyPasswordHash(), "", args.Password)
+		if err := lockout.Fail(r, nil, args.Email, LOGIN_FAILURE_UNKNOWN_EMAIL); err != nil {
+			log.Println("error recording a failed password attempt: ", err.Error())
+		}
+		return httpapi.Unauthorized(ERROR_INVALID_CREDENTIALS).WithCode(CodeInvalidCredentials)
+	}
+
+	if err := lockout.ComparePassword(r, stored, args.Password); err != nil {
+		if errors.Is(err, ErrPasswordMismatch) {
+			return httpapi.Unauthorized(ERROR_INVALID_CREDENTIALS).WithCode(CodeInvalidCredentials)
+		}
+		return err
+	}
+
+	// The password is only known while logging in, so this is the chance to upgrade hashes made with
+	// older parameters. Failing to do so should not stop the account from logging in.
+	if PasswordNeedsRehash(stored.Hash) {
+		if hash, err := HashPassword(args.Password); err != nil {
+			log.Println("error rehashing a password: ", err.Error())
+		} else if err := credentials.UpdateCredentials(int64(stored.AccountID), hash); err != nil {
+			log.Println("error saving a rehashed password: ", err.Error())
+		}
+	}
+
+	session, err := store.CreateSession(stored.AccountID)
+	if err != nil {
+		log.Println("error creating a session: ", err.Error())
+		return errors.New("an error occurred while creating a session. Please try again at a later time")
+	}
+
+	httpapi.WriteJSON(w, http.StatusOK, session)
+	return nil
+}
@@ -0,0 +1,252 @@
+package auth
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// LoginThrottle counts recent failed password attempts for an account or an IP address.
+type LoginThrottle struct {
+	// Key is "account:<id>" or "ip:<address>".
+	Key           string    `db:"key"`
+	Failures      int       `db:"failures"`
+	LastFailureAt time.Time `db:"last_failure_at"`
+}
+
+// ThrottlePolicy says when a throttle locks its key out: after Threshold failures in a row, for BaseDelay,
+// doubled for each further failure up to MaxDelay. The count starts over ResetAfter after the last failure.
+type ThrottlePolicy struct {
+	Threshold  int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+	ResetAfter time.Duration
+}
+
+// delay returns how long a key with the given number of failures is locked for after its last failure.
+func (p ThrottlePolicy) delay(failures int) time.Duration {
+	if failures < p.Threshold {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := p.Threshold; i < failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// RetryAfter returns how long the throttle's key is still locked out at now, or 0 when it is not.
+func (p ThrottlePolicy) RetryAfter(throttle LoginThrottle, now time.Time) time.Duration {
+	if remaining := throttle.LastFailureAt.Add(p.delay(throttle.Failures)).Sub(now); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// count returns the throttle with one more failure at now, starting over when the last one is too old.
+func (p ThrottlePolicy) count(throttle LoginThrottle, now time.Time) LoginThrottle {
+	if throttle.LastFailureAt.Before(now.Add(-p.ResetAfter)) {
+		throttle.Failures = 0
+	}
+	throttle.Failures++
+	throttle.LastFailureAt = now
+	return throttle
+}
+
+// LoginFailure is an entry in the audit trail of failed password attempts.
+type LoginFailure struct {
+	ID int64 `db:"id" json:"id"`
+	// AccountID is nil when the attempt was for an email with no account.
+	AccountID *int64    `db:"account_id" json:"account_id"`
+	IP        string    `db:"ip" json:"ip"`
+	Reason    string    `db:"reason" json:"reason"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// LoginAttemptRepository stores failed password attempts, both as counters used to lock out
+// attackers and as an audit trail.
+type LoginAttemptRepository interface {
+	// TakeAttempt counts an attempt for the key at now as failed, unless the policy has the key locked out,
+	// in which case it counts nothing and returns how long the lock has left. The check and the count are
+	// one step, so concurrent attempts cannot all get past the check before any of them is counted.
+	TakeAttempt(key string, now time.Time, policy ThrottlePolicy) (retryAfter time.Duration, err error)
+	// ForgiveAttempt takes back one attempt counted for the key, such as when the password was right.
+	ForgiveAttempt(key string) error
+	// GetThrottles returns the throttles which exist for the keys.
+	GetThrottles(keys []string) ([]LoginThrottle, error)
+	ClearThrottle(key string) error
+	RecordLoginFailure(failure *LoginFailure) error
+	// ListLoginFailures lists the account's failed attempts, newest first.
+	ListLoginFailures(accountID int64, limit int) ([]LoginFailure, error)
+}
+
+// PostgresLoginAttemptRepository stores failed attempts in the login_throttles and login_failures tables.
+type PostgresLoginAttemptRepository struct {
+	DB *sqlx.DB
+}
+
+// NewPostgresLoginAttemptRepository creates a LoginAttemptRepository backed by Postgres.
+func NewPostgresLoginAttemptRepository(db *sqlx.DB) *PostgresLoginAttemptRepository {
+	return &PostgresLoginAttemptRepository{DB: db}
+}
+
+func (p *PostgresLoginAttemptRepository) TakeAttempt(key string, now time.Time, policy ThrottlePolicy) (time.Duration, error) {
+	tx, err := p.DB.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// The row is created if needed so that it can be locked, which makes concurrent attempts for the key
+	// wait for this one to be counted
+	if _, err := tx.Exec("INSERT INTO login_throttles (key, failures, last_failure_at) VALUES ($1, 0, $2) ON CONFLICT (key) DO NOTHING", key, now); err != nil {
+		return 0, err
+	}
+
+	var throttle LoginThrottle
+	if err := tx.Get(&throttle, "SELECT key, failures, last_failure_at FROM login_throttles WHERE key = $1 FOR UPDATE", key); err != nil {
+		return 0, err
+	}
+
+	if retryAfter := policy.RetryAfter(throttle, now); retryAfter > 0 {
+		return retryAfter, nil
+	}
+
+	throttle = policy.count(throttle, now)
+	if _, err := tx.Exec("UPDATE login_throttles SET failures = $2, last_failure_at = $3 WHERE key = $1", key, throttle.Failures, throttle.LastFailureAt); err != nil {
+		return 0, err
+	}
+
+	return 0, tx.Commit()
+}
+
+func (p *PostgresLoginAttemptRepository) ForgiveAttempt(key string) error {
+	_, err := p.DB.Exec("UPDATE login_throttles SET failures = failures - 1 WHERE key = $1 AND failures > 0", key)
+	return err
+}
+
+func (p *PostgresLoginAttemptRepository) GetThrottles(keys []string) ([]LoginThrottle, error) {
+	query, args, err := sqlx.In("SELECT key, failures, last_failure_at FROM login_throttles WHERE key IN (?)", keys)
+	if err != nil {
+		return nil, err
+	}
+
+	throttles := []LoginThrottle{}
+	if err := p.DB.Select(&throttles, p.DB.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+	return throttles, nil
+}
+
+func (p *PostgresLoginAttemptRepository) ClearThrottle(key string) error {
+	_, err := p.DB.Exec("DELETE FROM login_throttles WHERE key = $1", key)
+	return err
+}
+
+func (p *PostgresLoginAttemptRepository) RecordLoginFailure(failure *LoginFailure) error {
+	query := "INSERT INTO login_failures (account_id, ip, reason, created_at) VALUES ($1, $2, $3, $4) RETURNING id"
+	return p.DB.QueryRow(query, failure.AccountID, failure.IP, failure.Reason, failure.CreatedAt).Scan(&failure.ID)
+}
+
+func (p *PostgresLoginAttemptRepository) ListLoginFailures(accountID int64, limit int) ([]LoginFailure, error) {
+	failures := []LoginFailure{}
+	query := "SELECT id, account_id, ip, reason, created_at FROM login_failures WHERE account_id = $1 ORDER BY created_at DESC, id DESC LIMIT $2"
+	if err := p.DB.Select(&failures, query, accountID, limit); err != nil {
+		return nil, err
+	}
+	return failures, nil
+}
+
+// MemoryLoginAttemptRepository stores failed attempts in memory. It is safe for concurrent use.
+type MemoryLoginAttemptRepository struct {
+	mu        sync.Mutex
+	throttles map[string]LoginThrottle
+	failures  []LoginFailure
+}
+
+// NewMemoryLoginAttemptRepository creates an empty MemoryLoginAttemptRepository.
+func NewMemoryLoginAttemptRepository() *MemoryLoginAttemptRepository {
+	return &MemoryLoginAttemptRepository{throttles: map[string]LoginThrottle{}}
+}
+
+func (m *MemoryLoginAttemptRepository) TakeAttempt(key string, now time.Time, policy ThrottlePolicy) (time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	throttle, ok := m.throttles[key]
+	if !ok {
+		throttle = LoginThrottle{Key: key}
+	}
+
+	if retryAfter := policy.RetryAfter(throttle, now); retryAfter > 0 {
+		return retryAfter, nil
+	}
+
+	m.throttles[key] = policy.count(throttle, now)
+	return 0, nil
+}
+
+func (m *MemoryLoginAttemptRepository) ForgiveAttempt(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if throttle, ok := m.throttles[key]; ok && throttle.Failures > 0 {
+		throttle.Failures--
+		m.throttles[key] = throttle
+	}
+	return nil
+}
+
+func (m *MemoryLoginAttemptRepository) GetThrottles(keys []string) ([]LoginThrottle, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	throttles := []LoginThrottle{}
+	for _, key := range keys {
+		if throttle, ok := m.throttles[key]; ok {
+			throttles = append(throttles, throttle)
+		}
+	}
+	return throttles, nil
+}
+
+func (m *MemoryLoginAttemptRepository) ClearThrottle(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.throttles, key)
+	return nil
+}
+
+func (m *MemoryLoginAttemptRepository) RecordLoginFailure(failure *LoginFailure) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	failure.ID = int64(len(m.failures) + 1)
+	m.failures = append(m.failures, *failure)
+	return nil
+}
+
+func (m *MemoryLoginAttemptRepository) ListLoginFailures(accountID int64, limit int) ([]LoginFailure, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	failures := []LoginFailure{}
+	for _, failure := range m.failures {
+		if failure.AccountID != nil && *failure.AccountID == accountID {
+			failures = append(failures, failure)
+		}
+	}
+
+	sort.Slice(failures, func(i, j int) bool { return failures[i].ID > failures[j].ID })
+	if len(failures) > limit {
+		failures = failures[:limit]
+	}
+	return failures, nil
+}
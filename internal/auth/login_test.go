@@ -0,0 +1,275 @@
+package auth
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi/httpapitest"
+)
+
+const loginQuery = "SELECT account.id, passwords.hash, COALESCE(passwords.salt, '') FROM account JOIN passwords ON passwords.account_email = account.email WHERE account.email = $1"
+
+func TestLogin_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	hash, err := HashPassword("password123")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(loginQuery)).
+		WithArgs("test@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hash", "salt"}).
+			AddRow(1, hash, ""))
+
+	mock.ExpectExec("INSERT INTO sessions").
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 1, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	req, err := http.NewRequest("POST", "/auth/login", strings.NewReader(`{"email": "test@example.com", "password": "password123"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	store := NewSessionStore(sqlxDB)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		LoginHandler(w, r, NewPostgresCredentialRepository(sqlxDB), store, NewLockout(NewMemoryLoginAttemptRepository()))
+	})
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var session Session
+	if err := json.Unmarshal(rr.Body.Bytes(), &session); err != nil {
+		t.Fatalf("could not decode the response body: %v", err)
+	}
+
+	if session.AccountID != 1 {
+		t.Errorf("expected account ID %d, got %d", 1, session.AccountID)
+	}
+
+	if session.Token == "" {
+		t.Errorf("expected a session token to be returned")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestLogin_RehashesLegacyHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	hashSalt, err := legacyHasher.GenerateHash([]byte("password123"), nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(loginQuery)).
+		WithArgs("test@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hash", "salt"}).
+			AddRow(1, base64.StdEncoding.EncodeToString(hashSalt.Hash), base64.StdEncoding.EncodeToString(hashSalt.Salt)))
+
+	var rehashed string
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE passwords SET hash = $1, salt = NULL WHERE account_email = (SELECT email FROM account WHERE id = $2)")).
+		WithArgs(phcHashArg{&rehashed}, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectExec("INSERT INTO sessions").
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 1, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	req := httptest.NewRequest("POST", "/auth/login", strings.NewReader(`{"email": "test@example.com", "password": "password123"}`))
+	rr := httptest.NewRecorder()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	LoginHandler(rr, req, NewPostgresCredentialRepository(sqlxDB), NewSessionStore(sqlxDB), NewLockout(NewMemoryLoginAttemptRepository()))
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	if err := ComparePassword(rehashed, "", "password123"); err != nil {
+		t.Errorf("expected the rehashed password to match: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+// phcHashArg matches a password hash in the PHC string format and keeps it.
+type phcHashArg struct {
+	hash *string
+}
+
+func (a phcHashArg) Match(value driver.Value) bool {
+	hash, ok := value.(string)
+	if !ok || !isPHCHash(hash) {
+		return false
+	}
+	*a.hash = hash
+	return true
+}
+
+func TestLogin_WrongPassword(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	hash, err := HashPassword("password123")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(loginQuery)).
+		WithArgs("test@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hash", "salt"}).
+			AddRow(1, hash, ""))
+
+	req, err := http.NewRequest("POST", "/auth/login", strings.NewReader(`{"email": "test@example.com", "password": "wrongpassword"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	store := NewSessionStore(sqlxDB)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		LoginHandler(w, r, NewPostgresCredentialRepository(sqlxDB), store, NewLockout(NewMemoryLoginAttemptRepository()))
+	})
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusUnauthorized {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusUnauthorized)
+	}
+
+	if httpapitest.Message(rr) != ERROR_INVALID_CREDENTIALS {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), ERROR_INVALID_CREDENTIALS)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestLogin_UnknownEmail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(loginQuery)).
+		WithArgs("nobody@example.com").
+		WillReturnError(sql.ErrNoRows)
+
+	req, err := http.NewRequest("POST", "/auth/login", strings.NewReader(`{"email": "nobody@example.com", "password": "password123"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	store := NewSessionStore(sqlxDB)
+
+	err = Login(rr, req, NewPostgresCredentialRepository(sqlxDB), store, NewLockout(NewMemoryLoginAttemptRepository()))
+
+	if err == nil || err.Error() != ERROR_INVALID_CREDENTIALS {
+		t.Errorf("Login() error = %v, wantErr %v", err, ERROR_INVALID_CREDENTIALS)
+	}
+
+	if status := httpapitest.Status(err); status != http.StatusUnauthorized {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusUnauthorized)
+	}
+}
+
+func TestDummyPasswordHash(t *testing.T) {
+	hash := dummyPasswordHash()
+	if PasswordNeedsRehash(hash) {
+		t.Errorf("expected the dummy hash to use Hasher's parameters, got %s", hash)
+	}
+
+	if err := ComparePassword(hash, "", "password123"); err != ErrPasswordMismatch {
+		t.Errorf("expected no password to match the dummy hash, got %v", err)
+	}
+
+	previous := Hasher
+	defer func() { Hasher = previous }()
+	Hasher = NewArgon2idHash(1, 16, 8*1024, 1, 32)
+
+	if updated := dummyPasswordHash(); updated == hash || PasswordNeedsRehash(updated) {
+		t.Errorf("expected the dummy hash to follow Hasher's parameters, got %s", updated)
+	}
+}
+
+func TestLogin_MissingPassword(t *testing.T) {
+	req, err := http.NewRequest("POST", "/auth/login", strings.NewReader(`{"email": "test@example.com"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	var mockCredentials CredentialRepository = nil
+	var mockStore *SessionStore = nil
+
+	err = Login(rr, req, mockCredentials, mockStore, nil)
+
+	expectedError := "password must be specified"
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("Login() error = %v, wantErr %v", err, expectedError)
+	}
+
+	if status := httpapitest.Status(err); status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+	}
+}
+
+func TestLogin_InvalidMethod(t *testing.T) {
+	req, err := http.NewRequest("GET", "/auth/login", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	var mockCredentials CredentialRepository = nil
+	var mockStore *SessionStore = nil
+
+	err = Login(rr, req, mockCredentials, mockStore, nil)
+
+	expectedError := "invalid request; request must be a POST request"
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("Login() error = %v, wantErr %v", err, expectedError)
+	}
+
+	if status := httpapitest.Status(err); status != http.StatusMethodNotAllowed {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusMethodNotAllowed)
+	}
+}
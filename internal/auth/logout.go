@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+)
+
+const ERROR_LOGOUT_SESSION_NOT_FOUND = "session not found"
+const ERROR_LOGOUT_SESSION_EXPIRED = "session has expired"
+
+// LogoutArgs represents the expected structure of the request body for logging out.
+//
+// @Description Structure for the logout request payload. The body may be omitted when the session token is sent in the Authorization header.
+type LogoutArgs struct {
+	// Deprecated: the numeric session ID to invalidate. Send the session token in the Authorization header instead.
+	SessionId *int64 `json:"session_id,omitempty"`
+}
+
+// Logout invalidates a session.
+//
+// @Summary Log out of an account
+// @Description This endpoint invalidates the given session so that it can no longer be used. Numeric session IDs are only accepted until the legacy session ID cutoff.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param Authorization header string false "Bearer session token"
+// @Param body body LogoutArgs false "logout request body"
+// @Success 200 {object} httpapi.MessageResponse "Successfully logged out!"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /auth/logout [post]
+func Logout(w http.ResponseWriter, r *http.Request, store *SessionStore) error {
+	if r.Method != http.MethodPost {
+		return httpapi.MethodNotAllowed(http.MethodPost)
+	}
+
+	// The body is optional when a bearer token is used
+	args := LogoutArgs{}
+	if err := httpapi.DecodeOptionalJSON(r, &args); err != nil {
+		return err
+	}
+
+	return logout(w, r, args, store)
+}
+
+// LogoutV2 invalidates the session whose token is in the Authorization header.
+//
+// @Summary Log out of an account
+// @Description This endpoint invalidates the session making the request so that it can no longer be used.
+// @Tags auth
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Success 200 {object} httpapi.MessageResponse "Successfully logged out!"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /v2/sessions/current [delete]
+func LogoutV2(w http.ResponseWriter, r *http.Request, store *SessionStore) error {
+	return logout(w, r, LogoutArgs{}, store)
+}
+
+// logout invalidates the session of the bearer token, or else the deprecated session ID in args.
+func logout(w http.ResponseWriter, r *http.Request, args LogoutArgs, store *SessionStore) error {
+	if BearerToken(r) == "" && args.SessionId == nil {
+		return httpapi.Validation(ERROR_SESSION_REQUIRED)
+	}
+
+	// Looked up the same way as for any other request, so legacy IDs stop working at the same cutoff
+	session, err := store.Authenticate(r, args.SessionId)
+	switch err {
+	case nil:
+	case ErrSessionNotFound:
+		return httpapi.Unauthorized(ERROR_LOGOUT_SESSION_NOT_FOUND).WithCode(CodeSessionNotFound)
+	case ErrSessionExpired:
+		return httpapi.Unauthorized(ERROR_LOGOUT_SESSION_EXPIRED).WithCode(CodeSessionExpired)
+	default:
+		return err
+	}
+
+	if token := BearerToken(r); token != "" {
+		err = store.DeleteSession(token)
+	} else {
+		err = store.DeleteSessionByID(session.ID)
+	}
+
+	if err != nil {
+		return errors.New("an error occurred while deleting the session: " + err.Error())
+	}
+
+	httpapi.WriteMessage(w, http.StatusOK, "Successfully logged out!")
+	return nil
+}
@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi/httpapitest"
+)
+
+func TestLogout_Success(t *testing.T) {
+	store := NewSessionStoreWithRepository(NewMemorySessionRepository())
+	session, err := store.CreateSession(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := http.NewRequest("POST", "/auth/logout", http.NoBody)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+session.Token)
+
+	rr := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		LogoutHandler(w, r, store)
+	})
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	expected := "Successfully logged out!"
+	if httpapitest.Message(rr) != expected {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expected)
+	}
+
+	if found, _ := store.GetSessionByToken(session.Token); found != nil {
+		t.Error("expected the session to be deleted")
+	}
+}
+
+func TestLogout_LegacySessionID(t *testing.T) {
+	store := NewSessionStoreWithRepository(NewMemorySessionRepository())
+	session, err := store.CreateSession(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := http.NewRequest("POST", "/auth/logout", strings.NewReader(fmt.Sprintf(`{"session_id": %d}`, session.ID)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	err = Logout(rr, req, store)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	if found, _ := store.GetSession(session.ID); found != nil {
+		t.Error("expected the session to be deleted")
+	}
+}
+
+func TestLogout_LegacySessionIDAfterCutoff(t *testing.T) {
+	store := NewSessionStoreWithRepository(NewMemorySessionRepository())
+	store.LegacyIDCutoff = time.Now().Add(-time.Hour)
+	session, err := store.CreateSession(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := http.NewRequest("POST", "/auth/logout", strings.NewReader(fmt.Sprintf(`{"session_id": %d}`, session.ID)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Logout(httptest.NewRecorder(), req, store)
+	if status := httpapitest.Status(err); status != http.StatusUnauthorized {
+		t.Errorf("expected the legacy session ID to be refused with %v, got %v (%v)", http.StatusUnauthorized, status, err)
+	}
+
+	if found, _ := store.GetSessionByToken(session.Token); found == nil {
+		t.Error("expected the session to be kept")
+	}
+}
+
+func TestLogout_UnknownOrExpiredSession(t *testing.T) {
+	sessions := NewMemorySessionRepository()
+	store := NewSessionStoreWithRepository(sessions)
+
+	expired, err := store.CreateSession(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sessions.UpdateSessionExpiry(expired.ID, time.Now().Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		token    string
+		wantCode httpapi.Code
+	}{
+		{name: "unknown token", token: "unknown-token", wantCode: CodeSessionNotFound},
+		{name: "expired session", token: expired.Token, wantCode: CodeSessionExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST", "/auth/logout", http.NoBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+
+			rr := httptest.NewRecorder()
+			LogoutHandler(rr, req, store)
+
+			if rr.Code != http.StatusUnauthorized || httpapitest.Code(rr) != tt.wantCode {
+				t.Errorf("expected %v with the code %v, got %v: %s", http.StatusUnauthorized, tt.wantCode, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestLogout_MissingSessionID(t *testing.T) {
+	req, err := http.NewRequest("POST", "/auth/logout", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	var mockStore *SessionStore = nil
+
+	err = Logout(rr, req, mockStore)
+
+	expectedError := ERROR_SESSION_REQUIRED
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("Logout() error = %v, wantErr %v", err, expectedError)
+	}
+
+	if status := httpapitest.Status(err); status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+	}
+}
+
+func TestLogout_DeleteError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM sessions WHERE id = $1")).
+		WithArgs(int64(12345)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "account_id", "created_at", "expires_at"}).AddRow(12345, 1, now, now.Add(time.Hour)))
+	mock.ExpectExec("UPDATE sessions SET expires_at").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM sessions WHERE id = \\$1").
+		WithArgs(int64(12345)).
+		WillReturnError(sql.ErrConnDone)
+
+	req, err := http.NewRequest("POST", "/auth/logout", strings.NewReader(`{"session_id": 12345}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	store := NewSessionStore(sqlxDB)
+
+	err = Logout(rr, req, store)
+	if err == nil {
+		t.Errorf("expected error, got nil")
+	}
+
+	if status := httpapitest.Status(err); status != http.StatusInternalServerError {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+	}
+}
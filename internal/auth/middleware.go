@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+)
+
+type contextKey int
+
+const sessionContextKey contextKey = iota
+
+// maxPeekedBodySize limits how much of a request body is read when looking for a legacy session_id.
+const maxPeekedBodySize = 1 << 20
+
+// The codes of the errors returned by Authenticate.
+const (
+	CodeSessionRequired httpapi.Code = "session_required"
+	CodeSessionNotFound httpapi.Code = "session_not_found"
+	CodeSessionExpired  httpapi.Code = "session_expired"
+)
+
+var (
+	ErrSessionRequired = httpapi.Unauthorized(ERROR_SESSION_REQUIRED).WithCode(CodeSessionRequired)
+	ErrSessionNotFound = httpapi.Forbidden("session not found").WithCode(CodeSessionNotFound)
+	ErrSessionExpired  = httpapi.Forbidden("session has expired").WithCode(CodeSessionExpired)
+	ErrForbidden       = httpapi.Forbidden("the session does not belong to the account being acted on")
+)
+
+// ContextWithSession returns a copy of ctx carrying the given session.
+func ContextWithSession(ctx context.Context, session *Session) context.Context {
+	return context.WithValue(ctx, sessionContextKey, session)
+}
+
+// SessionFromContext returns the session stored in ctx by Middleware, if any.
+func SessionFromContext(ctx context.Context) (*Session, bool) {
+	session, ok := ctx.Value(sessionContextKey).(*Session)
+	return session, ok && session != nil
+}
+
+// AccountIDFromContext returns the ID of the account whose session is stored in ctx, if any.
+func AccountIDFromContext(ctx context.Context) (int64, bool) {
+	session, ok := SessionFromContext(ctx)
+	if !ok {
+		return 0, false
+	}
+	return int64(session.AccountID), true
+}
+
+// Authenticate returns the caller's session. If the request has already been through Middleware the
+// session is taken from the request context; otherwise it is looked up from the bearer token or,
+// during the deprecation window, legacySessionID.
+func (s *SessionStore) Authenticate(r *http.Request, legacySessionID *int64) (*Session, error) {
+	session, ok := SessionFromContext(r.Context())
+	if !ok {
+		if BearerToken(r) == "" && legacySessionID == nil {
+			return nil, ErrSessionRequired
+		}
+
+		var err error
+		session, err = s.SessionFromRequest(r, legacySessionID)
+		if err != nil {
+			return nil, errors.New("an error occurred while retrieving the session: " + err.Error())
+		}
+	}
+
+	if session == nil {
+		return nil, ErrSessionNotFound
+	}
+
+	if session.IsExpired() {
+		return nil, ErrSessionExpired
+	}
+
+	return session, nil
+}
+
+// RequireAccount returns ErrForbidden unless the session belongs to the given account.
+func RequireAccount(session *Session, accountID int64) error {
+	if session == nil || int64(session.AccountID) != accountID {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// Middleware rejects requests without a valid session and stores the caller's session in the
+// request context for the wrapped handler.
+func (s *SessionStore) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var legacySessionID *int64
+		if BearerToken(r) == "" {
+			legacySessionID = legacySessionIDFromRequest(r)
+		}
+
+		session, err := s.Authenticate(r, legacySessionID)
+		if err != nil {
+			httpapi.WriteError(w, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(ContextWithSession(r.Context(), session)))
+	})
+}
+
+// legacySessionIDFromRequest finds a numeric session_id in the query string or JSON body.
+// The body is put back afterwards so that the handler can still decode it.
+func legacySessionIDFromRequest(r *http.Request) *int64 {
+	if sessionIDStr := r.URL.Query().Get("session_id"); sessionIDStr != "" {
+		sessionID, err := strconv.ParseInt(sessionIDStr, 10, 64)
+		if err != nil {
+			return nil
+		}
+		return &sessionID
+	}
+
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+
+	original := r.Body
+	body, err := io.ReadAll(io.LimitReader(original, maxPeekedBodySize))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), original), original}
+	if err != nil {
+		return nil
+	}
+
+	var args struct {
+		SessionId *int64 `json:"session_id"`
+	}
+	if err := json.Unmarshal(body, &args); err != nil {
+		return nil
+	}
+
+	return args.SessionId
+}
@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi/httpapitest"
+)
+
+func TestMiddleware_StoresSessionInContext(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	token := "test-session-token"
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM sessions WHERE token_hash = $1")).
+		WithArgs(hashSessionToken(token)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "token_hash", "account_id", "created_at", "expires_at"}).
+			AddRow(1, hashSessionToken(token), 7, now, now.Add(time.Hour)))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE sessions SET expires_at = $1 WHERE id = $2")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	store := NewSessionStore(sqlx.NewDb(db, "sqlmock"))
+
+	var gotAccountID int64
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAccountID, _ = AccountIDFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest("GET", "/account/get_account", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rr := httptest.NewRecorder()
+
+	store.Middleware(next).ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	if gotAccountID != 7 {
+		t.Errorf("expected account ID 7 in the request context, got %d", gotAccountID)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestMiddleware_MissingSession(t *testing.T) {
+	store := &SessionStore{}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the wrapped handler should not be called without a session")
+	})
+
+	req := httptest.NewRequest("DELETE", "/lobby/delete_lobby", strings.NewReader(`{"lobby_id": 1}`))
+	rr := httptest.NewRecorder()
+
+	store.Middleware(next).ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusUnauthorized {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusUnauthorized)
+	}
+}
+
+func TestMiddleware_ExpiredLegacySessionFromBody(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	past := time.Now().Add(-time.Hour)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM sessions WHERE id = $1")).
+		WithArgs(int64(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "account_id", "created_at", "expires_at"}).
+			AddRow(5, 1, past.Add(-time.Hour), past))
+
+	store := NewSessionStore(sqlx.NewDb(db, "sqlmock"))
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the wrapped handler should not be called with an expired session")
+	})
+
+	req := httptest.NewRequest("DELETE", "/account/delete_account", strings.NewReader(`{"account_id": 1, "session_id": 5}`))
+	rr := httptest.NewRecorder()
+
+	store.Middleware(next).ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusForbidden {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusForbidden)
+	}
+
+	if httpapitest.Message(rr) != ErrSessionExpired.Error() {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), ErrSessionExpired.Error())
+	}
+}
+
+func TestLegacySessionIDFromRequest_RestoresBody(t *testing.T) {
+	body := `{"account_id": 1, "session_id": 42}`
+	req := httptest.NewRequest("PUT", "/account/update_account", strings.NewReader(body))
+
+	sessionID := legacySessionIDFromRequest(req)
+	if sessionID == nil || *sessionID != 42 {
+		t.Fatalf("expected session ID 42, got %v", sessionID)
+	}
+
+	rest, err := io.ReadAll(req.Body)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if string(rest) != body {
+		t.Errorf("expected the request body to be restored, got %q", string(rest))
+	}
+}
+
+func TestRequireAccount(t *testing.T) {
+	session := &Session{AccountID: 3}
+
+	if err := RequireAccount(session, 3); err != nil {
+		t.Errorf("expected no error for the session's own account, got %v", err)
+	}
+
+	if err := RequireAccount(session, 4); err != ErrForbidden {
+		t.Errorf("expected ErrForbidden for another account, got %v", err)
+	}
+
+	if err := RequireAccount(nil, 3); err != ErrForbidden {
+		t.Errorf("expected ErrForbidden for a nil session, got %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+)
+
+// The default password policy. Argon2id accepts passwords of any length, but hashing one is only as fast
+// as its length allows, so very long passwords are refused.
+const (
+	DefaultMinPasswordLength = 6
+	DefaultMaxPasswordLength = 128
+)
+
+// PasswordPolicy is what every password set on the server must satisfy, whether it is for an account, a
+// lobby or a game. Request fields are checked against Passwords with the "password" validate tag.
+type PasswordPolicy struct {
+	MinLength int
+	MaxLength int
+
+	// denylist holds passwords known from breaches, in lower case.
+	denylist map[string]struct{}
+}
+
+// Passwords is the policy the "password" validate tag checks against.
+var Passwords = NewPasswordPolicy(DefaultMinPasswordLength, DefaultMaxPasswordLength)
+
+func init() {
+	httpapi.RegisterRule("password", func(value reflect.Value, param string) error {
+		return Passwords.Check(value.String())
+	})
+}
+
+// NewPasswordPolicy creates a policy for passwords between minLength and maxLength characters long, with an
+// empty denylist.
+func NewPasswordPolicy(minLength int, maxLength int) *PasswordPolicy {
+	return &PasswordPolicy{MinLength: minLength, MaxLength: maxLength, denylist: map[string]struct{}{}}
+}
+
+// LoadDenylist adds the passwords in the file at path, one per line, to the denylist. Lists of breached
+// passwords can be used as they are; blank lines are skipped.
+func (p *PasswordPolicy) LoadDenylist(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if password := strings.TrimSpace(scanner.Text()); password != "" {
+			p.denylist[strings.ToLower(password)] = struct{}{}
+		}
+	}
+	return scanner.Err()
+}
+
+// Check returns what is wrong with the password, or nil if it satisfies the policy. The message of the
+// error completes a sentence starting with the name of the password field.
+func (p *PasswordPolicy) Check(password string) error {
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		return fmt.Errorf("must be at least %d characters", p.MinLength)
+	}
+	if length > p.MaxLength {
+		return fmt.Errorf("must be at most %d characters", p.MaxLength)
+	}
+	if _, ok := p.denylist[strings.ToLower(password)]; ok {
+		return errors.New("is too common; choose one which has not appeared in a data breach")
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+)
+
+func TestPasswordPolicy_Check(t *testing.T) {
+	policy := NewPasswordPolicy(6, 10)
+
+	dir := t.TempDir()
+	denylist := filepath.Join(dir, "denylist.txt")
+	if err := os.WriteFile(denylist, []byte("123456\n\nPassword1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := policy.LoadDenylist(denylist); err != nil {
+		t.Fatalf("LoadDenylist() error = %v", err)
+	}
+
+	tests := []struct {
+		password string
+		want     string
+	}{
+		{password: "hunter", want: ""},
+		{password: "ünïcødé", want: ""},
+		{password: "short", want: "must be at least 6 characters"},
+		{password: "far too long", want: "must be at most 10 characters"},
+		{password: "123456", want: "is too common; choose one which has not appeared in a data breach"},
+		{password: "PASSWORD1", want: "is too common; choose one which has not appeared in a data breach"},
+	}
+
+	for _, tt := range tests {
+		err := policy.Check(tt.password)
+		if tt.want == "" && err != nil {
+			t.Errorf("Check(%q) error = %v, want nil", tt.password, err)
+		}
+		if tt.want != "" && (err == nil || err.Error() != tt.want) {
+			t.Errorf("Check(%q) error = %v, want %q", tt.password, err, tt.want)
+		}
+	}
+}
+
+func TestPasswordPolicy_LoadDenylistMissingFile(t *testing.T) {
+	if err := NewPasswordPolicy(6, 10).LoadDenylist(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("expected an error loading a denylist which does not exist")
+	}
+}
+
+func TestPasswordRule(t *testing.T) {
+	previous := Passwords
+	Passwords = NewPasswordPolicy(8, 128)
+	t.Cleanup(func() { Passwords = previous })
+
+	args := struct {
+		NewPassword string `json:"new_password" validate:"required,password"`
+	}{NewPassword: "hunter2"}
+
+	err := httpapi.Validate(&args)
+	if err == nil || err.Error() != "new_password must be at least 8 characters" {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrPasswordResetNotFound is returned when a password reset token is unknown, already used or expired.
+var ErrPasswordResetNotFound = errors.New("the password reset token is invalid or has expired")
+
+// DefaultPasswordResetLifetime is how long a password reset token can be used for.
+const DefaultPasswordResetLifetime = time.Hour
+
+// PasswordReset is an outstanding request to reset an account's password.
+type PasswordReset struct {
+	// TokenHash is the SHA-256 hash of the reset token. The token itself is only ever sent to the account's email.
+	TokenHash string    `db:"token_hash"`
+	AccountID int64     `db:"account_id"`
+	CreatedAt time.Time `db:"created_at"`
+	ExpiresAt time.Time `db:"expires_at"`
+}
+
+// PasswordResetRepository stores password reset tokens. An account has at most one outstanding token.
+type PasswordResetRepository interface {
+	// CreatePasswordReset stores the reset, replacing any earlier reset for the same account.
+	CreatePasswordReset(reset *PasswordReset) error
+	// UsePasswordReset calls use with the reset with the token hash and the credentials to save the new
+	// password with, and removes the reset if use succeeds, so that each token works once and a failed reset
+	// can be retried. It returns ErrPasswordResetNotFound when there is no such reset or it expired before
+	// now, and otherwise the error from use.
+	UsePasswordReset(tokenHash string, now time.Time, use func(reset *PasswordReset, credentials CredentialRepository) error) error
+}
+
+// PostgresPasswordResetRepository stores password resets in the password_resets table.
+type PostgresPasswordResetRepository struct {
+	DB *sqlx.DB
+}
+
+// NewPostgresPasswordResetRepository creates a PasswordResetRepository backed by Postgres.
+func NewPostgresPasswordResetRepository(db *sqlx.DB) *PostgresPasswordResetRepository {
+	return &PostgresPasswordResetRepository{DB: db}
+}
+
+func (p *PostgresPasswordResetRepository) CreatePasswordReset(reset *PasswordReset) error {
+	tx, err := p.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM password_resets WHERE account_id = $1`, reset.AccountID); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO password_resets (token_hash, account_id, created_at, expires_at) VALUES (:token_hash, :account_id, :created_at, :expires_at)`
+	if _, err := tx.NamedExec(query, reset); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (p *PostgresPasswordResetRepository) UsePasswordReset(tokenHash string, now time.Time, use func(reset *PasswordReset, credentials CredentialRepository) error) error {
+	tx, err := p.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// The deleted row stays locked until the transaction ends, so two requests cannot both use the
+	// token. The password is saved in the same transaction, so either both happen or neither does
+	var resets []PasswordReset
+	query := `DELETE FROM password_resets WHERE token_hash = $1 AND expires_at > $2 RETURNING token_hash, account_id, created_at, expires_at`
+	if err := tx.Select(&resets, query, tokenHash, now); err != nil {
+		return err
+	}
+
+	if len(resets) == 0 {
+		return ErrPasswordResetNotFound
+	}
+
+	if err := use(&resets[0], NewPostgresCredentialRepository(tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// MemoryPasswordResetRepository stores password resets in memory. It is safe for concurrent use.
+type MemoryPasswordResetRepository struct {
+	// Credentials are passed to the use function of UsePasswordReset to save the new password with.
+	Credentials CredentialRepository
+
+	mu     sync.Mutex
+	resets map[string]PasswordReset
+}
+
+// NewMemoryPasswordResetRepository creates an empty in-memory PasswordResetRepository which saves new
+// passwords with credentials.
+func NewMemoryPasswordResetRepository(credentials CredentialRepository) *MemoryPasswordResetRepository {
+	return &MemoryPasswordResetRepository{Credentials: credentials, resets: make(map[string]PasswordReset)}
+}
+
+func (m *MemoryPasswordResetRepository) CreatePasswordReset(reset *PasswordReset) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for tokenHash, existing := range m.resets {
+		if existing.AccountID == reset.AccountID {
+			delete(m.resets, tokenHash)
+		}
+	}
+
+	m.resets[reset.TokenHash] = *reset
+	return nil
+}
+
+func (m *MemoryPasswordResetRepository) UsePasswordReset(tokenHash string, now time.Time, use func(reset *PasswordReset, credentials CredentialRepository) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	reset, ok := m.resets[tokenHash]
+	if !ok || !reset.ExpiresAt.After(now) {
+		return ErrPasswordResetNotFound
+	}
+
+	if err := use(&reset, m.Credentials); err != nil {
+		return err
+	}
+
+	delete(m.resets, tokenHash)
+	return nil
+}
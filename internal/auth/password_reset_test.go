@@ -0,0 +1,311 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi/httpapitest"
+	"github.com/justinfarrelldev/open-ctp-server/internal/mail"
+)
+
+// memoryCredentials is a CredentialRepository for a single account with the ID 1.
+type memoryCredentials struct {
+	email       string
+	credentials *Credentials
+}
+
+func (m *memoryCredentials) StoreCredentials(accountEmail string, hash string) error {
+	m.email, m.credentials = accountEmail, &Credentials{AccountID: 1, Hash: hash}
+	return nil
+}
+
+func (m *memoryCredentials) UpdateCredentials(accountID int64, hash string) error {
+	if accountID != 1 || m.credentials == nil {
+		return ErrCredentialsNotFound
+	}
+	return m.StoreCredentials(m.email, hash)
+}
+
+func (m *memoryCredentials) CredentialsByEmail(email string) (*Credentials, error) {
+	if email != m.email || m.credentials == nil {
+		return nil, ErrCredentialsNotFound
+	}
+	return m.credentials, nil
+}
+
+func (m *memoryCredentials) CredentialsByAccountID(accountID int64) (*Credentials, error) {
+	if accountID != 1 || m.credentials == nil {
+		return nil, ErrCredentialsNotFound
+	}
+	return m.credentials, nil
+}
+
+// recordingSender keeps the emails it is asked to send.
+type recordingSender struct {
+	messages []mail.Message
+}
+
+func (r *recordingSender) Send(message mail.Message) error {
+	r.messages = append(r.messages, message)
+	return nil
+}
+
+// failingSender cannot send emails.
+type failingSender struct{}
+
+func (failingSender) Send(message mail.Message) error {
+	return errors.New("the mail server is unavailable")
+}
+
+// failingCredentials is a CredentialRepository which cannot update passwords.
+type failingCredentials struct {
+	*memoryCredentials
+}
+
+func (failingCredentials) UpdateCredentials(accountID int64, hash string) error {
+	return errors.New("the password could not be saved")
+}
+
+// usePasswordReset uses a password reset without doing anything with it.
+func usePasswordReset(reset *PasswordReset, credentials CredentialRepository) error {
+	return nil
+}
+
+var resetTokenPattern = regexp.MustCompile(`(?m)^[A-Za-z0-9_-]{43}$`)
+
+func TestPasswordResetFlow(t *testing.T) {
+	hash, err := HashPassword("password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	credentials := &memoryCredentials{}
+	credentials.StoreCredentials("player@example.com", hash)
+
+	resets := NewMemoryPasswordResetRepository(credentials)
+	store := NewSessionStoreWithRepository(NewMemorySessionRepository())
+	sender := &recordingSender{}
+	lockout := NewLockout(NewMemoryLoginAttemptRepository())
+
+	session, err := store.CreateSession(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Unknown emails get the same response, but no email
+	for _, email := range []string{"nobody@example.com", "player@example.com"} {
+		rr := httptest.NewRecorder()
+		ForgotPasswordHandler(rr, httptest.NewRequest(http.MethodPost, "/auth/forgot_password", strings.NewReader(`{"email": "`+email+`"}`)), credentials, resets, sender)
+
+		if rr.Code != http.StatusAccepted || httpapitest.Message(rr) != FORGOT_PASSWORD_RESPONSE {
+			t.Fatalf("ForgotPasswordHandler returned %d: %s", rr.Code, rr.Body.String())
+		}
+	}
+	WaitForPasswordResets()
+
+	if len(sender.messages) != 1 || sender.messages[0].To != "player@example.com" {
+		t.Fatalf("expected one email to the account, got %+v", sender.messages)
+	}
+	token := resetTokenPattern.FindString(sender.messages[0].Body)
+	if token == "" {
+		t.Fatalf("no token in the email: %s", sender.messages[0].Body)
+	}
+
+	// Someone guessing the old password has locked the account out
+	login := httptest.NewRequest(http.MethodPost, "/auth/login", nil)
+	accountID := int64(1)
+	for i := 0; i < lockout.AccountThreshold; i++ {
+		failAttempt(t, lockout, login, accountID)
+	}
+
+	if err := lockout.Check(login, &accountID, ""); httpapitest.Status(err) != http.StatusTooManyRequests {
+		t.Fatalf("expected the account to be locked out, got %v", err)
+	}
+
+	reset := func(token string, password string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		body := `{"token": "` + token + `", "new_password": "` + password + `"}`
+		ResetPasswordHandler(rr, httptest.NewRequest(http.MethodPost, "/auth/reset_password", strings.NewReader(body)), resets, store, lockout)
+		return rr
+	}
+
+	if rr := reset(token, "short"); rr.Code != http.StatusBadRequest || httpapitest.Message(rr) != "new_password must be at least 6 characters" {
+		t.Errorf("expected a short password to be refused, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	// The token survives the refused password
+	if rr := reset(token, "new-password"); rr.Code != http.StatusOK {
+		t.Fatalf("ResetPasswordHandler returned %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if err := ComparePassword(credentials.credentials.Hash, credentials.credentials.Salt, "new-password"); err != nil {
+		t.Errorf("expected the new password to be stored: %v", err)
+	}
+
+	if found, _ := store.GetSessionByToken(session.Token); found != nil {
+		t.Error("expected the account's sessions to be logged out")
+	}
+
+	if err := lockout.Check(httptest.NewRequest(http.MethodPost, "/auth/login", nil), &accountID, ""); err != nil {
+		t.Errorf("expected the reset to lift the lockout, got %v", err)
+	}
+
+	if rr := reset(token, "another-password"); rr.Code != http.StatusBadRequest || httpapitest.Message(rr) != ErrPasswordResetNotFound.Error() {
+		t.Errorf("expected the token to only work once, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestMemoryPasswordResetRepository_Expiry(t *testing.T) {
+	resets := NewMemoryPasswordResetRepository(&memoryCredentials{})
+	now := time.Now()
+
+	if err := resets.CreatePasswordReset(&PasswordReset{TokenHash: "old", AccountID: 1, CreatedAt: now, ExpiresAt: now.Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := resets.CreatePasswordReset(&PasswordReset{TokenHash: "new", AccountID: 1, CreatedAt: now, ExpiresAt: now.Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := resets.UsePasswordReset("old", now, usePasswordReset); err != ErrPasswordResetNotFound {
+		t.Errorf("expected a newer reset to replace the old one, got %v", err)
+	}
+
+	if err := resets.UsePasswordReset("new", now.Add(2*time.Hour), usePasswordReset); err != ErrPasswordResetNotFound {
+		t.Errorf("expected the reset to have expired, got %v", err)
+	}
+}
+
+func TestPostgresPasswordResetRepository_UsePasswordReset(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	now := time.Now()
+	query := regexp.QuoteMeta("DELETE FROM password_resets WHERE token_hash = $1 AND expires_at > $2 RETURNING token_hash, account_id, created_at, expires_at")
+
+	columns := []string{"token_hash", "account_id", "created_at", "expires_at"}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(query).
+		WithArgs("hash", now).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow("hash", 3, now, now.Add(time.Hour)))
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectQuery(query).
+		WithArgs("hash", now).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow("hash", 3, now, now.Add(time.Hour)))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE passwords SET hash = $1, salt = NULL WHERE account_email = (SELECT email FROM account WHERE id = $2)")).
+		WithArgs("new-hash", 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectQuery(query).
+		WithArgs("hash", now).
+		WillReturnRows(sqlmock.NewRows(columns))
+	mock.ExpectRollback()
+
+	resets := NewPostgresPasswordResetRepository(sqlx.NewDb(db, "sqlmock"))
+
+	// A failed use rolls back, which keeps the token
+	failure := errors.New("the password could not be saved")
+	if err := resets.UsePasswordReset("hash", now, func(reset *PasswordReset, credentials CredentialRepository) error { return failure }); err != failure {
+		t.Errorf("expected the error from use, got %v", err)
+	}
+
+	// The password is saved in the transaction which uses up the token
+	var accountID int64
+	err = resets.UsePasswordReset("hash", now, func(reset *PasswordReset, credentials CredentialRepository) error {
+		accountID = reset.AccountID
+		return credentials.UpdateCredentials(reset.AccountID, "new-hash")
+	})
+	if err != nil || accountID != 3 {
+		t.Errorf("unexpected reset for account %d: %v", accountID, err)
+	}
+
+	if err := resets.UsePasswordReset("hash", now, usePasswordReset); err != ErrPasswordResetNotFound {
+		t.Errorf("expected ErrPasswordResetNotFound, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestForgotPassword_EmailFailure(t *testing.T) {
+	credentials := &memoryCredentials{}
+	credentials.StoreCredentials("player@example.com", "hash")
+
+	rr := httptest.NewRecorder()
+	ForgotPasswordHandler(rr, httptest.NewRequest(http.MethodPost, "/auth/forgot_password", strings.NewReader(`{"email": "player@example.com"}`)), credentials, NewMemoryPasswordResetRepository(credentials), failingSender{})
+	WaitForPasswordResets()
+
+	// The response cannot differ from the one for an unknown email
+	if rr.Code != http.StatusAccepted || httpapitest.Message(rr) != FORGOT_PASSWORD_RESPONSE {
+		t.Errorf("ForgotPasswordHandler returned %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestResetPassword_FailedUpdateKeepsToken(t *testing.T) {
+	credentials := &memoryCredentials{}
+	credentials.StoreCredentials("player@example.com", "hash")
+
+	resets := NewMemoryPasswordResetRepository(failingCredentials{credentials})
+	store := NewSessionStoreWithRepository(NewMemorySessionRepository())
+	now := time.Now()
+	if err := resets.CreatePasswordReset(&PasswordReset{TokenHash: hashSessionToken("token"), AccountID: 1, CreatedAt: now, ExpiresAt: now.Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+
+	reset := func() *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		ResetPasswordHandler(rr, httptest.NewRequest(http.MethodPost, "/auth/reset_password", strings.NewReader(`{"token": "token", "new_password": "new-password"}`)), resets, store, NewLockout(NewMemoryLoginAttemptRepository()))
+		return rr
+	}
+
+	if rr := reset(); rr.Code != http.StatusInternalServerError {
+		t.Fatalf("expected the failed update to be reported, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	resets.Credentials = credentials
+	if rr := reset(); rr.Code != http.StatusOK {
+		t.Fatalf("expected the token to still work, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if err := ComparePassword(credentials.credentials.Hash, credentials.credentials.Salt, "new-password"); err != nil {
+		t.Errorf("expected the new password to be stored: %v", err)
+	}
+}
+
+// blockingSender sends emails once release is closed.
+type blockingSender struct {
+	release chan struct{}
+}
+
+func (b blockingSender) Send(message mail.Message) error {
+	<-b.release
+	return nil
+}
+
+func TestForgotPassword_RespondsBeforeSending(t *testing.T) {
+	credentials := &memoryCredentials{}
+	credentials.StoreCredentials("player@example.com", "hash")
+	sender := blockingSender{release: make(chan struct{})}
+	defer WaitForPasswordResets()
+	defer close(sender.release)
+
+	// The response for a known email cannot wait on the email, or its timing would give the account away
+	rr := httptest.NewRecorder()
+	ForgotPasswordHandler(rr, httptest.NewRequest(http.MethodPost, "/auth/forgot_password", strings.NewReader(`{"email": "player@example.com"}`)), credentials, NewMemoryPasswordResetRepository(credentials), sender)
+
+	if rr.Code != http.StatusAccepted || httpapitest.Message(rr) != FORGOT_PASSWORD_RESPONSE {
+		t.Errorf("ForgotPasswordHandler returned %d: %s", rr.Code, rr.Body.String())
+	}
+}
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+)
+
+// CodeResetTokenInvalid is the code of the error returned when a password reset token is unknown, used or expired.
+const CodeResetTokenInvalid httpapi.Code = "reset_token_invalid"
+
+// ResetPasswordArgs represents the expected structure of the request body for resetting a password.
+//
+// @Description Structure for the password reset request payload.
+type ResetPasswordArgs struct {
+	// The password reset token which was emailed to the account.
+	Token string `json:"token" validate:"required"`
+	// The new password for the account.
+	NewPassword string `json:"new_password" validate:"required,password"`
+}
+
+// ResetPassword sets a new password for an account using a token from /auth/forgot_password.
+//
+// @Summary Reset a forgotten password
+// @Description This endpoint sets a new password using a password reset token. The token can only be used once, every session of the account is logged out and a lockout from failed password attempts is lifted.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body ResetPasswordArgs true "password reset request body"
+// @Success 200 {object} httpapi.MessageResponse "Successfully reset password!"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /auth/reset_password [post]
+// @Router /v2/password-resets [post]
+func ResetPassword(w http.ResponseWriter, r *http.Request, resets PasswordResetRepository, store *SessionStore, lockout *Lockout) error {
+	if r.Method != http.MethodPost {
+		return httpapi.MethodNotAllowed(http.MethodPost)
+	}
+
+	args := ResetPasswordArgs{}
+	err := httpapi.DecodeJSON(r, &args)
+	if err != nil {
+		return err
+	}
+
+	// Checked before the token is used up, so that a rejected password can be retried with the same token
+	if err := httpapi.Validate(&args); err != nil {
+		return err
+	}
+
+	// Hashed before the token is used, so that the password is saved in the same step as the token is used up
+	hash, err := HashPassword(args.NewPassword)
+	if err != nil {
+		log.Println("error hashing a password: ", err.Error())
+		return errors.New("an error occurred while saving the password. Please try again later")
+	}
+
+	var accountID int64
+	err = resets.UsePasswordReset(hashSessionToken(args.Token), time.Now(), func(reset *PasswordReset, credentials CredentialRepository) error {
+		accountID = reset.AccountID
+		return credentials.UpdateCredentials(reset.AccountID, hash)
+	})
+	if errors.Is(err, ErrPasswordResetNotFound) {
+		return httpapi.Validation(err.Error()).WithCode(CodeResetTokenInvalid)
+	}
+	if err != nil {
+		log.Println("error saving a password: ", err.Error())
+		return errors.New("an error occurred while saving the password. Please try again at a later time")
+	}
+
+	// The owner has proven they control the account's email, so the failed attempts of whoever was guessing
+	// the old password no longer count against it. Failed attempts for an email with an account are counted
+	// against the account, so this covers the email too
+	if err := lockout.Unlock(accountID); err != nil {
+		log.Println("error clearing failed password attempts: ", err.Error())
+	}
+
+	// Whoever knew the old password may still be logged in
+	if err := store.DeleteAccountSessions(accountID, nil); err != nil {
+		return errors.New("the password was reset, but an error occurred while logging out the account's sessions")
+	}
+
+	httpapi.WriteMessage(w, http.StatusOK, "Successfully reset password!")
+	return nil
+}
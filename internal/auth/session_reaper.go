@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// DefaultSessionReaperInterval is how often the session reaper looks for expired sessions.
+const DefaultSessionReaperInterval = 10 * time.Minute
+
+// DefaultSessionReaperBatchSize is the maximum number of sessions deleted by a single query.
+const DefaultSessionReaperBatchSize = 500
+
+// PurgeExpiredSessions deletes up to batchSize expired sessions and returns how many were deleted.
+func (s *SessionStore) PurgeExpiredSessions(batchSize int) (int64, error) {
+	return s.repository().DeleteExpiredSessions(time.Now(), batchSize)
+}
+
+// RunSessionReaper periodically deletes expired sessions in batches until ctx is cancelled.
+// It blocks, so it should be started in its own goroutine.
+func (s *SessionStore) RunSessionReaper(ctx context.Context, interval time.Duration, batchSize int) {
+	if interval <= 0 {
+		interval = DefaultSessionReaperInterval
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultSessionReaperBatchSize
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Session reaper stopped")
+			return
+		case <-ticker.C:
+			s.reapExpiredSessions(ctx, batchSize)
+		}
+	}
+}
+
+// reapExpiredSessions deletes batches of expired sessions until a batch comes back short,
+// so that one large backlog does not hold a lock on the whole table.
+func (s *SessionStore) reapExpiredSessions(ctx context.Context, batchSize int) {
+	var total int64
+	for ctx.Err() == nil {
+		deleted, err := s.PurgeExpiredSessions(batchSize)
+		if err != nil {
+			log.Printf("Error purging expired sessions: %v", err)
+			return
+		}
+
+		total += deleted
+		if deleted < int64(batchSize) {
+			break
+		}
+	}
+
+	if total > 0 {
+		log.Printf("Purged %d expired sessions", total)
+	}
+}
@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestPurgeExpiredSessions_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	store := NewSessionStore(sqlxDB)
+
+	mock.ExpectExec("DELETE FROM sessions WHERE id IN \\(SELECT id FROM sessions WHERE expires_at < \\$1 LIMIT \\$2\\)").
+		WithArgs(sqlmock.AnyArg(), 100).
+		WillReturnResult(sqlmock.NewResult(0, 42))
+
+	deleted, err := store.PurgeExpiredSessions(100)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if deleted != 42 {
+		t.Errorf("expected 42 sessions to be deleted, got %d", deleted)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPurgeExpiredSessions_Error(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	store := NewSessionStore(sqlxDB)
+
+	mock.ExpectExec("DELETE FROM sessions WHERE id IN").
+		WithArgs(sqlmock.AnyArg(), 100).
+		WillReturnError(sql.ErrConnDone)
+
+	if _, err := store.PurgeExpiredSessions(100); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestRunSessionReaper_PurgesInBatchesAndStops(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	store := NewSessionStore(sqlxDB)
+
+	// A full batch means there may be more to delete, so the reaper should go again.
+	mock.ExpectExec("DELETE FROM sessions WHERE id IN").
+		WithArgs(sqlmock.AnyArg(), 2).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("DELETE FROM sessions WHERE id IN").
+		WithArgs(sqlmock.AnyArg(), 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		store.RunSessionReaper(ctx, 10*time.Millisecond, 2)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for mock.ExpectationsWereMet() != nil && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected the reaper to stop after the context was cancelled")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
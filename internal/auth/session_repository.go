@@ -0,0 +1,221 @@
+package auth
+
+import (
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrSessionExists is returned when a session is created with an ID which is already in use.
+var ErrSessionExists = errors.New("a session with the same ID already exists")
+
+// SessionRepository stores sessions. Lookups return ErrSessionNotFound when there is no matching session.
+type SessionRepository interface {
+	CreateSession(session *Session) error
+	GetSessionByID(sessionID int64) (*Session, error)
+	GetSessionByTokenHash(tokenHash string) (*Session, error)
+	UpdateSessionExpiry(sessionID int64, expiresAt time.Time) error
+	DeleteSessionByID(sessionID int64) error
+	DeleteSessionByTokenHash(tokenHash string) error
+	// DeleteAccountSessions deletes every session of the account, such as when the account is deleted.
+	DeleteAccountSessions(accountID int64) error
+	// DeleteOtherAccountSessions deletes every session of the account except the one with the given ID.
+	DeleteOtherAccountSessions(accountID int64, keepSessionID int64) error
+	// DeleteExpiredSessions deletes up to limit sessions which expired before the given time and returns how many were deleted.
+	DeleteExpiredSessions(before time.Time, limit int) (int64, error)
+}
+
+// PostgresSessionRepository stores sessions in the sessions table.
+type PostgresSessionRepository struct {
+	// DB is either the database or a transaction, so that sessions can be stored together with other changes.
+	DB sqlx.Ext
+}
+
+// NewPostgresSessionRepository creates a SessionRepository backed by Postgres. Pass a *sqlx.Tx to
+// make the repository part of a transaction.
+func NewPostgresSessionRepository(db sqlx.Ext) *PostgresSessionRepository {
+	return &PostgresSessionRepository{DB: db}
+}
+
+func (p *PostgresSessionRepository) CreateSession(session *Session) error {
+	query := `INSERT INTO sessions (id, token_hash, account_id, created_at, expires_at) VALUES (:id, :token_hash, :account_id, :created_at, :expires_at)`
+	_, err := sqlx.NamedExec(p.DB, query, session)
+	return err
+}
+
+func (p *PostgresSessionRepository) GetSessionByID(sessionID int64) (*Session, error) {
+	return p.getSession(`SELECT * FROM sessions WHERE id = $1`, sessionID)
+}
+
+func (p *PostgresSessionRepository) GetSessionByTokenHash(tokenHash string) (*Session, error) {
+	return p.getSession(`SELECT * FROM sessions WHERE token_hash = $1`, tokenHash)
+}
+
+func (p *PostgresSessionRepository) getSession(query string, arg interface{}) (*Session, error) {
+	var session Session
+	if err := sqlx.Get(p.DB, &session, query, arg); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (p *PostgresSessionRepository) UpdateSessionExpiry(sessionID int64, expiresAt time.Time) error {
+	_, err := p.DB.Exec(`UPDATE sessions SET expires_at = $1 WHERE id = $2`, expiresAt, sessionID)
+	return err
+}
+
+func (p *PostgresSessionRepository) DeleteSessionByID(sessionID int64) error {
+	_, err := p.DB.Exec(`DELETE FROM sessions WHERE id = $1`, sessionID)
+	return err
+}
+
+func (p *PostgresSessionRepository) DeleteSessionByTokenHash(tokenHash string) error {
+	_, err := p.DB.Exec(`DELETE FROM sessions WHERE token_hash = $1`, tokenHash)
+	return err
+}
+
+func (p *PostgresSessionRepository) DeleteAccountSessions(accountID int64) error {
+	_, err := p.DB.Exec(`DELETE FROM sessions WHERE account_id = $1`, accountID)
+	return err
+}
+
+func (p *PostgresSessionRepository) DeleteOtherAccountSessions(accountID int64, keepSessionID int64) error {
+	_, err := p.DB.Exec(`DELETE FROM sessions WHERE account_id = $1 AND id <> $2`, accountID, keepSessionID)
+	return err
+}
+
+func (p *PostgresSessionRepository) DeleteExpiredSessions(before time.Time, limit int) (int64, error) {
+	query := `DELETE FROM sessions WHERE id IN (SELECT id FROM sessions WHERE expires_at < $1 LIMIT $2)`
+	result, err := p.DB.Exec(query, before, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// MemorySessionRepository stores sessions in memory. It is safe for concurrent use.
+type MemorySessionRepository struct {
+	mu       sync.Mutex
+	sessions map[int64]Session
+}
+
+// NewMemorySessionRepository creates an empty in-memory SessionRepository.
+func NewMemorySessionRepository() *MemorySessionRepository {
+	return &MemorySessionRepository{sessions: make(map[int64]Session)}
+}
+
+func (m *MemorySessionRepository) CreateSession(session *Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.sessions[session.ID]; ok {
+		return ErrSessionExists
+	}
+
+	stored := *session
+	stored.Token = ""
+	m.sessions[session.ID] = stored
+	return nil
+}
+
+func (m *MemorySessionRepository) GetSessionByID(sessionID int64) (*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session, ok := m.sessions[sessionID]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	return &session, nil
+}
+
+func (m *MemorySessionRepository) GetSessionByTokenHash(tokenHash string) (*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, session := range m.sessions {
+		if session.TokenHash.Valid && session.TokenHash.String == tokenHash {
+			return &session, nil
+		}
+	}
+	return nil, ErrSessionNotFound
+}
+
+func (m *MemorySessionRepository) UpdateSessionExpiry(sessionID int64, expiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if session, ok := m.sessions[sessionID]; ok {
+		session.ExpiresAt = expiresAt
+		m.sessions[sessionID] = session
+	}
+	return nil
+}
+
+func (m *MemorySessionRepository) DeleteSessionByID(sessionID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.sessions, sessionID)
+	return nil
+}
+
+func (m *MemorySessionRepository) DeleteSessionByTokenHash(tokenHash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, session := range m.sessions {
+		if session.TokenHash.Valid && session.TokenHash.String == tokenHash {
+			delete(m.sessions, id)
+		}
+	}
+	return nil
+}
+
+func (m *MemorySessionRepository) DeleteAccountSessions(accountID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, session := range m.sessions {
+		if int64(session.AccountID) == accountID {
+			delete(m.sessions, id)
+		}
+	}
+	return nil
+}
+
+func (m *MemorySessionRepository) DeleteOtherAccountSessions(accountID int64, keepSessionID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, session := range m.sessions {
+		if int64(session.AccountID) == accountID && id != keepSessionID {
+			delete(m.sessions, id)
+		}
+	}
+	return nil
+}
+
+func (m *MemorySessionRepository) DeleteExpiredSessions(before time.Time, limit int) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var deleted int64
+	for id, session := range m.sessions {
+		if deleted >= int64(limit) {
+			break
+		}
+		if session.ExpiresAt.Before(before) {
+			delete(m.sessions, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
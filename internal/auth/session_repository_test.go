@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemorySessionStore_SessionLifecycle(t *testing.T) {
+	store := NewSessionStoreWithRepository(NewMemorySessionRepository())
+
+	session, err := store.CreateSession(1)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	byToken, err := store.GetSessionByToken(session.Token)
+	if err != nil || byToken == nil || byToken.ID != session.ID {
+		t.Fatalf("expected to find the session by its token, got %v, %v", byToken, err)
+	}
+
+	if err := store.DeleteSession(session.Token); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	deleted, err := store.GetSession(session.ID)
+	if err != nil || deleted != nil {
+		t.Errorf("expected the session to be deleted, got %v, %v", deleted, err)
+	}
+}
+
+func TestMemorySessionRepository_DeleteExpiredSessions(t *testing.T) {
+	repository := NewMemorySessionRepository()
+	now := time.Now()
+
+	for id, expiresAt := range map[int64]time.Time{1: now.Add(-time.Hour), 2: now.Add(-time.Minute), 3: now.Add(time.Hour)} {
+		if err := repository.CreateSession(&Session{ID: id, AccountID: 1, CreatedAt: now, ExpiresAt: expiresAt}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	if err := repository.CreateSession(&Session{ID: 3}); err != ErrSessionExists {
+		t.Errorf("expected ErrSessionExists, got %v", err)
+	}
+
+	deleted, err := repository.DeleteExpiredSessions(now, 10)
+	if err != nil || deleted != 2 {
+		t.Errorf("expected 2 expired sessions to be deleted, got %d, %v", deleted, err)
+	}
+
+	if _, err := repository.GetSessionByID(3); err != nil {
+		t.Errorf("expected the live session to remain, got %v", err)
+	}
+}
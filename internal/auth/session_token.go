@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const ERROR_SESSION_REQUIRED = "a session token must be provided in the Authorization header"
+
+// sessionTokenBytes is the number of random bytes in a session token (256 bits).
+const sessionTokenBytes = 32
+
+// DefaultLegacyIDCutoff is when numeric session IDs stop being accepted by default.
+var DefaultLegacyIDCutoff = time.Date(2027, time.April, 1, 0, 0, 0, 0, time.UTC)
+
+// generateSessionToken creates a cryptographically secure, URL-safe session token.
+func generateSessionToken() (string, error) {
+	randomBytes := make([]byte, sessionTokenBytes)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(randomBytes), nil
+}
+
+// hashSessionToken returns the hex-encoded SHA-256 hash of a session token. Only this hash is
+// stored, so a leaked sessions table cannot be used to impersonate players.
+func hashSessionToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewSecretToken creates a random single-use token, such as an email verification token, along with
+// the hash to store for it. The token is made and hashed the same way as a session token.
+func NewSecretToken() (token string, tokenHash string, err error) {
+	token, err = generateSessionToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	return token, hashSessionToken(token), nil
+}
+
+// HashSecretToken returns the hash stored for a token made by NewSecretToken.
+func HashSecretToken(token string) string {
+	return hashSessionToken(token)
+}
+
+// BearerToken returns the token from the request's "Authorization: Bearer" header, or an
+// empty string if there is none.
+func BearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+
+	return strings.TrimSpace(token)
+}
+
+// acceptsLegacyIDs reports whether numeric session IDs are still accepted.
+func (s *SessionStore) acceptsLegacyIDs() bool {
+	return s.LegacyIDCutoff.IsZero() || time.Now().Before(s.LegacyIDCutoff)
+}
+
+// SessionFromRequest looks up the session for a request. The bearer token in the Authorization
+// header is preferred; legacySessionID (the old numeric session_id from the query string or
+// request body) is only used when there is no token and legacy IDs are still accepted.
+// A nil session is returned if no session could be found.
+func (s *SessionStore) SessionFromRequest(r *http.Request, legacySessionID *int64) (*Session, error) {
+	if token := BearerToken(r); token != "" {
+		return s.GetSessionByToken(token)
+	}
+
+	if legacySessionID == nil {
+		return nil, nil
+	}
+
+	if !s.acceptsLegacyIDs() {
+		log.Printf("Rejected legacy session ID %d; numeric session IDs are no longer accepted", *legacySessionID)
+		return nil, nil
+	}
+
+	log.Printf("Deprecated numeric session ID used for %s", r.URL.Path)
+	return s.GetSession(*legacySessionID)
+}
@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestGenerateSessionToken(t *testing.T) {
+	token, err := generateSessionToken()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// 32 bytes of unpadded base64 is 43 characters
+	if len(token) != 43 {
+		t.Errorf("expected a 43 character token, got %d characters", len(token))
+	}
+
+	other, err := generateSessionToken()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if token == other {
+		t.Error("expected two generated tokens to differ")
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{name: "bearer token", header: "Bearer abc123", want: "abc123"},
+		{name: "lowercase scheme", header: "bearer abc123", want: "abc123"},
+		{name: "no header", header: "", want: ""},
+		{name: "basic auth", header: "Basic dXNlcjpwYXNz", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+
+			if got := BearerToken(req); got != tt.want {
+				t.Errorf("BearerToken() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSessionFromRequest_BearerToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	store := NewSessionStore(sqlxDB)
+
+	token := "test-session-token"
+	createdAt := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM sessions WHERE token_hash = $1")).
+		WithArgs(hashSessionToken(token)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "token_hash", "account_id", "created_at", "expires_at"}).
+			AddRow(1, hashSessionToken(token), 7, createdAt, createdAt.Add(time.Hour)))
+	mock.ExpectExec("UPDATE sessions SET expires_at").
+		WithArgs(sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	// The legacy ID should be ignored when a token is present
+	legacyID := int64(99)
+	session, err := store.SessionFromRequest(req, &legacyID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if session == nil || session.AccountID != 7 {
+		t.Errorf("expected the session for account 7, got %v", session)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSessionFromRequest_LegacyID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	store := NewSessionStore(sqlxDB)
+	store.LegacyIDCutoff = time.Now().Add(time.Hour)
+
+	createdAt := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM sessions WHERE id = $1")).
+		WithArgs(int64(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "account_id", "created_at", "expires_at"}).
+			AddRow(5, 7, createdAt, createdAt.Add(time.Hour)))
+	mock.ExpectExec("UPDATE sessions SET expires_at").
+		WithArgs(sqlmock.AnyArg(), 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	req, _ := http.NewRequest("GET", "/", nil)
+	legacyID := int64(5)
+	session, err := store.SessionFromRequest(req, &legacyID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if session == nil || session.ID != 5 {
+		t.Errorf("expected session 5, got %v", session)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSessionFromRequest_LegacyIDAfterCutoff(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	store := NewSessionStore(sqlxDB)
+	store.LegacyIDCutoff = time.Now().Add(-time.Hour)
+
+	req, _ := http.NewRequest("GET", "/", nil)
+	legacyID := int64(5)
+	session, err := store.SessionFromRequest(req, &legacyID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if session != nil {
+		t.Errorf("expected no session once legacy IDs are no longer accepted, got %v", session)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
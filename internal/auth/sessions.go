@@ -13,23 +13,94 @@ import (
 
 // Session represents a user session
 type Session struct {
-	ID        int64     `db:"id" json:"id"`
+	// ID is the numeric session ID.
+	//
+	// Deprecated: numeric session IDs are only accepted until the store's LegacyIDCutoff. Use Token instead.
+	ID int64 `db:"id" json:"id"`
+
+	// Token is the opaque bearer token for the session. It is only known when the session is created,
+	// since only its hash is stored.
+	Token string `db:"-" json:"token,omitempty"`
+
+	// TokenHash is the SHA-256 hash of the session's token.
+	TokenHash sql.NullString `db:"token_hash" json:"-"`
+
 	AccountID int       `db:"account_id" json:"account_id"`
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
 	ExpiresAt time.Time `db:"expires_at" json:"expires_at"`
 }
 
-// SessionStore handles session-related database operations
+// DefaultSessionIdleTimeout is how long a session stays valid after it was last used.
+const DefaultSessionIdleTimeout = 12 * time.Hour
+
+// DefaultSessionMaxLifetime is how long a session can be kept alive by sliding expiry before
+// the player has to log in again.
+const DefaultSessionMaxLifetime = 7 * 24 * time.Hour
+
+// SessionStore handles session lifetimes on top of a SessionRepository
 type SessionStore struct {
+	// Sessions is where sessions are stored. When nil, sessions are stored in DB.
+	Sessions SessionRepository
+
+	// DB is the Postgres database sessions are stored in when Sessions is nil.
 	DB *sqlx.DB
+
+	// IdleTimeout is how long a session stays valid after its last interaction.
+	// Defaults to DefaultSessionIdleTimeout when zero.
+	IdleTimeout time.Duration
+
+	// MaxLifetime is the absolute maximum lifetime of a session, regardless of activity.
+	// Defaults to DefaultSessionMaxLifetime when zero.
+	MaxLifetime time.Duration
+
+	// LegacyIDCutoff is the point after which numeric session IDs are no longer accepted
+	// in place of a session token. Legacy IDs are always accepted when zero.
+	LegacyIDCutoff time.Time
 }
 
-// NewSessionStore creates a new SessionStore
+// NewSessionStore creates a new SessionStore which stores sessions in Postgres
 func NewSessionStore(db *sqlx.DB) *SessionStore {
 	if db == nil {
 		log.Println("Database connection is nil")
 	}
-	return &SessionStore{DB: db}
+	return &SessionStore{DB: db, IdleTimeout: DefaultSessionIdleTimeout, MaxLifetime: DefaultSessionMaxLifetime}
+}
+
+// NewSessionStoreWithRepository creates a new SessionStore which stores sessions in the given repository
+func NewSessionStoreWithRepository(sessions SessionRepository) *SessionStore {
+	return &SessionStore{Sessions: sessions, IdleTimeout: DefaultSessionIdleTimeout, MaxLifetime: DefaultSessionMaxLifetime}
+}
+
+func (s *SessionStore) repository() SessionRepository {
+	if s.Sessions != nil {
+		return s.Sessions
+	}
+	return NewPostgresSessionRepository(s.DB)
+}
+
+func (s *SessionStore) idleTimeout() time.Duration {
+	if s.IdleTimeout <= 0 {
+		return DefaultSessionIdleTimeout
+	}
+	return s.IdleTimeout
+}
+
+func (s *SessionStore) maxLifetime() time.Duration {
+	if s.MaxLifetime <= 0 {
+		return DefaultSessionMaxLifetime
+	}
+	return s.MaxLifetime
+}
+
+// nextExpiry returns the expiry time for a session created at createdAt which was used at now.
+// The expiry slides forward with each interaction but never past the session's max lifetime.
+func (s *SessionStore) nextExpiry(createdAt time.Time, now time.Time) time.Time {
+	expiresAt := now.Add(s.idleTimeout())
+	maxExpiresAt := createdAt.Add(s.maxLifetime())
+	if expiresAt.After(maxExpiresAt) {
+		return maxExpiresAt
+	}
+	return expiresAt
 }
 
 // CreateSession creates a new session for a user
@@ -43,36 +114,77 @@ func NewSessionStore(db *sqlx.DB) *SessionStore {
 // @Failure 400 {object} error
 // @Router /sessions [post]
 func (s *SessionStore) CreateSession(accountID int) (*Session, error) {
+	if s.Sessions == nil && s.DB == nil {
+		log.Println("Database connection is nil")
+		return nil, errors.New("Database connection is nil")
+	}
+
+	return s.CreateSessionIn(s.repository(), accountID)
+}
+
+// CreateSessionIn creates a new session for a user in the given repository rather than the store's own,
+// using the store's lifetimes. This lets the session be stored in the same transaction as other changes,
+// such as the account it belongs to.
+func (s *SessionStore) CreateSessionIn(sessions SessionRepository, accountID int) (*Session, error) {
 	sessionID, err := generateSessionID()
 	if err != nil {
 		log.Printf("Error generating session ID: %v", err)
 		return nil, err
 	}
 
-	if s.DB == nil {
-		log.Println("Database connection is nil")
-		return nil, errors.New("Database connection is nil")
+	token, err := generateSessionToken()
+	if err != nil {
+		log.Printf("Error generating session token: %v", err)
+		return nil, err
 	}
 
+	now := time.Now()
 	session := &Session{
 		ID:        sessionID,
+		Token:     token,
+		TokenHash: sql.NullString{String: hashSessionToken(token), Valid: true},
 		AccountID: accountID,
-		CreatedAt: time.Now(),
-		ExpiresAt: time.Now().Add(12 * time.Hour), // Session expires in 12 hours
+		CreatedAt: now,
+		ExpiresAt: s.nextExpiry(now, now), // Session expires in 12 hours unless it is used
 	}
 
-	query := `INSERT INTO sessions (id, account_id, created_at, expires_at) VALUES (:id, :account_id, :created_at, :expires_at)`
-	_, err = s.DB.NamedExec(query, session)
+	err = sessions.CreateSession(session)
 	if err != nil {
 		log.Printf("Error creating session for account ID %d: %v", accountID, err)
 		return nil, err
 	}
 
-	log.Printf("Session created: %v", session)
+	// The token is a credential, so only the session ID is logged
+	log.Printf("Session created: %d", session.ID)
+	return session, nil
+}
+
+// GetSessionByToken retrieves a session by its bearer token. Like GetSession, retrieving a session
+// that has not expired pushes back its expiry.
+func (s *SessionStore) GetSessionByToken(token string) (*Session, error) {
+	session, err := s.repository().GetSessionByTokenHash(hashSessionToken(token))
+	if err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			log.Println("Session not found for the given token")
+			return nil, nil
+		}
+		log.Printf("Error retrieving session by token: %v", err)
+		return nil, err
+	}
+
+	log.Printf("Session retrieved: %d", session.ID)
+
+	if !session.IsExpired() {
+		s.refreshSession(session)
+	}
+
 	return session, nil
 }
 
-// GetSession retrieves a session by its ID
+// GetSession retrieves a session by its ID. Retrieving a session that has not expired
+// counts as an interaction, so its expiry is pushed back (up to the store's MaxLifetime).
+//
+// Deprecated: look sessions up with GetSessionByToken or SessionFromRequest instead.
 // @Summary Get a session
 // @Description Get a session by its ID
 // @Tags sessions
@@ -83,11 +195,9 @@ func (s *SessionStore) CreateSession(accountID int) (*Session, error) {
 // @Failure 404 {object} error
 // @Router /sessions/{id} [get]
 func (s *SessionStore) GetSession(sessionID int64) (*Session, error) {
-	var session Session
-	query := `SELECT * FROM sessions WHERE id = $1`
-	err := s.DB.Get(&session, query, sessionID)
+	session, err := s.repository().GetSessionByID(sessionID)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, ErrSessionNotFound) {
 			log.Printf("Session not found: %d", sessionID)
 			return nil, nil
 		}
@@ -95,30 +205,96 @@ func (s *SessionStore) GetSession(sessionID int64) (*Session, error) {
 		return nil, err
 	}
 
-	log.Printf("Session retrieved: %v", session)
-	return &session, nil
+	log.Printf("Session retrieved: %d", session.ID)
+
+	if !session.IsExpired() {
+		s.refreshSession(session)
+	}
+
+	return session, nil
+}
+
+// SessionActive reports whether the session still exists and has not expired, such as for a connection
+// which outlives the request that authenticated it. Unlike GetSession, it does not count as an interaction.
+func (s *SessionStore) SessionActive(sessionID int64) (bool, error) {
+	session, err := s.repository().GetSessionByID(sessionID)
+	if errors.Is(err, ErrSessionNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return !session.IsExpired(), nil
+}
+
+// refreshSession slides the expiry of a session forward. A failed refresh is logged rather than
+// returned, since the session itself is still valid until its current expiry.
+func (s *SessionStore) refreshSession(session *Session) {
+	expiresAt := s.nextExpiry(session.CreatedAt, time.Now())
+	if !expiresAt.After(session.ExpiresAt) {
+		return
+	}
+
+	if err := s.repository().UpdateSessionExpiry(session.ID, expiresAt); err != nil {
+		log.Printf("Error refreshing session %d: %v", session.ID, err)
+		return
+	}
+
+	session.ExpiresAt = expiresAt
 }
 
-// DeleteSession deletes a session by its ID. Should not be exposed to end users via any API endpoints.
+// DeleteSession deletes a session by its token. Should not be exposed to end users via any API endpoints.
 // This should instead be used to invalidate any sessions when, for example, a user logs out or an account is deleted.
 // @Summary Delete a session
-// @Description Delete a session by its ID
+// @Description Delete a session by its token
 // @Tags sessions
 // @Accept json
 // @Produce json
-// @Param id path string true "Session ID"
+// @Param token path string true "Session token"
 // @Success 204
 // @Failure 404 {object} error
-// @Router /sessions/{id} [delete]
-func (s *SessionStore) DeleteSession(sessionID string) error {
-	query := `DELETE FROM sessions WHERE id = $1`
-	_, err := s.DB.Exec(query, sessionID)
+// @Router /sessions/{token} [delete]
+func (s *SessionStore) DeleteSession(token string) error {
+	err := s.repository().DeleteSessionByTokenHash(hashSessionToken(token))
+	if err != nil {
+		log.Printf("Error deleting session by token: %v", err)
+		return err
+	}
+
+	log.Println("Session deleted by token")
+	return nil
+}
+
+// DeleteSessionByID deletes a session by its numeric ID.
+//
+// Deprecated: numeric session IDs are being phased out. Use DeleteSession instead.
+func (s *SessionStore) DeleteSessionByID(sessionID int64) error {
+	err := s.repository().DeleteSessionByID(sessionID)
+	if err != nil {
+		log.Printf("Error deleting session %d: %v", sessionID, err)
+		return err
+	}
+
+	log.Printf("Session deleted: %d", sessionID)
+	return nil
+}
+
+// DeleteAccountSessions deletes every session of the account, such as after its password changes.
+// When keep is not nil, that session is left alone so the player making the change stays logged in.
+func (s *SessionStore) DeleteAccountSessions(accountID int64, keep *Session) error {
+	var err error
+	if keep == nil {
+		err = s.repository().DeleteAccountSessions(accountID)
+	} else {
+		err = s.repository().DeleteOtherAccountSessions(accountID, keep.ID)
+	}
 	if err != nil {
-		log.Printf("Error deleting session %s: %v", sessionID, err)
+		log.Printf("Error deleting the sessions of account %d: %v", accountID, err)
 		return err
 	}
 
-	log.Printf("Session deleted: %s", sessionID)
+	log.Printf("Sessions deleted for account %d", accountID)
 	return nil
 }
 
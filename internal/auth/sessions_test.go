@@ -21,7 +21,7 @@ func TestCreateSession_Success(t *testing.T) {
 
 	accountID := 1
 	mock.ExpectExec("INSERT INTO sessions").
-		WithArgs(sqlmock.AnyArg(), accountID, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), accountID, sqlmock.AnyArg(), sqlmock.AnyArg()).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	session, err := store.CreateSession(accountID)
@@ -33,6 +33,10 @@ func TestCreateSession_Success(t *testing.T) {
 		t.Errorf("expected account ID %d, got %d", accountID, session.AccountID)
 	}
 
+	if session.Token == "" || session.TokenHash.String != hashSessionToken(session.Token) {
+		t.Errorf("expected the session token and its hash to be set, got %v", session)
+	}
+
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("there were unfulfilled expectations: %s", err)
 	}
@@ -50,7 +54,7 @@ func TestCreateSession_Error(t *testing.T) {
 
 	accountID := 1
 	mock.ExpectExec("INSERT INTO sessions").
-		WithArgs(sqlmock.AnyArg(), accountID, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), accountID, sqlmock.AnyArg(), sqlmock.AnyArg()).
 		WillReturnError(sql.ErrConnDone)
 
 	_, err = store.CreateSession(accountID)
@@ -83,6 +87,9 @@ func TestGetSession_Success(t *testing.T) {
 	mock.ExpectQuery("SELECT \\* FROM sessions WHERE id = \\$1").
 		WithArgs(sessionID).
 		WillReturnRows(rows)
+	mock.ExpectExec("UPDATE sessions SET expires_at = \\$1 WHERE id = \\$2").
+		WithArgs(sqlmock.AnyArg(), sessionID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	session, err := store.GetSession(sessionID)
 	if err != nil {
@@ -93,6 +100,83 @@ func TestGetSession_Success(t *testing.T) {
 		t.Errorf("expected session ID %d, got %d", sessionID, session.ID)
 	}
 
+	if !session.ExpiresAt.After(expiresAt) {
+		t.Errorf("expected session expiry to be extended past %v, got %v", expiresAt, session.ExpiresAt)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetSession_RefreshCappedAtMaxLifetime(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	store := NewSessionStore(sqlxDB)
+	store.MaxLifetime = 24 * time.Hour
+
+	var sessionID int64 = 12345678
+	createdAt := time.Now().Add(-20 * time.Hour)
+	expiresAt := time.Now().Add(1 * time.Hour)
+	maxExpiresAt := createdAt.Add(store.MaxLifetime)
+
+	rows := sqlmock.NewRows([]string{"id", "account_id", "created_at", "expires_at"}).
+		AddRow(sessionID, 1, createdAt, expiresAt)
+	mock.ExpectQuery("SELECT \\* FROM sessions WHERE id = \\$1").
+		WithArgs(sessionID).
+		WillReturnRows(rows)
+	mock.ExpectExec("UPDATE sessions SET expires_at = \\$1 WHERE id = \\$2").
+		WithArgs(maxExpiresAt, sessionID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	session, err := store.GetSession(sessionID)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if !session.ExpiresAt.Equal(maxExpiresAt) {
+		t.Errorf("expected session expiry %v, got %v", maxExpiresAt, session.ExpiresAt)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetSession_ExpiredIsNotRefreshed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	store := NewSessionStore(sqlxDB)
+
+	var sessionID int64 = 12345678
+	createdAt := time.Now().Add(-13 * time.Hour)
+	expiresAt := time.Now().Add(-1 * time.Hour)
+
+	rows := sqlmock.NewRows([]string{"id", "account_id", "created_at", "expires_at"}).
+		AddRow(sessionID, 1, createdAt, expiresAt)
+	mock.ExpectQuery("SELECT \\* FROM sessions WHERE id = \\$1").
+		WithArgs(sessionID).
+		WillReturnRows(rows)
+
+	session, err := store.GetSession(sessionID)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if !session.IsExpired() {
+		t.Errorf("expected session to still be expired")
+	}
+
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("there were unfulfilled expectations: %s", err)
 	}
@@ -137,12 +221,12 @@ func TestDeleteSession_Success(t *testing.T) {
 	sqlxDB := sqlx.NewDb(db, "sqlmock")
 	store := NewSessionStore(sqlxDB)
 
-	sessionID := "test-session-id"
-	mock.ExpectExec("DELETE FROM sessions WHERE id = \\$1").
-		WithArgs(sessionID).
+	token := "test-session-token"
+	mock.ExpectExec("DELETE FROM sessions WHERE token_hash = \\$1").
+		WithArgs(hashSessionToken(token)).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err = store.DeleteSession(sessionID)
+	err = store.DeleteSession(token)
 	if err != nil {
 		t.Errorf("expected no error, got %v", err)
 	}
@@ -162,12 +246,12 @@ func TestDeleteSession_Error(t *testing.T) {
 	sqlxDB := sqlx.NewDb(db, "sqlmock")
 	store := NewSessionStore(sqlxDB)
 
-	sessionID := "test-session-id"
-	mock.ExpectExec("DELETE FROM sessions WHERE id = \\$1").
-		WithArgs(sessionID).
+	token := "test-session-token"
+	mock.ExpectExec("DELETE FROM sessions WHERE token_hash = \\$1").
+		WithArgs(hashSessionToken(token)).
 		WillReturnError(sql.ErrConnDone)
 
-	err = store.DeleteSession(sessionID)
+	err = store.DeleteSession(token)
 	if err == nil {
 		t.Errorf("expected error, got nil")
 	}
@@ -177,6 +261,31 @@ func TestDeleteSession_Error(t *testing.T) {
 	}
 }
 
+func TestDeleteSessionByID_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	store := NewSessionStore(sqlxDB)
+
+	var sessionID int64 = 12345678
+	mock.ExpectExec("DELETE FROM sessions WHERE id = \\$1").
+		WithArgs(sessionID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = store.DeleteSessionByID(sessionID)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
 func TestIsExpired(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -0,0 +1,149 @@
+// Package config holds the server's settings. They are read from, in increasing precedence, their
+// defaults, a YAML file, environment variables and command-line flags.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/ratelimit"
+)
+
+// Where accounts, sessions, lobbies and games can be kept.
+const (
+	STORAGE_POSTGRES = "postgres"
+	STORAGE_MEMORY   = "memory"
+)
+
+// Config holds every setting of the server. Each field is read from the YAML file under its yaml tag and
+// from the environment variable in its env tag, and can be set with a flag named after its YAML path,
+// such as --session-max-lifetime. Fields tagged secret are redacted when the configuration is printed, and
+// fields tagged fileonly, which do not fit in a single value, can only be set in the YAML file.
+type Config struct {
+	// Port is the port the server listens on.
+	Port int `yaml:"port" env:"PORT"`
+	// Storage is where to keep accounts, sessions, lobbies and games: postgres or memory.
+	Storage string `yaml:"storage" env:"STORAGE"`
+	// DatabaseURL is the Postgres connection string, which is only needed with postgres storage.
+	DatabaseURL string `yaml:"database_url" env:"SUPABASE_DB_URL" secret:"true"`
+
+	Session   SessionConfig   `yaml:"session"`
+	Argon2    Argon2Config    `yaml:"argon2"`
+	Passwords PasswordsConfig `yaml:"passwords"`
+	Mail      MailConfig      `yaml:"mail"`
+
+	// RateLimits changes the limits of the rate limit policies, adds policies and gives routes other ones
+	// than they have in code.
+	RateLimits ratelimit.Config `yaml:"rate_limits" fileonly:"true"`
+
+	// AllowedOrigins lists the origins, such as https://play.example.com, which browsers may open lobby
+	// event WebSockets from besides the server's own.
+	AllowedOrigins []string `yaml:"allowed_origins" env:"ALLOWED_ORIGINS"`
+	// ClientIPHeader names the header a proxy puts the client's address in, such as Fly-Client-IP. It must
+	// only be set when the proxy overwrites the header, or clients can pick their own address.
+	ClientIPHeader string `yaml:"client_ip_header" env:"CLIENT_IP_HEADER"`
+	// AdminToken enables the /admin routes, which take it as a bearer token.
+	AdminToken string `yaml:"admin_token" env:"ADMIN_TOKEN" secret:"true"`
+	// RequireVerifiedEmail makes accounts verify their email before they can create lobbies or games.
+	RequireVerifiedEmail bool `yaml:"require_verified_email" env:"REQUIRE_VERIFIED_EMAIL"`
+}
+
+// SessionConfig holds how long sessions last.
+type SessionConfig struct {
+	// IdleTimeout is how long a session stays valid after it was last used.
+	IdleTimeout time.Duration `yaml:"idle_timeout" env:"SESSION_IDLE_TIMEOUT"`
+	// MaxLifetime is how long a session can be kept alive by using it.
+	MaxLifetime time.Duration `yaml:"max_lifetime" env:"SESSION_MAX_LIFETIME"`
+	// LegacyIDCutoff is when numeric session IDs stop being accepted in place of bearer tokens.
+	LegacyIDCutoff time.Time `yaml:"legacy_id_cutoff" env:"SESSION_LEGACY_ID_CUTOFF"`
+}
+
+// Argon2Config holds the parameters passwords are hashed with. Passwords hashed with other parameters
+// are rehashed the next time they are used to log in.
+type Argon2Config struct {
+	Time    uint32 `yaml:"time" env:"ARGON2_TIME"`
+	Memory  uint32 `yaml:"memory" env:"ARGON2_MEMORY"`
+	Threads uint8  `yaml:"threads" env:"ARGON2_THREADS"`
+}
+
+// PasswordsConfig holds the policy every password set on the server is checked against.
+type PasswordsConfig struct {
+	MinLength uint16 `yaml:"min_length" env:"PASSWORD_MIN_LENGTH"`
+	MaxLength uint16 `yaml:"max_length" env:"PASSWORD_MAX_LENGTH"`
+	// DenylistFile names a file of breached passwords, one per line, which are refused.
+	DenylistFile string `yaml:"denylist_file" env:"PASSWORD_DENYLIST_FILE"`
+}
+
+// MailConfig holds how emails are sent. They are sent through SMTPAddr when it is set, and otherwise
+// written to the log, unless File names a file to collect them in.
+type MailConfig struct {
+	SMTPAddr     string `yaml:"smtp_addr" env:"SMTP_ADDR"`
+	SMTPUsername string `yaml:"smtp_username" env:"SMTP_USERNAME"`
+	SMTPPassword string `yaml:"smtp_password" env:"SMTP_PASSWORD" secret:"true"`
+	From         string `yaml:"from" env:"MAIL_FROM"`
+	File         string `yaml:"file" env:"MAIL_FILE"`
+}
+
+// Default returns the configuration used for settings which are not set anywhere.
+func Default() Config {
+	return Config{
+		Port:    9000,
+		Storage: STORAGE_POSTGRES,
+		Session: SessionConfig{
+			IdleTimeout:    auth.DefaultSessionIdleTimeout,
+			MaxLifetime:    auth.DefaultSessionMaxLifetime,
+			LegacyIDCutoff: auth.DefaultLegacyIDCutoff,
+		},
+		Argon2: Argon2Config{
+			Time:    auth.DefaultArgon2Time,
+			Memory:  auth.DefaultArgon2Memory,
+			Threads: auth.DefaultArgon2Threads,
+		},
+		Passwords: PasswordsConfig{
+			MinLength: auth.DefaultMinPasswordLength,
+			MaxLength: auth.DefaultMaxPasswordLength,
+		},
+		RateLimits: ratelimit.DefaultConfig(),
+	}
+}
+
+// Validate checks that the server can start with the configuration. It returns every problem it finds.
+func (c *Config) Validate() error {
+	var problems []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Port > 0 && c.Port <= 65535, "port must be between 1 and 65535")
+	check(c.Storage == STORAGE_POSTGRES || c.Storage == STORAGE_MEMORY, "storage must be %s or %s, not %q", STORAGE_POSTGRES, STORAGE_MEMORY, c.Storage)
+	check(c.Storage != STORAGE_POSTGRES || c.DatabaseURL != "", "database_url (SUPABASE_DB_URL) must be set when storage is %s; set it or run with --storage=%s", STORAGE_POSTGRES, STORAGE_MEMORY)
+
+	check(c.Session.IdleTimeout > 0, "session.idle_timeout must be positive")
+	check(c.Session.MaxLifetime > 0, "session.max_lifetime must be positive")
+
+	check(c.Argon2.Time > 0, "argon2.time must be positive")
+	check(c.Argon2.Memory > 0, "argon2.memory must be positive")
+	check(c.Argon2.Threads > 0, "argon2.threads must be positive")
+
+	check(c.Passwords.MinLength > 0, "passwords.min_length must be positive")
+	check(c.Passwords.MinLength <= c.Passwords.MaxLength, "passwords.min_length (%d) must not be greater than passwords.max_length (%d)", c.Passwords.MinLength, c.Passwords.MaxLength)
+
+	check(c.Mail.SMTPAddr == "" || c.Mail.From != "", "mail.from (MAIL_FROM) must be set when mail.smtp_addr is set")
+
+	if err := c.RateLimits.Validate(); err != nil {
+		problems = append(problems, fmt.Errorf("rate_limits: %v", err))
+	}
+
+	for _, origin := range c.AllowedOrigins {
+		parsed, err := url.Parse(origin)
+		check(err == nil && parsed.Scheme != "" && parsed.Host != "" && strings.Trim(parsed.Path, "/") == "", "allowed_origins must hold origins such as https://play.example.com, not %q", origin)
+	}
+
+	return errors.Join(problems...)
+}
@@ -0,0 +1,253 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/justinfarrelldev/open-ctp-server/internal/ratelimit"
+	"gopkg.in/yaml.v2"
+)
+
+const redacted = "REDACTED"
+
+// setting is one field of a Config, with where it can be set from.
+type setting struct {
+	// path is the field's YAML path, such as "session.max_lifetime".
+	path   string
+	env    string
+	secret bool
+	value  reflect.Value
+}
+
+// flagName returns the name of the flag setting s, such as "session-max-lifetime".
+func (s setting) flagName() string {
+	return strings.NewReplacer(".", "-", "_", "-").Replace(s.path)
+}
+
+// settings lists the fields of the Config c points to which can be set from the environment and flags,
+// nested structs flattened.
+func settings(c *Config) []setting {
+	var all []setting
+	var walk func(value reflect.Value, prefix string)
+	walk = func(value reflect.Value, prefix string) {
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			path := prefix + field.Tag.Get("yaml")
+
+			if field.Tag.Get("fileonly") == "true" {
+				continue
+			}
+
+			if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Time{}) {
+				walk(value.Field(i), path+".")
+				continue
+			}
+
+			all = append(all, setting{
+				path:   path,
+				env:    field.Tag.Get("env"),
+				secret: field.Tag.Get("secret") == "true",
+				value:  value.Field(i),
+			})
+		}
+	}
+	walk(reflect.ValueOf(c).Elem(), "")
+	return all
+}
+
+// parse sets value, a field of a Config, from its text form. Durations are written like "12h", times in
+// RFC 3339, such as "2027-04-01T00:00:00Z", and lists separated by commas.
+func parse(value reflect.Value, text string) error {
+	switch value.Interface().(type) {
+	case time.Duration:
+		parsed, err := time.ParseDuration(text)
+		if err != nil {
+			return errors.New("must be a duration such as 12h")
+		}
+		value.SetInt(int64(parsed))
+		return nil
+	case time.Time:
+		parsed, err := time.Parse(time.RFC3339, text)
+		if err != nil {
+			return errors.New("must be an RFC 3339 timestamp such as 2027-04-01T00:00:00Z")
+		}
+		value.Set(reflect.ValueOf(parsed))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(text)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(text)
+		if err != nil {
+			return errors.New("must be true or false")
+		}
+		value.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(text, 10, value.Type().Bits())
+		if err != nil {
+			return errors.New("must be a whole number")
+		}
+		value.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(text, 10, value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be a whole number below %d", uint64(1)<<value.Type().Bits())
+		}
+		value.SetUint(parsed)
+	case reflect.Slice:
+		if value.Type().Elem().Kind() != reflect.String {
+			panic(fmt.Sprintf("config: settings of type %s cannot be parsed", value.Type()))
+		}
+		var items []string
+		for _, item := range strings.Split(text, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value.Set(reflect.ValueOf(items))
+	default:
+		panic(fmt.Sprintf("config: settings of type %s cannot be parsed", value.Type()))
+	}
+	return nil
+}
+
+// Flags holds the settings given on the command line, which override every other source.
+type Flags struct {
+	values map[string]string
+}
+
+// flagValue is the flag of one setting. Its value is checked when it is given and applied by Load.
+type flagValue struct {
+	flags   *Flags
+	setting setting
+}
+
+func (f *flagValue) String() string {
+	if f.flags == nil {
+		return ""
+	}
+	return f.flags.values[f.setting.path]
+}
+
+func (f *flagValue) Set(text string) error {
+	if err := parse(reflect.New(f.setting.value.Type()).Elem(), text); err != nil {
+		return err
+	}
+	f.flags.values[f.setting.path] = text
+	return nil
+}
+
+// IsBoolFlag lets boolean settings be given as --require-verified-email, without a value.
+func (f *flagValue) IsBoolFlag() bool {
+	return f.setting.value.Kind() == reflect.Bool
+}
+
+// RegisterFlags defines a flag on fs for every setting, such as --port and --session-max-lifetime.
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	flags := &Flags{values: map[string]string{}}
+
+	defaults := Default()
+	for _, s := range settings(&defaults) {
+		usage := "sets " + s.path
+		if s.env != "" {
+			usage += ", like " + s.env
+		}
+		if !s.secret && !s.value.IsZero() {
+			usage += fmt.Sprintf(" (default %s)", format(s.value))
+		}
+		fs.Var(&flagValue{flags: flags, setting: s}, s.flagName(), usage)
+	}
+	return flags
+}
+
+// Load builds the configuration from, in increasing precedence, the defaults, the YAML file at path,
+// the environment and flags, which may be nil. When path is empty, CONFIG_FILE names the file, if any.
+// A .env file in the working directory is read into the environment first, without replacing variables
+// which are already set. The configuration is not validated.
+func Load(path string, flags *Flags) (Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return Config{}, fmt.Errorf("error loading .env: %v", err)
+	}
+
+	config := Default()
+
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path != "" {
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return Config{}, err
+		}
+		// Strict decoding refuses map keys which are already set, so the file's rate limit policies are read
+		// on their own and the built-in ones it does not replace are added back after
+		config.RateLimits = ratelimit.Config{}
+		if err := yaml.UnmarshalStrict(contents, &config); err != nil {
+			return Config{}, fmt.Errorf("error reading %s: %v", path, err)
+		}
+		config.RateLimits = config.RateLimits.WithDefaults()
+	}
+
+	for _, s := range settings(&config) {
+		if s.env == "" {
+			continue
+		}
+		if text, ok := os.LookupEnv(s.env); ok && text != "" {
+			if err := parse(s.value, text); err != nil {
+				return Config{}, fmt.Errorf("%s %v: %q", s.env, err, text)
+			}
+		}
+	}
+
+	if flags != nil {
+		for _, s := range settings(&config) {
+			if text, ok := flags.values[s.path]; ok {
+				if err := parse(s.value, text); err != nil {
+					return Config{}, fmt.Errorf("--%s %v: %q", s.flagName(), err, text)
+				}
+			}
+		}
+	}
+
+	return config, nil
+}
+
+// Print writes the configuration to w as YAML, which Load can read back. Secrets which are set are
+// replaced with REDACTED.
+func (c Config) Print(w io.Writer) error {
+	for _, s := range settings(&c) {
+		if s.secret && !s.value.IsZero() {
+			s.value.SetString(redacted)
+		}
+	}
+
+	out, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// format returns the text form of a setting, which parse reads back.
+func format(value reflect.Value) string {
+	switch v := value.Interface().(type) {
+	case time.Duration:
+		return v.String()
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case []string:
+		return strings.Join(v, ",")
+	}
+	return fmt.Sprint(value.Interface())
+}
@@ -1,83 +1,97 @@
 package game
 
 import (
-	"encoding/json"
 	"errors"
-	"fmt"
+	"log"
 	"net/http"
 
-	"github.com/jmoiron/sqlx"
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
 )
 
 // CreateGameArgs represents the expected structure of the request body for creating a game.
 //
 // @Description Structure for the game creation request payload.
 type CreateGameArgs struct {
+	// Name is the name of the game shown in the launcher.
+	Name string `json:"name" validate:"required"`
+	// Ruleset is the version of the rules the game is played with ("ctp2" or "ctp1"). Defaults to "ctp2".
+	Ruleset Ruleset `json:"ruleset,omitempty" validate:"oneof=ctp2 ctp1"`
+	// MapSize is the size of the map ("small", "medium", "large" or "gigantic"). Defaults to "medium".
+	MapSize MapSize `json:"map_size,omitempty" validate:"oneof=small medium large gigantic"`
+	// MaxPlayers is the maximum number of players, between 2 and 8. Defaults to 8.
+	MaxPlayers int `json:"max_players,omitempty" validate:"min=2,max=8"`
 	// PasswordProtected indicates whether the game is password-protected.
 	// If true, a password must be provided.
 	PasswordProtected bool `json:"password_protected"`
 	// Password is the password for the game.
 	// This field is required if PasswordProtected is true.
-	// It must be longer than 6 characters.
-	Password string `json:"password"`
+	// It must satisfy the server's password policy.
+	Password string `json:"password" validate:"required_with=PasswordProtected,excluded_without=PasswordProtected,password"`
 }
 
-const ERROR_PASSWORD_TOO_SHORT = "password must be longer than 6 characters"
-const ERROR_PASSWORD_REQUIRED_BUT_NO_PASSWORD = "password is required when password_protected is true"
-
-// CreateGame handles the creation of a new game.
+// CreateGame handles the creation of a new game hosted by the caller.
 //
 // @Summary Create a new game
-// @Description This endpoint creates a new multiplayer game, optionally protected by a password.
+// @Description This endpoint creates a new multiplayer game hosted by the caller, optionally protected by a password.
 // @Tags game
 // @Accept json
 // @Produce json
+// @Param Authorization header string true "Bearer session token"
 // @Param body body CreateGameArgs true "Game creation request body"
-// @Success 201 {string} string "Game successfully created"
-// @Failure 400 {object} error "Bad Request"
-// @Failure 500 {object} error "Internal Server Error"
+// @Success 201 {object} game.Game "Game successfully created"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
 // @Router /game/create_game [post]
-func CreateGame(w http.ResponseWriter, r *http.Request, db *sqlx.DB) error {
+// @Router /v2/games [post]
+func CreateGame(w http.ResponseWriter, r *http.Request, games GameRepository, store *auth.SessionStore) error {
 
 	if r.Method != "POST" {
-		w.WriteHeader(http.StatusBadRequest)
-
-		return errors.New("invalid request; request must be a POST request")
+		return httpapi.MethodNotAllowed(http.MethodPost)
 	}
 
-	decoder := json.NewDecoder(r.Body)
-	decoder.DisallowUnknownFields()
-
 	game := CreateGameArgs{}
-	err := decoder.Decode(&game)
-
+	err := httpapi.DecodeJSON(r, &game)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-
-		return errors.New("an error occurred while decoding the request body:" + err.Error())
+		return err
 	}
 
-	if game.PasswordProtected && game.Password == "" {
-		w.WriteHeader(http.StatusBadRequest)
-
-		return errors.New(ERROR_PASSWORD_REQUIRED_BUT_NO_PASSWORD)
+	if err := httpapi.Validate(&game); err != nil {
+		return err
 	}
 
-	if !game.PasswordProtected && len(game.Password) > 0 {
-		w.WriteHeader(http.StatusBadRequest)
-
-		return errors.New("password was provided despite password_protected being set to false")
+	if game.Ruleset == "" {
+		game.Ruleset = RulesetCTP2
+	}
+	if game.MapSize == "" {
+		game.MapSize = MapSizeMedium
+	}
+	if game.MaxPlayers == 0 {
+		game.MaxPlayers = MaxPlayers
 	}
 
-	if len(game.Password) < 6 {
-		w.WriteHeader(http.StatusBadRequest)
+	session, err := store.Authenticate(r, nil)
+	if err != nil {
+		return err
+	}
 
-		return errors.New(ERROR_PASSWORD_TOO_SHORT)
+	var passwordHash *string
+	if game.PasswordProtected {
+		hash, err := auth.HashPassword(game.Password)
+		if err != nil {
+			log.Println("error hashing a game password: ", err.Error())
+			return errors.New("an error occurred while saving the password. Please try again later")
+		}
+		passwordHash = &hash
 	}
 
-	// TODO salt & hash password here / handle it in Supabase or something then actually store the game somewhere
+	created, err := games.CreateGame(&game, int64(session.AccountID), passwordHash)
+	if err != nil {
+		return errors.New("an error occurred while storing the game in the database: " + err.Error())
+	}
 
-	w.WriteHeader(http.StatusCreated)
-	fmt.Println("Successfully created game!")
+	httpapi.WriteJSON(w, http.StatusCreated, created)
 	return nil
 }
@@ -6,14 +6,19 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi/httpapitest"
 )
 
 func TestCreateGame_PasswordTooShort(t *testing.T) {
 	// Create a test request with a password that is less than 6 characters
 	body := CreateGameArgs{
+		Name:              "Test Game",
 		PasswordProtected: true,
 		Password:          "123", // This password is less than 6 characters
 	}
@@ -27,13 +32,13 @@ func TestCreateGame_PasswordTooShort(t *testing.T) {
 	rr := httptest.NewRecorder()
 
 	// DB is not needed for this test
-	var mockDB *sqlx.DB = nil
+	var mockDB GameRepository = nil
 
 	// Call the function to test
-	err = CreateGame(rr, req, mockDB)
+	err = CreateGame(rr, req, mockDB, nil)
 
 	// Check if the error is what we expect
-	expectedError := ERROR_PASSWORD_TOO_SHORT
+	expectedError := "password must be at least 6 characters"
 	if err == nil || err.Error() != expectedError {
 		t.Errorf("CreateGame() error = %v, wantErr %v", err, expectedError)
 	}
@@ -42,6 +47,7 @@ func TestCreateGame_PasswordTooShort(t *testing.T) {
 func TestCreateGame_PasswordRequiredWhenPasswordProtectedIsTrue(t *testing.T) {
 	// Create a test request with password protected set to true but no password
 	body := CreateGameArgs{
+		Name:              "Test Game",
 		PasswordProtected: true,
 	}
 	jsonBody, _ := json.Marshal(body)
@@ -54,13 +60,13 @@ func TestCreateGame_PasswordRequiredWhenPasswordProtectedIsTrue(t *testing.T) {
 	rr := httptest.NewRecorder()
 
 	// DB is not needed for this test
-	var mockDB *sqlx.DB = nil
+	var mockDB GameRepository = nil
 
 	// Call the function to test
-	err = CreateGame(rr, req, mockDB)
+	err = CreateGame(rr, req, mockDB, nil)
 
 	// Check if the error is what we expect
-	expectedError := ERROR_PASSWORD_REQUIRED_BUT_NO_PASSWORD
+	expectedError := "password must be specified when password_protected is set"
 	if err == nil || err.Error() != expectedError {
 		t.Errorf("CreateGame() error = %v, wantErr %v", err, expectedError)
 	}
@@ -74,26 +80,26 @@ func TestCreateGame_Success(t *testing.T) {
 	defer db.Close()
 
 	game := CreateGameArgs{
+		Name:              "Test Game",
 		PasswordProtected: true,
 		Password:          "password123",
 	}
 
-	mock.ExpectQuery("INSERT INTO game \\(password_protected, password\\) VALUES \\(\\$1, \\$2\\)").
-		WithArgs(game.PasswordProtected, game.Password).
-		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	expectInsertGame(mock, game.Name, 1)
 
 	jsonBody, _ := json.Marshal(game)
 	req, err := http.NewRequest("POST", "/game/create_game", bytes.NewBuffer(jsonBody))
 	if err != nil {
 		t.Fatal(err)
 	}
+	req = withSession(req, 1)
 
 	rr := httptest.NewRecorder()
 	sqlxDB := sqlx.NewDb(db, "sqlmock")
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := CreateGame(w, r, sqlxDB)
+		err := CreateGame(w, r, NewPostgresGameRepository(sqlxDB), &auth.SessionStore{DB: sqlxDB})
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
@@ -111,24 +117,24 @@ func TestGameHandler_Success(t *testing.T) {
 	defer db.Close()
 
 	game := CreateGameArgs{
+		Name:              "Test Game",
 		PasswordProtected: true,
 		Password:          "password123",
 	}
 
-	mock.ExpectQuery("INSERT INTO game \\(password_protected, password\\) VALUES \\(\\$1, \\$2\\)").
-		WithArgs(game.PasswordProtected, game.Password).
-		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	expectInsertGame(mock, game.Name, 1)
 
 	jsonBody, _ := json.Marshal(game)
 	req, err := http.NewRequest("POST", "/game/create_game", bytes.NewBuffer(jsonBody))
 	if err != nil {
 		t.Fatal(err)
 	}
+	req = withSession(req, 1)
 
 	rr := httptest.NewRecorder()
 	sqlxDB := sqlx.NewDb(db, "sqlmock")
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		GameHandler(w, r, sqlxDB)
+		GameHandler(w, r, NewPostgresGameRepository(sqlxDB), &auth.SessionStore{DB: sqlxDB})
 	})
 
 	handler.ServeHTTP(rr, req)
@@ -159,7 +165,7 @@ func TestGameHandler_Failure(t *testing.T) {
 	rr := httptest.NewRecorder()
 	sqlxDB := sqlx.NewDb(db, "sqlmock")
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		GameHandler(w, r, sqlxDB)
+		GameHandler(w, r, NewPostgresGameRepository(sqlxDB), &auth.SessionStore{DB: sqlxDB})
 	})
 
 	handler.ServeHTTP(rr, req)
@@ -180,10 +186,10 @@ func TestCreateGame_InvalidMethod(t *testing.T) {
 	rr := httptest.NewRecorder()
 
 	// DB is not needed for this test
-	var mockDB *sqlx.DB = nil
+	var mockDB GameRepository = nil
 
 	// Call the function to test
-	err = CreateGame(rr, req, mockDB)
+	err = CreateGame(rr, req, mockDB, nil)
 
 	// Check if the error is what we expect
 	expectedError := "invalid request; request must be a POST request"
@@ -191,8 +197,8 @@ func TestCreateGame_InvalidMethod(t *testing.T) {
 		t.Errorf("CreateGame() error = %v, wantErr %v", err, expectedError)
 	}
 
-	if status := rr.Code; status != http.StatusBadRequest {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+	if status := httpapitest.Status(err); status != http.StatusMethodNotAllowed {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusMethodNotAllowed)
 	}
 }
 
@@ -208,18 +214,100 @@ func TestCreateGame_DecodeError(t *testing.T) {
 	rr := httptest.NewRecorder()
 
 	// DB is not needed for this test
-	var mockDB *sqlx.DB = nil
+	var mockDB GameRepository = nil
 
 	// Call the function to test
-	err = CreateGame(rr, req, mockDB)
+	err = CreateGame(rr, req, mockDB, nil)
 
 	// Check if the error is what we expect
-	expectedError := "an error occurred while decoding the request body:json: cannot unmarshal number into Go struct field CreateGameArgs.password of type string"
+	expectedError := "an error occurred while decoding the request body: json: cannot unmarshal number into Go struct field CreateGameArgs.password of type string"
 	if err == nil || err.Error() != expectedError {
 		t.Errorf("CreateGame() error = %v, wantErr %v", err, expectedError)
 	}
 
-	if status := rr.Code; status != http.StatusInternalServerError {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+	if status := httpapitest.Status(err); status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+	}
+}
+
+func TestCreateGame_WithoutPassword(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("INSERT INTO game").
+		WithArgs("Open Game", int64(1), RulesetCTP2, MapSizeMedium, MaxPlayers, GameStatusOpen, false, nil).
+		WillReturnRows(sqlmock.NewRows(gameRowColumns).
+			AddRow(1, time.Now(), "Open Game", 1, "ctp2", "medium", 8, "open", false))
+
+	req, err := http.NewRequest("POST", "/game/create_game", bytes.NewBufferString(`{"name": "Open Game"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = withSession(req, 1)
+
+	rr := httptest.NewRecorder()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	err = CreateGame(rr, req, NewPostgresGameRepository(sqlxDB), &auth.SessionStore{DB: sqlxDB})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if status := rr.Code; status != http.StatusCreated {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusCreated)
+	}
+
+	var created Game
+	if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil {
+		t.Fatalf("could not decode the response body: %v", err)
+	}
+
+	if created.ID != 1 || created.HostAccountId != 1 || created.Status != GameStatusOpen {
+		t.Errorf("unexpected game returned: %+v", created)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCreateGame_InvalidMaxPlayers(t *testing.T) {
+	req, err := http.NewRequest("POST", "/game/create_game", bytes.NewBufferString(`{"name": "Big Game", "max_players": 9}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	err = CreateGame(rr, req, nil, nil)
+
+	if err == nil || err.Error() != "max_players must be between 2 and 8" {
+		t.Errorf("CreateGame() error = %v, wantErr %v", err, "max_players must be between 2 and 8")
+	}
+
+	if status := httpapitest.Status(err); status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+	}
+}
+
+func TestCreateGame_MissingSession(t *testing.T) {
+	req, err := http.NewRequest("POST", "/game/create_game", bytes.NewBufferString(`{"name": "Test Game"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	err = CreateGame(rr, req, nil, &auth.SessionStore{})
+
+	if err != auth.ErrSessionRequired {
+		t.Errorf("CreateGame() error = %v, wantErr %v", err, auth.ErrSessionRequired)
+	}
+
+	if status := httpapitest.Status(err); status != http.StatusUnauthorized {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusUnauthorized)
 	}
 }
@@ -0,0 +1,107 @@
+package game
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+)
+
+// DeleteGameArgs represents the expected structure of the request body for deleting a game.
+//
+// @Description Structure for the game deletion request payload.
+type DeleteGameArgs struct {
+	// The game ID for the game that will be deleted.
+	GameId int64 `json:"game_id" validate:"required"`
+}
+
+// DeleteGame deletes a game by the game ID. Only the host of the game can delete it.
+//
+// @Summary Deletes a game
+// @Description This endpoint deletes a hosted multiplayer game.
+// @Tags game
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Param body body DeleteGameArgs true "game deletion request body"
+// @Success 200 {object} httpapi.MessageResponse "Successfully deleted game!"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 404 {object} httpapi.ErrorResponse "Not Found"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /game/delete_game [delete]
+func DeleteGame(w http.ResponseWriter, r *http.Request, games GameRepository, store *auth.SessionStore) error {
+
+	if r.Method != http.MethodDelete {
+		return httpapi.MethodNotAllowed(http.MethodDelete)
+	}
+
+	args := DeleteGameArgs{}
+	err := httpapi.DecodeJSON(r, &args)
+	if err != nil {
+		return err
+	}
+
+	return deleteGame(w, r, args, games, store)
+}
+
+// DeleteGameV2 deletes the game with the ID in the path.
+//
+// @Summary Deletes a game
+// @Description This endpoint deletes a hosted multiplayer game.
+// @Tags game
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Param id path int true "game ID"
+// @Success 200 {object} httpapi.MessageResponse "Successfully deleted game!"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 404 {object} httpapi.ErrorResponse "Not Found"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /v2/games/{id} [delete]
+func DeleteGameV2(w http.ResponseWriter, r *http.Request, games GameRepository, store *auth.SessionStore) error {
+	gameId, err := httpapi.PathID(r, "id")
+	if err != nil {
+		return err
+	}
+
+	return deleteGame(w, r, DeleteGameArgs{GameId: gameId}, games, store)
+}
+
+// deleteGame deletes the game in args. Only its host can delete it.
+func deleteGame(w http.ResponseWriter, r *http.Request, args DeleteGameArgs, games GameRepository, store *auth.SessionStore) error {
+	if err := httpapi.Validate(&args); err != nil {
+		return err
+	}
+
+	session, err := store.Authenticate(r, nil)
+	if err != nil {
+		return err
+	}
+
+	hostAccountID, err := games.HostAccountID(args.GameId)
+	if err == ErrGameNotFound {
+		return httpapi.NotFound(fmt.Sprintf("no game exists with the ID %d", args.GameId))
+	}
+	if err != nil {
+		return fmt.Errorf("an error occurred while retrieving the game host: %v", err)
+	}
+
+	if err := auth.RequireAccount(session, hostAccountID); err != nil {
+		return err
+	}
+
+	err = games.DeleteGame(args.GameId)
+	if err == ErrGameNotFound {
+		return httpapi.NotFound(fmt.Sprintf("no game exists with the ID %d", args.GameId))
+	}
+	if err != nil {
+		return fmt.Errorf("an error occurred while deleting the game with the ID %d: %v", args.GameId, err)
+	}
+
+	httpapi.WriteMessage(w, http.StatusOK, "Successfully deleted game!")
+	return nil
+}
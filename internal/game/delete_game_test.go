@@ -0,0 +1,115 @@
+package game
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi/httpapitest"
+)
+
+const gameHostQuery = "SELECT host_account_id FROM game WHERE id = $1"
+
+func TestDeleteGame_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(gameHostQuery)).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"host_account_id"}).AddRow(1))
+
+	mock.ExpectExec("DELETE FROM game WHERE id = \\$1").
+		WithArgs(int64(1)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	req, err := http.NewRequest("DELETE", "/game/delete_game", strings.NewReader(`{"game_id": 1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = withSession(req, 1)
+
+	rr := httptest.NewRecorder()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		DeleteGameHandler(w, r, NewPostgresGameRepository(sqlxDB), &auth.SessionStore{DB: sqlxDB})
+	})
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	expectedResponse := "Successfully deleted game!"
+	if httpapitest.Message(rr) != expectedResponse {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedResponse)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDeleteGame_NotHost(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(gameHostQuery)).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"host_account_id"}).AddRow(2))
+
+	req, err := http.NewRequest("DELETE", "/game/delete_game", strings.NewReader(`{"game_id": 1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = withSession(req, 1)
+
+	rr := httptest.NewRecorder()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	err = DeleteGame(rr, req, NewPostgresGameRepository(sqlxDB), &auth.SessionStore{DB: sqlxDB})
+
+	if err != auth.ErrForbidden {
+		t.Errorf("DeleteGame() error = %v, wantErr %v", err, auth.ErrForbidden)
+	}
+
+	if status := httpapitest.Status(err); status != http.StatusForbidden {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusForbidden)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDeleteGame_GameIDNotSpecified(t *testing.T) {
+	req, err := http.NewRequest("DELETE", "/game/delete_game", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	err = DeleteGame(rr, req, nil, nil)
+
+	expectedError := "game_id must be specified"
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("DeleteGame() error = %v, wantErr %v", err, expectedError)
+	}
+
+	if status := httpapitest.Status(err); status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+	}
+}
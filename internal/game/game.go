@@ -0,0 +1,75 @@
+package game
+
+import "time"
+
+// GameStatus is the lifecycle state of a hosted game.
+type GameStatus string
+
+const (
+	// GameStatusOpen is a game which is waiting for players to join.
+	GameStatusOpen GameStatus = "open"
+	// GameStatusInProgress is a game which has been started by the host.
+	GameStatusInProgress GameStatus = "in_progress"
+	// GameStatusFinished is a game which has ended.
+	GameStatusFinished GameStatus = "finished"
+)
+
+// MapSize is one of the map sizes offered by the game setup screen.
+type MapSize string
+
+const (
+	MapSizeSmall    MapSize = "small"
+	MapSizeMedium   MapSize = "medium"
+	MapSizeLarge    MapSize = "large"
+	MapSizeGigantic MapSize = "gigantic"
+)
+
+// Ruleset is the version of the game rules that a game is played with.
+type Ruleset string
+
+const (
+	RulesetCTP2 Ruleset = "ctp2"
+	RulesetCTP1 Ruleset = "ctp1"
+)
+
+const (
+	// MinPlayers is the smallest number of players a multiplayer game can be set up for.
+	MinPlayers = 2
+	// MaxPlayers is the largest number of players a game can be set up for.
+	MaxPlayers = 8
+)
+
+// Game represents a hosted multiplayer game.
+//
+// @Description Structure for representing a hosted multiplayer game.
+type Game struct {
+	// ID is the unique identifier for the game.
+	ID int64 `json:"id" db:"id"`
+
+	// CreatedAt is when the game was created.
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+
+	// Name is the name of the game.
+	Name string `json:"name" db:"name"`
+
+	// HostAccountId is the account ID of the player hosting the game.
+	HostAccountId int64 `json:"host_account_id" db:"host_account_id"`
+
+	// Ruleset is the version of the rules the game is played with.
+	Ruleset Ruleset `json:"ruleset" db:"ruleset"`
+
+	// MapSize is the size of the generated map.
+	MapSize MapSize `json:"map_size" db:"map_size"`
+
+	// MaxPlayers is the maximum number of players who can join the game.
+	MaxPlayers int `json:"max_players" db:"max_players"`
+
+	// Status is the lifecycle state of the game.
+	Status GameStatus `json:"status" db:"status"`
+
+	// PasswordProtected indicates whether a password is needed to join the game.
+	PasswordProtected bool `json:"password_protected" db:"password_protected"`
+}
+
+// gameColumns are the columns selected when reading a Game. The password hash is never selected.
+const gameColumns = "id, created_at, name, host_account_id, ruleset, map_size, max_players, status, password_protected"
@@ -3,13 +3,48 @@ package game
 import (
 	"net/http"
 
-	"github.com/jmoiron/sqlx"
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
 )
 
-func GameHandler(w http.ResponseWriter, r *http.Request, db *sqlx.DB) {
-	if err := CreateGame(w, r, db); err != nil {
-		// Handle the error, e.g., log it and send an appropriate response to the client
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+func GameHandler(w http.ResponseWriter, r *http.Request, games GameRepository, store *auth.SessionStore) {
+	if err := CreateGame(w, r, games, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func GetGameHandler(w http.ResponseWriter, r *http.Request, games GameRepository, store *auth.SessionStore) {
+	if err := GetGame(w, r, games, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func ListGamesHandler(w http.ResponseWriter, r *http.Request, games GameRepository, store *auth.SessionStore) {
+	if err := ListGames(w, r, games, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func DeleteGameHandler(w http.ResponseWriter, r *http.Request, games GameRepository, store *auth.SessionStore) {
+	if err := DeleteGame(w, r, games, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func DeleteGameV2Handler(w http.ResponseWriter, r *http.Request, games GameRepository, store *auth.SessionStore) {
+	if err := DeleteGameV2(w, r, games, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func GetGameV2Handler(w http.ResponseWriter, r *http.Request, games GameRepository, store *auth.SessionStore) {
+	if err := GetGameV2(w, r, games, store); err != nil {
+		httpapi.WriteError(w, err)
 		return
 	}
 }
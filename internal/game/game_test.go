@@ -0,0 +1,28 @@
+package game
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+)
+
+var gameRowColumns = []string{"id", "created_at", "name", "host_account_id", "ruleset", "map_size", "max_players", "status", "password_protected"}
+
+// withSession returns a copy of req which carries a valid session for the given account, as though it had been through auth.Middleware.
+func withSession(req *http.Request, accountID int) *http.Request {
+	session := &auth.Session{
+		AccountID: accountID,
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	return req.WithContext(auth.ContextWithSession(req.Context(), session))
+}
+
+func expectInsertGame(mock sqlmock.Sqlmock, name string, hostAccountID int64) {
+	mock.ExpectQuery("INSERT INTO game \\(name, host_account_id, ruleset, map_size, max_players, status, password_protected, password_hash\\) VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5, \\$6, \\$7, \\$8\\) RETURNING").
+		WithArgs(name, hostAccountID, RulesetCTP2, MapSizeMedium, MaxPlayers, GameStatusOpen, true, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(gameRowColumns).
+			AddRow(1, time.Now(), name, hostAccountID, "ctp2", "medium", 8, "open", true))
+}
@@ -0,0 +1,76 @@
+package game
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+)
+
+// GetGame gets a game by the game ID.
+//
+// @Summary Gets a game
+// @Description This endpoint gets a hosted multiplayer game's info. The game password is never returned.
+// @Tags game
+// @Produce json
+// @Param game_id query int true "game ID"
+//
+// @Success 200 {object} game.Game "Game successfully retrieved"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 404 {object} httpapi.ErrorResponse "Not Found"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /game/get_game [get]
+func GetGame(w http.ResponseWriter, r *http.Request, games GameRepository, store *auth.SessionStore) error {
+	if r.Method != "GET" {
+		return httpapi.MethodNotAllowed(http.MethodGet)
+	}
+
+	gameIdStr := r.URL.Query().Get("game_id")
+	if gameIdStr == "" {
+		return httpapi.Validation("game_id is required")
+	}
+
+	gameId, err := strconv.ParseInt(gameIdStr, 10, 64)
+	if err != nil {
+		return httpapi.Validation("invalid game_id")
+	}
+
+	return getGame(w, gameId, games)
+}
+
+// GetGameV2 gets the game with the ID in the path.
+//
+// @Summary Gets a game
+// @Description This endpoint gets a hosted multiplayer game's info. The game password is never returned.
+// @Tags game
+// @Produce json
+// @Param id path int true "game ID"
+// @Success 200 {object} game.Game "Game successfully retrieved"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 404 {object} httpapi.ErrorResponse "Not Found"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /v2/games/{id} [get]
+func GetGameV2(w http.ResponseWriter, r *http.Request, games GameRepository, store *auth.SessionStore) error {
+	gameId, err := httpapi.PathID(r, "id")
+	if err != nil {
+		return err
+	}
+
+	return getGame(w, gameId, games)
+}
+
+// getGame writes the game with the given ID.
+func getGame(w http.ResponseWriter, gameId int64, games GameRepository) error {
+	game, err := games.GetGame(gameId)
+	if err == ErrGameNotFound {
+		return httpapi.NotFound(fmt.Sprintf("no game exists with the ID %d", gameId))
+	}
+	if err != nil {
+		return fmt.Errorf("an error occurred while getting the game with the ID %d: %v", gameId, err)
+	}
+
+	httpapi.WriteJSON(w, http.StatusOK, game)
+	return nil
+}
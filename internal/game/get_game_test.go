@@ -0,0 +1,115 @@
+package game
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi/httpapitest"
+)
+
+func TestGetGame_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT " + gameColumns + " FROM game WHERE id = $1")).
+		WithArgs(int64(300)).
+		WillReturnRows(sqlmock.NewRows(gameRowColumns).
+			AddRow(300, time.Now(), "Test Game", 1, "ctp2", "large", 4, "open", true))
+
+	req, err := http.NewRequest("GET", "/game/get_game?game_id=300", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		GetGameHandler(w, r, NewPostgresGameRepository(sqlxDB), nil)
+	})
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var game Game
+	if err := json.Unmarshal(rr.Body.Bytes(), &game); err != nil {
+		t.Fatalf("could not decode the response body: %v", err)
+	}
+
+	if game.ID != 300 || game.MapSize != MapSizeLarge || game.MaxPlayers != 4 || !game.PasswordProtected {
+		t.Errorf("unexpected game returned: %+v", game)
+	}
+
+	if strings.Contains(rr.Body.String(), "password_hash") {
+		t.Errorf("the game password hash should never be returned: %s", rr.Body.String())
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetGame_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT " + gameColumns + " FROM game WHERE id = $1")).
+		WithArgs(int64(1)).
+		WillReturnError(sql.ErrNoRows)
+
+	req, err := http.NewRequest("GET", "/game/get_game?game_id=1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	err = GetGame(rr, req, NewPostgresGameRepository(sqlxDB), nil)
+
+	expectedError := "no game exists with the ID 1"
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("GetGame() error = %v, wantErr %v", err, expectedError)
+	}
+
+	if status := httpapitest.Status(err); status != http.StatusNotFound {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusNotFound)
+	}
+}
+
+func TestGetGame_MissingGameID(t *testing.T) {
+	req, err := http.NewRequest("GET", "/game/get_game", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	err = GetGame(rr, req, nil, nil)
+
+	expectedError := "game_id is required"
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("GetGame() error = %v, wantErr %v", err, expectedError)
+	}
+
+	if status := httpapitest.Status(err); status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+	}
+}
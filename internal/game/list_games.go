@@ -0,0 +1,75 @@
+package game
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+)
+
+const (
+	// DefaultListGamesLimit is the number of games returned when no limit is given.
+	DefaultListGamesLimit = 50
+	// MaxListGamesLimit is the largest number of games returned by a single call.
+	MaxListGamesLimit = 200
+)
+
+// ListGames lists hosted games, newest first.
+//
+// @Summary Lists games
+// @Description This endpoint lists hosted multiplayer games so that they can be shown in the launcher. By default only open games are listed.
+// @Tags game
+// @Produce json
+// @Param status query string false "game status to filter by (open, in_progress or finished)" default(open)
+// @Param limit query int false "maximum number of games to return (at most 200)" default(50)
+// @Param offset query int false "number of games to skip" default(0)
+//
+// @Success 200 {array} game.Game "Games successfully retrieved"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /game/list_games [get]
+// @Router /v2/games [get]
+func ListGames(w http.ResponseWriter, r *http.Request, games GameRepository, store *auth.SessionStore) error {
+	if r.Method != "GET" {
+		return httpapi.MethodNotAllowed(http.MethodGet)
+	}
+
+	queryParams := r.URL.Query()
+
+	status := GameStatus(queryParams.Get("status"))
+	switch status {
+	case "":
+		status = GameStatusOpen
+	case GameStatusOpen, GameStatusInProgress, GameStatusFinished:
+	default:
+		return httpapi.Validation("status must be one of open, in_progress or finished")
+	}
+
+	limit := DefaultListGamesLimit
+	if limitStr := queryParams.Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > MaxListGamesLimit {
+			return httpapi.Validation(fmt.Sprintf("limit must be a number between 1 and %d", MaxListGamesLimit))
+		}
+		limit = parsed
+	}
+
+	offset := 0
+	if offsetStr := queryParams.Get("offset"); offsetStr != "" {
+		parsed, err := strconv.Atoi(offsetStr)
+		if err != nil || parsed < 0 {
+			return httpapi.Validation("offset must be a non-negative number")
+		}
+		offset = parsed
+	}
+
+	listed, err := games.ListGames(status, limit, offset)
+	if err != nil {
+		return fmt.Errorf("an error occurred while listing games: %v", err)
+	}
+
+	httpapi.WriteJSON(w, http.StatusOK, listed)
+	return nil
+}
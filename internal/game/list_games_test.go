@@ -0,0 +1,117 @@
+package game
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi/httpapitest"
+)
+
+const listGamesQuery = "SELECT " + gameColumns + " FROM game WHERE status = $1 ORDER BY created_at DESC, id DESC LIMIT $2 OFFSET $3"
+
+func TestListGames_Defaults(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta(listGamesQuery)).
+		WithArgs(GameStatusOpen, DefaultListGamesLimit, 0).
+		WillReturnRows(sqlmock.NewRows(gameRowColumns).
+			AddRow(2, now, "Second Game", 2, "ctp2", "small", 2, "open", false).
+			AddRow(1, now.Add(-time.Minute), "First Game", 1, "ctp1", "medium", 8, "open", true))
+
+	req, err := http.NewRequest("GET", "/game/list_games", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ListGamesHandler(w, r, NewPostgresGameRepository(sqlxDB), nil)
+	})
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var games []Game
+	if err := json.Unmarshal(rr.Body.Bytes(), &games); err != nil {
+		t.Fatalf("could not decode the response body: %v", err)
+	}
+
+	if len(games) != 2 || games[0].ID != 2 || games[1].Ruleset != RulesetCTP1 {
+		t.Errorf("unexpected games returned: %+v", games)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestListGames_EmptyIsArray(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(listGamesQuery)).
+		WithArgs(GameStatusInProgress, 10, 20).
+		WillReturnRows(sqlmock.NewRows(gameRowColumns))
+
+	req, err := http.NewRequest("GET", "/game/list_games?status=in_progress&limit=10&offset=20", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	if err := ListGames(rr, req, NewPostgresGameRepository(sqlxDB), nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if rr.Body.String() != "[]" {
+		t.Errorf("handler returned unexpected body: got %v want %v", rr.Body.String(), "[]")
+	}
+}
+
+func TestListGames_InvalidParameters(t *testing.T) {
+	tests := []string{
+		"/game/list_games?status=paused",
+		"/game/list_games?limit=0",
+		"/game/list_games?limit=1000",
+		"/game/list_games?offset=-1",
+	}
+
+	for _, url := range tests {
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+
+		err = ListGames(rr, req, nil, nil)
+		if err == nil {
+			t.Errorf("%s: expected an error", url)
+		}
+
+		if status := httpapitest.Status(err); status != http.StatusBadRequest {
+			t.Errorf("%s: handler returned wrong status code: got %v want %v", url, status, http.StatusBadRequest)
+		}
+	}
+}
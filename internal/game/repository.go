@@ -0,0 +1,224 @@
+package game
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrGameNotFound is returned when no game exists with the requested ID.
+var ErrGameNotFound = errors.New("no game exists with the given ID")
+
+// GameRepository stores hosted games. Lookups of a single game return ErrGameNotFound when the game does not exist.
+type GameRepository interface {
+	// CreateGame stores a new open game hosted by the account. The password hash is nil for games without a password.
+	CreateGame(args *CreateGameArgs, hostAccountID int64, passwordHash *string) (*Game, error)
+	GetGame(gameID int64) (*Game, error)
+	// ListGames lists games with the given status, newest first.
+	ListGames(status GameStatus, limit int, offset int) ([]Game, error)
+	HostAccountID(gameID int64) (int64, error)
+	DeleteGame(gameID int64) error
+}
+
+// PostgresGameRepository stores games in the game table.
+type PostgresGameRepository struct {
+	DB *sqlx.DB
+}
+
+// NewPostgresGameRepository creates a GameRepository backed by Postgres.
+func NewPostgresGameRepository(db *sqlx.DB) *PostgresGameRepository {
+	return &PostgresGameRepository{DB: db}
+}
+
+func (p *PostgresGameRepository) CreateGame(args *CreateGameArgs, hostAccountID int64, passwordHash *string) (*Game, error) {
+	var game Game
+	err := p.DB.QueryRowx(
+		"INSERT INTO game (name, host_account_id, ruleset, map_size, max_players, status, password_protected, password_hash) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING "+gameColumns,
+		args.Name, hostAccountID, args.Ruleset, args.MapSize, args.MaxPlayers, GameStatusOpen, args.PasswordProtected, passwordHash,
+	).StructScan(&game)
+	if err != nil {
+		return nil, errors.New("an error occurred while inserting a game into the database: " + err.Error())
+	}
+
+	return &game, nil
+}
+
+func (p *PostgresGameRepository) GetGame(gameID int64) (*Game, error) {
+	var game Game
+	if err := p.DB.Get(&game, "SELECT "+gameColumns+" FROM game WHERE id = $1", gameID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrGameNotFound
+		}
+		return nil, err
+	}
+
+	return &game, nil
+}
+
+func (p *PostgresGameRepository) ListGames(status GameStatus, limit int, offset int) ([]Game, error) {
+	games := []Game{}
+	query := "SELECT " + gameColumns + " FROM game WHERE status = $1 ORDER BY created_at DESC, id DESC LIMIT $2 OFFSET $3"
+	if err := p.DB.Select(&games, query, status, limit, offset); err != nil {
+		return nil, err
+	}
+
+	return games, nil
+}
+
+func (p *PostgresGameRepository) HostAccountID(gameID int64) (int64, error) {
+	var hostAccountID int64
+	err := p.DB.QueryRow("SELECT host_account_id FROM game WHERE id = $1", gameID).Scan(&hostAccountID)
+	if err == sql.ErrNoRows {
+		return 0, ErrGameNotFound
+	}
+
+	return hostAccountID, err
+}
+
+func (p *PostgresGameRepository) DeleteGame(gameID int64) error {
+	result, err := p.DB.Exec("DELETE FROM game WHERE id = $1", gameID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("an error occurred while checking the affected rows: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrGameNotFound
+	}
+
+	return nil
+}
+
+// MemoryGameRepository keeps games in memory. It is meant for tests and for running the server without a database.
+type MemoryGameRepository struct {
+	mu     sync.RWMutex
+	nextID int64
+	games  map[int64]*Game
+}
+
+// NewMemoryGameRepository creates an empty in-memory GameRepository.
+func NewMemoryGameRepository() *MemoryGameRepository {
+	return &MemoryGameRepository{games: map[int64]*Game{}}
+}
+
+func (m *MemoryGameRepository) CreateGame(args *CreateGameArgs, hostAccountID int64, passwordHash *string) (*Game, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextID++
+	game := &Game{
+		ID:                m.nextID,
+		CreatedAt:         time.Now(),
+		Name:              args.Name,
+		HostAccountId:     hostAccountID,
+		Ruleset:           args.Ruleset,
+		MapSize:           args.MapSize,
+		MaxPlayers:        args.MaxPlayers,
+		Status:            GameStatusOpen,
+		PasswordProtected: args.PasswordProtected,
+	}
+	m.games[game.ID] = game
+
+	created := *game
+	return &created, nil
+}
+
+func (m *MemoryGameRepository) GetGame(gameID int64) (*Game, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	game, ok := m.games[gameID]
+	if !ok {
+		return nil, ErrGameNotFound
+	}
+
+	found := *game
+	return &found, nil
+}
+
+func (m *MemoryGameRepository) ListGames(status GameStatus, limit int, offset int) ([]Game, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	games := []Game{}
+	for _, game := range m.games {
+		if game.Status == status {
+			games = append(games, *game)
+		}
+	}
+
+	sort.Slice(games, func(i, j int) bool {
+		if !games[i].CreatedAt.Equal(games[j].CreatedAt) {
+			return games[i].CreatedAt.After(games[j].CreatedAt)
+		}
+		return games[i].ID > games[j].ID
+	})
+
+	if offset >= len(games) {
+		return []Game{}, nil
+	}
+	games = games[offset:]
+	if len(games) > limit {
+		games = games[:limit]
+	}
+	return games, nil
+}
+
+func (m *MemoryGameRepository) HostAccountID(gameID int64) (int64, error) {
+	game, err := m.GetGame(gameID)
+	if err != nil {
+		return 0, err
+	}
+	return game.HostAccountId, nil
+}
+
+func (m *MemoryGameRepository) DeleteGame(gameID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.games[gameID]; !ok {
+		return ErrGameNotFound
+	}
+
+	delete(m.games, gameID)
+	return nil
+}
+
+// CountHostedGames counts the games the account hosts, whatever their status. It is meant to be the
+// MemoryAccountRepository's HostedGames.
+func (m *MemoryGameRepository) CountHostedGames(accountID int64) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	count := 0
+	for _, game := range m.games {
+		if game.HostAccountId == accountID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// DeleteHostedGames deletes every game the account hosts, as the game table's cascade does in Postgres.
+// It is meant to be one of the MemoryAccountRepository's DeleteHooks, and cannot fail.
+func (m *MemoryGameRepository) DeleteHostedGames(accountID int64) (func(), error) {
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		for id, game := range m.games {
+			if game.HostAccountId == accountID {
+				delete(m.games, id)
+			}
+		}
+	}, nil
+}
@@ -0,0 +1,113 @@
+package game
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+)
+
+func TestMemoryGameRepository_GameLifecycle(t *testing.T) {
+	games := NewMemoryGameRepository()
+	store := auth.NewSessionStoreWithRepository(auth.NewMemorySessionRepository())
+
+	host, err := store.CreateSession(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	guest, err := store.CreateSession(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"First", "Second"} {
+		req := httptest.NewRequest(http.MethodPost, "/game/create_game", strings.NewReader(`{"name": "`+name+`", "password_protected": true, "password": "password123"}`))
+		req.Header.Set("Authorization", "Bearer "+host.Token)
+		rr := httptest.NewRecorder()
+
+		GameHandler(rr, req, games, store)
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("GameHandler returned %d: %s", rr.Code, rr.Body.String())
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	ListGamesHandler(rr, httptest.NewRequest(http.MethodGet, "/game/list_games?limit=1&offset=1", nil), games, store)
+
+	var listed []Game
+	if err := json.Unmarshal(rr.Body.Bytes(), &listed); err != nil {
+		t.Fatalf("could not decode the games: %v (%s)", err, rr.Body.String())
+	}
+	// Games are listed newest first, so the second page holds the first game
+	if len(listed) != 1 || listed[0].Name != "First" || listed[0].MapSize != MapSizeMedium || !listed[0].PasswordProtected {
+		t.Errorf("unexpected games: %+v", listed)
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/game/delete_game", strings.NewReader(`{"game_id": 1}`))
+	req.Header.Set("Authorization", "Bearer "+guest.Token)
+	rr = httptest.NewRecorder()
+
+	DeleteGameHandler(rr, req, games, store)
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("expected only the host to be able to delete the game, got %d", rr.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/game/delete_game", strings.NewReader(`{"game_id": 1}`))
+	req.Header.Set("Authorization", "Bearer "+host.Token)
+	rr = httptest.NewRecorder()
+
+	DeleteGameHandler(rr, req, games, store)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("DeleteGameHandler returned %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if _, err := games.GetGame(1); err != ErrGameNotFound {
+		t.Errorf("expected ErrGameNotFound, got %v", err)
+	}
+}
+
+func TestMemoryGameRepository_V2Routes(t *testing.T) {
+	games := NewMemoryGameRepository()
+	store := auth.NewSessionStoreWithRepository(auth.NewMemorySessionRepository())
+
+	host, err := store.CreateSession(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := games.CreateGame(&CreateGameArgs{Name: "First", MapSize: MapSizeSmall, MaxPlayers: 4}, 1, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v2/games/{id}", func(w http.ResponseWriter, r *http.Request) {
+		GetGameV2Handler(w, r, games, store)
+	})
+	mux.HandleFunc("DELETE /v2/games/{id}", func(w http.ResponseWriter, r *http.Request) {
+		DeleteGameV2Handler(w, r, games, store)
+	})
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v2/games/1", nil))
+
+	var game Game
+	if err := json.Unmarshal(rr.Body.Bytes(), &game); err != nil || game.Name != "First" {
+		t.Fatalf("GET /v2/games/1 returned %d: %s", rr.Code, rr.Body.String())
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/v2/games/1", nil)
+	req.Header.Set("Authorization", "Bearer "+host.Token)
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("DELETE /v2/games/1 returned %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v2/games/1", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected the deleted game not to be found, got %d", rr.Code)
+	}
+}
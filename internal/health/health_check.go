@@ -1,9 +1,10 @@
 package health
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
 )
 
 type Response struct {
@@ -18,13 +19,10 @@ type Response struct {
 // @Accept json
 // @Produce json
 // @Success 200 {object} Response
-// @Failure 500 {object} error
+// @Failure 500 {object} httpapi.ErrorResponse
 // @Router /health [get]
 func HealthCheck(w http.ResponseWriter, r *http.Request) error {
 	fmt.Println("got health check request")
-	resp := Response{Status: "OK"}
-	jsonBody, _ := json.Marshal(resp)
-
-	_, err := w.Write(jsonBody)
-	return err
+	httpapi.WriteJSON(w, http.StatusOK, Response{Status: "OK"})
+	return nil
 }
@@ -0,0 +1,119 @@
+// Package httpapi holds what every handler shares about how it answers: typed errors with
+// machine-readable codes, and the JSON bodies they and successful responses are written as.
+package httpapi
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Code is a machine-readable error code. Clients should branch on it rather than on the message, which
+// is meant for people and may change.
+type Code string
+
+// The generic codes, one for each kind of error. Packages define more specific codes for errors their
+// clients need to tell apart, such as auth.CodeInvalidCredentials.
+const (
+	CodeValidation       Code = "validation_error"
+	CodeUnauthorized     Code = "unauthorized"
+	CodeForbidden        Code = "forbidden"
+	CodeNotFound         Code = "not_found"
+	CodeMethodNotAllowed Code = "method_not_allowed"
+	CodeConflict         Code = "conflict"
+	CodeTooManyRequests  Code = "too_many_requests"
+	CodeInternal         Code = "internal_error"
+)
+
+// Error is an error which knows how it should be reported to the client.
+type Error struct {
+	// Status is the HTTP status code of the response.
+	Status int
+	// Code tells the client what went wrong.
+	Code Code
+	// Message explains what went wrong to a person.
+	Message string
+	// Fields lists what is wrong with each invalid field, for validation errors.
+	Fields []FieldError
+	// Header holds headers to send with the error, such as Retry-After.
+	Header http.Header
+	// Err is the underlying error, if any. It is logged but not sent to the client.
+	Err error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// WithCode returns a copy of the error with a more specific code.
+func (e *Error) WithCode(code Code) *Error {
+	copied := *e
+	copied.Code = code
+	return &copied
+}
+
+// NewError creates an Error with the given status, code and message.
+func NewError(status int, code Code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+// Validation is returned when the request is malformed or a field has an invalid value.
+func Validation(message string) *Error {
+	return NewError(http.StatusBadRequest, CodeValidation, message)
+}
+
+// Unauthorized is returned when the request does not say who is making it.
+func Unauthorized(message string) *Error {
+	return NewError(http.StatusUnauthorized, CodeUnauthorized, message)
+}
+
+// Forbidden is returned when the caller is not allowed to do what they asked.
+func Forbidden(message string) *Error {
+	return NewError(http.StatusForbidden, CodeForbidden, message)
+}
+
+// NotFound is returned when what the request refers to does not exist.
+func NotFound(message string) *Error {
+	return NewError(http.StatusNotFound, CodeNotFound, message)
+}
+
+// Conflict is returned when the request clashes with the current state, such as an email already in use.
+func Conflict(message string) *Error {
+	return NewError(http.StatusConflict, CodeConflict, message)
+}
+
+// MethodNotAllowed is returned when an endpoint is called with the wrong HTTP method. allowed is the
+// Allow header, such as "POST" or "GET, HEAD, POST".
+func MethodNotAllowed(allowed string) *Error {
+	message := fmt.Sprintf("invalid request; request must be a %s request", allowed)
+	if methods := strings.Split(allowed, ", "); len(methods) > 1 {
+		message = fmt.Sprintf("invalid request; request must be %s or %s", strings.Join(methods[:len(methods)-1], ", "), methods[len(methods)-1])
+	}
+
+	err := NewError(http.StatusMethodNotAllowed, CodeMethodNotAllowed, message)
+	err.Header = http.Header{"Allow": []string{allowed}}
+	return err
+}
+
+// TooManyRequests is returned when the caller has to wait before trying again. A positive retryAfter
+// is sent as the Retry-After header, rounded up to whole seconds.
+func TooManyRequests(message string, retryAfter time.Duration) *Error {
+	err := NewError(http.StatusTooManyRequests, CodeTooManyRequests, message)
+	if retryAfter > 0 {
+		seconds := int((retryAfter + time.Second - 1) / time.Second)
+		err.Header = http.Header{"Retry-After": []string{strconv.Itoa(seconds)}}
+	}
+	return err
+}
+
+// Internal is returned when the server fails. err is kept for the logs; the client only gets a generic
+// message, since err may describe the database or other internals.
+func Internal(err error) *Error {
+	return &Error{Status: http.StatusInternalServerError, Code: CodeInternal, Message: "an internal error occurred; please try again later", Err: err}
+}
@@ -0,0 +1,102 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func decodeErrorResponse(t *testing.T, rr *httptest.ResponseRecorder) ErrorBody {
+	t.Helper()
+
+	var response ErrorResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("could not decode the error response %q: %v", rr.Body.String(), err)
+	}
+	return response.Error
+}
+
+func TestWriteError(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		code    Code
+		message string
+	}{
+		{"Validation", Validation("lobby_id must be specified"), http.StatusBadRequest, CodeValidation, "lobby_id must be specified"},
+		{"SpecificCode", Forbidden("the lobby is closed").WithCode("lobby_closed"), http.StatusForbidden, "lobby_closed", "the lobby is closed"},
+		{"Wrapped", fmt.Errorf("checking the lobby: %w", NotFound("no lobby exists with the ID 1")), http.StatusNotFound, CodeNotFound, "no lobby exists with the ID 1"},
+		{"OneMethod", MethodNotAllowed(http.MethodPut), http.StatusMethodNotAllowed, CodeMethodNotAllowed, "invalid request; request must be a PUT request"},
+		{"SeveralMethods", MethodNotAllowed("GET, HEAD, POST"), http.StatusMethodNotAllowed, CodeMethodNotAllowed, "invalid request; request must be GET, HEAD or POST"},
+		{"Untyped", errors.New("connection reset"), http.StatusInternalServerError, CodeInternal, "an internal error occurred; please try again later"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			WriteError(rr, tt.err)
+
+			if rr.Code != tt.status {
+				t.Errorf("got status %v want %v", rr.Code, tt.status)
+			}
+			if contentType := rr.Header().Get("Content-Type"); contentType != "application/json" {
+				t.Errorf("got Content-Type %q want application/json", contentType)
+			}
+
+			body := decodeErrorResponse(t, rr)
+			if body.Code != tt.code || body.Message != tt.message {
+				t.Errorf("got %+v want code %q and message %q", body, tt.code, tt.message)
+			}
+		})
+	}
+}
+
+func TestWriteError_Headers(t *testing.T) {
+	rr := httptest.NewRecorder()
+	WriteError(rr, MethodNotAllowed(http.MethodPost))
+
+	if rr.Code != http.StatusMethodNotAllowed || rr.Header().Get("Allow") != http.MethodPost {
+		t.Errorf("got status %v and Allow %q", rr.Code, rr.Header().Get("Allow"))
+	}
+
+	rr = httptest.NewRecorder()
+	WriteError(rr, TooManyRequests("slow down", 1500*time.Millisecond))
+
+	if rr.Code != http.StatusTooManyRequests || rr.Header().Get("Retry-After") != "2" {
+		t.Errorf("got status %v and Retry-After %q", rr.Code, rr.Header().Get("Retry-After"))
+	}
+}
+
+func TestWithCode_LeavesOriginalAlone(t *testing.T) {
+	base := Forbidden("session not found")
+	specific := base.WithCode("session_not_found")
+
+	if base.Code != CodeForbidden || specific.Code != "session_not_found" {
+		t.Errorf("got codes %q and %q", base.Code, specific.Code)
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	var args struct {
+		LobbyId int64 `json:"lobby_id"`
+	}
+
+	if err := DecodeJSON(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"lobby_id": 1}`)), &args); err != nil || args.LobbyId != 1 {
+		t.Fatalf("got %+v, %v", args, err)
+	}
+
+	for _, body := range []string{`{"lobby_id": "one"}`, `{"lobby": 1}`, `not json`} {
+		err := DecodeJSON(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)), &args)
+
+		var apiErr *Error
+		if !errors.As(err, &apiErr) || apiErr.Status != http.StatusBadRequest || apiErr.Code != CodeValidation {
+			t.Errorf("%s: expected a validation error, got %v", body, err)
+		}
+	}
+}
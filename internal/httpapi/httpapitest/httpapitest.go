@@ -0,0 +1,50 @@
+// Package httpapitest helps tests read the responses written by package httpapi.
+package httpapitest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+)
+
+// Message returns the message of an error response or a message response. Bodies which are neither are
+// returned as they are, without surrounding whitespace.
+func Message(rr *httptest.ResponseRecorder) string {
+	var body struct {
+		Error   *httpapi.ErrorBody `json:"error"`
+		Message *string            `json:"message"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		return strings.TrimSpace(rr.Body.String())
+	}
+
+	if body.Error != nil {
+		return body.Error.Message
+	}
+	if body.Message != nil {
+		return *body.Message
+	}
+	return strings.TrimSpace(rr.Body.String())
+}
+
+// Code returns the code of an error response, or an empty code when the response is not an error.
+func Code(rr *httptest.ResponseRecorder) httpapi.Code {
+	var body httpapi.ErrorResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		return ""
+	}
+	return body.Error.Code
+}
+
+// Status returns the status httpapi.WriteError would respond to err with.
+func Status(err error) int {
+	var apiErr *httpapi.Error
+	if !errors.As(err, &apiErr) {
+		return http.StatusInternalServerError
+	}
+	return apiErr.Status
+}
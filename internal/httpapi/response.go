@@ -0,0 +1,102 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+)
+
+// ErrorBody describes what went wrong.
+//
+// @Description What went wrong with a request.
+type ErrorBody struct {
+	// Code is a machine-readable error code, such as "not_found" or "invalid_credentials".
+	Code Code `json:"code" example:"validation_error"`
+	// Message explains what went wrong to a person.
+	Message string `json:"message" example:"account_id must be specified"`
+	// Fields lists what is wrong with each invalid field, for validation errors.
+	Fields []FieldError `json:"fields,omitempty"`
+}
+
+// ErrorResponse is the body of every error response.
+//
+// @Description The body of every error response.
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// MessageResponse is the body of successful responses which have nothing to return but a message.
+//
+// @Description The body of a successful response which only carries a message.
+type MessageResponse struct {
+	Message string `json:"message" example:"Successfully updated account!"`
+}
+
+// WriteError writes err as an ErrorResponse. Errors other than *Error are internal errors. Internal
+// errors are logged, since they are usually a bug or an outage rather than a mistake of the client's.
+func WriteError(w http.ResponseWriter, err error) {
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		apiErr = Internal(err)
+	}
+
+	if apiErr.Status >= http.StatusInternalServerError {
+		log.Println("internal error: ", err.Error())
+	}
+
+	for key, values := range apiErr.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+
+	WriteJSON(w, apiErr.Status, ErrorResponse{Error: ErrorBody{Code: apiErr.Code, Message: apiErr.Message, Fields: apiErr.Fields}})
+}
+
+// WriteJSON writes v as the JSON body of a response with the given status.
+func WriteJSON(w http.ResponseWriter, status int, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		log.Println("error marshalling a response: ", err.Error())
+		status = http.StatusInternalServerError
+		body, _ = json.Marshal(ErrorResponse{Error: ErrorBody{Code: CodeInternal, Message: "the response could not be encoded"}})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// WriteMessage writes a MessageResponse with the given status.
+func WriteMessage(w http.ResponseWriter, status int, message string) {
+	WriteJSON(w, status, MessageResponse{Message: message})
+}
+
+// DecodeJSON decodes the request body into v, refusing fields v does not have. Malformed bodies are
+// reported as validation errors.
+func DecodeJSON(r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(v); err != nil {
+		return &Error{
+			Status:  http.StatusBadRequest,
+			Code:    CodeValidation,
+			Message: "an error occurred while decoding the request body: " + err.Error(),
+			Err:     err,
+		}
+	}
+	return nil
+}
+
+// DecodeOptionalJSON is DecodeJSON for requests whose body may be left out. An empty body leaves v as it is.
+func DecodeOptionalJSON(r *http.Request, v interface{}) error {
+	err := DecodeJSON(r, v)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,77 @@
+package httpapi
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// PathID returns the wildcard with the given name from the request's route pattern, such as the id of
+// GET /v2/lobbies/{id}, as a number.
+func PathID(r *http.Request, name string) (int64, error) {
+	id, err := strconv.ParseInt(r.PathValue(name), 10, 64)
+	if err != nil {
+		return 0, Validation(fmt.Sprintf("%s must be a number", name))
+	}
+	return id, nil
+}
+
+// ClientIP returns the IP address the request came from. header names a header holding the client's
+// address, such as Fly-Client-IP, and must only be given behind a proxy which overwrites it. When it is
+// empty or the header is missing, the connection's address is used.
+func ClientIP(r *http.Request, header string) string {
+	if header != "" {
+		if ip := strings.TrimSpace(r.Header.Get(header)); ip != "" {
+			return ip
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// JSONErrors answers requests which match none of the mux's routes with a JSON error like every other
+// one, rather than the plain text the mux writes by itself. Routes which only differ from the request in
+// their method get 405 Method Not Allowed, and the rest 404 Not Found.
+func JSONErrors(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler, pattern := mux.Handler(r)
+		if pattern != "" {
+			mux.ServeHTTP(w, r)
+			return
+		}
+
+		// The mux's own handler works out which methods the path allows
+		unmatched := &unmatchedWriter{header: http.Header{}}
+		handler.ServeHTTP(unmatched, r)
+
+		if unmatched.status == http.StatusMethodNotAllowed {
+			WriteError(w, MethodNotAllowed(unmatched.header.Get("Allow")))
+			return
+		}
+		WriteError(w, NotFound(fmt.Sprintf("no endpoint exists at %s", r.URL.Path)))
+	})
+}
+
+// unmatchedWriter records the status the mux answers an unmatched request with and throws the body away.
+type unmatchedWriter struct {
+	header http.Header
+	status int
+}
+
+func (w *unmatchedWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *unmatchedWriter) Write(body []byte) (int, error) {
+	return len(body), nil
+}
+
+func (w *unmatchedWriter) WriteHeader(status int) {
+	w.status = status
+}
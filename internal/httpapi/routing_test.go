@@ -0,0 +1,77 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPathID(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/v2/lobbies/12", nil)
+	r.SetPathValue("id", "12")
+
+	if id, err := PathID(r, "id"); err != nil || id != 12 {
+		t.Errorf("got %v, %v want 12", id, err)
+	}
+
+	r.SetPathValue("id", "twelve")
+	_, err := PathID(r, "id")
+	if err == nil || err.Error() != "id must be a number" {
+		t.Errorf("got %v want id must be a number", err)
+	}
+}
+
+func TestDecodeOptionalJSON(t *testing.T) {
+	args := struct {
+		Password string `json:"password"`
+	}{Password: "unchanged"}
+
+	if err := DecodeOptionalJSON(httptest.NewRequest(http.MethodPost, "/", nil), &args); err != nil || args.Password != "unchanged" {
+		t.Errorf("got %+v, %v for an empty body", args, err)
+	}
+
+	if err := DecodeOptionalJSON(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"password": "secret"}`)), &args); err != nil || args.Password != "secret" {
+		t.Errorf("got %+v, %v", args, err)
+	}
+
+	err := DecodeOptionalJSON(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`not json`)), &args)
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusBadRequest {
+		t.Errorf("expected a validation error, got %v", err)
+	}
+}
+
+func TestJSONErrors(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v2/lobbies/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("DELETE /v2/lobbies/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	handler := JSONErrors(mux)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v2/lobbies/1", nil))
+	if rr.Code != http.StatusNoContent {
+		t.Errorf("got status %v for a matching route", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v2/lobbies/1", nil))
+	if rr.Code != http.StatusMethodNotAllowed || decodeErrorResponse(t, rr).Code != CodeMethodNotAllowed {
+		t.Errorf("got status %v and body %q", rr.Code, rr.Body.String())
+	}
+	if allow := rr.Header().Get("Allow"); !strings.Contains(allow, http.MethodDelete) || !strings.Contains(allow, http.MethodGet) {
+		t.Errorf("got Allow %q", allow)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v2/games", nil))
+	body := decodeErrorResponse(t, rr)
+	if rr.Code != http.StatusNotFound || body.Code != CodeNotFound || body.Message != "no endpoint exists at /v2/games" {
+		t.Errorf("got status %v and body %+v", rr.Code, body)
+	}
+}
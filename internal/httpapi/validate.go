@@ -0,0 +1,311 @@
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	netmail "net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// FieldError describes what is wrong with one field of a request.
+//
+// @Description What is wrong with one field of a request.
+type FieldError struct {
+	// Field is the JSON path of the field, such as "account.experience_level".
+	Field string `json:"field" example:"max_players"`
+	// Message explains what is wrong with the field to a person.
+	Message string `json:"message" example:"max_players must be between 2 and 8"`
+}
+
+// Rule checks a field against a validate tag which takes param, such as the 8 of max=8. The field is
+// never a nil pointer; pointers are dereferenced before the rule is called. The message of the error
+// completes a sentence starting with the field's name, for example "must be at least 6 characters".
+type Rule func(value reflect.Value, param string) error
+
+var (
+	rulesMu sync.RWMutex
+	rules   = map[string]Rule{}
+)
+
+// RegisterRule makes a rule available to validate tags under the given name. Packages register the
+// rules they own, such as auth's password policy, when they are initialised.
+func RegisterRule(name string, rule Rule) {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	rules[name] = rule
+}
+
+// Validate checks v, a struct or a pointer to one, against the validate tags of its fields and those of
+// the structs it contains. It returns a validation error listing every invalid field, or nil.
+//
+// A validate tag is a comma-separated list of rules:
+//
+//	required                the field must be set (non-zero, or a non-nil pointer)
+//	required_with=Field     the field must be set when the sibling Field is
+//	required_without=Field  the field must be set when the sibling Field is not
+//	excluded_without=Field  the field must not be set when the sibling Field is not
+//	nonempty                the struct, or the struct pointed to, must have at least one field set
+//	min=N, max=N            bounds on numbers, and on the length of strings, slices and maps
+//	oneof=a b c             the value must be one of those listed
+//	email                   the string must be an email address
+//
+// Other rules are the ones registered with RegisterRule. Rules other than required_with,
+// required_without and nonempty are skipped when the field is not set, so optional fields may be left out.
+func Validate(v interface{}) error {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	var fields []FieldError
+	validateStruct(value, "", &fields)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = field.Message
+	}
+
+	err := Validation(strings.Join(messages, "; "))
+	err.Fields = fields
+	return err
+}
+
+func validateStruct(value reflect.Value, prefix string, fields *[]FieldError) {
+	structType := value.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		structField := structType.Field(i)
+		// The fields of embedded structs are decoded even when the struct's type is unexported
+		if !structField.IsExported() && !structField.Anonymous {
+			continue
+		}
+
+		field := value.Field(i)
+		name := fieldName(structField)
+		if name == "-" {
+			continue
+		}
+
+		path := prefix
+		if !structField.Anonymous {
+			path = joinPath(prefix, name)
+		}
+
+		if tag := structField.Tag.Get("validate"); tag != "" {
+			for _, message := range checkField(value, field, tag) {
+				*fields = append(*fields, FieldError{Field: path, Message: path + " " + message})
+			}
+		}
+
+		// Nested structs are validated too, so that the tags of Account apply within CreateAccountArgs
+		inner := field
+		if inner.Kind() == reflect.Ptr {
+			if inner.IsNil() {
+				continue
+			}
+			inner = inner.Elem()
+		}
+		if inner.Kind() == reflect.Struct {
+			validateStruct(inner, path, fields)
+		}
+	}
+}
+
+// checkField returns what is wrong with the field, one message for each rule it breaks.
+func checkField(parent reflect.Value, field reflect.Value, tag string) []string {
+	var messages []string
+	set := !field.IsZero()
+
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+
+		var err error
+		switch name {
+		case "required":
+			if !set {
+				err = errors.New("must be specified")
+			}
+		case "required_with":
+			if !set && isSet(parent, param) {
+				err = fmt.Errorf("must be specified when %s is set", siblingName(parent, param))
+			}
+		case "required_without":
+			if !set && !isSet(parent, param) {
+				err = fmt.Errorf("must be specified unless %s is", siblingName(parent, param))
+			}
+		case "excluded_without":
+			if set && !isSet(parent, param) {
+				err = fmt.Errorf("must not be set unless %s is set", siblingName(parent, param))
+			}
+		case "nonempty":
+			err = checkNonEmpty(field)
+		default:
+			if !set {
+				continue
+			}
+			err = checkRule(name, param, indirect(field), tag)
+		}
+
+		if err != nil {
+			messages = append(messages, err.Error())
+		}
+	}
+
+	return messages
+}
+
+func checkRule(name string, param string, value reflect.Value, tag string) error {
+	switch name {
+	case "min", "max":
+		// A field with both bounds is reported once, with both of them
+		lower, hasLower := tagParam(tag, "min")
+		upper, hasUpper := tagParam(tag, "max")
+		if name == "max" && hasLower {
+			return nil
+		}
+		return checkBounds(value, lower, hasLower, upper, hasUpper)
+	case "oneof":
+		options := strings.Fields(param)
+		actual := fmt.Sprint(value)
+		for _, option := range options {
+			if actual == option {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of %s", listOptions(options))
+	case "email":
+		if _, err := netmail.ParseAddress(value.String()); err != nil {
+			return fmt.Errorf("must be a valid email address: %v", err)
+		}
+		return nil
+	}
+
+	rulesMu.RLock()
+	rule, ok := rules[name]
+	rulesMu.RUnlock()
+	if !ok {
+		panic(fmt.Sprintf("httpapi: unknown validate rule %q", name))
+	}
+	return rule(value, param)
+}
+
+func checkBounds(value reflect.Value, lower string, hasLower bool, upper string, hasUpper bool) error {
+	var actual float64
+	unit := ""
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		actual = float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		actual = float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		actual = value.Float()
+	case reflect.String:
+		actual = float64(utf8.RuneCountInString(value.String()))
+		unit = " characters"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		actual = float64(value.Len())
+		unit = " items"
+	default:
+		panic(fmt.Sprintf("httpapi: min and max cannot be used on a %s", value.Kind()))
+	}
+
+	tooSmall := hasLower && actual < parseBound(lower)
+	tooLarge := hasUpper && actual > parseBound(upper)
+	if !tooSmall && !tooLarge {
+		return nil
+	}
+
+	switch {
+	case hasLower && hasUpper:
+		return fmt.Errorf("must be between %s and %s%s", lower, upper, unit)
+	case hasLower:
+		return fmt.Errorf("must be at least %s%s", lower, unit)
+	default:
+		return fmt.Errorf("must be at most %s%s", upper, unit)
+	}
+}
+
+func checkNonEmpty(field reflect.Value) error {
+	value := indirect(field)
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i := 0; i < value.NumField(); i++ {
+		if value.Type().Field(i).IsExported() && !value.Field(i).IsZero() {
+			return nil
+		}
+	}
+	return errors.New("must set at least one field")
+}
+
+// isSet reports whether the sibling field with the given Go name is set.
+func isSet(parent reflect.Value, name string) bool {
+	sibling := parent.FieldByName(name)
+	if !sibling.IsValid() {
+		panic(fmt.Sprintf("httpapi: %s has no field %s", parent.Type(), name))
+	}
+	return !sibling.IsZero()
+}
+
+// siblingName returns the JSON name of the sibling field with the given Go name.
+func siblingName(parent reflect.Value, name string) string {
+	structField, _ := parent.Type().FieldByName(name)
+	return fieldName(structField)
+}
+
+func fieldName(structField reflect.StructField) string {
+	name, _, _ := strings.Cut(structField.Tag.Get("json"), ",")
+	if name == "" {
+		return structField.Name
+	}
+	return name
+}
+
+func joinPath(prefix string, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+func indirect(value reflect.Value) reflect.Value {
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	return value
+}
+
+func tagParam(tag string, name string) (string, bool) {
+	for _, rule := range strings.Split(tag, ",") {
+		if ruleName, param, ok := strings.Cut(rule, "="); ok && ruleName == name {
+			return param, true
+		}
+	}
+	return "", false
+}
+
+func parseBound(bound string) float64 {
+	parsed, err := strconv.ParseFloat(bound, 64)
+	if err != nil {
+		panic(fmt.Sprintf("httpapi: invalid bound %q", bound))
+	}
+	return parsed
+}
+
+// listOptions lists options as "a, b or c".
+func listOptions(options []string) string {
+	if len(options) == 1 {
+		return options[0]
+	}
+	return strings.Join(options[:len(options)-1], ", ") + " or " + options[len(options)-1]
+}
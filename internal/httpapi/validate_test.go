@@ -0,0 +1,135 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type validateInner struct {
+	Name  *string `json:"name,omitempty"`
+	Level int     `json:"level,omitempty" validate:"min=0,max=5"`
+}
+
+type validateArgs struct {
+	Id        int64          `json:"id" validate:"required"`
+	Size      string         `json:"size,omitempty" validate:"oneof=small medium large"`
+	Players   int            `json:"players,omitempty" validate:"min=2,max=8"`
+	Title     string         `json:"title,omitempty" validate:"max=5"`
+	Email     string         `json:"email,omitempty" validate:"email"`
+	Protected bool           `json:"protected"`
+	Password  string         `json:"password,omitempty" validate:"required_with=Protected,excluded_without=Protected"`
+	Inner     *validateInner `json:"inner,omitempty" validate:"required_without=Id,nonempty"`
+	Tags      []string       `json:"tags,omitempty" validate:"min=1"`
+	Nickname  string         `json:"nickname,omitempty" validate:"shouting"`
+}
+
+func init() {
+	RegisterRule("shouting", func(value reflect.Value, param string) error {
+		if !strings.HasSuffix(value.String(), "!") {
+			return errors.New("must end with an exclamation mark")
+		}
+		return nil
+	})
+}
+
+func validationFields(t *testing.T, err error) []FieldError {
+	t.Helper()
+
+	if err == nil {
+		return nil
+	}
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusBadRequest || apiErr.Code != CodeValidation {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	return apiErr.Fields
+}
+
+func TestValidate(t *testing.T) {
+	name := "lobby"
+	tests := []struct {
+		name    string
+		args    validateArgs
+		message string
+	}{
+		{"Valid", validateArgs{Id: 1, Size: "small", Players: 4, Email: "player@example.com", Protected: true, Password: "secret", Tags: []string{"a"}, Nickname: "hi!"}, ""},
+		{"OptionalFieldsLeftOut", validateArgs{Id: 1}, ""},
+		{"Required", validateArgs{Inner: &validateInner{Name: &name}}, "id must be specified"},
+		{"OneOf", validateArgs{Id: 1, Size: "huge"}, "size must be one of small, medium or large"},
+		{"Between", validateArgs{Id: 1, Players: 9}, "players must be between 2 and 8"},
+		{"AtMostCharacters", validateArgs{Id: 1, Title: "Lobby one"}, "title must be at most 5 characters"},
+		{"AtLeastItems", validateArgs{Id: 1, Tags: []string{}}, "tags must be at least 1 items"},
+		{"Email", validateArgs{Id: 1, Email: "player"}, "email must be a valid email address: mail: missing '@' or angle-addr"},
+		{"RequiredWith", validateArgs{Id: 1, Protected: true}, "password must be specified when protected is set"},
+		{"ExcludedWithout", validateArgs{Id: 1, Password: "secret"}, "password must not be set unless protected is set"},
+		{"NonEmpty", validateArgs{Id: 1, Inner: &validateInner{}}, "inner must set at least one field"},
+		{"Nested", validateArgs{Id: 1, Inner: &validateInner{Name: &name, Level: 6}}, "inner.level must be between 0 and 5"},
+		{"Registered", validateArgs{Id: 1, Nickname: "hi"}, "nickname must end with an exclamation mark"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(&tt.args)
+			if tt.message == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+
+			if err == nil || err.Error() != tt.message {
+				t.Errorf("got %v want %q", err, tt.message)
+			}
+		})
+	}
+}
+
+func TestValidate_ReportsEveryField(t *testing.T) {
+	err := Validate(&validateArgs{Size: "huge", Players: 1})
+
+	fields := validationFields(t, err)
+	want := []FieldError{
+		{Field: "id", Message: "id must be specified"},
+		{Field: "size", Message: "size must be one of small, medium or large"},
+		{Field: "players", Message: "players must be between 2 and 8"},
+		{Field: "inner", Message: "inner must be specified unless id is"},
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("got fields %+v want %+v", fields, want)
+	}
+
+	if err.Error() != "id must be specified; size must be one of small, medium or large; players must be between 2 and 8; inner must be specified unless id is" {
+		t.Errorf("got message %q", err.Error())
+	}
+}
+
+func TestValidate_Embedded(t *testing.T) {
+	type args struct {
+		validateInner
+		Id int64 `json:"id" validate:"required"`
+	}
+
+	fields := validationFields(t, Validate(args{validateInner: validateInner{Level: -1}}))
+	want := []FieldError{
+		{Field: "level", Message: "level must be between 0 and 5"},
+		{Field: "id", Message: "id must be specified"},
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("got fields %+v want %+v", fields, want)
+	}
+}
+
+func TestWriteError_Fields(t *testing.T) {
+	rr := httptest.NewRecorder()
+	WriteError(rr, Validate(&validateArgs{Size: "huge", Id: 1}))
+
+	body := decodeErrorResponse(t, rr)
+	if len(body.Fields) != 1 || body.Fields[0].Field != "size" {
+		t.Errorf("got fields %+v", body.Fields)
+	}
+}
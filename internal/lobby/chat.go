@@ -0,0 +1,160 @@
+package lobby
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+)
+
+const (
+	// MaxChatMessageLength is the longest chat message, in characters, which can be sent.
+	MaxChatMessageLength = 500
+	// ChatRateLimit is how many messages a member can send to a lobby within ChatRateWindow.
+	ChatRateLimit = 5
+	// ChatRateWindow is the window over which ChatRateLimit applies.
+	ChatRateWindow = 10 * time.Second
+)
+
+const ERROR_MESSAGE_REQUIRED = "message must be specified"
+
+// ERROR_MESSAGE_TOO_LONG is built from MaxChatMessageLength so that the two cannot disagree.
+var ERROR_MESSAGE_TOO_LONG = fmt.Sprintf("message must be at most %d characters", MaxChatMessageLength)
+
+const ERROR_LOBBY_MUTED = "the lobby has been muted by its owner"
+const ERROR_MEMBER_MUTED = "you have been muted in this lobby"
+const ERROR_CHAT_RATE_LIMITED = "you are sending messages too quickly"
+
+// The codes of the errors which keep an account from chatting.
+const (
+	CodeLobbyMuted      httpapi.Code = "lobby_muted"
+	CodeMemberMuted     httpapi.Code = "member_muted"
+	CodeChatRateLimited httpapi.Code = "chat_rate_limited"
+)
+
+// Message is a chat message sent in a lobby.
+//
+// @Description Structure for representing a lobby chat message.
+type Message struct {
+	// ID is the unique identifier for the message.
+	ID int64 `json:"id" db:"id"`
+
+	// LobbyId is the lobby the message was sent in.
+	LobbyId int64 `json:"lobby_id" db:"lobby_id"`
+
+	// AccountId is the account which sent the message.
+	AccountId int64 `json:"account_id" db:"account_id"`
+
+	// Message is the text of the message.
+	Message string `json:"message" db:"message"`
+
+	// CreatedAt is when the message was sent.
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// chatRateLimiter limits how often each member can send messages to each lobby.
+type chatRateLimiter struct {
+	mu        sync.Mutex
+	recent    map[[2]int64][]time.Time
+	lastSweep time.Time
+}
+
+func newChatRateLimiter() *chatRateLimiter {
+	return &chatRateLimiter{recent: make(map[[2]int64][]time.Time)}
+}
+
+// chatLimiter is shared by every way of sending a message so that the limit cannot be dodged by switching.
+var chatLimiter = newChatRateLimiter()
+
+// allow records a message from the account and reports whether it is within the rate limit.
+func (l *chatRateLimiter) allow(lobbyID int64, accountID int64, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key := [2]int64{lobbyID, accountID}
+	cutoff := now.Add(-ChatRateWindow)
+	l.sweep(now, cutoff)
+
+	recent := l.recent[key][:0]
+	for _, sentAt := range l.recent[key] {
+		if sentAt.After(cutoff) {
+			recent = append(recent, sentAt)
+		}
+	}
+
+	if len(recent) >= ChatRateLimit {
+		l.recent[key] = recent
+		return false
+	}
+
+	l.recent[key] = append(recent, now)
+	return true
+}
+
+// sweep forgets the members whose messages were all sent before cutoff, at most once every ChatRateWindow,
+// so that members who have stopped chatting are not kept forever.
+func (l *chatRateLimiter) sweep(now time.Time, cutoff time.Time) {
+	if now.Sub(l.lastSweep) < ChatRateWindow {
+		return
+	}
+	l.lastSweep = now
+
+	for key, recent := range l.recent {
+		if len(recent) == 0 || !recent[len(recent)-1].After(cutoff) {
+			delete(l.recent, key)
+		}
+	}
+}
+
+// sendChatMessage checks that the account may chat in the lobby, stores the message and publishes it
+// to the lobby's subscribers.
+func sendChatMessage(lobbies LobbyRepository, lobbyID int64, accountID int64, text string) (*Message, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, httpapi.Validation(ERROR_MESSAGE_REQUIRED)
+	}
+
+	if utf8.RuneCountInString(text) > MaxChatMessageLength {
+		return nil, httpapi.Validation(ERROR_MESSAGE_TOO_LONG)
+	}
+
+	chatter, err := lobbies.Chatter(lobbyID, accountID)
+	if err == ErrLobbyNotFound {
+		return nil, httpapi.NotFound(fmt.Sprintf("no lobby exists with the ID %d", lobbyID))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while checking the lobby membership: %v", err)
+	}
+
+	// The owner can always talk, even in a muted lobby
+	if chatter.OwnerAccountId != strconv.FormatInt(accountID, 10) {
+		if !chatter.MemberMuted.Valid {
+			return nil, httpapi.Forbidden(fmt.Sprintf("you are not a member of the lobby with the ID %d", lobbyID)).WithCode(CodeNotLobbyMember)
+		}
+
+		if chatter.LobbyMuted {
+			return nil, httpapi.Forbidden(ERROR_LOBBY_MUTED).WithCode(CodeLobbyMuted)
+		}
+
+		if chatter.MemberMuted.Bool {
+			return nil, httpapi.Forbidden(ERROR_MEMBER_MUTED).WithCode(CodeMemberMuted)
+		}
+	}
+
+	if !chatLimiter.allow(lobbyID, accountID, time.Now()) {
+		return nil, httpapi.TooManyRequests(ERROR_CHAT_RATE_LIMITED, 0).WithCode(CodeChatRateLimited)
+	}
+
+	message := Message{LobbyId: lobbyID, AccountId: accountID, Message: text}
+	if err := lobbies.AddMessage(&message); err != nil {
+		return nil, fmt.Errorf("an error occurred while storing the message: %v", err)
+	}
+
+	Events.Publish(Event{Type: EventChatMessage, LobbyId: lobbyID, AccountId: accountID, Message: &message, Time: message.CreatedAt})
+
+	return &message, nil
+}
@@ -0,0 +1,186 @@
+package lobby
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi/httpapitest"
+)
+
+const chatterQuery = "SELECT lobby.owner_account_id, lobby.is_muted AS lobby_muted, lobby_member.is_muted AS member_muted FROM lobby LEFT JOIN lobby_member ON lobby_member.lobby_id = lobby.id AND lobby_member.account_id = $2 WHERE lobby.id = $1"
+const insertMessageQuery = "INSERT INTO lobby_message (lobby_id, account_id, message) VALUES ($1, $2, $3) RETURNING id, created_at"
+
+// expectChatter expects the lookup of whether the account can chat in the lobby. A nil memberMuted means the account is not a member.
+func expectChatter(mock sqlmock.Sqlmock, lobbyID int64, accountID int64, ownerStr string, lobbyMuted bool, memberMuted interface{}) {
+	mock.ExpectQuery(regexp.QuoteMeta(chatterQuery)).
+		WithArgs(lobbyID, accountID).
+		WillReturnRows(sqlmock.NewRows([]string{"owner_account_id", "lobby_muted", "member_muted"}).AddRow(ownerStr, lobbyMuted, memberMuted))
+}
+
+func TestChatRateLimiter(t *testing.T) {
+	limiter := newChatRateLimiter()
+	now := time.Now()
+
+	for i := 0; i < ChatRateLimit; i++ {
+		if !limiter.allow(1, 2, now) {
+			t.Fatalf("message %d was rate limited", i+1)
+		}
+	}
+
+	if limiter.allow(1, 2, now) {
+		t.Error("expected the message over the limit to be rejected")
+	}
+
+	if !limiter.allow(1, 3, now) {
+		t.Error("expected another account to be unaffected by the limit")
+	}
+
+	if !limiter.allow(1, 2, now.Add(ChatRateWindow)) {
+		t.Error("expected the limit to reset once the window has passed")
+	}
+}
+
+func TestChatRateLimiter_ForgetsQuietMembers(t *testing.T) {
+	limiter := newChatRateLimiter()
+	now := time.Now()
+
+	limiter.allow(1, 2, now)
+	limiter.allow(1, 3, now)
+
+	limiter.allow(1, 4, now.Add(2*ChatRateWindow))
+
+	if len(limiter.recent) != 1 {
+		t.Errorf("expected only the member who chatted last to be kept, got %v", limiter.recent)
+	}
+}
+
+func TestSendChatMessage_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"Empty", "   ", ERROR_MESSAGE_REQUIRED},
+		{"TooLong", strings.Repeat("a", MaxChatMessageLength+1), ERROR_MESSAGE_TOO_LONG},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, _, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer db.Close()
+
+			_, err = sendChatMessage(NewPostgresLobbyRepository(sqlx.NewDb(db, "sqlmock")), 1, 2, tt.text)
+			if status := httpapitest.Status(err); status != http.StatusBadRequest {
+				t.Errorf("got status %v want %v", status, http.StatusBadRequest)
+			}
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("got error %v want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendChatMessage_Forbidden(t *testing.T) {
+	tests := []struct {
+		name        string
+		lobbyMuted  bool
+		memberMuted interface{}
+		want        string
+	}{
+		{"NotAMember", false, nil, "you are not a member of the lobby with the ID 1"},
+		{"LobbyMuted", true, false, ERROR_LOBBY_MUTED},
+		{"MemberMuted", false, true, ERROR_MEMBER_MUTED},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer db.Close()
+
+			expectChatter(mock, 1, 2, "1", tt.lobbyMuted, tt.memberMuted)
+
+			_, err = sendChatMessage(NewPostgresLobbyRepository(sqlx.NewDb(db, "sqlmock")), 1, 2, "gg")
+			if status := httpapitest.Status(err); status != http.StatusForbidden {
+				t.Errorf("got status %v want %v", status, http.StatusForbidden)
+			}
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("got error %v want %v", err, tt.want)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestSendChatMessage_OwnerCanChatInMutedLobby(t *testing.T) {
+	chatLimiter = newChatRateLimiter()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	events, unsubscribe := Events.Subscribe(1, 0)
+	defer unsubscribe()
+
+	now := time.Now()
+	expectChatter(mock, 1, 1, "1", true, nil)
+	mock.ExpectQuery(regexp.QuoteMeta(insertMessageQuery)).
+		WithArgs(int64(1), int64(1), "Small map, no barbarians").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(7, now))
+
+	message, err := sendChatMessage(NewPostgresLobbyRepository(sqlx.NewDb(db, "sqlmock")), 1, 1, "  Small map, no barbarians ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if message.ID != 7 || message.Message != "Small map, no barbarians" {
+		t.Errorf("unexpected message: %+v", message)
+	}
+
+	event, ok := receiveEvent(t, events)
+	if !ok || event.Type != EventChatMessage || event.Message == nil || event.Message.ID != 7 {
+		t.Errorf("unexpected event: %+v", event)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSendChatMessage_RateLimited(t *testing.T) {
+	chatLimiter = newChatRateLimiter()
+	for i := 0; i < ChatRateLimit; i++ {
+		chatLimiter.allow(1, 2, time.Now())
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	expectChatter(mock, 1, 2, "1", false, false)
+
+	_, err = sendChatMessage(NewPostgresLobbyRepository(sqlx.NewDb(db, "sqlmock")), 1, 2, "gg")
+	if status := httpapitest.Status(err); status != http.StatusTooManyRequests {
+		t.Errorf("got status %v want %v", status, http.StatusTooManyRequests)
+	}
+	if err == nil || err.Error() != ERROR_CHAT_RATE_LIMITED {
+		t.Errorf("got error %v want %v", err, ERROR_CHAT_RATE_LIMITED)
+	}
+}
@@ -1,13 +1,14 @@
 package lobby
 
 import (
-	"encoding/json"
 	"errors"
+	"fmt"
+	"log"
 	"net/http"
 	"strconv"
 
-	"github.com/jmoiron/sqlx"
 	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
 )
 
 // CreateLobbyArgs represents the expected structure of the request body for creating a lobby for use within the server.
@@ -17,84 +18,114 @@ type CreateLobbyArgs struct {
 	// The lobby to create.
 	Lobby Lobby `json:"lobby"`
 
-	// FIXME make passwords actually get stored with lobbies
-	// The password for the lobby to be created
-	Password string `json:"password"`
+	// The password for the lobby to be created. It is required to join the lobby while it is private.
+	Password string `json:"password" validate:"required,password"`
 }
 
-const ERROR_PASSWORD_TOO_SHORT = "password must be longer than 6 characters"
-const ERROR_PASSWORD_REQUIRED_BUT_NO_PASSWORD = "password is required"
-
 // CreateLobby handles the creation of a new lobby.
 //
 // @Summary Create a new lobby
-// @Description This endpoint creates a new multiplayer lobby, protected by a password.
+// @Description This endpoint creates a new multiplayer lobby, protected by a password. The caller becomes the owner; the owner name is taken from their account.
 // @Tags lobby
 // @Accept json
 // @Produce json
+// @Param Authorization header string true "Bearer session token"
 // @Param body body CreateLobbyArgs true "lobby creation request body"
-// @Success 201 {string} string "Lobby successfully created"
-// @Failure 400 {object} error "Bad Request"
-// @Failure 403 {object} error "Forbidden"
-// @Failure 500 {object} error "Internal Server Error"
+// @Success 201 {object} httpapi.MessageResponse "Successfully created lobby!"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
 // @Router /lobby/create_lobby [post]
-func CreateLobby(w http.ResponseWriter, r *http.Request, db *sqlx.DB, store *auth.SessionStore) error {
+func CreateLobby(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) error {
 
 	if r.Method != "POST" {
-		return errors.New("invalid request; request must be a POST request")
+		return httpapi.MethodNotAllowed(http.MethodPost)
 	}
 
-	decoder := json.NewDecoder(r.Body)
-	decoder.DisallowUnknownFields()
+	if _, err := createLobby(r, lobbies, store); err != nil {
+		return err
+	}
 
-	lobby := CreateLobbyArgs{}
-	err := decoder.Decode(&lobby)
+	httpapi.WriteMessage(w, http.StatusCreated, "Successfully created lobby!")
+	return nil
+}
 
+// CreateLobbyV2 creates a new lobby and responds with it, along with its URL in the Location header.
+//
+// @Summary Create a new lobby
+// @Description This endpoint creates a new multiplayer lobby, protected by a password. The caller becomes the owner; the owner name is taken from their account. The new lobby's URL is in the Location header.
+// @Tags lobby
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Param body body CreateLobbyArgs true "lobby creation request body"
+// @Success 201 {object} lobby.Lobby "Lobby successfully created"
+// @Header 201 {string} Location "/v2/lobbies/{id}"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /v2/lobbies [post]
+func CreateLobbyV2(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) error {
+	lobby, err := createLobby(r, lobbies, store)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-
-		return errors.New("an error occurred while decoding the request body:" + err.Error())
+		return err
 	}
 
-	if lobby.Password == "" {
-		w.WriteHeader(http.StatusBadRequest)
+	w.Header().Set("Location", fmt.Sprintf("/v2/lobbies/%d", lobby.ID))
+	httpapi.WriteJSON(w, http.StatusCreated, lobby)
+	return nil
+}
+
+// createLobby creates the lobby in the request body, owned by the caller, and returns it.
+func createLobby(r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) (*Lobby, error) {
+	lobby := CreateLobbyArgs{}
+	err := httpapi.DecodeJSON(r, &lobby)
+	if err != nil {
+		return nil, err
+	}
 
-		return errors.New(ERROR_PASSWORD_REQUIRED_BUT_NO_PASSWORD)
+	if err := httpapi.Validate(&lobby); err != nil {
+		return nil, err
 	}
 
-	if _, err := strconv.ParseInt(lobby.Lobby.OwnerAccountId, 10, 64); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		return errors.New("OwnerAccountId must be a valid number")
+	session, err := store.Authenticate(r, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(lobby.Password) < 6 {
-		w.WriteHeader(http.StatusBadRequest)
+	// The owner is always the caller; a client which still sends its own account ID must send the right one
+	if lobby.Lobby.OwnerAccountId != "" {
+		ownerAccountID, err := strconv.ParseInt(lobby.Lobby.OwnerAccountId, 10, 64)
+		if err != nil {
+			return nil, httpapi.Validation("OwnerAccountId must be a valid number")
+		}
 
-		return errors.New(ERROR_PASSWORD_TOO_SHORT)
+		if err := auth.RequireAccount(session, ownerAccountID); err != nil {
+			return nil, err
+		}
 	}
 
-	err = storeLobby(&lobby.Lobby, db)
-
+	ownerName, err := lobbies.AccountName(int64(session.AccountID))
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		return errors.New("an error occurred while storing the lobby in the database: " + err.Error())
+		return nil, errors.New("an error occurred while getting the owner's account: " + err.Error())
 	}
 
-	w.WriteHeader(http.StatusCreated)
+	lobby.Lobby.OwnerAccountId = strconv.Itoa(session.AccountID)
+	lobby.Lobby.OwnerName = ownerName
 
-	// TODO store password if needed
-	return nil
-}
+	passwordHash, err := auth.HashPassword(lobby.Password)
+	if err != nil {
+		log.Println("error hashing a lobby password: ", err.Error())
+		return nil, errors.New("an error occurred while saving the password. Please try again later")
+	}
+
+	err = lobbies.CreateLobby(&lobby.Lobby, LobbyPassword{Hash: passwordHash})
 
-func storeLobby(lobby *Lobby, db *sqlx.DB) error {
-	result, err := db.Query(
-		"INSERT INTO lobby (name, owner_name, owner_account_id, is_closed, is_muted, is_public) VALUES ($1, $2, $3, $4, $5, $6)",
-		lobby.Name, lobby.OwnerName, lobby.OwnerAccountId, lobby.IsClosed, lobby.IsMuted, lobby.IsPublic,
-	)
 	if err != nil {
-		return errors.New("an error occurred while inserting a lobby into the database: " + err.Error())
+		return nil, errors.New("an error occurred while storing the lobby in the database: " + err.Error())
 	}
 
-	defer result.Close()
-	return nil
+	return &lobby.Lobby, nil
 }
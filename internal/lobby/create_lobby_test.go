@@ -5,12 +5,15 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
 	"strings"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
 	auth "github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi/httpapitest"
 )
 
 func TestCreateLobby_Success(t *testing.T) {
@@ -29,9 +32,18 @@ func TestCreateLobby_Success(t *testing.T) {
 		IsPublic:       true,
 	}
 
-	mock.ExpectQuery("INSERT INTO lobby \\(name, owner_name, owner_account_id, is_closed, is_muted, is_public\\) VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5, \\$6\\)").
-		WithArgs(lobby.Name, lobby.OwnerName, lobby.OwnerAccountId, lobby.IsClosed, lobby.IsMuted, lobby.IsPublic).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name FROM account WHERE id = $1")).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Account Name"))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO lobby \\(name, owner_name, owner_account_id, is_closed, is_muted, is_public, password_hash\\) VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5, \\$6, \\$7\\)").
+		WithArgs(lobby.Name, "Account Name", "1", lobby.IsClosed, lobby.IsMuted, lobby.IsPublic, sqlmock.AnyArg()).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO lobby_member (lobby_id, account_id) VALUES ($1, $2)")).
+		WithArgs(int64(1), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
 
 	lobbyBytes, err := json.Marshal(lobby)
 	if err != nil {
@@ -43,6 +55,7 @@ func TestCreateLobby_Success(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	req = withSession(req, 1)
 
 	rr := httptest.NewRecorder()
 	sqlxDB := sqlx.NewDb(db, "sqlmock")
@@ -52,9 +65,9 @@ func TestCreateLobby_Success(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := CreateLobby(w, r, sqlxDB, mockStore)
+		err := CreateLobby(w, r, NewPostgresLobbyRepository(sqlxDB), mockStore)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
@@ -63,6 +76,10 @@ func TestCreateLobby_Success(t *testing.T) {
 	if status := rr.Code; status != http.StatusCreated {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusCreated)
 	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
 }
 
 func TestCreateLobby_InvalidMethod(t *testing.T) {
@@ -85,21 +102,21 @@ func TestCreateLobby_InvalidMethod(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := CreateLobby(w, r, sqlxDB, mockStore)
+		err := CreateLobby(w, r, NewPostgresLobbyRepository(sqlxDB), mockStore)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
 	handler.ServeHTTP(rr, req)
 
-	if status := rr.Code; status != http.StatusInternalServerError {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+	if status := rr.Code; status != http.StatusMethodNotAllowed {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusMethodNotAllowed)
 	}
 
 	expectedError := "invalid request; request must be a POST request"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
 
@@ -123,21 +140,21 @@ func TestCreateLobby_DecodeError(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := CreateLobby(w, r, sqlxDB, mockStore)
+		err := CreateLobby(w, r, NewPostgresLobbyRepository(sqlxDB), mockStore)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
 	handler.ServeHTTP(rr, req)
 
-	if status := rr.Code; status != http.StatusInternalServerError {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
 	}
 
-	expectedError := "an error occurred while decoding the request body:json: cannot unmarshal number into Go struct field CreateLobbyArgs.password of type string"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	expectedError := "an error occurred while decoding the request body: json: cannot unmarshal number into Go struct field CreateLobbyArgs.password of type string"
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
 
@@ -176,7 +193,7 @@ func TestCreateLobby_PasswordTooShort(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := CreateLobby(w, r, sqlxDB, mockStore)
+		err := CreateLobby(w, r, NewPostgresLobbyRepository(sqlxDB), mockStore)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 		}
@@ -188,9 +205,9 @@ func TestCreateLobby_PasswordTooShort(t *testing.T) {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
 	}
 
-	expectedError := ERROR_PASSWORD_TOO_SHORT
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	expectedError := "password must be at least 6 characters"
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
 
@@ -214,7 +231,7 @@ func TestCreateLobby_PasswordRequired(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := CreateLobby(w, r, sqlxDB, mockStore)
+		err := CreateLobby(w, r, NewPostgresLobbyRepository(sqlxDB), mockStore)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 		}
@@ -226,8 +243,8 @@ func TestCreateLobby_PasswordRequired(t *testing.T) {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
 	}
 
-	expectedError := ERROR_PASSWORD_REQUIRED_BUT_NO_PASSWORD
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	expectedError := "password must be specified"
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
@@ -1,13 +1,11 @@
 package lobby
 
 import (
-	"encoding/json"
-	"errors"
 	"fmt"
 	"net/http"
 
-	"github.com/jmoiron/sqlx"
 	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
 )
 
 // DeleteLobbyArgs represents the expected structure of the request body for deleting a lobby.
@@ -15,7 +13,7 @@ import (
 // @Description Structure for the lobby deletion request payload.
 type DeleteLobbyArgs struct {
 	// The lobby ID for the lobby that will be deleted.
-	LobbyId int64 `json:"lobby_id"`
+	LobbyId int64 `json:"lobby_id" validate:"required"`
 }
 
 // DeleteLobby deletes a lobby by the lobby ID.
@@ -25,50 +23,74 @@ type DeleteLobbyArgs struct {
 // @Tags lobby
 // @Accept json
 // @Produce json
+// @Param Authorization header string true "Bearer session token"
 // @Param body body DeleteLobbyArgs true "lobby deletion request body"
-// @Success 200 {string} string "Successfully deleted lobby!"
-// @Failure 400 {object} error "Bad Request"
-// @Failure 403 {object} error "Forbidden"
-// @Failure 500 {object} error "Internal Server Error"
+// @Success 200 {object} httpapi.MessageResponse "Successfully deleted lobby!"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 404 {object} httpapi.ErrorResponse "Not Found"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
 // @Router /lobby/delete_lobby [delete]
-func DeleteLobby(w http.ResponseWriter, r *http.Request, db *sqlx.DB, store *auth.SessionStore) error {
+func DeleteLobby(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) error {
 
 	if r.Method != http.MethodDelete {
-		return errors.New("invalid request; request must be a DELETE request")
+		return httpapi.MethodNotAllowed(http.MethodDelete)
 	}
 
-	decoder := json.NewDecoder(r.Body)
-	decoder.DisallowUnknownFields()
-
 	args := DeleteLobbyArgs{}
-	err := decoder.Decode(&args)
+	err := httpapi.DecodeJSON(r, &args)
+	if err != nil {
+		return err
+	}
+
+	return deleteLobby(w, r, args, lobbies, store)
+}
 
+// DeleteLobbyV2 deletes the lobby with the ID in the path.
+//
+// @Summary Deletes a lobby
+// @Description This endpoint deletes a multiplayer lobby.
+// @Tags lobby
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Param id path int true "lobby ID"
+// @Success 200 {object} httpapi.MessageResponse "Successfully deleted lobby!"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 404 {object} httpapi.ErrorResponse "Not Found"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /v2/lobbies/{id} [delete]
+func DeleteLobbyV2(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) error {
+	lobbyId, err := httpapi.PathID(r, "id")
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		return errors.New("an error occurred while decoding the request body: " + err.Error())
+		return err
 	}
 
-	if args.LobbyId == 0 {
-		w.WriteHeader(http.StatusBadRequest)
-		return errors.New("lobby_id must be specified")
+	return deleteLobby(w, r, DeleteLobbyArgs{LobbyId: lobbyId}, lobbies, store)
+}
+
+// deleteLobby deletes the lobby in args. Only its owner can delete it.
+func deleteLobby(w http.ResponseWriter, r *http.Request, args DeleteLobbyArgs, lobbies LobbyRepository, store *auth.SessionStore) error {
+	if err := httpapi.Validate(&args); err != nil {
+		return err
 	}
 
-	query := "DELETE FROM lobby WHERE id = $1"
-	result, err := db.Exec(query, args.LobbyId)
-	if err != nil {
-		return fmt.Errorf("an error occurred while deleting the lobby with the ID %d: %v", args.LobbyId, err)
+	if _, err := authorizeLobbyOwner(r, lobbies, store, args.LobbyId); err != nil {
+		return err
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	err := lobbies.DeleteLobby(args.LobbyId)
+	if err == ErrLobbyNotFound {
+		return httpapi.NotFound(fmt.Sprintf("no lobby exists with the ID %d", args.LobbyId))
+	}
 	if err != nil {
-		return fmt.Errorf("an error occurred while checking the affected rows: %v", err)
+		return fmt.Errorf("an error occurred while deleting the lobby with the ID %d: %v", args.LobbyId, err)
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("no lobby exists with the ID %d", args.LobbyId)
-	}
+	Events.Publish(Event{Type: EventLobbyDeleted, LobbyId: args.LobbyId})
 
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("Successfully deleted lobby!"))
+	httpapi.WriteMessage(w, http.StatusOK, "Successfully deleted lobby!")
 	return nil
 }
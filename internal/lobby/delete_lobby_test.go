@@ -9,6 +9,8 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
 	auth "github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi/httpapitest"
 )
 
 func TestDeleteLobby_Success(t *testing.T) {
@@ -20,6 +22,8 @@ func TestDeleteLobby_Success(t *testing.T) {
 
 	lobbyID := int64(1)
 
+	expectLobbyOwner(mock, 1, "1")
+
 	mock.ExpectExec("DELETE FROM lobby WHERE id = \\$1").
 		WithArgs(lobbyID).
 		WillReturnResult(sqlmock.NewResult(1, 1))
@@ -28,6 +32,7 @@ func TestDeleteLobby_Success(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	req = withSession(req, 1)
 
 	rr := httptest.NewRecorder()
 	sqlxDB := sqlx.NewDb(db, "sqlmock")
@@ -37,9 +42,9 @@ func TestDeleteLobby_Success(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := DeleteLobby(w, r, sqlxDB, mockStore)
+		err := DeleteLobby(w, r, NewPostgresLobbyRepository(sqlxDB), mockStore)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
@@ -50,8 +55,8 @@ func TestDeleteLobby_Success(t *testing.T) {
 	}
 
 	expectedResponse := "Successfully deleted lobby!"
-	if strings.TrimSpace(rr.Body.String()) != expectedResponse {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedResponse)
+	if httpapitest.Message(rr) != expectedResponse {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedResponse)
 	}
 }
 
@@ -75,21 +80,21 @@ func TestDeleteLobby_InvalidMethod(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := DeleteLobby(w, r, sqlxDB, mockStore)
+		err := DeleteLobby(w, r, NewPostgresLobbyRepository(sqlxDB), mockStore)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
 	handler.ServeHTTP(rr, req)
 
-	if status := rr.Code; status != http.StatusInternalServerError {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+	if status := rr.Code; status != http.StatusMethodNotAllowed {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusMethodNotAllowed)
 	}
 
 	expectedError := "invalid request; request must be a DELETE request"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
 
@@ -113,21 +118,21 @@ func TestDeleteLobby_DecodeError(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := DeleteLobby(w, r, sqlxDB, mockStore)
+		err := DeleteLobby(w, r, NewPostgresLobbyRepository(sqlxDB), mockStore)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
 	handler.ServeHTTP(rr, req)
 
-	if status := rr.Code; status != http.StatusInternalServerError {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
 	}
 
 	expectedError := "an error occurred while decoding the request body: json: cannot unmarshal string into Go struct field DeleteLobbyArgs.lobby_id of type int64"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
 
@@ -151,7 +156,7 @@ func TestDeleteLobby_LobbyIDNotSpecified(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := DeleteLobby(w, r, sqlxDB, mockStore)
+		err := DeleteLobby(w, r, NewPostgresLobbyRepository(sqlxDB), mockStore)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 		}
@@ -164,8 +169,8 @@ func TestDeleteLobby_LobbyIDNotSpecified(t *testing.T) {
 	}
 
 	expectedError := "lobby_id must be specified"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
 
@@ -178,6 +183,8 @@ func TestDeleteLobby_NoRowsAffected(t *testing.T) {
 
 	lobbyID := int64(1)
 
+	expectLobbyOwner(mock, 1, "1")
+
 	mock.ExpectExec("DELETE FROM lobby WHERE id = \\$1").
 		WithArgs(lobbyID).
 		WillReturnResult(sqlmock.NewResult(1, 0))
@@ -186,6 +193,7 @@ func TestDeleteLobby_NoRowsAffected(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	req = withSession(req, 1)
 
 	rr := httptest.NewRecorder()
 	sqlxDB := sqlx.NewDb(db, "sqlmock")
@@ -195,20 +203,20 @@ func TestDeleteLobby_NoRowsAffected(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := DeleteLobby(w, r, sqlxDB, mockStore)
+		err := DeleteLobby(w, r, NewPostgresLobbyRepository(sqlxDB), mockStore)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
 	handler.ServeHTTP(rr, req)
 
-	if status := rr.Code; status != http.StatusInternalServerError {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusNotFound)
 	}
 
 	expectedError := "no lobby exists with the ID 1"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
@@ -0,0 +1,152 @@
+package lobby
+
+import (
+	"sync"
+	"time"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+)
+
+// EventType identifies what happened to a lobby.
+type EventType string
+
+const (
+	EventMemberJoined EventType = "member_joined"
+	EventMemberLeft   EventType = "member_left"
+	EventMemberKicked EventType = "member_kicked"
+	EventMemberBanned EventType = "member_banned"
+	EventReadyChanged EventType = "ready_changed"
+	EventLobbyUpdated EventType = "lobby_updated"
+	EventLobbyDeleted EventType = "lobby_deleted"
+	EventMemberMuted  EventType = "member_muted"
+	EventChatMessage  EventType = "chat_message"
+	EventOwnerChanged EventType = "owner_changed"
+	// EventError is only sent to the client whose chat message was rejected.
+	EventError EventType = "error"
+)
+
+// eventBufferSize is how many events can be queued for a subscriber before it is considered too slow and dropped.
+const eventBufferSize = 32
+
+// Event is a change to a lobby which is pushed to the lobby's subscribers.
+//
+// @Description Structure for representing a realtime lobby event.
+type Event struct {
+	// Type is what happened to the lobby.
+	Type EventType `json:"type"`
+
+	// LobbyId is the lobby the event happened in.
+	LobbyId int64 `json:"lobby_id"`
+
+	// AccountId is the account the event is about, for member events, or the new owner, for owner_changed events.
+	AccountId int64 `json:"account_id,omitempty"`
+
+	// Ready is the member's new ready state, for ready_changed events.
+	Ready *bool `json:"ready,omitempty"`
+
+	// Lobby holds the settings which changed, for lobby_updated events.
+	Lobby *LobbyParam `json:"lobby,omitempty"`
+
+	// Muted is the member's new mute state, for member_muted events.
+	Muted *bool `json:"muted,omitempty"`
+
+	// Message is the chat message which was sent, for chat_message events.
+	Message *Message `json:"message,omitempty"`
+
+	// Error explains why the client's chat message was rejected, for error events.
+	Error string `json:"error,omitempty"`
+
+	// ErrorCode is the machine-readable code of the error, for error events.
+	ErrorCode httpapi.Code `json:"error_code,omitempty"`
+
+	// Time is when the event happened.
+	Time time.Time `json:"time"`
+}
+
+// Hub is an in-process publish/subscribe hub for lobby events.
+type Hub struct {
+	mu sync.RWMutex
+	// subscribers holds the account each subscriber of a lobby subscribed as.
+	subscribers map[int64]map[chan Event]int64
+}
+
+// NewHub creates an empty Hub.
+func NewHub() *Hub {
+	return &Hub{subscribers: make(map[int64]map[chan Event]int64)}
+}
+
+// Events is the hub which the lobby handlers publish to.
+var Events = NewHub()
+
+// Subscribe returns a channel which receives the events published for a lobby on behalf of an account,
+// and a function which unsubscribes. The channel is closed when the subscription ends: when unsubscribe
+// is called, when the subscriber falls too far behind, when the lobby is deleted, or when the account
+// leaves the lobby or is kicked or banned from it, after the event saying so.
+func (h *Hub) Subscribe(lobbyID int64, accountID int64) (<-chan Event, func()) {
+	ch := make(chan Event, eventBufferSize)
+
+	h.mu.Lock()
+	if h.subscribers[lobbyID] == nil {
+		h.subscribers[lobbyID] = make(map[chan Event]int64)
+	}
+	h.subscribers[lobbyID][ch] = accountID
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		h.remove(lobbyID, ch)
+		h.mu.Unlock()
+	}
+}
+
+// Publish sends an event to every subscriber of the event's lobby without blocking.
+func (h *Hub) Publish(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subscribers[event.LobbyId] {
+		select {
+		case ch <- event:
+		default:
+			// A subscriber which cannot keep up is dropped rather than holding up everyone else
+			h.remove(event.LobbyId, ch)
+		}
+	}
+
+	// Nobody should stay subscribed to a lobby which no longer exists, or which they are no longer in
+	switch event.Type {
+	case EventLobbyDeleted:
+		for ch := range h.subscribers[event.LobbyId] {
+			h.remove(event.LobbyId, ch)
+		}
+	case EventMemberLeft, EventMemberKicked, EventMemberBanned:
+		for ch, accountID := range h.subscribers[event.LobbyId] {
+			if accountID == event.AccountId {
+				h.remove(event.LobbyId, ch)
+			}
+		}
+	}
+}
+
+// remove closes and forgets a subscriber. h.mu must be held.
+func (h *Hub) remove(lobbyID int64, ch chan Event) {
+	subscribers, ok := h.subscribers[lobbyID]
+	if !ok {
+		return
+	}
+
+	if _, ok := subscribers[ch]; !ok {
+		return
+	}
+
+	delete(subscribers, ch)
+	close(ch)
+
+	if len(subscribers) == 0 {
+		delete(h.subscribers, lobbyID)
+	}
+}
@@ -0,0 +1,126 @@
+package lobby
+
+import (
+	"testing"
+	"time"
+)
+
+func receiveEvent(t *testing.T, events <-chan Event) (Event, bool) {
+	t.Helper()
+
+	select {
+	case event, ok := <-events:
+		return event, ok
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for an event")
+		return Event{}, false
+	}
+}
+
+func TestHub_PublishesToSubscribersOfTheLobby(t *testing.T) {
+	hub := NewHub()
+
+	events, unsubscribe := hub.Subscribe(1, 0)
+	defer unsubscribe()
+
+	otherEvents, unsubscribeOther := hub.Subscribe(2, 0)
+	defer unsubscribeOther()
+
+	hub.Publish(Event{Type: EventMemberJoined, LobbyId: 1, AccountId: 3})
+
+	event, ok := receiveEvent(t, events)
+	if !ok || event.Type != EventMemberJoined || event.AccountId != 3 {
+		t.Errorf("unexpected event: %+v", event)
+	}
+
+	if event.Time.IsZero() {
+		t.Error("expected the event time to be set")
+	}
+
+	select {
+	case event := <-otherEvents:
+		t.Errorf("a subscriber of another lobby received %+v", event)
+	default:
+	}
+}
+
+func TestHub_UnsubscribeClosesChannel(t *testing.T) {
+	hub := NewHub()
+
+	events, unsubscribe := hub.Subscribe(1, 0)
+	unsubscribe()
+	// Unsubscribing twice must be safe
+	unsubscribe()
+
+	if _, ok := receiveEvent(t, events); ok {
+		t.Error("expected the channel to be closed")
+	}
+
+	if len(hub.subscribers) != 0 {
+		t.Errorf("expected no subscribers to be left, got %d lobbies", len(hub.subscribers))
+	}
+}
+
+func TestHub_DropsSlowSubscribers(t *testing.T) {
+	hub := NewHub()
+
+	events, unsubscribe := hub.Subscribe(1, 0)
+	defer unsubscribe()
+
+	for i := 0; i < eventBufferSize+1; i++ {
+		hub.Publish(Event{Type: EventLobbyUpdated, LobbyId: 1})
+	}
+
+	received := 0
+	for range events {
+		received++
+	}
+
+	if received != eventBufferSize {
+		t.Errorf("expected %d buffered events before the subscriber was dropped, got %d", eventBufferSize, received)
+	}
+}
+
+func TestHub_LobbyDeletedEndsSubscriptions(t *testing.T) {
+	hub := NewHub()
+
+	events, unsubscribe := hub.Subscribe(1, 0)
+	defer unsubscribe()
+
+	hub.Publish(Event{Type: EventLobbyDeleted, LobbyId: 1})
+
+	event, ok := receiveEvent(t, events)
+	if !ok || event.Type != EventLobbyDeleted {
+		t.Errorf("expected a lobby_deleted event, got %+v", event)
+	}
+
+	if _, ok := receiveEvent(t, events); ok {
+		t.Error("expected the channel to be closed after the lobby was deleted")
+	}
+}
+
+func TestHub_RemovedMemberSubscriptionsEnd(t *testing.T) {
+	hub := NewHub()
+
+	removed, unsubscribeRemoved := hub.Subscribe(1, 2)
+	defer unsubscribeRemoved()
+	other, unsubscribeOther := hub.Subscribe(1, 3)
+	defer unsubscribeOther()
+
+	hub.Publish(Event{Type: EventMemberKicked, LobbyId: 1, AccountId: 2})
+
+	if event, ok := receiveEvent(t, removed); !ok || event.Type != EventMemberKicked {
+		t.Errorf("expected the kicked member to be told, got %+v", event)
+	}
+	if _, ok := receiveEvent(t, removed); ok {
+		t.Error("expected the kicked member's channel to be closed")
+	}
+
+	if event, ok := receiveEvent(t, other); !ok || event.Type != EventMemberKicked {
+		t.Errorf("expected the other member to stay subscribed, got %+v", event)
+	}
+	hub.Publish(Event{Type: EventLobbyUpdated, LobbyId: 1})
+	if event, ok := receiveEvent(t, other); !ok || event.Type != EventLobbyUpdated {
+		t.Errorf("expected the other member to keep receiving events, got %+v", event)
+	}
+}
@@ -1,72 +1,77 @@
 package lobby
 
 import (
-	"database/sql"
-	"encoding/json"
-	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 
-	"github.com/jmoiron/sqlx"
 	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
 )
 
-// GetLobbyArgs represents the expected structure of the request body for getting a lobby.
-//
-// @Description Structure for the lobby acquisition request payload.
-type GetLobbyArgs struct {
-	// The lobby ID for the lobby that will be retrieved.
-	LobbyId int8 `json:"lobby_id"`
-}
-
 // GetLobby gets a lobby by the lobby ID.
 //
 // @Summary Gets a lobby
-// @Description This endpoint gets a multiplayer lobby's info.
+// @Description This endpoint gets a multiplayer lobby's info. The lobby password is never returned.
 // @Tags lobby
-// @Accept json
 // @Produce json
-// @Param body body GetLobbyArgs true "lobby acquisition request body"
+// @Param lobby_id query int true "lobby ID"
 //
 // @Success 200 {object} lobby.Lobby "Lobby successfully retrieved"
-// @Failure 400 {object} error "Bad Request"
-// @Failure 403 {object} error "Forbidden"
-// @Failure 500 {object} error "Internal Server Error"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 404 {object} httpapi.ErrorResponse "Not Found"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
 // @Router /lobby/get_lobby [get]
-func GetLobby(w http.ResponseWriter, r *http.Request, db *sqlx.DB, store *auth.SessionStore) error {
+func GetLobby(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) error {
 
 	if r.Method != "GET" {
-		return errors.New("invalid request; request must be a GET request")
+		return httpapi.MethodNotAllowed(http.MethodGet)
 	}
 
-	decoder := json.NewDecoder(r.Body)
-	decoder.DisallowUnknownFields()
-
-	argsGotten := GetLobbyArgs{}
-	err := decoder.Decode(&argsGotten)
+	lobbyIdStr := r.URL.Query().Get("lobby_id")
+	if lobbyIdStr == "" {
+		return httpapi.Validation("lobby_id is required")
+	}
 
+	lobbyId, err := strconv.ParseInt(lobbyIdStr, 10, 64)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		return errors.New("an error occurred while decoding the request body: " + err.Error())
+		return httpapi.Validation("invalid lobby_id")
 	}
 
-	var lobby Lobby
+	return getLobby(w, lobbyId, lobbies)
+}
 
-	query := "SELECT id, name, owner_name, is_closed, is_muted, is_public FROM lobby WHERE id = $1"
-	if err := db.Get(&lobby, query, argsGotten.LobbyId); err != nil {
-		if err == sql.ErrNoRows {
-			return fmt.Errorf("no lobby exists with the ID %d", argsGotten.LobbyId)
-		}
-		return fmt.Errorf("an error occurred while getting the lobby with the ID %d: %v", argsGotten.LobbyId, err)
+// GetLobbyV2 gets the lobby with the ID in the path.
+//
+// @Summary Gets a lobby
+// @Description This endpoint gets a multiplayer lobby's info. The lobby password is never returned.
+// @Tags lobby
+// @Produce json
+// @Param id path int true "lobby ID"
+// @Success 200 {object} lobby.Lobby "Lobby successfully retrieved"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 404 {object} httpapi.ErrorResponse "Not Found"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /v2/lobbies/{id} [get]
+func GetLobbyV2(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) error {
+	lobbyId, err := httpapi.PathID(r, "id")
+	if err != nil {
+		return err
 	}
 
-	lobbyBytes, err := json.Marshal(lobby)
+	return getLobby(w, lobbyId, lobbies)
+}
+
+// getLobby writes the lobby with the given ID.
+func getLobby(w http.ResponseWriter, lobbyId int64, lobbies LobbyRepository) error {
+	lobby, err := lobbies.GetLobby(lobbyId)
+	if err == ErrLobbyNotFound {
+		return httpapi.NotFound(fmt.Sprintf("no lobby exists with the ID %d", lobbyId))
+	}
 	if err != nil {
-		return fmt.Errorf("Error marshalling struct: %v", err)
+		return fmt.Errorf("an error occurred while getting the lobby with the ID %d: %v", lobbyId, err)
 	}
 
-	w.Write(lobbyBytes)
-
-	fmt.Println("Successfully got lobby!")
+	httpapi.WriteJSON(w, http.StatusOK, lobby)
 	return nil
 }
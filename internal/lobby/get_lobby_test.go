@@ -4,12 +4,13 @@ import (
 	"database/sql"
 	"net/http"
 	"net/http/httptest"
-	"strings"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
 	auth "github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi/httpapitest"
 )
 
 func TestGetLobby_Success(t *testing.T) {
@@ -30,12 +31,12 @@ func TestGetLobby_Success(t *testing.T) {
 		IsPublic:       true,
 	}
 
-	mock.ExpectQuery("SELECT id, name, owner_name, is_closed, is_muted, is_public FROM lobby WHERE id = \\$1").
+	mock.ExpectQuery("SELECT id, name, owner_name, owner_account_id, is_closed, is_muted, is_public FROM lobby WHERE id = \\$1").
 		WithArgs(lobbyID).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "owner_name", "owner_account_id", "is_closed", "is_muted", "is_public"}).
 			AddRow(expectedLobby.ID, expectedLobby.Name, expectedLobby.OwnerName, expectedLobby.OwnerAccountId, expectedLobby.IsClosed, expectedLobby.IsMuted, expectedLobby.IsPublic))
 
-	req, err := http.NewRequest("GET", "/lobby/get_lobby", strings.NewReader(`{"lobby_id": 1}`))
+	req, err := http.NewRequest("GET", "/lobby/get_lobby?lobby_id=1", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -48,9 +49,9 @@ func TestGetLobby_Success(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := GetLobby(w, r, sqlxDB, mockStore)
+		err := GetLobby(w, r, NewPostgresLobbyRepository(sqlxDB), mockStore)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
@@ -61,8 +62,8 @@ func TestGetLobby_Success(t *testing.T) {
 	}
 
 	expectedResponse := `{"id":1,"name":"Test Lobby","owner_name":"Owner","owner_account_id":"1","is_closed":false,"is_muted":false,"is_public":true}`
-	if strings.TrimSpace(rr.Body.String()) != expectedResponse {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedResponse)
+	if httpapitest.Message(rr) != expectedResponse {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedResponse)
 	}
 }
 
@@ -75,11 +76,11 @@ func TestGetLobby_NotFound(t *testing.T) {
 
 	lobbyID := int8(1)
 
-	mock.ExpectQuery("SELECT id, name, owner_name, is_closed, is_muted, is_public FROM lobby WHERE id = \\$1").
+	mock.ExpectQuery("SELECT id, name, owner_name, owner_account_id, is_closed, is_muted, is_public FROM lobby WHERE id = \\$1").
 		WithArgs(lobbyID).
 		WillReturnError(sql.ErrNoRows)
 
-	req, err := http.NewRequest("GET", "/lobby/get_lobby", strings.NewReader(`{"lobby_id": 1}`))
+	req, err := http.NewRequest("GET", "/lobby/get_lobby?lobby_id=1", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -92,21 +93,21 @@ func TestGetLobby_NotFound(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := GetLobby(w, r, sqlxDB, mockStore)
+		err := GetLobby(w, r, NewPostgresLobbyRepository(sqlxDB), mockStore)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
 	handler.ServeHTTP(rr, req)
 
-	if status := rr.Code; status != http.StatusInternalServerError {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusNotFound)
 	}
 
 	expectedError := "no lobby exists with the ID 1"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
 
@@ -117,7 +118,7 @@ func TestGetLobby_InvalidMethod(t *testing.T) {
 	}
 	defer db.Close()
 
-	req, err := http.NewRequest("POST", "/lobby/get_lobby", strings.NewReader(`{"lobby_id": 1}`))
+	req, err := http.NewRequest("POST", "/lobby/get_lobby?lobby_id=1", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -130,32 +131,32 @@ func TestGetLobby_InvalidMethod(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := GetLobby(w, r, sqlxDB, mockStore)
+		err := GetLobby(w, r, NewPostgresLobbyRepository(sqlxDB), mockStore)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
 	handler.ServeHTTP(rr, req)
 
-	if status := rr.Code; status != http.StatusInternalServerError {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+	if status := rr.Code; status != http.StatusMethodNotAllowed {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusMethodNotAllowed)
 	}
 
 	expectedError := "invalid request; request must be a GET request"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
 
-func TestGetLobby_DecodeError(t *testing.T) {
+func TestGetLobby_InvalidLobbyID(t *testing.T) {
 	db, _, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 	defer db.Close()
 
-	req, err := http.NewRequest("GET", "/lobby/get_lobby", strings.NewReader(`{"lobby_id": "invalid"}`))
+	req, err := http.NewRequest("GET", "/lobby/get_lobby?lobby_id=invalid", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -168,20 +169,20 @@ func TestGetLobby_DecodeError(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := GetLobby(w, r, sqlxDB, mockStore)
+		err := GetLobby(w, r, NewPostgresLobbyRepository(sqlxDB), mockStore)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
 	handler.ServeHTTP(rr, req)
 
-	if status := rr.Code; status != http.StatusInternalServerError {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
 	}
 
-	expectedError := "an error occurred while decoding the request body: json: cannot unmarshal string into Go struct field GetLobbyArgs.lobby_id of type int8"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	expectedError := "invalid lobby_id"
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
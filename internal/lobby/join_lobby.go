@@ -0,0 +1,152 @@
+package lobby
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+)
+
+// JoinLobbyArgs represents the expected structure of the request body for joining a lobby.
+//
+// @Description Structure for the lobby join request payload.
+type JoinLobbyArgs struct {
+	// The lobby ID for the lobby that will be joined.
+	LobbyId int64 `json:"lobby_id" validate:"required"`
+	// The lobby password. Only required for private lobbies.
+	Password string `json:"password,omitempty"`
+}
+
+// JoinLobbyBody represents the expected structure of the request body for joining a lobby through /v2,
+// which takes the lobby ID from the path. It may be left out for public lobbies.
+//
+// @Description Structure for the v2 lobby join request payload.
+type JoinLobbyBody struct {
+	// The lobby password. Only required for private lobbies.
+	Password string `json:"password,omitempty"`
+}
+
+// JoinLobby adds the caller to a lobby.
+//
+// @Summary Joins a lobby
+// @Description This endpoint adds the caller to a multiplayer lobby. Closed and full lobbies cannot be joined, and private lobbies require the lobby password. The lobby owner can always join a closed or private lobby.
+// @Tags lobby
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Param body body JoinLobbyArgs true "lobby join request body"
+// @Success 200 {object} httpapi.MessageResponse "Successfully joined lobby!"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 404 {object} httpapi.ErrorResponse "Not Found"
+// @Failure 409 {object} httpapi.ErrorResponse "Conflict"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /lobby/join_lobby [post]
+func JoinLobby(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) error {
+
+	if r.Method != http.MethodPost {
+		return httpapi.MethodNotAllowed(http.MethodPost)
+	}
+
+	args := JoinLobbyArgs{}
+	err := httpapi.DecodeJSON(r, &args)
+	if err != nil {
+		return err
+	}
+
+	return joinLobby(w, r, args, lobbies, store)
+}
+
+// JoinLobbyV2 adds the caller to the lobby with the ID in the path.
+//
+// @Summary Joins a lobby
+// @Description This endpoint adds the caller to a multiplayer lobby. Closed and full lobbies cannot be joined, and private lobbies require the lobby password. The lobby owner can always join a closed or private lobby.
+// @Tags lobby
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Param id path int true "lobby ID"
+// @Param body body JoinLobbyBody false "lobby join request body"
+// @Success 200 {object} httpapi.MessageResponse "Successfully joined lobby!"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 404 {object} httpapi.ErrorResponse "Not Found"
+// @Failure 409 {object} httpapi.ErrorResponse "Conflict"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /v2/lobbies/{id}/members [post]
+func JoinLobbyV2(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) error {
+	lobbyId, err := httpapi.PathID(r, "id")
+	if err != nil {
+		return err
+	}
+
+	body := JoinLobbyBody{}
+	if err := httpapi.DecodeOptionalJSON(r, &body); err != nil {
+		return err
+	}
+
+	return joinLobby(w, r, JoinLobbyArgs{LobbyId: lobbyId, Password: body.Password}, lobbies, store)
+}
+
+// joinLobby adds the caller to the lobby in args.
+func joinLobby(w http.ResponseWriter, r *http.Request, args JoinLobbyArgs, lobbies LobbyRepository, store *auth.SessionStore) error {
+	if err := httpapi.Validate(&args); err != nil {
+		return err
+	}
+
+	session, err := store.Authenticate(r, nil)
+	if err != nil {
+		return err
+	}
+	accountID := int64(session.AccountID)
+
+	access, err := lobbies.LobbyAccess(args.LobbyId)
+	if err == ErrLobbyNotFound {
+		return httpapi.NotFound(fmt.Sprintf("no lobby exists with the ID %d", args.LobbyId))
+	}
+	if err != nil {
+		return fmt.Errorf("an error occurred while getting the lobby with the ID %d: %v", args.LobbyId, err)
+	}
+
+	if access.OwnerAccountId != strconv.FormatInt(accountID, 10) {
+		banned, err := lobbies.IsBanned(args.LobbyId, accountID)
+		if err != nil {
+			return fmt.Errorf("an error occurred while checking the lobby bans: %v", err)
+		}
+
+		if banned {
+			return httpapi.Forbidden(ERROR_BANNED_FROM_LOBBY).WithCode(CodeBannedFromLobby)
+		}
+
+		if access.IsClosed {
+			return httpapi.Forbidden(ERROR_LOBBY_CLOSED).WithCode(CodeLobbyClosed)
+		}
+
+		if err := access.checkPassword(args.Password); err != nil {
+			return err
+		}
+	}
+
+	added, err := lobbies.AddMember(args.LobbyId, accountID)
+	if err == ErrLobbyFull {
+		return httpapi.Conflict(ERROR_LOBBY_FULL).WithCode(CodeLobbyFull)
+	}
+	if err == ErrLobbyNotFound {
+		return httpapi.NotFound(fmt.Sprintf("no lobby exists with the ID %d", args.LobbyId))
+	}
+	if err != nil {
+		return fmt.Errorf("an error occurred while joining the lobby with the ID %d: %v", args.LobbyId, err)
+	}
+
+	// Joining a lobby the caller is already in is not a change worth telling anyone about
+	if added {
+		Events.Publish(Event{Type: EventMemberJoined, LobbyId: args.LobbyId, AccountId: accountID})
+	}
+
+	httpapi.WriteMessage(w, http.StatusOK, "Successfully joined lobby!")
+	return nil
+}
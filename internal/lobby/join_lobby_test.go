@@ -0,0 +1,306 @@
+package lobby
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi/httpapitest"
+)
+
+const lobbyAccessQuery = "SELECT owner_account_id, is_closed, is_public, password_hash, password_salt FROM lobby WHERE id = $1"
+const lobbyBanQuery = "SELECT EXISTS (SELECT 1 FROM lobby_ban WHERE lobby_id = $1 AND account_id = $2)"
+
+var lobbyAccessColumns = []string{"owner_account_id", "is_closed", "is_public", "password_hash", "password_salt"}
+
+const lobbyRoomQuery = "SELECT max_members, (SELECT COUNT(*) FROM lobby_member WHERE lobby_id = $1) AS member_count, " +
+	"EXISTS (SELECT 1 FROM lobby_member WHERE lobby_id = $1 AND account_id = $2) AS is_member FROM lobby WHERE id = $1 FOR UPDATE"
+
+var lobbyRoomColumns = []string{"max_members", "member_count", "is_member"}
+
+// expectAddMember expects an account to be added to a lobby which has room for it.
+func expectAddMember(mock sqlmock.Sqlmock, lobbyID int64, accountID int64) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(lobbyRoomQuery)).
+		WithArgs(lobbyID, accountID).
+		WillReturnRows(sqlmock.NewRows(lobbyRoomColumns).AddRow(8, 1, false))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO lobby_member (lobby_id, account_id) VALUES ($1, $2)")).
+		WithArgs(lobbyID, accountID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+}
+
+func callJoinLobby(t *testing.T, sqlxDB *sqlx.DB, body string, accountID int) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req, err := http.NewRequest("POST", "/lobby/join_lobby", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = withSession(req, accountID)
+
+	rr := httptest.NewRecorder()
+	store := &auth.SessionStore{DB: sqlxDB}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		JoinLobbyHandler(w, r, NewPostgresLobbyRepository(sqlxDB), store)
+	})
+
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestJoinLobby_PublicLobby(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(lobbyAccessQuery)).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows(lobbyAccessColumns).AddRow("1", false, true, nil, nil))
+	mock.ExpectQuery(regexp.QuoteMeta(lobbyBanQuery)).
+		WithArgs(int64(1), int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	expectAddMember(mock, 1, 2)
+
+	rr := callJoinLobby(t, sqlx.NewDb(db, "sqlmock"), `{"lobby_id": 1}`, 2)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	expectedResponse := "Successfully joined lobby!"
+	if httpapitest.Message(rr) != expectedResponse {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedResponse)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestJoinLobby_PrivateLobbyPasswords(t *testing.T) {
+	hash, err := auth.HashPassword("password123")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		password   string
+		wantStatus int
+	}{
+		{name: "correct password", password: "password123", wantStatus: http.StatusOK},
+		{name: "wrong password", password: "wrongpassword", wantStatus: http.StatusForbidden},
+		{name: "no password", password: "", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer db.Close()
+
+			mock.ExpectQuery(regexp.QuoteMeta(lobbyAccessQuery)).
+				WithArgs(int64(1)).
+				WillReturnRows(sqlmock.NewRows(lobbyAccessColumns).AddRow("1", false, false, hash, nil))
+			mock.ExpectQuery(regexp.QuoteMeta(lobbyBanQuery)).
+				WithArgs(int64(1), int64(2)).
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+			if tt.wantStatus == http.StatusOK {
+				expectAddMember(mock, 1, 2)
+			}
+
+			rr := callJoinLobby(t, sqlx.NewDb(db, "sqlmock"), `{"lobby_id": 1, "password": "`+tt.password+`"}`, 2)
+
+			if status := rr.Code; status != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestJoinLobby_Closed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(lobbyAccessQuery)).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows(lobbyAccessColumns).AddRow("1", true, true, nil, nil))
+	mock.ExpectQuery(regexp.QuoteMeta(lobbyBanQuery)).
+		WithArgs(int64(1), int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	rr := callJoinLobby(t, sqlx.NewDb(db, "sqlmock"), `{"lobby_id": 1}`, 2)
+
+	if status := rr.Code; status != http.StatusForbidden {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusForbidden)
+	}
+
+	if httpapitest.Message(rr) != ERROR_LOBBY_CLOSED {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), ERROR_LOBBY_CLOSED)
+	}
+}
+
+func TestJoinLobby_Banned(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(lobbyAccessQuery)).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows(lobbyAccessColumns).AddRow("1", false, true, nil, nil))
+	mock.ExpectQuery(regexp.QuoteMeta(lobbyBanQuery)).
+		WithArgs(int64(1), int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	rr := callJoinLobby(t, sqlx.NewDb(db, "sqlmock"), `{"lobby_id": 1}`, 2)
+
+	if status := rr.Code; status != http.StatusForbidden {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusForbidden)
+	}
+
+	if httpapitest.Message(rr) != ERROR_BANNED_FROM_LOBBY {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), ERROR_BANNED_FROM_LOBBY)
+	}
+}
+
+func TestJoinLobby_OwnerBypassesClosedAndPassword(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(lobbyAccessQuery)).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows(lobbyAccessColumns).AddRow("1", true, false, "aGFzaA==", "c2FsdA=="))
+	expectAddMember(mock, 1, 1)
+
+	rr := callJoinLobby(t, sqlx.NewDb(db, "sqlmock"), `{"lobby_id": 1}`, 1)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestJoinLobby_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(lobbyAccessQuery)).
+		WithArgs(int64(1)).
+		WillReturnError(sql.ErrNoRows)
+
+	rr := callJoinLobby(t, sqlx.NewDb(db, "sqlmock"), `{"lobby_id": 1}`, 2)
+
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusNotFound)
+	}
+}
+
+func TestJoinLobby_Full(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(lobbyAccessQuery)).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows(lobbyAccessColumns).AddRow("1", false, true, nil, nil))
+	mock.ExpectQuery(regexp.QuoteMeta(lobbyBanQuery)).
+		WithArgs(int64(1), int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(lobbyRoomQuery)).
+		WithArgs(int64(1), int64(2)).
+		WillReturnRows(sqlmock.NewRows(lobbyRoomColumns).AddRow(8, 8, false))
+	mock.ExpectRollback()
+
+	rr := callJoinLobby(t, sqlx.NewDb(db, "sqlmock"), `{"lobby_id": 1}`, 2)
+
+	if status := rr.Code; status != http.StatusConflict {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusConflict)
+	}
+
+	if code := httpapitest.Code(rr); code != CodeLobbyFull {
+		t.Errorf("handler returned unexpected code: got %v want %v", code, CodeLobbyFull)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPostgresLobbyRepository_AddMemberExistingMember(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// A member of a full lobby is already in it, so is not refused
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(lobbyRoomQuery)).
+		WithArgs(int64(1), int64(2)).
+		WillReturnRows(sqlmock.NewRows(lobbyRoomColumns).AddRow(8, 8, true))
+	mock.ExpectRollback()
+
+	added, err := NewPostgresLobbyRepository(sqlx.NewDb(db, "sqlmock")).AddMember(1, 2)
+	if err != nil || added {
+		t.Errorf("expected the member not to be added again, got %v, %v", added, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPostgresLobbyRepository_AddMemberInsertError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(lobbyRoomQuery)).
+		WithArgs(int64(1), int64(2)).
+		WillReturnRows(sqlmock.NewRows(lobbyRoomColumns).AddRow(8, 1, false))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO lobby_member (lobby_id, account_id) VALUES ($1, $2)")).
+		WithArgs(int64(1), int64(2)).
+		WillReturnError(sql.ErrConnDone)
+	mock.ExpectRollback()
+
+	added, err := NewPostgresLobbyRepository(sqlx.NewDb(db, "sqlmock")).AddMember(1, 2)
+	if err == nil || added {
+		t.Errorf("expected the error to be returned, got %v, %v", added, err)
+	}
+}
@@ -0,0 +1,137 @@
+package lobby
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+)
+
+// KickMemberArgs represents the expected structure of the request body for kicking a member from a lobby.
+//
+// @Description Structure for the lobby kick request payload.
+type KickMemberArgs struct {
+	// The lobby ID for the lobby the member will be kicked from.
+	LobbyId int64 `json:"lobby_id" validate:"required"`
+	// The account ID of the member who will be kicked.
+	AccountId int64 `json:"account_id" validate:"required"`
+	// Whether the member should also be banned from rejoining the lobby.
+	Ban bool `json:"ban,omitempty"`
+}
+
+// KickMember removes a member from a lobby, optionally banning them from rejoining. Only the lobby owner can kick members.
+//
+// @Summary Kicks a lobby member
+// @Description This endpoint lets the lobby owner remove a member from the lobby, and optionally ban them from rejoining it.
+// @Tags lobby
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Param body body KickMemberArgs true "lobby kick request body"
+// @Success 200 {object} httpapi.MessageResponse "Successfully kicked member!"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 404 {object} httpapi.ErrorResponse "Not Found"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /lobby/kick_member [post]
+func KickMember(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) error {
+
+	if r.Method != http.MethodPost {
+		return httpapi.MethodNotAllowed(http.MethodPost)
+	}
+
+	args := KickMemberArgs{}
+	err := httpapi.DecodeJSON(r, &args)
+	if err != nil {
+		return err
+	}
+
+	return kickMember(w, r, args, lobbies, store)
+}
+
+// KickMemberV2 removes the member with the account ID in the path from the lobby with the ID in the path.
+//
+// @Summary Kicks a lobby member
+// @Description This endpoint lets the lobby owner remove a member from the lobby, and optionally ban them from rejoining it. Pass ban=true to ban them.
+// @Tags lobby
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Param id path int true "lobby ID"
+// @Param account_id path int true "account ID of the member"
+// @Param ban query bool false "also ban the account from the lobby"
+// @Success 200 {object} httpapi.MessageResponse "Successfully kicked member!"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 404 {object} httpapi.ErrorResponse "Not Found"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /v2/lobbies/{id}/members/{account_id} [delete]
+func KickMemberV2(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) error {
+	lobbyId, err := httpapi.PathID(r, "id")
+	if err != nil {
+		return err
+	}
+
+	accountId, err := httpapi.PathID(r, "account_id")
+	if err != nil {
+		return err
+	}
+
+	ban := false
+	if banStr := r.URL.Query().Get("ban"); banStr != "" {
+		ban, err = strconv.ParseBool(banStr)
+		if err != nil {
+			return httpapi.Validation("ban must be true or false")
+		}
+	}
+
+	return kickMember(w, r, KickMemberArgs{LobbyId: lobbyId, AccountId: accountId, Ban: ban}, lobbies, store)
+}
+
+// kickMember removes, and optionally bans, the member in args from their lobby. Only the lobby owner can do so.
+func kickMember(w http.ResponseWriter, r *http.Request, args KickMemberArgs, lobbies LobbyRepository, store *auth.SessionStore) error {
+	if err := httpapi.Validate(&args); err != nil {
+		return err
+	}
+
+	session, err := authorizeLobbyOwner(r, lobbies, store, args.LobbyId)
+	if err != nil {
+		return err
+	}
+
+	if int64(session.AccountID) == args.AccountId {
+		return httpapi.Validation(ERROR_CANNOT_KICK_OWNER).WithCode(CodeCannotKickOwner)
+	}
+
+	// Ban before removing the member so that they cannot rejoin in between
+	if args.Ban {
+		if err := lobbies.Ban(args.LobbyId, args.AccountId); err != nil {
+			return fmt.Errorf("an error occurred while banning account %d from the lobby with the ID %d: %v", args.AccountId, args.LobbyId, err)
+		}
+	}
+
+	removed, err := lobbies.RemoveMember(args.LobbyId, args.AccountId)
+	if err != nil {
+		return fmt.Errorf("an error occurred while kicking account %d from the lobby with the ID %d: %v", args.AccountId, args.LobbyId, err)
+	}
+
+	// Banning an account which is not currently in the lobby is still useful
+	if args.Ban {
+		Events.Publish(Event{Type: EventMemberBanned, LobbyId: args.LobbyId, AccountId: args.AccountId})
+
+		httpapi.WriteMessage(w, http.StatusOK, "Successfully banned member!")
+		return nil
+	}
+
+	if !removed {
+		return httpapi.NotFound(fmt.Sprintf("account %d is not a member of the lobby with the ID %d", args.AccountId, args.LobbyId)).WithCode(CodeNotLobbyMember)
+	}
+
+	Events.Publish(Event{Type: EventMemberKicked, LobbyId: args.LobbyId, AccountId: args.AccountId})
+
+	httpapi.WriteMessage(w, http.StatusOK, "Successfully kicked member!")
+	return nil
+}
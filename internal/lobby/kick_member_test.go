@@ -0,0 +1,128 @@
+package lobby
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi/httpapitest"
+)
+
+func callKickMember(t *testing.T, sqlxDB *sqlx.DB, body string, accountID int) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req, err := http.NewRequest("POST", "/lobby/kick_member", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = withSession(req, accountID)
+
+	rr := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		KickMemberHandler(w, r, NewPostgresLobbyRepository(sqlxDB), &auth.SessionStore{DB: sqlxDB})
+	})
+
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestKickMember_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	expectLobbyOwner(mock, 1, "1")
+	mock.ExpectExec("DELETE FROM lobby_member WHERE lobby_id = \\$1 AND account_id = \\$2").
+		WithArgs(int64(1), int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	rr := callKickMember(t, sqlx.NewDb(db, "sqlmock"), `{"lobby_id": 1, "account_id": 2}`, 1)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	expectedResponse := "Successfully kicked member!"
+	if httpapitest.Message(rr) != expectedResponse {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedResponse)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestKickMember_Ban(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	expectLobbyOwner(mock, 1, "1")
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO lobby_ban (lobby_id, account_id) VALUES ($1, $2) ON CONFLICT (lobby_id, account_id) DO NOTHING")).
+		WithArgs(int64(1), int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM lobby_member WHERE lobby_id = \\$1 AND account_id = \\$2").
+		WithArgs(int64(1), int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	rr := callKickMember(t, sqlx.NewDb(db, "sqlmock"), `{"lobby_id": 1, "account_id": 2, "ban": true}`, 1)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	expectedResponse := "Successfully banned member!"
+	if httpapitest.Message(rr) != expectedResponse {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedResponse)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestKickMember_NotOwner(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	expectLobbyOwner(mock, 1, "1")
+
+	rr := callKickMember(t, sqlx.NewDb(db, "sqlmock"), `{"lobby_id": 1, "account_id": 3}`, 2)
+
+	if status := rr.Code; status != http.StatusForbidden {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusForbidden)
+	}
+}
+
+func TestKickMember_CannotKickOwner(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	expectLobbyOwner(mock, 1, "1")
+
+	rr := callKickMember(t, sqlx.NewDb(db, "sqlmock"), `{"lobby_id": 1, "account_id": 1}`, 1)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+	}
+
+	if httpapitest.Message(rr) != ERROR_CANNOT_KICK_OWNER {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), ERROR_CANNOT_KICK_OWNER)
+	}
+}
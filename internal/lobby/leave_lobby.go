@@ -0,0 +1,109 @@
+package lobby
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+)
+
+// LeaveLobbyArgs represents the expected structure of the request body for leaving a lobby.
+//
+// @Description Structure for the lobby leave request payload.
+type LeaveLobbyArgs struct {
+	// The lobby ID for the lobby that will be left.
+	LobbyId int64 `json:"lobby_id" validate:"required"`
+}
+
+// LeaveLobby removes the caller from a lobby. When the owner leaves, the member who has been in the
+// lobby longest becomes the owner, and a lobby with nobody left in it is deleted.
+//
+// @Summary Leaves a lobby
+// @Description This endpoint removes the caller from a multiplayer lobby they have joined. If the caller owns the lobby, ownership passes to the member who joined earliest, or the lobby is deleted if it is empty.
+// @Tags lobby
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Param body body LeaveLobbyArgs true "lobby leave request body"
+// @Success 200 {object} httpapi.MessageResponse "Successfully left lobby!"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 404 {object} httpapi.ErrorResponse "Not Found"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /lobby/leave_lobby [post]
+func LeaveLobby(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) error {
+
+	if r.Method != http.MethodPost {
+		return httpapi.MethodNotAllowed(http.MethodPost)
+	}
+
+	args := LeaveLobbyArgs{}
+	err := httpapi.DecodeJSON(r, &args)
+	if err != nil {
+		return err
+	}
+
+	return leaveLobby(w, r, args, lobbies, store)
+}
+
+// LeaveLobbyV2 removes the caller from the lobby with the ID in the path.
+//
+// @Summary Leaves a lobby
+// @Description This endpoint removes the caller from a multiplayer lobby they have joined. If the caller owns the lobby, ownership passes to the member who joined earliest, or the lobby is deleted if it is empty.
+// @Tags lobby
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Param id path int true "lobby ID"
+// @Success 200 {object} httpapi.MessageResponse "Successfully left lobby!"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 404 {object} httpapi.ErrorResponse "Not Found"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /v2/lobbies/{id}/members/me [delete]
+func LeaveLobbyV2(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) error {
+	lobbyId, err := httpapi.PathID(r, "id")
+	if err != nil {
+		return err
+	}
+
+	return leaveLobby(w, r, LeaveLobbyArgs{LobbyId: lobbyId}, lobbies, store)
+}
+
+// leaveLobby removes the caller from the lobby in args.
+func leaveLobby(w http.ResponseWriter, r *http.Request, args LeaveLobbyArgs, lobbies LobbyRepository, store *auth.SessionStore) error {
+	if err := httpapi.Validate(&args); err != nil {
+		return err
+	}
+
+	session, err := store.Authenticate(r, nil)
+	if err != nil {
+		return err
+	}
+
+	accountID := int64(session.AccountID)
+
+	leave, err := lobbies.Leave(args.LobbyId, accountID)
+	if err == ErrLobbyNotFound {
+		return httpapi.NotFound(fmt.Sprintf("no lobby exists with the ID %d", args.LobbyId))
+	}
+	if err == ErrNotLobbyMember {
+		return httpapi.NotFound(fmt.Sprintf("you are not a member of the lobby with the ID %d", args.LobbyId))
+	}
+	if err != nil {
+		return err
+	}
+
+	Events.Publish(Event{Type: EventMemberLeft, LobbyId: args.LobbyId, AccountId: accountID})
+	if leave.HasNewOwner {
+		Events.Publish(Event{Type: EventOwnerChanged, LobbyId: args.LobbyId, AccountId: leave.NewOwnerID})
+	}
+	if leave.LobbyDeleted {
+		Events.Publish(Event{Type: EventLobbyDeleted, LobbyId: args.LobbyId})
+	}
+
+	httpapi.WriteMessage(w, http.StatusOK, "Successfully left lobby!")
+	return nil
+}
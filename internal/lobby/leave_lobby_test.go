@@ -0,0 +1,187 @@
+package lobby
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi/httpapitest"
+)
+
+// expectLeaveLobbyOwner expects the locked lookup of the lobby owner made when leaving a lobby.
+func expectLeaveLobbyOwner(mock sqlmock.Sqlmock, lobbyID int64, ownerAccountID string) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT owner_account_id FROM lobby WHERE id = $1 FOR UPDATE")).
+		WithArgs(lobbyID).
+		WillReturnRows(sqlmock.NewRows([]string{"owner_account_id"}).AddRow(ownerAccountID))
+}
+
+func TestLeaveLobby_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	expectLeaveLobbyOwner(mock, 1, "1")
+	mock.ExpectExec("DELETE FROM lobby_member WHERE lobby_id = \\$1 AND account_id = \\$2").
+		WithArgs(int64(1), 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	req, err := http.NewRequest("POST", "/lobby/leave_lobby", strings.NewReader(`{"lobby_id": 1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = withSession(req, 2)
+
+	rr := httptest.NewRecorder()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		LeaveLobbyHandler(w, r, NewPostgresLobbyRepository(sqlxDB), &auth.SessionStore{DB: sqlxDB})
+	})
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestLeaveLobby_NotAMember(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	expectLeaveLobbyOwner(mock, 1, "1")
+	mock.ExpectExec("DELETE FROM lobby_member WHERE lobby_id = \\$1 AND account_id = \\$2").
+		WithArgs(int64(1), 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	req, err := http.NewRequest("POST", "/lobby/leave_lobby", strings.NewReader(`{"lobby_id": 1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = withSession(req, 2)
+
+	rr := httptest.NewRecorder()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	err = LeaveLobby(rr, req, NewPostgresLobbyRepository(sqlxDB), &auth.SessionStore{DB: sqlxDB})
+
+	expectedError := "you are not a member of the lobby with the ID 1"
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("LeaveLobby() error = %v, wantErr %v", err, expectedError)
+	}
+
+	if status := httpapitest.Status(err); status != http.StatusNotFound {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusNotFound)
+	}
+}
+
+func TestLeaveLobby_OwnerPromotesLongestPresentMember(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	expectLeaveLobbyOwner(mock, 1, "1")
+	mock.ExpectExec("DELETE FROM lobby_member WHERE lobby_id = \\$1 AND account_id = \\$2").
+		WithArgs(int64(1), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT account_id FROM lobby_member WHERE lobby_id = $1 AND account_id <> $2 ORDER BY joined_at, account_id LIMIT 1")).
+		WithArgs(int64(1), int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"account_id"}).AddRow(3))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name FROM account WHERE id = $1")).
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Veteran"))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE lobby SET owner_account_id = $1, owner_name = $2 WHERE id = $3")).
+		WithArgs("3", "Veteran", int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	events, unsubscribe := Events.Subscribe(1, 0)
+	defer unsubscribe()
+
+	req, err := http.NewRequest("POST", "/lobby/leave_lobby", strings.NewReader(`{"lobby_id": 1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = withSession(req, 1)
+
+	rr := httptest.NewRecorder()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	LeaveLobbyHandler(rr, req, NewPostgresLobbyRepository(sqlxDB), &auth.SessionStore{DB: sqlxDB})
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	if event, ok := receiveEvent(t, events); !ok || event.Type != EventMemberLeft || event.AccountId != 1 {
+		t.Errorf("unexpected event: %+v", event)
+	}
+
+	if event, ok := receiveEvent(t, events); !ok || event.Type != EventOwnerChanged || event.AccountId != 3 {
+		t.Errorf("unexpected event: %+v", event)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestLeaveLobby_LastOwnerDeletesLobby(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	expectLeaveLobbyOwner(mock, 1, "1")
+	mock.ExpectExec("DELETE FROM lobby_member WHERE lobby_id = \\$1 AND account_id = \\$2").
+		WithArgs(int64(1), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT account_id FROM lobby_member WHERE lobby_id = $1 AND account_id <> $2 ORDER BY joined_at, account_id LIMIT 1")).
+		WithArgs(int64(1), int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"account_id"}))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM lobby WHERE id = $1")).
+		WithArgs(int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	req, err := http.NewRequest("POST", "/lobby/leave_lobby", strings.NewReader(`{"lobby_id": 1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = withSession(req, 1)
+
+	rr := httptest.NewRecorder()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	LeaveLobbyHandler(rr, req, NewPostgresLobbyRepository(sqlxDB), &auth.SessionStore{DB: sqlxDB})
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
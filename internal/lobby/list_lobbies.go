@@ -0,0 +1,231 @@
+package lobby
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/account"
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+)
+
+const (
+	// DefaultListLobbiesLimit is the number of lobbies returned when no limit is given.
+	DefaultListLobbiesLimit = 20
+	// MaxListLobbiesLimit is the largest number of lobbies returned by a single call.
+	MaxListLobbiesLimit = 100
+)
+
+// LobbySort is an order in which lobbies can be listed.
+type LobbySort string
+
+const (
+	LobbySortNewest      LobbySort = "newest"
+	LobbySortOldest      LobbySort = "oldest"
+	LobbySortName        LobbySort = "name"
+	LobbySortMostMembers LobbySort = "most_members"
+)
+
+// lobbySortOrders maps each sort to the column it orders by and the direction. Ties are always
+// broken by the lobby ID in the same direction so that cursors are stable.
+var lobbySortOrders = map[LobbySort]struct {
+	column    string
+	ascending bool
+}{
+	LobbySortNewest:      {column: "created_at", ascending: false},
+	LobbySortOldest:      {column: "created_at", ascending: true},
+	LobbySortName:        {column: "name", ascending: true},
+	LobbySortMostMembers: {column: "member_count", ascending: false},
+}
+
+// LobbySummary is a lobby as shown in the lobby browser.
+//
+// @Description Structure for representing a player lobby in the lobby browser.
+type LobbySummary struct {
+	Lobby
+
+	// CreatedAt is when the lobby was created.
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+
+	// MaxMembers is the number of members the lobby has room for.
+	MaxMembers int `json:"max_members" db:"max_members"`
+
+	// MemberCount is the number of members currently in the lobby.
+	MemberCount int `json:"member_count" db:"member_count"`
+
+	// OwnerExperienceLevel is the experience level of the lobby owner.
+	OwnerExperienceLevel account.ExperienceLevel `json:"owner_experience_level" db:"owner_experience_level"`
+}
+
+// ListLobbiesResponse is a page of lobbies from the lobby browser.
+//
+// @Description Structure for the lobby browser response.
+type ListLobbiesResponse struct {
+	// Lobbies is the current page of lobbies.
+	Lobbies []LobbySummary `json:"lobbies"`
+
+	// NextCursor is passed as the cursor parameter to fetch the next page. It is empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// lobbyCursor is the position after which the next page starts. It is sent to clients base64-encoded.
+type lobbyCursor struct {
+	Sort  LobbySort `json:"s"`
+	Value string    `json:"v"`
+	ID    int64     `json:"id"`
+}
+
+func encodeLobbyCursor(sort LobbySort, summary *LobbySummary) string {
+	cursor := lobbyCursor{Sort: sort, ID: summary.ID}
+	switch sort {
+	case LobbySortNewest, LobbySortOldest:
+		cursor.Value = summary.CreatedAt.UTC().Format(time.RFC3339Nano)
+	case LobbySortName:
+		cursor.Value = summary.Name
+	case LobbySortMostMembers:
+		cursor.Value = strconv.Itoa(summary.MemberCount)
+	}
+
+	cursorBytes, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(cursorBytes)
+}
+
+// decodeLobbyCursor returns the value to compare against for the given sort along with the lobby ID.
+func decodeLobbyCursor(encoded string, sort LobbySort) (interface{}, int64, error) {
+	cursorBytes, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, 0, errors.New("invalid cursor")
+	}
+
+	var cursor lobbyCursor
+	if err := json.Unmarshal(cursorBytes, &cursor); err != nil {
+		return nil, 0, errors.New("invalid cursor")
+	}
+
+	if cursor.Sort != sort {
+		return nil, 0, errors.New("the cursor was created for a different sort order")
+	}
+
+	switch sort {
+	case LobbySortNewest, LobbySortOldest:
+		createdAt, err := time.Parse(time.RFC3339Nano, cursor.Value)
+		if err != nil {
+			return nil, 0, errors.New("invalid cursor")
+		}
+		return createdAt, cursor.ID, nil
+	case LobbySortMostMembers:
+		memberCount, err := strconv.Atoi(cursor.Value)
+		if err != nil {
+			return nil, 0, errors.New("invalid cursor")
+		}
+		return memberCount, cursor.ID, nil
+	default:
+		return cursor.Value, cursor.ID, nil
+	}
+}
+
+// escapeLike escapes the LIKE wildcards in a user-provided search string.
+func escapeLike(search string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(search)
+}
+
+// ListLobbies lists the public, open lobbies for the lobby browser.
+//
+// @Summary Lists lobbies
+// @Description This endpoint lists public lobbies which are not closed, one page at a time. Pass the returned next_cursor as the cursor parameter to get the next page.
+// @Tags lobby
+// @Produce json
+// @Param name query string false "only include lobbies whose name contains this text (case-insensitive)"
+// @Param has_free_slots query bool false "only include lobbies which have room for more members"
+// @Param is_muted query bool false "only include lobbies which are (or are not) muted"
+// @Param owner_experience_level query int false "only include lobbies whose owner has this experience level"
+// @Param sort query string false "sort order (newest, oldest, name or most_members)" default(newest)
+// @Param limit query int false "maximum number of lobbies to return (at most 100)" default(20)
+// @Param cursor query string false "cursor returned by the previous page"
+//
+// @Success 200 {object} lobby.ListLobbiesResponse "Lobbies successfully retrieved"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /lobby/list_lobbies [get]
+// @Router /v2/lobbies [get]
+func ListLobbies(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) error {
+	if r.Method != "GET" {
+		return httpapi.MethodNotAllowed(http.MethodGet)
+	}
+
+	queryParams := r.URL.Query()
+
+	options := LobbyListOptions{Name: queryParams.Get("name")}
+
+	if hasFreeSlotsStr := queryParams.Get("has_free_slots"); hasFreeSlotsStr != "" {
+		hasFreeSlots, err := strconv.ParseBool(hasFreeSlotsStr)
+		if err != nil {
+			return httpapi.Validation("has_free_slots must be true or false")
+		}
+		options.HasFreeSlots = &hasFreeSlots
+	}
+
+	if isMutedStr := queryParams.Get("is_muted"); isMutedStr != "" {
+		isMuted, err := strconv.ParseBool(isMutedStr)
+		if err != nil {
+			return httpapi.Validation("is_muted must be true or false")
+		}
+		options.IsMuted = &isMuted
+	}
+
+	if levelStr := queryParams.Get("owner_experience_level"); levelStr != "" {
+		level, err := strconv.Atoi(levelStr)
+		if err != nil || level < int(account.Beginner) || level > int(account.Impossible) {
+			return httpapi.Validation(fmt.Sprintf("owner_experience_level must be a number between %d and %d", account.Beginner, account.Impossible))
+		}
+		experienceLevel := account.ExperienceLevel(level)
+		options.OwnerExperienceLevel = &experienceLevel
+	}
+
+	options.Sort = LobbySort(queryParams.Get("sort"))
+	if options.Sort == "" {
+		options.Sort = LobbySortNewest
+	}
+	if _, ok := lobbySortOrders[options.Sort]; !ok {
+		return httpapi.Validation("sort must be one of newest, oldest, name or most_members")
+	}
+
+	limit := DefaultListLobbiesLimit
+	if limitStr := queryParams.Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > MaxListLobbiesLimit {
+			return httpapi.Validation(fmt.Sprintf("limit must be a number between 1 and %d", MaxListLobbiesLimit))
+		}
+		limit = parsed
+	}
+	// One extra lobby is fetched to find out whether there is another page
+	options.Limit = limit + 1
+
+	if cursorStr := queryParams.Get("cursor"); cursorStr != "" {
+		value, id, err := decodeLobbyCursor(cursorStr, options.Sort)
+		if err != nil {
+			return httpapi.Validation(err.Error())
+		}
+		options.After = &LobbyPosition{Value: value, ID: id}
+	}
+
+	summaries, err := lobbies.ListLobbies(options)
+	if err != nil {
+		return fmt.Errorf("an error occurred while listing lobbies: %v", err)
+	}
+
+	response := ListLobbiesResponse{Lobbies: summaries}
+	if len(summaries) > limit {
+		response.Lobbies = summaries[:limit]
+		response.NextCursor = encodeLobbyCursor(options.Sort, &response.Lobbies[limit-1])
+	}
+
+	httpapi.WriteJSON(w, http.StatusOK, response)
+	return nil
+}
@@ -0,0 +1,142 @@
+package lobby
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+var lobbySummaryColumns = []string{"id", "name", "owner_name", "owner_account_id", "is_closed", "is_muted", "is_public", "created_at", "max_members", "owner_experience_level", "member_count"}
+
+func callListLobbies(t *testing.T, sqlxDB *sqlx.DB, url string) (*httptest.ResponseRecorder, ListLobbiesResponse) {
+	t.Helper()
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ListLobbiesHandler(w, r, NewPostgresLobbyRepository(sqlxDB), nil)
+	})
+
+	handler.ServeHTTP(rr, req)
+
+	var response ListLobbiesResponse
+	if rr.Code == http.StatusOK {
+		if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+			t.Fatalf("could not decode the response body: %v", err)
+		}
+	}
+
+	return rr, response
+}
+
+func TestListLobbies_PaginatesWithCursor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	// The first page fetches one extra row to find out whether there is another page
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE lobby.is_public AND NOT lobby.is_closed) AS lobbies ORDER BY created_at DESC, id DESC LIMIT $1")).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows(lobbySummaryColumns).
+			AddRow(3, "Third", "Owner", "1", false, false, true, now, 8, 2, 1).
+			AddRow(2, "Second", "Owner", "1", false, false, true, now.Add(-time.Minute), 8, 2, 4).
+			AddRow(1, "First", "Owner", "1", false, false, true, now.Add(-2*time.Minute), 8, 2, 8))
+
+	rr, firstPage := callListLobbies(t, sqlxDB, "/lobby/list_lobbies?limit=2")
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	if len(firstPage.Lobbies) != 2 || firstPage.Lobbies[1].ID != 2 || firstPage.Lobbies[1].MemberCount != 4 {
+		t.Fatalf("unexpected first page: %+v", firstPage.Lobbies)
+	}
+
+	if firstPage.NextCursor == "" {
+		t.Fatal("expected a cursor for the next page")
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("AS lobbies WHERE (created_at, id) < ($1, $2) ORDER BY created_at DESC, id DESC LIMIT $3")).
+		WithArgs(now.Add(-time.Minute), int64(2), 3).
+		WillReturnRows(sqlmock.NewRows(lobbySummaryColumns).
+			AddRow(1, "First", "Owner", "1", false, false, true, now.Add(-2*time.Minute), 8, 2, 8))
+
+	rr, secondPage := callListLobbies(t, sqlxDB, "/lobby/list_lobbies?limit=2&cursor="+firstPage.NextCursor)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	if len(secondPage.Lobbies) != 1 || secondPage.NextCursor != "" {
+		t.Errorf("unexpected second page: %+v", secondPage)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestListLobbies_Filters(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("AS lobbies WHERE name ILIKE '%' || $1 || '%' AND member_count < max_members AND is_muted = $2 AND owner_experience_level = $3 ORDER BY name ASC, id ASC LIMIT $4")).
+		WithArgs(`100\%`, false, 3, DefaultListLobbiesLimit+1).
+		WillReturnRows(sqlmock.NewRows(lobbySummaryColumns))
+
+	rr, response := callListLobbies(t, sqlx.NewDb(db, "sqlmock"), "/lobby/list_lobbies?name=100%25&has_free_slots=true&is_muted=false&owner_experience_level=3&sort=name")
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	if response.Lobbies == nil || len(response.Lobbies) != 0 {
+		t.Errorf("expected an empty list of lobbies, got %+v", response.Lobbies)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestListLobbies_InvalidParameters(t *testing.T) {
+	otherSortCursor := encodeLobbyCursor(LobbySortName, &LobbySummary{Lobby: Lobby{ID: 1, Name: "Lobby"}})
+
+	tests := []string{
+		"/lobby/list_lobbies?sort=popular",
+		"/lobby/list_lobbies?limit=0",
+		"/lobby/list_lobbies?limit=101",
+		"/lobby/list_lobbies?has_free_slots=maybe",
+		"/lobby/list_lobbies?is_muted=maybe",
+		"/lobby/list_lobbies?owner_experience_level=6",
+		"/lobby/list_lobbies?cursor=not-a-cursor",
+		"/lobby/list_lobbies?sort=newest&cursor=" + otherSortCursor,
+	}
+
+	for _, url := range tests {
+		rr, _ := callListLobbies(t, nil, url)
+
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("%s: handler returned wrong status code: got %v want %v", url, status, http.StatusBadRequest)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package lobby
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+)
+
+// ListMembers lists the members of a lobby in the order they joined.
+//
+// @Summary Lists lobby members
+// @Description This endpoint lists the members of a multiplayer lobby along with their experience levels.
+// @Tags lobby
+// @Produce json
+// @Param lobby_id query int true "lobby ID"
+//
+// @Success 200 {array} lobby.Member "Members successfully retrieved"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /lobby/list_members [get]
+func ListMembers(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) error {
+	if r.Method != "GET" {
+		return httpapi.MethodNotAllowed(http.MethodGet)
+	}
+
+	lobbyIdStr := r.URL.Query().Get("lobby_id")
+	if lobbyIdStr == "" {
+		return httpapi.Validation("lobby_id is required")
+	}
+
+	lobbyId, err := strconv.ParseInt(lobbyIdStr, 10, 64)
+	if err != nil {
+		return httpapi.Validation("invalid lobby_id")
+	}
+
+	return listMembers(w, lobbyId, lobbies)
+}
+
+// ListMembersV2 lists the members of the lobby with the ID in the path.
+//
+// @Summary Lists lobby members
+// @Description This endpoint lists the members of a multiplayer lobby along with their experience levels.
+// @Tags lobby
+// @Produce json
+// @Param id path int true "lobby ID"
+// @Success 200 {array} lobby.Member "Members successfully retrieved"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /v2/lobbies/{id}/members [get]
+func ListMembersV2(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) error {
+	lobbyId, err := httpapi.PathID(r, "id")
+	if err != nil {
+		return err
+	}
+
+	return listMembers(w, lobbyId, lobbies)
+}
+
+// listMembers writes the members of the lobby with the given ID.
+func listMembers(w http.ResponseWriter, lobbyId int64, lobbies LobbyRepository) error {
+	members, err := lobbies.ListMembers(lobbyId)
+	if err != nil {
+		return fmt.Errorf("an error occurred while listing the members of the lobby with the ID %d: %v", lobbyId, err)
+	}
+
+	httpapi.WriteJSON(w, http.StatusOK, members)
+	return nil
+}
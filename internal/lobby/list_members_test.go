@@ -0,0 +1,85 @@
+package lobby
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/justinfarrelldev/open-ctp-server/internal/account"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi/httpapitest"
+)
+
+func TestListMembers_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT lobby_member.account_id, account.name, account.experience_level, lobby_member.is_ready, lobby_member.is_muted, lobby_member.joined_at FROM lobby_member JOIN account ON account.id = lobby_member.account_id WHERE lobby_member.lobby_id = $1 ORDER BY lobby_member.joined_at")).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"account_id", "name", "experience_level", "is_ready", "is_muted", "joined_at"}).
+			AddRow(1, "Owner", 5, true, false, now.Add(-time.Minute)).
+			AddRow(2, "Player", 0, false, false, now))
+
+	req, err := http.NewRequest("GET", "/lobby/list_members?lobby_id=1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ListMembersHandler(w, r, NewPostgresLobbyRepository(sqlxDB), nil)
+	})
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var members []Member
+	if err := json.Unmarshal(rr.Body.Bytes(), &members); err != nil {
+		t.Fatalf("could not decode the response body: %v", err)
+	}
+
+	if len(members) != 2 {
+		t.Fatalf("expected 2 members, got %d", len(members))
+	}
+
+	if members[0].ExperienceLevel != account.Impossible || !members[0].IsReady || members[1].Name != "Player" {
+		t.Errorf("unexpected members returned: %+v", members)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestListMembers_MissingLobbyID(t *testing.T) {
+	req, err := http.NewRequest("GET", "/lobby/list_members", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	err = ListMembers(rr, req, nil, nil)
+
+	expectedError := "lobby_id is required"
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("ListMembers() error = %v, wantErr %v", err, expectedError)
+	}
+
+	if status := httpapitest.Status(err); status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+	}
+}
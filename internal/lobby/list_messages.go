@@ -0,0 +1,124 @@
+package lobby
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+)
+
+const (
+	// DefaultListMessagesLimit is the number of messages returned when no limit is given.
+	DefaultListMessagesLimit = 50
+	// MaxListMessagesLimit is the largest number of messages returned by a single call.
+	MaxListMessagesLimit = 200
+)
+
+// ListMessages lists a lobby's chat history, newest first.
+//
+// @Summary Lists lobby chat messages
+// @Description This endpoint lists the chat history of a lobby the caller is a member of, newest first. Pass the ID of the oldest message received as before to get the previous page.
+// @Tags lobby
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Param lobby_id query int true "lobby ID"
+// @Param before query int false "only include messages older than the message with this ID"
+// @Param limit query int false "maximum number of messages to return (at most 200)" default(50)
+//
+// @Success 200 {array} lobby.Message "Messages successfully retrieved"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /lobby/list_messages [get]
+func ListMessages(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) error {
+	if r.Method != "GET" {
+		return httpapi.MethodNotAllowed(http.MethodGet)
+	}
+
+	queryParams := r.URL.Query()
+
+	lobbyIdStr := queryParams.Get("lobby_id")
+	if lobbyIdStr == "" {
+		return httpapi.Validation("lobby_id is required")
+	}
+
+	lobbyId, err := strconv.ParseInt(lobbyIdStr, 10, 64)
+	if err != nil {
+		return httpapi.Validation("invalid lobby_id")
+	}
+
+	return listMessages(w, r, lobbyId, lobbies, store)
+}
+
+// ListMessagesV2 lists the chat history of the lobby with the ID in the path, newest first.
+//
+// @Summary Lists lobby chat messages
+// @Description This endpoint lists the chat history of a lobby the caller is a member of, newest first. Pass the ID of the oldest message received as before to get the previous page.
+// @Tags lobby
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Param id path int true "lobby ID"
+// @Param before query int false "only include messages older than the message with this ID"
+// @Param limit query int false "maximum number of messages to return (at most 200)" default(50)
+// @Success 200 {array} lobby.Message "Messages successfully retrieved"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /v2/lobbies/{id}/messages [get]
+func ListMessagesV2(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) error {
+	lobbyId, err := httpapi.PathID(r, "id")
+	if err != nil {
+		return err
+	}
+
+	return listMessages(w, r, lobbyId, lobbies, store)
+}
+
+// listMessages writes a page of the chat history of the lobby with the given ID, which the before and limit
+// query parameters select.
+func listMessages(w http.ResponseWriter, r *http.Request, lobbyId int64, lobbies LobbyRepository, store *auth.SessionStore) error {
+	queryParams := r.URL.Query()
+	var before *int64
+	if beforeStr := queryParams.Get("before"); beforeStr != "" {
+		parsed, err := strconv.ParseInt(beforeStr, 10, 64)
+		if err != nil {
+			return httpapi.Validation("invalid before")
+		}
+		before = &parsed
+	}
+
+	limit := DefaultListMessagesLimit
+	if limitStr := queryParams.Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > MaxListMessagesLimit {
+			return httpapi.Validation(fmt.Sprintf("limit must be a number between 1 and %d", MaxListMessagesLimit))
+		}
+		limit = parsed
+	}
+
+	session, err := store.Authenticate(r, nil)
+	if err != nil {
+		return err
+	}
+
+	isMember, err := lobbies.IsMemberOrOwner(lobbyId, int64(session.AccountID))
+	if err != nil {
+		return fmt.Errorf("an error occurred while checking the lobby membership: %v", err)
+	}
+
+	if !isMember {
+		return httpapi.Forbidden(fmt.Sprintf("you are not a member of the lobby with the ID %d", lobbyId))
+	}
+
+	messages, err := lobbies.ListMessages(lobbyId, before, limit)
+	if err != nil {
+		return fmt.Errorf("an error occurred while listing the messages of the lobby with the ID %d: %v", lobbyId, err)
+	}
+
+	httpapi.WriteJSON(w, http.StatusOK, messages)
+	return nil
+}
@@ -0,0 +1,111 @@
+package lobby
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi/httpapitest"
+)
+
+func TestListMessages_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(lobbyMembershipQuery)).
+		WithArgs(int64(3), 2, "2").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, lobby_id, account_id, message, created_at FROM lobby_message WHERE lobby_id = $1 AND id < $2 ORDER BY id DESC LIMIT $3")).
+		WithArgs(int64(3), int64(20), 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "lobby_id", "account_id", "message", "created_at"}).
+			AddRow(19, 3, 1, "gigantic map?", now).
+			AddRow(18, 3, 2, "sure", now.Add(-time.Second)))
+
+	req, err := http.NewRequest("GET", "/lobby/list_messages?lobby_id=3&before=20&limit=2", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = withSession(req, 2)
+
+	rr := httptest.NewRecorder()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ListMessagesHandler(w, r, NewPostgresLobbyRepository(sqlxDB), &auth.SessionStore{DB: sqlxDB})
+	})
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var messages []Message
+	if err := json.Unmarshal(rr.Body.Bytes(), &messages); err != nil {
+		t.Fatalf("could not decode the response: %v", err)
+	}
+
+	if len(messages) != 2 || messages[0].ID != 19 || messages[1].ID != 18 {
+		t.Errorf("unexpected messages: %+v", messages)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestListMessages_NotAMember(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(lobbyMembershipQuery)).
+		WithArgs(int64(3), 2, "2").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	req, err := http.NewRequest("GET", "/lobby/list_messages?lobby_id=3", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = withSession(req, 2)
+
+	rr := httptest.NewRecorder()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	ListMessagesHandler(rr, req, NewPostgresLobbyRepository(sqlxDB), &auth.SessionStore{DB: sqlxDB})
+
+	if status := rr.Code; status != http.StatusForbidden {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusForbidden)
+	}
+}
+
+func TestListMessages_InvalidLimit(t *testing.T) {
+	req, err := http.NewRequest("GET", "/lobby/list_messages?lobby_id=3&limit=500", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	err = ListMessages(rr, req, nil, nil)
+	if err == nil {
+		t.Error("expected an error for a limit over the maximum")
+	}
+
+	if status := httpapitest.Status(err); status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+	}
+}
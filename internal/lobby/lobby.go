@@ -10,16 +10,16 @@ type Lobby struct {
 	// Name is the name of the lobby.
 	Name string `json:"name" db:"name"`
 
-	// OwnerName is the name of the lobby owner.
+	// OwnerName is the account name of the lobby owner. It is set by the server.
 	OwnerName string `json:"owner_name" db:"owner_name"`
 
-	// OwnerAccountId is the account ID of the lobby owner.
+	// OwnerAccountId is the account ID of the lobby owner. It is set by the server from the caller's session.
 	OwnerAccountId string `json:"owner_account_id" db:"owner_account_id"`
 
 	// IsClosed indicates if the lobby is closed.
 	IsClosed bool `json:"is_closed" db:"is_closed"`
 
-	// IsMuted indicates if the lobby is muted.
+	// IsMuted indicates if the lobby is muted; only the owner can chat in a muted lobby.
 	IsMuted bool `json:"is_muted" db:"is_muted"`
 
 	// IsPublic indicates if the lobby is public.
@@ -36,16 +36,16 @@ type LobbyParam struct {
 	// Name is the name of the lobby.
 	Name *string `json:"name,omitempty" db:"name"`
 
-	// OwnerName is the name of the lobby owner.
+	// OwnerName is the account name of the lobby owner. It cannot be updated directly.
 	OwnerName *string `json:"owner_name,omitempty" db:"owner_name"`
 
-	// OwnerAccountId is the account ID of the lobby owner.
+	// OwnerAccountId is the account ID of the lobby owner. It cannot be updated directly; use /lobby/transfer_ownership.
 	OwnerAccountId *string `json:"owner_account_id,omitempty" db:"owner_account_id"`
 
 	// IsClosed indicates if the lobby is closed.
 	IsClosed *bool `json:"is_closed,omitempty" db:"is_closed"`
 
-	// IsMuted indicates if the lobby is muted.
+	// IsMuted indicates if the lobby is muted; only the owner can chat in a muted lobby.
 	IsMuted *bool `json:"is_muted,omitempty" db:"is_muted"`
 
 	// IsPublic indicates if the lobby is public.
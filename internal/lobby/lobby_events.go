@@ -0,0 +1,251 @@
+package lobby
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+	"golang.org/x/net/websocket"
+)
+
+// EventsProtocol is the WebSocket subprotocol of lobby events. Browsers cannot set the Authorization
+// header on WebSocket requests, so they offer this protocol along with "bearer.<session token>" instead,
+// and the server picks this one.
+const EventsProtocol = "ctp-lobby-events"
+
+// bearerProtocolPrefix starts the subprotocol carrying the session token.
+const bearerProtocolPrefix = "bearer."
+
+// sessionCheckInterval is how often the session of a quiet lobby's subscriber is checked, so that its
+// WebSocket is closed soon after the session is logged out or expires.
+const sessionCheckInterval = time.Minute
+
+// AllowedOrigins lists the origins, such as https://play.example.com, which browsers may open lobby event
+// WebSockets from besides the server's own. Clients which are not browsers send no Origin and are let in.
+var AllowedOrigins []string
+
+// LobbyEvents upgrades the request to a WebSocket which receives the lobby's events as JSON messages.
+// Clients can chat by sending {"type": "chat_message", "message": "..."} over the same WebSocket; a
+// rejected message is answered with an error event. Only members of the lobby and its owner can subscribe.
+//
+// @Summary Streams lobby events
+// @Description This endpoint upgrades to a WebSocket which pushes lobby.Event messages (members joining, leaving or being kicked, ready state changes, settings changes, mutes, chat messages and the lobby being deleted) to members of the lobby. Members can send chat messages by sending {"type": "chat_message", "message": "..."} over the WebSocket. The WebSocket is closed when the member leaves or is removed from the lobby, after the event saying so, and when the session ends.
+// @Tags lobby
+// @Param lobby_id query int true "lobby ID"
+// @Param Authorization header string false "Bearer session token"
+// @Param Sec-WebSocket-Protocol header string false "ctp-lobby-events, bearer.<session token>, for clients which cannot set the Authorization header"
+// @Success 101 {object} lobby.Event "Switching protocols; events are sent as JSON messages"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /lobby/events [get]
+func LobbyEvents(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) error {
+	if r.Method != "GET" {
+		return httpapi.MethodNotAllowed(http.MethodGet)
+	}
+
+	queryParams := r.URL.Query()
+
+	lobbyIdStr := queryParams.Get("lobby_id")
+	if lobbyIdStr == "" {
+		return httpapi.Validation("lobby_id is required")
+	}
+
+	lobbyId, err := strconv.ParseInt(lobbyIdStr, 10, 64)
+	if err != nil {
+		return httpapi.Validation("invalid lobby_id")
+	}
+
+	return lobbyEvents(w, r, lobbyId, lobbies, store)
+}
+
+// LobbyEventsV2 upgrades the request to a WebSocket which receives the events of the lobby with the ID in
+// the path.
+//
+// @Summary Streams lobby events
+// @Description This endpoint upgrades to a WebSocket which pushes lobby.Event messages (members joining, leaving or being kicked, ready state changes, settings changes, mutes, chat messages and the lobby being deleted) to members of the lobby. Members can send chat messages by sending {"type": "chat_message", "message": "..."} over the WebSocket. The WebSocket is closed when the member leaves or is removed from the lobby, after the event saying so, and when the session ends.
+// @Tags lobby
+// @Param Authorization header string false "Bearer session token"
+// @Param Sec-WebSocket-Protocol header string false "ctp-lobby-events, bearer.<session token>, for clients which cannot set the Authorization header"
+// @Param id path int true "lobby ID"
+// @Success 101 {object} lobby.Event "Switching protocols; events are sent as JSON messages"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /v2/lobbies/{id}/events [get]
+func LobbyEventsV2(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) error {
+	lobbyId, err := httpapi.PathID(r, "id")
+	if err != nil {
+		return err
+	}
+
+	return lobbyEvents(w, r, lobbyId, lobbies, store)
+}
+
+// lobbyEvents streams the events of the lobby with the given ID over a WebSocket.
+func lobbyEvents(w http.ResponseWriter, r *http.Request, lobbyId int64, lobbies LobbyRepository, store *auth.SessionStore) error {
+	if token := protocolBearerToken(r); token != "" && auth.BearerToken(r) == "" {
+		r = r.Clone(r.Context())
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	session, err := store.Authenticate(r, nil)
+	if err != nil {
+		return err
+	}
+
+	isMember, err := lobbies.IsMemberOrOwner(lobbyId, int64(session.AccountID))
+	if err != nil {
+		return fmt.Errorf("an error occurred while checking the lobby membership: %v", err)
+	}
+
+	if !isMember {
+		return httpapi.Forbidden(fmt.Sprintf("you are not a member of the lobby with the ID %d", lobbyId))
+	}
+
+	// Subscribe before upgrading so that no events are missed in between. The subscription ends when the
+	// account leaves the lobby or is removed from it.
+	events, unsubscribe := Events.Subscribe(lobbyId, int64(session.AccountID))
+	defer unsubscribe()
+
+	// The socket outlives the request, so the session is checked again before each event, and regularly
+	// in between, in case it has logged out or expired since
+	sessionActive := func() bool {
+		active, err := store.SessionActive(session.ID)
+		if err != nil {
+			log.Println("error checking a lobby subscriber's session: ", err.Error())
+		}
+		return active
+	}
+
+	server := websocket.Server{
+		Handshake: handshake,
+		Handler: func(ws *websocket.Conn) {
+			streamEvents(ws, lobbyId, events, sessionActive, sessionCheckInterval, func(text string) error {
+				_, err := sendChatMessage(lobbies, lobbyId, int64(session.AccountID), text)
+				return err
+			})
+		},
+	}
+	server.ServeHTTP(w, r)
+	return nil
+}
+
+// protocolBearerToken returns the session token offered as a "bearer.<token>" subprotocol, or an empty
+// string if there is none.
+func protocolBearerToken(r *http.Request) string {
+	for _, header := range r.Header.Values("Sec-WebSocket-Protocol") {
+		for _, protocol := range strings.Split(header, ",") {
+			if token, ok := strings.CutPrefix(strings.TrimSpace(protocol), bearerProtocolPrefix); ok {
+				return token
+			}
+		}
+	}
+	return ""
+}
+
+// handshake refuses WebSockets opened by pages from origins other than the server's own and
+// AllowedOrigins, and picks EventsProtocol when the client offers it. The bearer subprotocol is never
+// picked, so the token is not sent back.
+func handshake(config *websocket.Config, r *http.Request) error {
+	if origin := r.Header.Get("Origin"); origin != "" && !originAllowed(origin, r.Host) {
+		return fmt.Errorf("the origin %s may not open lobby events", origin)
+	}
+
+	offered := config.Protocol
+	config.Protocol = nil
+	for _, protocol := range offered {
+		if protocol == EventsProtocol {
+			config.Protocol = []string{EventsProtocol}
+		}
+	}
+	return nil
+}
+
+// originAllowed reports whether a page from origin may open a WebSocket to the server at host.
+func originAllowed(origin string, host string) bool {
+	if parsed, err := url.Parse(origin); err == nil && strings.EqualFold(parsed.Host, host) {
+		return true
+	}
+
+	for _, allowed := range AllowedOrigins {
+		if strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientMessage is a message sent by a client over the lobby WebSocket.
+type clientMessage struct {
+	Type    EventType `json:"type"`
+	Message string    `json:"message"`
+}
+
+// streamEvents sends events to the WebSocket until the subscription ends, the session stops being active
+// or the client goes away. The session is checked before each event and every checkInterval. Chat messages
+// sent by the client are passed to chat, and any error is sent back to the client alone.
+func streamEvents(ws *websocket.Conn, lobbyID int64, events <-chan Event, sessionActive func() bool, checkInterval time.Duration, chat func(text string) error) {
+	// Reading is also the only way to notice that the client has disconnected
+	disconnected := make(chan struct{})
+	go func() {
+		defer close(disconnected)
+		for {
+			var data string
+			if err := websocket.Message.Receive(ws, &data); err != nil {
+				return
+			}
+
+			var message clientMessage
+			if err := json.Unmarshal([]byte(data), &message); err != nil || message.Type != EventChatMessage {
+				continue
+			}
+
+			if !sessionActive() {
+				return
+			}
+
+			if err := chat(message.Message); err != nil {
+				var apiErr *httpapi.Error
+				if !errors.As(err, &apiErr) {
+					log.Println("internal error: ", err.Error())
+					apiErr = httpapi.Internal(err)
+				}
+
+				// websocket.JSON.Send locks the connection, so this cannot interleave with the events below
+				websocket.JSON.Send(ws, Event{Type: EventError, LobbyId: lobbyID, Error: apiErr.Message, ErrorCode: apiErr.Code, Time: time.Now()})
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok || !sessionActive() {
+				return
+			}
+			if err := websocket.JSON.Send(ws, event); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if !sessionActive() {
+				return
+			}
+		case <-disconnected:
+			return
+		}
+	}
+}
@@ -0,0 +1,374 @@
+package lobby
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"golang.org/x/net/websocket"
+)
+
+const lobbyMembershipQuery = "SELECT EXISTS (SELECT 1 FROM lobby_member WHERE lobby_id = $1 AND account_id = $2) OR EXISTS (SELECT 1 FROM lobby WHERE id = $1 AND owner_account_id = $3)"
+
+// newEventsServer serves LobbyEvents with requests authenticated as the given account. It returns the
+// session the requests use, which is kept in the returned store.
+func newEventsServer(t *testing.T, sqlxDB *sqlx.DB, accountID int) (*httptest.Server, *auth.SessionStore, *auth.Session) {
+	t.Helper()
+
+	store := auth.NewSessionStoreWithRepository(auth.NewMemorySessionRepository())
+	session, err := store.CreateSession(accountID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		LobbyEventsHandler(w, r.WithContext(auth.ContextWithSession(r.Context(), session)), NewPostgresLobbyRepository(sqlxDB), store)
+	}))
+	t.Cleanup(server.Close)
+	return server, store, session
+}
+
+// dialEvents connects to the events of the lobby served by server.
+func dialEvents(t *testing.T, server *httptest.Server, lobbyID int64) *websocket.Conn {
+	t.Helper()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/lobby/events?lobby_id=" + strconv.FormatInt(lobbyID, 10)
+	ws, err := websocket.Dial(wsURL, "", server.URL)
+	if err != nil {
+		t.Fatalf("could not connect to the WebSocket: %v", err)
+	}
+	t.Cleanup(func() { ws.Close() })
+
+	ws.SetReadDeadline(time.Now().Add(time.Second))
+	return ws
+}
+
+func TestLobbyEvents_StreamsEvents(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(lobbyMembershipQuery)).
+		WithArgs(int64(42), 2, "2").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	server, _, _ := newEventsServer(t, sqlx.NewDb(db, "sqlmock"), 2)
+	ws := dialEvents(t, server, 42)
+
+	// The subscription is made before the upgrade, so it is in place once the dial returns
+	Events.Publish(Event{Type: EventMemberJoined, LobbyId: 42, AccountId: 3})
+
+	var event Event
+	if err := websocket.JSON.Receive(ws, &event); err != nil {
+		t.Fatalf("could not receive an event: %v", err)
+	}
+
+	if event.Type != EventMemberJoined || event.LobbyId != 42 || event.AccountId != 3 {
+		t.Errorf("unexpected event: %+v", event)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestLobbyEvents_NotAMember(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(lobbyMembershipQuery)).
+		WithArgs(int64(42), 2, "2").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	req, err := http.NewRequest("GET", "/lobby/events?lobby_id=42", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = withSession(req, 2)
+
+	rr := httptest.NewRecorder()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	LobbyEventsHandler(rr, req, NewPostgresLobbyRepository(sqlxDB), &auth.SessionStore{DB: sqlxDB})
+
+	if status := rr.Code; status != http.StatusForbidden {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusForbidden)
+	}
+}
+
+func TestLobbyEvents_TokenProtocol(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	// The token is looked up, which proves that the subprotocol was used
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM sessions WHERE token_hash = $1")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "account_id", "created_at", "expires_at"}))
+
+	req, err := http.NewRequest("GET", "/lobby/events?lobby_id=42", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Sec-WebSocket-Protocol", EventsProtocol+", bearer.abc")
+
+	rr := httptest.NewRecorder()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	LobbyEventsHandler(rr, req, NewPostgresLobbyRepository(sqlxDB), auth.NewSessionStore(sqlxDB))
+
+	if status := rr.Code; status != http.StatusForbidden {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusForbidden)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestLobbyEvents_TokenQueryParameterIsIgnored(t *testing.T) {
+	req, err := http.NewRequest("GET", "/lobby/events?lobby_id=42&token=abc", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	LobbyEventsHandler(rr, req, nil, &auth.SessionStore{})
+
+	if status := rr.Code; status != http.StatusUnauthorized {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusUnauthorized)
+	}
+}
+
+func TestLobbyEvents_Handshake(t *testing.T) {
+	defer func(allowed []string) { AllowedOrigins = allowed }(AllowedOrigins)
+	AllowedOrigins = []string{"https://play.example.com"}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	tests := []struct {
+		name      string
+		origin    string
+		protocol  string
+		connected bool
+	}{
+		{"another origin", "https://evil.example.com", "", false},
+		{"an allowed origin", "https://play.example.com", EventsProtocol, true},
+		{"the server's own origin", "", "", true},
+	}
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	server, _, _ := newEventsServer(t, sqlxDB, 2)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectQuery(regexp.QuoteMeta(lobbyMembershipQuery)).
+				WithArgs(int64(46), 2, "2").
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+			origin := tt.origin
+			if origin == "" {
+				origin = server.URL
+			}
+			config, err := websocket.NewConfig("ws"+strings.TrimPrefix(server.URL, "http")+"/lobby/events?lobby_id=46", origin)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.protocol != "" {
+				config.Protocol = []string{tt.protocol, "bearer.abc"}
+			}
+
+			ws, err := websocket.DialConfig(config)
+			if (err == nil) != tt.connected {
+				t.Fatalf("got error %v, want connected %v", err, tt.connected)
+			}
+			if err != nil {
+				return
+			}
+			defer ws.Close()
+
+			if tt.protocol != "" && (len(ws.Config().Protocol) != 1 || ws.Config().Protocol[0] != EventsProtocol) {
+				t.Errorf("expected the server to pick %s, got %q", EventsProtocol, ws.Config().Protocol)
+			}
+		})
+	}
+}
+
+func TestLobbyEvents_MissingSession(t *testing.T) {
+	req, err := http.NewRequest("GET", "/lobby/events?lobby_id=42", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	LobbyEventsHandler(rr, req, nil, &auth.SessionStore{})
+
+	if status := rr.Code; status != http.StatusUnauthorized {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusUnauthorized)
+	}
+}
+
+func TestLobbyEvents_ChatOverWebSocket(t *testing.T) {
+	chatLimiter = newChatRateLimiter()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(lobbyMembershipQuery)).
+		WithArgs(int64(43), 2, "2").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	expectChatter(mock, 43, 2, "1", false, false)
+	mock.ExpectQuery(regexp.QuoteMeta(insertMessageQuery)).
+		WithArgs(int64(43), int64(2), "glhf").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+	expectChatter(mock, 43, 2, "1", false, true)
+
+	server, _, _ := newEventsServer(t, sqlx.NewDb(db, "sqlmock"), 2)
+	ws := dialEvents(t, server, 43)
+
+	if err := websocket.JSON.Send(ws, clientMessage{Type: EventChatMessage, Message: "glhf"}); err != nil {
+		t.Fatalf("could not send a chat message: %v", err)
+	}
+
+	var event Event
+	if err := websocket.JSON.Receive(ws, &event); err != nil {
+		t.Fatalf("could not receive an event: %v", err)
+	}
+
+	if event.Type != EventChatMessage || event.Message == nil || event.Message.Message != "glhf" {
+		t.Errorf("unexpected event: %+v", event)
+	}
+
+	// The second message is rejected because the member has been muted in the meantime
+	if err := websocket.JSON.Send(ws, clientMessage{Type: EventChatMessage, Message: "still there?"}); err != nil {
+		t.Fatalf("could not send a chat message: %v", err)
+	}
+
+	if err := websocket.JSON.Receive(ws, &event); err != nil {
+		t.Fatalf("could not receive an event: %v", err)
+	}
+
+	if event.Type != EventError || event.Error != ERROR_MEMBER_MUTED {
+		t.Errorf("unexpected event: %+v", event)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestLobbyEvents_RemovedMemberStopsReceivingEvents(t *testing.T) {
+	for _, removal := range []EventType{EventMemberKicked, EventMemberBanned, EventMemberLeft} {
+		t.Run(string(removal), func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			mock.ExpectQuery(regexp.QuoteMeta(lobbyMembershipQuery)).
+				WithArgs(int64(44), 2, "2").
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+			server, _, _ := newEventsServer(t, sqlx.NewDb(db, "sqlmock"), 2)
+			ws := dialEvents(t, server, 44)
+
+			// Removing another member leaves the subscription alone
+			Events.Publish(Event{Type: removal, LobbyId: 44, AccountId: 3})
+			Events.Publish(Event{Type: removal, LobbyId: 44, AccountId: 2})
+			Events.Publish(Event{Type: EventChatMessage, LobbyId: 44, Message: &Message{Message: "secret"}})
+
+			for _, accountID := range []int64{3, 2} {
+				var event Event
+				if err := websocket.JSON.Receive(ws, &event); err != nil {
+					t.Fatalf("could not receive an event: %v", err)
+				}
+				if event.Type != removal || event.AccountId != accountID {
+					t.Errorf("unexpected event: %+v", event)
+				}
+			}
+
+			var event Event
+			if err := websocket.JSON.Receive(ws, &event); err == nil {
+				t.Errorf("expected the WebSocket to be closed after the member was removed, got %+v", event)
+			}
+		})
+	}
+}
+
+func TestLobbyEvents_LoggedOutSessionStopsReceivingEvents(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(lobbyMembershipQuery)).
+		WithArgs(int64(45), 2, "2").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	server, store, session := newEventsServer(t, sqlx.NewDb(db, "sqlmock"), 2)
+	ws := dialEvents(t, server, 45)
+
+	if err := store.DeleteSession(session.Token); err != nil {
+		t.Fatal(err)
+	}
+	Events.Publish(Event{Type: EventChatMessage, LobbyId: 45, Message: &Message{Message: "secret"}})
+
+	var event Event
+	if err := websocket.JSON.Receive(ws, &event); err == nil {
+		t.Errorf("expected the WebSocket to be closed after the session ended, got %+v", event)
+	}
+}
+
+func TestStreamEvents_ChecksTheSessionWithoutEvents(t *testing.T) {
+	var active atomic.Bool
+	active.Store(true)
+
+	events := make(chan Event)
+	server := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		streamEvents(ws, 46, events, active.Load, 10*time.Millisecond, func(text string) error { return nil })
+	}))
+	t.Cleanup(server.Close)
+
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http"), "", server.URL)
+	if err != nil {
+		t.Fatalf("could not connect to the WebSocket: %v", err)
+	}
+	t.Cleanup(func() { ws.Close() })
+	ws.SetReadDeadline(time.Now().Add(time.Second))
+
+	// Nothing happens in the lobby, but the WebSocket is closed all the same once the session ends
+	active.Store(false)
+
+	var event Event
+	err = websocket.JSON.Receive(ws, &event)
+	if err == nil {
+		t.Fatalf("expected the WebSocket to be closed after the session ended, got %+v", event)
+	}
+	if netErr, ok := err.(interface{ Timeout() bool }); ok && netErr.Timeout() {
+		t.Error("expected the WebSocket to be closed before the read deadline")
+	}
+}
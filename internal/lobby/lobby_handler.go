@@ -3,38 +3,209 @@ package lobby
 import (
 	"net/http"
 
-	"github.com/jmoiron/sqlx"
 	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
 )
 
-func CreateLobbyHandler(w http.ResponseWriter, r *http.Request, db *sqlx.DB, store *auth.SessionStore) {
-	if err := CreateLobby(w, r, db, store); err != nil {
-		// Handle the error, e.g., log it and send an appropriate response to the client
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+func CreateLobbyHandler(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) {
+	if err := CreateLobby(w, r, lobbies, store); err != nil {
+		httpapi.WriteError(w, err)
 		return
 	}
 }
 
-func GetLobbyHandler(w http.ResponseWriter, r *http.Request, db *sqlx.DB, store *auth.SessionStore) {
-	if err := GetLobby(w, r, db, store); err != nil {
-		// Handle the error, e.g., log it and send an appropriate response to the client
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+func CreateLobbyV2Handler(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) {
+	if err := CreateLobbyV2(w, r, lobbies, store); err != nil {
+		httpapi.WriteError(w, err)
 		return
 	}
 }
 
-func UpdateLobbyHandler(w http.ResponseWriter, r *http.Request, db *sqlx.DB, store *auth.SessionStore) {
-	if err := UpdateLobby(w, r, db, store); err != nil {
-		// Handle the error, e.g., log it and send an appropriate response to the client
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+func GetLobbyHandler(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) {
+	if err := GetLobby(w, r, lobbies, store); err != nil {
+		httpapi.WriteError(w, err)
 		return
 	}
 }
 
-func DeleteLobbyHandler(w http.ResponseWriter, r *http.Request, db *sqlx.DB, store *auth.SessionStore) {
-	if err := DeleteLobby(w, r, db, store); err != nil {
-		// Handle the error, e.g., log it and send an appropriate response to the client
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+func UpdateLobbyHandler(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) {
+	if err := UpdateLobby(w, r, lobbies, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func DeleteLobbyHandler(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) {
+	if err := DeleteLobby(w, r, lobbies, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func JoinLobbyHandler(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) {
+	if err := JoinLobby(w, r, lobbies, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func LeaveLobbyHandler(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) {
+	if err := LeaveLobby(w, r, lobbies, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func KickMemberHandler(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) {
+	if err := KickMember(w, r, lobbies, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func ListMembersHandler(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) {
+	if err := ListMembers(w, r, lobbies, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func ListLobbiesHandler(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) {
+	if err := ListLobbies(w, r, lobbies, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func SetReadyHandler(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) {
+	if err := SetReady(w, r, lobbies, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func LobbyEventsHandler(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) {
+	if err := LobbyEvents(w, r, lobbies, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func SendMessageHandler(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) {
+	if err := SendMessage(w, r, lobbies, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func ListMessagesHandler(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) {
+	if err := ListMessages(w, r, lobbies, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func MuteMemberHandler(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) {
+	if err := MuteMember(w, r, lobbies, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func TransferOwnershipHandler(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) {
+	if err := TransferOwnership(w, r, lobbies, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func DeleteLobbyV2Handler(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) {
+	if err := DeleteLobbyV2(w, r, lobbies, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func GetLobbyV2Handler(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) {
+	if err := GetLobbyV2(w, r, lobbies, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func JoinLobbyV2Handler(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) {
+	if err := JoinLobbyV2(w, r, lobbies, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func KickMemberV2Handler(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) {
+	if err := KickMemberV2(w, r, lobbies, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func LeaveLobbyV2Handler(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) {
+	if err := LeaveLobbyV2(w, r, lobbies, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func ListMembersV2Handler(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) {
+	if err := ListMembersV2(w, r, lobbies, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func ListMessagesV2Handler(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) {
+	if err := ListMessagesV2(w, r, lobbies, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func LobbyEventsV2Handler(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) {
+	if err := LobbyEventsV2(w, r, lobbies, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func MuteMemberV2Handler(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) {
+	if err := MuteMemberV2(w, r, lobbies, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func SendMessageV2Handler(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) {
+	if err := SendMessageV2(w, r, lobbies, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func SetReadyV2Handler(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) {
+	if err := SetReadyV2(w, r, lobbies, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func TransferOwnershipV2Handler(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) {
+	if err := TransferOwnershipV2(w, r, lobbies, store); err != nil {
+		httpapi.WriteError(w, err)
+		return
+	}
+}
+
+func UpdateLobbyV2Handler(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) {
+	if err := UpdateLobbyV2(w, r, lobbies, store); err != nil {
+		httpapi.WriteError(w, err)
 		return
 	}
 }
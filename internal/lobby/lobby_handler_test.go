@@ -6,12 +6,14 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
 	"strings"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
 	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi/httpapitest"
 )
 
 func TestCreateLobbyHandler_Success(t *testing.T) {
@@ -30,9 +32,18 @@ func TestCreateLobbyHandler_Success(t *testing.T) {
 		IsPublic:       true,
 	}
 
-	mock.ExpectQuery("INSERT INTO lobby \\(name, owner_name, owner_account_id, is_closed, is_muted, is_public\\) VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5, \\$6\\)").
-		WithArgs(lobby.Name, lobby.OwnerName, lobby.OwnerAccountId, lobby.IsClosed, lobby.IsMuted, lobby.IsPublic).
+	mock.ExpectQuery("SELECT name FROM account WHERE id = \\$1").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow(lobby.OwnerName))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO lobby \\(name, owner_name, owner_account_id, is_closed, is_muted, is_public, password_hash\\) VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5, \\$6, \\$7\\)").
+		WithArgs(lobby.Name, lobby.OwnerName, lobby.OwnerAccountId, lobby.IsClosed, lobby.IsMuted, lobby.IsPublic, sqlmock.AnyArg()).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO lobby_member (lobby_id, account_id) VALUES ($1, $2)")).
+		WithArgs(int64(1), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
 
 	lobbyBytes, err := json.Marshal(lobby)
 	if err != nil {
@@ -44,6 +55,7 @@ func TestCreateLobbyHandler_Success(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	req = withSession(req, 1)
 
 	rr := httptest.NewRecorder()
 	sqlxDB := sqlx.NewDb(db, "sqlmock")
@@ -53,7 +65,7 @@ func TestCreateLobbyHandler_Success(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		CreateLobbyHandler(w, r, sqlxDB, mockStore)
+		CreateLobbyHandler(w, r, NewPostgresLobbyRepository(sqlxDB), mockStore)
 	})
 
 	handler.ServeHTTP(rr, req)
@@ -83,18 +95,18 @@ func TestCreateLobbyHandler_InvalidMethod(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		CreateLobbyHandler(w, r, sqlxDB, mockStore)
+		CreateLobbyHandler(w, r, NewPostgresLobbyRepository(sqlxDB), mockStore)
 	})
 
 	handler.ServeHTTP(rr, req)
 
-	if status := rr.Code; status != http.StatusInternalServerError {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+	if status := rr.Code; status != http.StatusMethodNotAllowed {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusMethodNotAllowed)
 	}
 
 	expectedError := "invalid request; request must be a POST request"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
 
@@ -118,18 +130,18 @@ func TestCreateLobbyHandler_DecodeError(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		CreateLobbyHandler(w, r, sqlxDB, mockStore)
+		CreateLobbyHandler(w, r, NewPostgresLobbyRepository(sqlxDB), mockStore)
 	})
 
 	handler.ServeHTTP(rr, req)
 
-	if status := rr.Code; status != http.StatusInternalServerError {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
 	}
 
-	expectedError := "an error occurred while decoding the request body:json: cannot unmarshal number into Go struct field CreateLobbyArgs.password of type string"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	expectedError := "an error occurred while decoding the request body: json: cannot unmarshal number into Go struct field CreateLobbyArgs.password of type string"
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
 
@@ -168,7 +180,7 @@ func TestCreateLobbyHandler_PasswordTooShort(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		CreateLobbyHandler(w, r, sqlxDB, mockStore)
+		CreateLobbyHandler(w, r, NewPostgresLobbyRepository(sqlxDB), mockStore)
 	})
 
 	handler.ServeHTTP(rr, req)
@@ -177,9 +189,9 @@ func TestCreateLobbyHandler_PasswordTooShort(t *testing.T) {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
 	}
 
-	expectedError := ERROR_PASSWORD_TOO_SHORT
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	expectedError := "password must be at least 6 characters"
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
 
@@ -203,7 +215,7 @@ func TestCreateLobbyHandler_PasswordRequired(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		CreateLobbyHandler(w, r, sqlxDB, mockStore)
+		CreateLobbyHandler(w, r, NewPostgresLobbyRepository(sqlxDB), mockStore)
 	})
 
 	handler.ServeHTTP(rr, req)
@@ -212,9 +224,9 @@ func TestCreateLobbyHandler_PasswordRequired(t *testing.T) {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
 	}
 
-	expectedError := ERROR_PASSWORD_REQUIRED_BUT_NO_PASSWORD
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	expectedError := "password must be specified"
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
 
@@ -234,12 +246,12 @@ func TestGetLobbyHandler_Success(t *testing.T) {
 		IsPublic:  true,
 	}
 
-	mock.ExpectQuery("SELECT id, name, owner_name, is_closed, is_muted, is_public FROM lobby WHERE id = \\$1").
+	mock.ExpectQuery("SELECT id, name, owner_name, owner_account_id, is_closed, is_muted, is_public FROM lobby WHERE id = \\$1").
 		WithArgs(lobby.ID).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "owner_name", "is_closed", "is_muted", "is_public"}).
 			AddRow(lobby.ID, lobby.Name, lobby.OwnerName, lobby.IsClosed, lobby.IsMuted, lobby.IsPublic))
 
-	req, err := http.NewRequest("GET", "/lobby/get_lobby", strings.NewReader(`{"lobby_id": 1}`))
+	req, err := http.NewRequest("GET", "/lobby/get_lobby?lobby_id=1", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -252,7 +264,7 @@ func TestGetLobbyHandler_Success(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		GetLobbyHandler(w, r, sqlxDB, mockStore)
+		GetLobbyHandler(w, r, NewPostgresLobbyRepository(sqlxDB), mockStore)
 	})
 
 	handler.ServeHTTP(rr, req)
@@ -269,7 +281,7 @@ func TestGetLobbyHandler_InvalidMethod(t *testing.T) {
 	}
 	defer db.Close()
 
-	req, err := http.NewRequest("POST", "/lobby/get_lobby", strings.NewReader(`{"lobby_id": 1}`))
+	req, err := http.NewRequest("POST", "/lobby/get_lobby?lobby_id=1", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -282,29 +294,29 @@ func TestGetLobbyHandler_InvalidMethod(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		GetLobbyHandler(w, r, sqlxDB, mockStore)
+		GetLobbyHandler(w, r, NewPostgresLobbyRepository(sqlxDB), mockStore)
 	})
 
 	handler.ServeHTTP(rr, req)
 
-	if status := rr.Code; status != http.StatusInternalServerError {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+	if status := rr.Code; status != http.StatusMethodNotAllowed {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusMethodNotAllowed)
 	}
 
 	expectedError := "invalid request; request must be a GET request"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
 
-func TestGetLobbyHandler_DecodeError(t *testing.T) {
+func TestGetLobbyHandler_InvalidLobbyID(t *testing.T) {
 	db, _, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 	defer db.Close()
 
-	req, err := http.NewRequest("GET", "/lobby/get_lobby", strings.NewReader(`{"lobby_id": "invalid"}`))
+	req, err := http.NewRequest("GET", "/lobby/get_lobby?lobby_id=invalid", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -317,18 +329,18 @@ func TestGetLobbyHandler_DecodeError(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		GetLobbyHandler(w, r, sqlxDB, mockStore)
+		GetLobbyHandler(w, r, NewPostgresLobbyRepository(sqlxDB), mockStore)
 	})
 
 	handler.ServeHTTP(rr, req)
 
-	if status := rr.Code; status != http.StatusInternalServerError {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
 	}
 
-	expectedError := "an error occurred while decoding the request body: json: cannot unmarshal string into Go struct field GetLobbyArgs.lobby_id of type int8"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	expectedError := "invalid lobby_id"
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
 func TestGetLobbyHandler_LobbyNotFound(t *testing.T) {
@@ -338,11 +350,11 @@ func TestGetLobbyHandler_LobbyNotFound(t *testing.T) {
 	}
 	defer db.Close()
 
-	mock.ExpectQuery("SELECT id, name, owner_name, is_closed, is_muted, is_public FROM lobby WHERE id = \\$1").
+	mock.ExpectQuery("SELECT id, name, owner_name, owner_account_id, is_closed, is_muted, is_public FROM lobby WHERE id = \\$1").
 		WithArgs(1).
 		WillReturnError(sql.ErrNoRows)
 
-	req, err := http.NewRequest("GET", "/lobby/get_lobby", strings.NewReader(`{"lobby_id": 1}`))
+	req, err := http.NewRequest("GET", "/lobby/get_lobby?lobby_id=1", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -355,18 +367,18 @@ func TestGetLobbyHandler_LobbyNotFound(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		GetLobbyHandler(w, r, sqlxDB, mockStore)
+		GetLobbyHandler(w, r, NewPostgresLobbyRepository(sqlxDB), mockStore)
 	})
 
 	handler.ServeHTTP(rr, req)
 
-	if status := rr.Code; status != http.StatusInternalServerError {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusNotFound)
 	}
 
 	expectedError := "no lobby exists with the ID 1"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
 
@@ -377,6 +389,8 @@ func TestDeleteLobbyHandler_Success(t *testing.T) {
 	}
 	defer db.Close()
 
+	expectLobbyOwner(mock, 1, "1")
+
 	mock.ExpectExec("DELETE FROM lobby WHERE id = \\$1").
 		WithArgs(1).
 		WillReturnResult(sqlmock.NewResult(1, 1))
@@ -385,6 +399,7 @@ func TestDeleteLobbyHandler_Success(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	req = withSession(req, 1)
 
 	rr := httptest.NewRecorder()
 	sqlxDB := sqlx.NewDb(db, "sqlmock")
@@ -394,7 +409,7 @@ func TestDeleteLobbyHandler_Success(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		DeleteLobbyHandler(w, r, sqlxDB, mockStore)
+		DeleteLobbyHandler(w, r, NewPostgresLobbyRepository(sqlxDB), mockStore)
 	})
 
 	handler.ServeHTTP(rr, req)
@@ -404,8 +419,8 @@ func TestDeleteLobbyHandler_Success(t *testing.T) {
 	}
 
 	expected := "Successfully deleted lobby!"
-	if strings.TrimSpace(rr.Body.String()) != expected {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expected)
+	if httpapitest.Message(rr) != expected {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expected)
 	}
 }
 
@@ -429,18 +444,18 @@ func TestDeleteLobbyHandler_InvalidMethod(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		DeleteLobbyHandler(w, r, sqlxDB, mockStore)
+		DeleteLobbyHandler(w, r, NewPostgresLobbyRepository(sqlxDB), mockStore)
 	})
 
 	handler.ServeHTTP(rr, req)
 
-	if status := rr.Code; status != http.StatusInternalServerError {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+	if status := rr.Code; status != http.StatusMethodNotAllowed {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusMethodNotAllowed)
 	}
 
 	expectedError := "invalid request; request must be a DELETE request"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
 
@@ -464,18 +479,18 @@ func TestDeleteLobbyHandler_DecodeError(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		DeleteLobbyHandler(w, r, sqlxDB, mockStore)
+		DeleteLobbyHandler(w, r, NewPostgresLobbyRepository(sqlxDB), mockStore)
 	})
 
 	handler.ServeHTTP(rr, req)
 
-	if status := rr.Code; status != http.StatusInternalServerError {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
 	}
 
 	expectedError := "an error occurred while decoding the request body: json: cannot unmarshal string into Go struct field DeleteLobbyArgs.lobby_id of type int64"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
 
@@ -486,6 +501,8 @@ func TestDeleteLobbyHandler_LobbyNotFound(t *testing.T) {
 	}
 	defer db.Close()
 
+	expectLobbyOwner(mock, 1, "1")
+
 	mock.ExpectExec("DELETE FROM lobby WHERE id = \\$1").
 		WithArgs(1).
 		WillReturnResult(sqlmock.NewResult(1, 0))
@@ -494,6 +511,7 @@ func TestDeleteLobbyHandler_LobbyNotFound(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	req = withSession(req, 1)
 
 	rr := httptest.NewRecorder()
 	sqlxDB := sqlx.NewDb(db, "sqlmock")
@@ -503,18 +521,18 @@ func TestDeleteLobbyHandler_LobbyNotFound(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		DeleteLobbyHandler(w, r, sqlxDB, mockStore)
+		DeleteLobbyHandler(w, r, NewPostgresLobbyRepository(sqlxDB), mockStore)
 	})
 
 	handler.ServeHTTP(rr, req)
 
-	if status := rr.Code; status != http.StatusInternalServerError {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusNotFound)
 	}
 
 	expectedError := "no lobby exists with the ID 1"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
 
@@ -538,7 +556,7 @@ func TestDeleteLobbyHandler_LobbyIdRequired(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		DeleteLobbyHandler(w, r, sqlxDB, mockStore)
+		DeleteLobbyHandler(w, r, NewPostgresLobbyRepository(sqlxDB), mockStore)
 	})
 
 	handler.ServeHTTP(rr, req)
@@ -548,7 +566,7 @@ func TestDeleteLobbyHandler_LobbyIdRequired(t *testing.T) {
 	}
 
 	expectedError := "lobby_id must be specified"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
@@ -0,0 +1,45 @@
+package lobby
+
+import (
+	"time"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/account"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+)
+
+const ERROR_LOBBY_CLOSED = "the lobby is closed"
+const ERROR_BANNED_FROM_LOBBY = "you have been banned from this lobby"
+const ERROR_CANNOT_KICK_OWNER = "the lobby owner cannot be kicked"
+const ERROR_LOBBY_FULL = "the lobby is full"
+
+// The codes of the errors about joining and membership.
+const (
+	CodeLobbyClosed     httpapi.Code = "lobby_closed"
+	CodeBannedFromLobby httpapi.Code = "banned_from_lobby"
+	CodeCannotKickOwner httpapi.Code = "cannot_kick_owner"
+	CodeNotLobbyMember  httpapi.Code = "not_lobby_member"
+	CodeLobbyFull       httpapi.Code = "lobby_full"
+)
+
+// Member represents an account which has joined a lobby.
+//
+// @Description Structure for representing a member of a player lobby.
+type Member struct {
+	// AccountId is the ID of the member's account.
+	AccountId int64 `json:"account_id" db:"account_id"`
+
+	// Name is the member's account name.
+	Name string `json:"name" db:"name"`
+
+	// ExperienceLevel is the member's experience level (0=beginner, 1=easy, 2=medium, 3=hard, 4=very hard, 5=impossible).
+	ExperienceLevel account.ExperienceLevel `json:"experience_level" db:"experience_level"`
+
+	// IsReady indicates whether the member is ready for the game to start.
+	IsReady bool `json:"is_ready" db:"is_ready"`
+
+	// IsMuted indicates whether the lobby owner has stopped the member sending chat messages.
+	IsMuted bool `json:"is_muted" db:"is_muted"`
+
+	// JoinedAt is when the member joined the lobby.
+	JoinedAt time.Time `json:"joined_at" db:"joined_at"`
+}
@@ -0,0 +1,577 @@
+package lobby
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/account"
+)
+
+// defaultMaxMembers is the number of members a lobby has room for, as set by the lobby table's default.
+const defaultMaxMembers = 8
+
+// memoryLobby is a lobby held by MemoryLobbyRepository together with its members, bans and chat history.
+type memoryLobby struct {
+	lobby     Lobby
+	createdAt time.Time
+	password  LobbyPassword
+	members   map[int64]*Member
+	bans      map[int64]bool
+	messages  []Message
+}
+
+// MemoryLobbyRepository keeps lobbies in memory. Account names and experience levels are looked up in
+// the given AccountRepository, which plays the part of the account table.
+type MemoryLobbyRepository struct {
+	accounts account.AccountRepository
+
+	mu            sync.RWMutex
+	nextLobbyID   int64
+	nextMessageID int64
+	lobbies       map[int64]*memoryLobby
+}
+
+// NewMemoryLobbyRepository creates an empty in-memory LobbyRepository.
+func NewMemoryLobbyRepository(accounts account.AccountRepository) *MemoryLobbyRepository {
+	return &MemoryLobbyRepository{accounts: accounts, lobbies: map[int64]*memoryLobby{}}
+}
+
+func (m *MemoryLobbyRepository) CreateLobby(lobby *Lobby, password LobbyPassword) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ownerAccountID, err := strconv.ParseInt(lobby.OwnerAccountId, 10, 64)
+	if err != nil {
+		return fmt.Errorf("the owner account ID %q is not a number", lobby.OwnerAccountId)
+	}
+
+	m.nextLobbyID++
+	lobby.ID = m.nextLobbyID
+	createdAt := time.Now()
+	m.lobbies[lobby.ID] = &memoryLobby{
+		lobby:     *lobby,
+		createdAt: createdAt,
+		password:  password,
+		members:   map[int64]*Member{ownerAccountID: {AccountId: ownerAccountID, JoinedAt: createdAt}},
+		bans:      map[int64]bool{},
+	}
+	return nil
+}
+
+func (m *MemoryLobbyRepository) GetLobby(lobbyID int64) (*Lobby, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stored, ok := m.lobbies[lobbyID]
+	if !ok {
+		return nil, ErrLobbyNotFound
+	}
+
+	lobby := stored.lobby
+	return &lobby, nil
+}
+
+func (m *MemoryLobbyRepository) ListLobbies(options LobbyListOptions) ([]LobbySummary, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	order := lobbySortOrders[options.Sort]
+
+	lobbies := []LobbySummary{}
+	for _, stored := range m.lobbies {
+		if !stored.lobby.IsPublic || stored.lobby.IsClosed {
+			continue
+		}
+
+		summary := LobbySummary{
+			Lobby:       stored.lobby,
+			CreatedAt:   stored.createdAt,
+			MaxMembers:  defaultMaxMembers,
+			MemberCount: len(stored.members),
+		}
+		if ownerID, err := strconv.ParseInt(stored.lobby.OwnerAccountId, 10, 64); err == nil {
+			if owner, err := m.accounts.GetAccount(ownerID); err == nil {
+				summary.OwnerExperienceLevel = owner.ExperienceLevel
+			}
+		}
+
+		if options.Name != "" && !strings.Contains(strings.ToLower(summary.Name), strings.ToLower(options.Name)) {
+			continue
+		}
+		if options.HasFreeSlots != nil && (summary.MemberCount < summary.MaxMembers) != *options.HasFreeSlots {
+			continue
+		}
+		if options.IsMuted != nil && summary.IsMuted != *options.IsMuted {
+			continue
+		}
+		if options.OwnerExperienceLevel != nil && summary.OwnerExperienceLevel != *options.OwnerExperienceLevel {
+			continue
+		}
+		if options.After != nil {
+			comparison := compareLobbyPosition(options.Sort, &summary, options.After)
+			if (order.ascending && comparison <= 0) || (!order.ascending && comparison >= 0) {
+				continue
+			}
+		}
+
+		lobbies = append(lobbies, summary)
+	}
+
+	sort.Slice(lobbies, func(i, j int) bool {
+		comparison := compareLobbyPosition(options.Sort, &lobbies[i], lobbyPosition(options.Sort, &lobbies[j]))
+		if order.ascending {
+			return comparison < 0
+		}
+		return comparison > 0
+	})
+
+	if len(lobbies) > options.Limit {
+		lobbies = lobbies[:options.Limit]
+	}
+	return lobbies, nil
+}
+
+// lobbyPosition returns the position of the lobby in the given sort order.
+func lobbyPosition(sort LobbySort, summary *LobbySummary) *LobbyPosition {
+	switch sort {
+	case LobbySortName:
+		return &LobbyPosition{Value: summary.Name, ID: summary.ID}
+	case LobbySortMostMembers:
+		return &LobbyPosition{Value: summary.MemberCount, ID: summary.ID}
+	default:
+		return &LobbyPosition{Value: summary.CreatedAt, ID: summary.ID}
+	}
+}
+
+// compareLobbyPosition compares the lobby's position in the given sort order against another position,
+// returning -1, 0 or 1 the same way as the (column, id) row comparison used by Postgres.
+func compareLobbyPosition(sort LobbySort, summary *LobbySummary, position *LobbyPosition) int {
+	comparison := 0
+	switch sort {
+	case LobbySortName:
+		comparison = strings.Compare(summary.Name, position.Value.(string))
+	case LobbySortMostMembers:
+		comparison = compareInt64(int64(summary.MemberCount), int64(position.Value.(int)))
+	default:
+		comparison = summary.CreatedAt.Compare(position.Value.(time.Time))
+	}
+
+	if comparison != 0 {
+		return comparison
+	}
+	return compareInt64(summary.ID, position.ID)
+}
+
+func compareInt64(a int64, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (m *MemoryLobbyRepository) UpdateLobby(lobbyID int64, update *LobbyParam, password *LobbyPassword) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.lobbies[lobbyID]
+	if !ok {
+		return nil
+	}
+
+	if update.Name != nil {
+		stored.lobby.Name = *update.Name
+	}
+	if update.IsClosed != nil {
+		stored.lobby.IsClosed = *update.IsClosed
+	}
+	if update.IsMuted != nil {
+		stored.lobby.IsMuted = *update.IsMuted
+	}
+	if update.IsPublic != nil {
+		stored.lobby.IsPublic = *update.IsPublic
+	}
+	if password != nil {
+		stored.password = *password
+	}
+
+	return nil
+}
+
+func (m *MemoryLobbyRepository) DeleteLobby(lobbyID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.lobbies[lobbyID]; !ok {
+		return ErrLobbyNotFound
+	}
+
+	delete(m.lobbies, lobbyID)
+	return nil
+}
+
+func (m *MemoryLobbyRepository) LobbyOwner(lobbyID int64) (string, error) {
+	lobby, err := m.GetLobby(lobbyID)
+	if err != nil {
+		return "", err
+	}
+	return lobby.OwnerAccountId, nil
+}
+
+func (m *MemoryLobbyRepository) LobbyAccess(lobbyID int64) (*LobbyAccess, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stored, ok := m.lobbies[lobbyID]
+	if !ok {
+		return nil, ErrLobbyNotFound
+	}
+
+	return &LobbyAccess{
+		OwnerAccountId: stored.lobby.OwnerAccountId,
+		IsClosed:       stored.lobby.IsClosed,
+		IsPublic:       stored.lobby.IsPublic,
+		PasswordHash:   sql.NullString{String: stored.password.Hash, Valid: stored.password.Hash != ""},
+	}, nil
+}
+
+func (m *MemoryLobbyRepository) AccountName(accountID int64) (string, error) {
+	return m.accounts.AccountName(accountID)
+}
+
+func (m *MemoryLobbyRepository) AddMember(lobbyID int64, accountID int64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.lobbies[lobbyID]
+	if !ok {
+		return false, ErrLobbyNotFound
+	}
+
+	if _, ok := stored.members[accountID]; ok {
+		return false, nil
+	}
+
+	if len(stored.members) >= defaultMaxMembers {
+		return false, ErrLobbyFull
+	}
+
+	stored.members[accountID] = &Member{AccountId: accountID, JoinedAt: time.Now()}
+	return true, nil
+}
+
+func (m *MemoryLobbyRepository) RemoveMember(lobbyID int64, accountID int64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.lobbies[lobbyID]
+	if !ok {
+		return false, nil
+	}
+
+	if _, ok := stored.members[accountID]; !ok {
+		return false, nil
+	}
+
+	delete(stored.members, accountID)
+	return true, nil
+}
+
+func (m *MemoryLobbyRepository) ListMembers(lobbyID int64) ([]Member, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	members := []Member{}
+	stored, ok := m.lobbies[lobbyID]
+	if !ok {
+		return members, nil
+	}
+
+	for _, member := range m.sortedMembers(stored) {
+		// Members whose account is gone are left out, as they are by the join against the account table
+		memberAccount, err := m.accounts.GetAccount(member.AccountId)
+		if err != nil {
+			continue
+		}
+
+		listed := *member
+		listed.Name = memberAccount.Name
+		listed.ExperienceLevel = memberAccount.ExperienceLevel
+		members = append(members, listed)
+	}
+
+	return members, nil
+}
+
+// sortedMembers returns the members of the lobby in the order they joined. The caller must hold the lock.
+func (m *MemoryLobbyRepository) sortedMembers(stored *memoryLobby) []*Member {
+	members := make([]*Member, 0, len(stored.members))
+	for _, member := range stored.members {
+		members = append(members, member)
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		if !members[i].JoinedAt.Equal(members[j].JoinedAt) {
+			return members[i].JoinedAt.Before(members[j].JoinedAt)
+		}
+		return members[i].AccountId < members[j].AccountId
+	})
+	return members
+}
+
+func (m *MemoryLobbyRepository) IsMemberOrOwner(lobbyID int64, accountID int64) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stored, ok := m.lobbies[lobbyID]
+	if !ok {
+		return false, nil
+	}
+
+	_, isMember := stored.members[accountID]
+	return isMember || stored.lobby.OwnerAccountId == strconv.FormatInt(accountID, 10), nil
+}
+
+func (m *MemoryLobbyRepository) SetReady(lobbyID int64, accountID int64, ready bool) error {
+	return m.updateMember(lobbyID, accountID, func(member *Member) { member.IsReady = ready })
+}
+
+func (m *MemoryLobbyRepository) SetMuted(lobbyID int64, accountID int64, muted bool) error {
+	return m.updateMember(lobbyID, accountID, func(member *Member) { member.IsMuted = muted })
+}
+
+func (m *MemoryLobbyRepository) updateMember(lobbyID int64, accountID int64, update func(member *Member)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.lobbies[lobbyID]
+	if !ok {
+		return ErrNotLobbyMember
+	}
+
+	member, ok := stored.members[accountID]
+	if !ok {
+		return ErrNotLobbyMember
+	}
+
+	update(member)
+	return nil
+}
+
+func (m *MemoryLobbyRepository) Leave(lobbyID int64, accountID int64) (*LeaveResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.lobbies[lobbyID]
+	if !ok {
+		return nil, ErrLobbyNotFound
+	}
+	isOwner := stored.lobby.OwnerAccountId == strconv.FormatInt(accountID, 10)
+
+	// The owner is in the lobby whether or not they joined it
+	if _, isMember := stored.members[accountID]; !isMember && !isOwner {
+		return nil, ErrNotLobbyMember
+	}
+	delete(stored.members, accountID)
+
+	leave := &LeaveResult{}
+	if !isOwner {
+		return leave, nil
+	}
+
+	members := m.sortedMembers(stored)
+	if len(members) == 0 {
+		// Nobody is left to hand the lobby to
+		delete(m.lobbies, lobbyID)
+		leave.LobbyDeleted = true
+		return leave, nil
+	}
+
+	if err := m.setLobbyOwner(stored, members[0].AccountId); err != nil {
+		return nil, err
+	}
+	leave.NewOwnerID, leave.HasNewOwner = members[0].AccountId, true
+	return leave, nil
+}
+
+func (m *MemoryLobbyRepository) TransferOwnership(lobbyID int64, fromAccountID int64, toAccountID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.lobbies[lobbyID]
+	if !ok {
+		return ErrNotLobbyMember
+	}
+
+	if _, ok := stored.members[toAccountID]; !ok {
+		return ErrNotLobbyMember
+	}
+
+	if err := m.setLobbyOwner(stored, toAccountID); err != nil {
+		return err
+	}
+
+	// The previous owner may never have joined, but should not be shut out of the lobby they handed over
+	if _, ok := stored.members[fromAccountID]; !ok {
+		stored.members[fromAccountID] = &Member{AccountId: fromAccountID, JoinedAt: time.Now()}
+	}
+	return nil
+}
+
+// ReleaseOwnedLobbies hands every lobby the account owns to its longest present member, or deletes the lobby
+// when nobody else is in it. The account's memberships, bans and messages go too, as they would by cascading
+// in Postgres. It is meant to be one of the MemoryAccountRepository's DeleteHooks, so the names of the
+// possible new owners are looked up first, and the commit changing the lobbies cannot fail.
+func (m *MemoryLobbyRepository) ReleaseOwnedLobbies(accountID int64) (func(), error) {
+	owner := strconv.FormatInt(accountID, 10)
+
+	m.mu.RLock()
+	names := map[int64]string{}
+	for _, stored := range m.lobbies {
+		if stored.lobby.OwnerAccountId != owner {
+			continue
+		}
+		for memberID := range stored.members {
+			if memberID == accountID {
+				continue
+			}
+			name, err := m.accounts.AccountName(memberID)
+			if err != nil {
+				m.mu.RUnlock()
+				return nil, err
+			}
+			names[memberID] = name
+		}
+	}
+	m.mu.RUnlock()
+
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		for lobbyID, stored := range m.lobbies {
+			delete(stored.members, accountID)
+			delete(stored.bans, accountID)
+
+			messages := stored.messages[:0]
+			for _, message := range stored.messages {
+				if message.AccountId != accountID {
+					messages = append(messages, message)
+				}
+			}
+			stored.messages = messages
+
+			if stored.lobby.OwnerAccountId != owner {
+				continue
+			}
+
+			// Members who joined after the names were looked up are passed over
+			released := false
+			for _, member := range m.sortedMembers(stored) {
+				if name, ok := names[member.AccountId]; ok {
+					stored.lobby.OwnerAccountId = strconv.FormatInt(member.AccountId, 10)
+					stored.lobby.OwnerName = name
+					released = true
+					break
+				}
+			}
+			if !released {
+				delete(m.lobbies, lobbyID)
+			}
+		}
+	}, nil
+}
+
+// setLobbyOwner makes the account the owner of the lobby. The caller must hold the lock.
+func (m *MemoryLobbyRepository) setLobbyOwner(stored *memoryLobby, accountID int64) error {
+	name, err := m.accounts.AccountName(accountID)
+	if err != nil {
+		return err
+	}
+
+	stored.lobby.OwnerAccountId = strconv.FormatInt(accountID, 10)
+	stored.lobby.OwnerName = name
+	return nil
+}
+
+func (m *MemoryLobbyRepository) IsBanned(lobbyID int64, accountID int64) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stored, ok := m.lobbies[lobbyID]
+	return ok && stored.bans[accountID], nil
+}
+
+func (m *MemoryLobbyRepository) Ban(lobbyID int64, accountID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.lobbies[lobbyID]
+	if !ok {
+		return ErrLobbyNotFound
+	}
+
+	stored.bans[accountID] = true
+	return nil
+}
+
+func (m *MemoryLobbyRepository) Chatter(lobbyID int64, accountID int64) (*Chatter, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stored, ok := m.lobbies[lobbyID]
+	if !ok {
+		return nil, ErrLobbyNotFound
+	}
+
+	chatter := &Chatter{OwnerAccountId: stored.lobby.OwnerAccountId, LobbyMuted: stored.lobby.IsMuted}
+	if member, ok := stored.members[accountID]; ok {
+		chatter.MemberMuted = sql.NullBool{Bool: member.IsMuted, Valid: true}
+	}
+	return chatter, nil
+}
+
+func (m *MemoryLobbyRepository) AddMessage(message *Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.lobbies[message.LobbyId]
+	if !ok {
+		return ErrLobbyNotFound
+	}
+
+	m.nextMessageID++
+	message.ID = m.nextMessageID
+	message.CreatedAt = time.Now()
+	stored.messages = append(stored.messages, *message)
+	return nil
+}
+
+func (m *MemoryLobbyRepository) ListMessages(lobbyID int64, before *int64, limit int) ([]Message, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	messages := []Message{}
+	stored, ok := m.lobbies[lobbyID]
+	if !ok {
+		return messages, nil
+	}
+
+	// Messages are stored oldest first, so walk them backwards to return the newest first
+	for i := len(stored.messages) - 1; i >= 0 && len(messages) < limit; i-- {
+		if before != nil && stored.messages[i].ID >= *before {
+			continue
+		}
+		messages = append(messages, stored.messages[i])
+	}
+
+	return messages, nil
+}
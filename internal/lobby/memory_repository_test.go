@@ -0,0 +1,415 @@
+package lobby
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/account"
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi/httpapitest"
+)
+
+// newMemoryLobbies creates an in-memory lobby repository with an account for each of the given names,
+// which get the IDs 1, 2, 3 and so on.
+func newMemoryLobbies(t *testing.T, names ...string) (*MemoryLobbyRepository, *auth.SessionStore) {
+	accounts := account.NewMemoryAccountRepository()
+	for _, name := range names {
+		if _, err := accounts.CreateAccount(&account.Account{Name: name, Email: strings.ToLower(name) + "@example.com", ExperienceLevel: account.Hard}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return NewMemoryLobbyRepository(accounts), auth.NewSessionStoreWithRepository(auth.NewMemorySessionRepository())
+}
+
+// callLobbyHandler calls a lobby handler as the given account and returns the response.
+func callLobbyHandler(handler func(http.ResponseWriter, *http.Request, LobbyRepository, *auth.SessionStore), lobbies LobbyRepository, store *auth.SessionStore, method string, url string, body string, accountID int) *httptest.ResponseRecorder {
+	req := withSession(httptest.NewRequest(method, url, strings.NewReader(body)), accountID)
+	rr := httptest.NewRecorder()
+	handler(rr, req, lobbies, store)
+	return rr
+}
+
+// callLobbyRoute calls a lobby handler registered under a /v2 route pattern, so that it can read the
+// wildcards in the URL, as the given account and returns the response.
+func callLobbyRoute(pattern string, handler func(http.ResponseWriter, *http.Request, LobbyRepository, *auth.SessionStore), lobbies LobbyRepository, store *auth.SessionStore, method string, url string, body string, accountID int) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		handler(w, r, lobbies, store)
+	})
+
+	req := withSession(httptest.NewRequest(method, url, strings.NewReader(body)), accountID)
+	rr := httptest.NewRecorder()
+	httpapi.JSONErrors(mux).ServeHTTP(rr, req)
+	return rr
+}
+
+func TestMemoryLobbyRepository_LobbyLifecycle(t *testing.T) {
+	lobbies, store := newMemoryLobbies(t, "Owner", "Guest", "Latecomer")
+
+	rr := callLobbyHandler(CreateLobbyHandler, lobbies, store, http.MethodPost, "/lobby/create_lobby", `{"lobby": {"name": "Test Lobby", "is_public": true}, "password": "password123"}`, 1)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("CreateLobbyHandler returned %d: %s", rr.Code, rr.Body.String())
+	}
+
+	for _, accountID := range []int{2, 3} {
+		rr = callLobbyHandler(JoinLobbyHandler, lobbies, store, http.MethodPost, "/lobby/join_lobby", `{"lobby_id": 1}`, accountID)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("JoinLobbyHandler returned %d: %s", rr.Code, rr.Body.String())
+		}
+	}
+
+	rr = callLobbyHandler(SetReadyHandler, lobbies, store, http.MethodPost, "/lobby/set_ready", `{"lobby_id": 1, "ready": true}`, 2)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("SetReadyHandler returned %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = callLobbyHandler(ListMembersHandler, lobbies, store, http.MethodGet, "/lobby/list_members?lobby_id=1", "", 1)
+	var members []Member
+	if err := json.Unmarshal(rr.Body.Bytes(), &members); err != nil {
+		t.Fatalf("could not decode the members: %v (%s)", err, rr.Body.String())
+	}
+	if len(members) != 3 || members[0].Name != "Owner" || members[1].Name != "Guest" || !members[1].IsReady || members[1].ExperienceLevel != account.Hard || members[2].Name != "Latecomer" {
+		t.Errorf("unexpected members: %+v", members)
+	}
+
+	// The owner leaving hands the lobby to the member who joined first after them
+	rr = callLobbyHandler(LeaveLobbyHandler, lobbies, store, http.MethodPost, "/lobby/leave_lobby", `{"lobby_id": 1}`, 1)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("LeaveLobbyHandler returned %d: %s", rr.Code, rr.Body.String())
+	}
+
+	lobby, err := lobbies.GetLobby(1)
+	if err != nil || lobby.OwnerAccountId != "2" || lobby.OwnerName != "Guest" {
+		t.Fatalf("expected Guest to own the lobby, got %+v, %v", lobby, err)
+	}
+
+	rr = callLobbyHandler(TransferOwnershipHandler, lobbies, store, http.MethodPost, "/lobby/transfer_ownership", `{"lobby_id": 1, "account_id": 3}`, 2)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("TransferOwnershipHandler returned %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = callLobbyHandler(KickMemberHandler, lobbies, store, http.MethodPost, "/lobby/kick_member", `{"lobby_id": 1, "account_id": 2, "ban": true}`, 3)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("KickMemberHandler returned %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = callLobbyHandler(JoinLobbyHandler, lobbies, store, http.MethodPost, "/lobby/join_lobby", `{"lobby_id": 1}`, 2)
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("expected the banned member to be refused, got %d", rr.Code)
+	}
+
+	// The last member leaving deletes the lobby
+	rr = callLobbyHandler(LeaveLobbyHandler, lobbies, store, http.MethodPost, "/lobby/leave_lobby", `{"lobby_id": 1}`, 3)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("LeaveLobbyHandler returned %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if _, err := lobbies.GetLobby(1); err != ErrLobbyNotFound {
+		t.Errorf("expected ErrLobbyNotFound, got %v", err)
+	}
+}
+
+func TestMemoryLobbyRepository_V2LobbyLifecycle(t *testing.T) {
+	lobbies, store := newMemoryLobbies(t, "Owner", "Guest", "Latecomer")
+
+	rr := callLobbyRoute("POST /v2/lobbies", CreateLobbyV2Handler, lobbies, store, http.MethodPost, "/v2/lobbies", `{"lobby": {"name": "Test Lobby", "is_public": true}, "password": "password123"}`, 1)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("POST /v2/lobbies returned %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var created Lobby
+	if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil || created.ID != 1 || created.Name != "Test Lobby" || created.OwnerName != "Owner" {
+		t.Errorf("expected the created lobby, got %s", rr.Body.String())
+	}
+	if location := rr.Header().Get("Location"); location != "/v2/lobbies/1" {
+		t.Errorf("expected the Location /v2/lobbies/1, got %q", location)
+	}
+
+	rr = callLobbyRoute("POST /v2/lobbies/{id}/members", JoinLobbyV2Handler, lobbies, store, http.MethodPost, "/v2/lobbies/1/members", "", 2)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("POST /v2/lobbies/1/members returned %d: %s", rr.Code, rr.Body.String())
+	}
+	rr = callLobbyRoute("POST /v2/lobbies/{id}/members", JoinLobbyV2Handler, lobbies, store, http.MethodPost, "/v2/lobbies/1/members", `{"password": "password123"}`, 3)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("POST /v2/lobbies/1/members returned %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = callLobbyRoute("PUT /v2/lobbies/{id}/members/me/ready", SetReadyV2Handler, lobbies, store, http.MethodPut, "/v2/lobbies/1/members/me/ready", `{"ready": true}`, 2)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("PUT /v2/lobbies/1/members/me/ready returned %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = callLobbyRoute("GET /v2/lobbies/{id}/members", ListMembersV2Handler, lobbies, store, http.MethodGet, "/v2/lobbies/1/members", "", 1)
+	var members []Member
+	if err := json.Unmarshal(rr.Body.Bytes(), &members); err != nil {
+		t.Fatalf("could not decode the members: %v (%s)", err, rr.Body.String())
+	}
+	if len(members) != 3 || members[0].Name != "Owner" || members[1].Name != "Guest" || !members[1].IsReady {
+		t.Errorf("unexpected members: %+v", members)
+	}
+
+	rr = callLobbyRoute("PUT /v2/lobbies/{id}/owner", TransferOwnershipV2Handler, lobbies, store, http.MethodPut, "/v2/lobbies/1/owner", `{"account_id": 3}`, 1)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("PUT /v2/lobbies/1/owner returned %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = callLobbyRoute("DELETE /v2/lobbies/{id}/members/{account_id}", KickMemberV2Handler, lobbies, store, http.MethodDelete, "/v2/lobbies/1/members/2?ban=true", "", 3)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("DELETE /v2/lobbies/1/members/2 returned %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = callLobbyRoute("POST /v2/lobbies/{id}/members", JoinLobbyV2Handler, lobbies, store, http.MethodPost, "/v2/lobbies/1/members", "", 2)
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("expected the banned member to be refused, got %d", rr.Code)
+	}
+
+	rr = callLobbyRoute("GET /v2/lobbies/{id}", GetLobbyV2Handler, lobbies, store, http.MethodGet, "/v2/lobbies/1", "", 1)
+	var lobby Lobby
+	if err := json.Unmarshal(rr.Body.Bytes(), &lobby); err != nil || lobby.OwnerAccountId != "3" {
+		t.Errorf("expected Latecomer to own the lobby, got %+v, %v", lobby, err)
+	}
+
+	rr = callLobbyRoute("DELETE /v2/lobbies/{id}", DeleteLobbyV2Handler, lobbies, store, http.MethodDelete, "/v2/lobbies/1", "", 3)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("DELETE /v2/lobbies/1 returned %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = callLobbyRoute("GET /v2/lobbies/{id}", GetLobbyV2Handler, lobbies, store, http.MethodGet, "/v2/lobbies/1", "", 1)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected the deleted lobby not to be found, got %d", rr.Code)
+	}
+}
+
+func TestMemoryLobbyRepository_V2Routes(t *testing.T) {
+	lobbies, store := newMemoryLobbies(t, "Owner")
+
+	rr := callLobbyRoute("GET /v2/lobbies/{id}", GetLobbyV2Handler, lobbies, store, http.MethodGet, "/v2/lobbies/first", "", 1)
+	if rr.Code != http.StatusBadRequest || httpapitest.Message(rr) != "id must be a number" {
+		t.Errorf("expected a non-numeric ID to be refused, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = callLobbyRoute("GET /v2/lobbies/{id}", GetLobbyV2Handler, lobbies, store, http.MethodPost, "/v2/lobbies/1", "", 1)
+	if rr.Code != http.StatusMethodNotAllowed || httpapitest.Code(rr) != httpapi.CodeMethodNotAllowed {
+		t.Errorf("expected the wrong method to be refused, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestMemoryLobbyRepository_Chat(t *testing.T) {
+	lobbies, store := newMemoryLobbies(t, "Owner", "Guest")
+
+	if err := lobbies.CreateLobby(&Lobby{Name: "Chatty", OwnerName: "Owner", OwnerAccountId: "1", IsPublic: true}, LobbyPassword{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lobbies.AddMember(1, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, text := range []string{"first", "second", "third"} {
+		rr := callLobbyHandler(SendMessageHandler, lobbies, store, http.MethodPost, "/lobby/send_message", `{"lobby_id": 1, "message": "`+text+`"}`, 2)
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("SendMessageHandler returned %d: %s", rr.Code, rr.Body.String())
+		}
+	}
+
+	rr := callLobbyHandler(ListMessagesHandler, lobbies, store, http.MethodGet, "/lobby/list_messages?lobby_id=1&before=3&limit=1", "", 1)
+	var messages []Message
+	if err := json.Unmarshal(rr.Body.Bytes(), &messages); err != nil {
+		t.Fatalf("could not decode the messages: %v (%s)", err, rr.Body.String())
+	}
+	if len(messages) != 1 || messages[0].Message != "second" {
+		t.Errorf("unexpected messages: %+v", messages)
+	}
+
+	rr = callLobbyHandler(MuteMemberHandler, lobbies, store, http.MethodPost, "/lobby/mute_member", `{"lobby_id": 1, "account_id": 2, "muted": true}`, 1)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("MuteMemberHandler returned %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = callLobbyHandler(SendMessageHandler, lobbies, store, http.MethodPost, "/lobby/send_message", `{"lobby_id": 1, "message": "hello?"}`, 2)
+	if rr.Code != http.StatusForbidden || httpapitest.Message(rr) != ERROR_MEMBER_MUTED {
+		t.Errorf("expected the muted member to be refused, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestMemoryLobbyRepository_ListLobbies(t *testing.T) {
+	lobbies, store := newMemoryLobbies(t, "Owner")
+
+	for _, lobby := range []Lobby{
+		{Name: "Charlie", OwnerAccountId: "1", IsPublic: true},
+		{Name: "alpha", OwnerAccountId: "1", IsPublic: true},
+		{Name: "Hidden", OwnerAccountId: "1", IsPublic: false},
+		{Name: "Bravo", OwnerAccountId: "1", IsPublic: true, IsClosed: true},
+		{Name: "Delta", OwnerAccountId: "1", IsPublic: true, IsMuted: true},
+	} {
+		if err := lobbies.CreateLobby(&lobby, LobbyPassword{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var names []string
+	url := "/lobby/list_lobbies?sort=name&limit=2"
+	for page := 0; url != ""; page++ {
+		if page > 3 {
+			t.Fatal("too many pages")
+		}
+
+		rr := callLobbyHandler(ListLobbiesHandler, lobbies, store, http.MethodGet, url, "", 1)
+		var response ListLobbiesResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+			t.Fatalf("could not decode the lobbies: %v (%s)", err, rr.Body.String())
+		}
+
+		for _, lobby := range response.Lobbies {
+			names = append(names, lobby.Name)
+			if lobby.OwnerExperienceLevel != account.Hard {
+				t.Errorf("expected the owner's experience level, got %+v", lobby)
+			}
+		}
+
+		url = ""
+		if response.NextCursor != "" {
+			url = "/lobby/list_lobbies?sort=name&limit=2&cursor=" + response.NextCursor
+		}
+	}
+
+	// The in-memory repository sorts names by byte value, so upper case comes first
+	if strings.Join(names, ",") != "Charlie,Delta,alpha" {
+		t.Errorf("unexpected lobbies: %v", names)
+	}
+
+	rr := callLobbyHandler(ListLobbiesHandler, lobbies, store, http.MethodGet, "/lobby/list_lobbies?is_muted=true&name=EL", "", 1)
+	var response ListLobbiesResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Lobbies) != 1 || response.Lobbies[0].Name != "Delta" {
+		t.Errorf("unexpected lobbies: %+v", response.Lobbies)
+	}
+}
+
+func TestMemoryLobbyRepository_ReleaseOwnedLobbies(t *testing.T) {
+	accounts := account.NewMemoryAccountRepository()
+	for _, name := range []string{"Owner", "Guest"} {
+		if _, err := accounts.CreateAccount(&account.Account{Name: name, Email: strings.ToLower(name) + "@example.com"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lobbies := NewMemoryLobbyRepository(accounts)
+	accounts.DeleteHooks = append(accounts.DeleteHooks, lobbies.ReleaseOwnedLobbies)
+
+	for _, name := range []string{"Shared", "Empty"} {
+		if err := lobbies.CreateLobby(&Lobby{Name: name, OwnerName: "Owner", OwnerAccountId: "1"}, LobbyPassword{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := lobbies.AddMember(1, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := accounts.DeleteAccount(1); err != nil {
+		t.Fatal(err)
+	}
+
+	shared, err := lobbies.GetLobby(1)
+	if err != nil || shared.OwnerAccountId != "2" || shared.OwnerName != "Guest" {
+		t.Errorf("expected Guest to own the shared lobby, got %+v, %v", shared, err)
+	}
+
+	if isMember, _ := lobbies.IsMemberOrOwner(1, 1); isMember {
+		t.Error("expected the deleted account to have left the shared lobby")
+	}
+
+	if _, err := lobbies.GetLobby(2); err != ErrLobbyNotFound {
+		t.Errorf("expected the empty lobby to be deleted, got %v", err)
+	}
+}
+
+func TestMemoryLobbyRepository_ReleaseOwnedLobbiesFailsWithoutChanges(t *testing.T) {
+	accounts := account.NewMemoryAccountRepository()
+	if _, err := accounts.CreateAccount(&account.Account{Name: "Owner", Email: "owner@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	lobbies := NewMemoryLobbyRepository(accounts)
+	accounts.DeleteHooks = append(accounts.DeleteHooks, lobbies.ReleaseOwnedLobbies)
+
+	if err := lobbies.CreateLobby(&Lobby{Name: "Shared", OwnerName: "Owner", OwnerAccountId: "1"}, LobbyPassword{}); err != nil {
+		t.Fatal(err)
+	}
+	// The member has no account, so the lobby cannot be handed to them
+	if _, err := lobbies.AddMember(1, 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lobbies.AddMember(1, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := accounts.DeleteAccount(1); err == nil {
+		t.Fatal("expected the deletion to fail")
+	}
+
+	if _, err := accounts.GetAccount(1); err != nil {
+		t.Errorf("expected the account to be kept, got %v", err)
+	}
+	if members, err := lobbies.ListMembers(1); err != nil || len(members) != 1 || members[0].AccountId != 1 {
+		t.Errorf("expected the account to still be in its lobby, got %+v, %v", members, err)
+	}
+}
+
+func TestMemoryLobbyRepository_OwnerIsFirstMember(t *testing.T) {
+	lobbies, store := newMemoryLobbies(t, "Owner")
+
+	rr := callLobbyHandler(CreateLobbyHandler, lobbies, store, http.MethodPost, "/lobby/create_lobby", `{"lobby": {"name": "Test Lobby", "is_public": true}, "password": "password123"}`, 1)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("CreateLobbyHandler returned %d: %s", rr.Code, rr.Body.String())
+	}
+
+	members, err := lobbies.ListMembers(1)
+	if err != nil || len(members) != 1 || members[0].AccountId != 1 {
+		t.Errorf("expected the owner to be the only member, got %+v, %v", members, err)
+	}
+
+	summaries, err := lobbies.ListLobbies(LobbyListOptions{Limit: 10})
+	if err != nil || len(summaries) != 1 || summaries[0].MemberCount != 1 {
+		t.Errorf("expected the lobby to have one member, got %+v, %v", summaries, err)
+	}
+}
+
+func TestMemoryLobbyRepository_JoinFullLobby(t *testing.T) {
+	names := []string{"Owner"}
+	for i := 1; i <= defaultMaxMembers; i++ {
+		names = append(names, fmt.Sprintf("Player%d", i))
+	}
+	lobbies, store := newMemoryLobbies(t, names...)
+
+	rr := callLobbyHandler(CreateLobbyHandler, lobbies, store, http.MethodPost, "/lobby/create_lobby", `{"lobby": {"name": "Test Lobby", "is_public": true}, "password": "password123"}`, 1)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("CreateLobbyHandler returned %d: %s", rr.Code, rr.Body.String())
+	}
+
+	// The owner takes the first place, so the players after them fill the rest
+	for accountID := 2; accountID <= defaultMaxMembers; accountID++ {
+		rr = callLobbyHandler(JoinLobbyHandler, lobbies, store, http.MethodPost, "/lobby/join_lobby", `{"lobby_id": 1}`, accountID)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("JoinLobbyHandler returned %d: %s", rr.Code, rr.Body.String())
+		}
+	}
+
+	rr = callLobbyHandler(JoinLobbyHandler, lobbies, store, http.MethodPost, "/lobby/join_lobby", `{"lobby_id": 1}`, defaultMaxMembers+1)
+	if rr.Code != http.StatusConflict || httpapitest.Code(rr) != CodeLobbyFull {
+		t.Errorf("expected the full lobby to refuse the join, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	// A member joining again is not refused
+	rr = callLobbyHandler(JoinLobbyHandler, lobbies, store, http.MethodPost, "/lobby/join_lobby", `{"lobby_id": 1}`, 2)
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected a member to be able to join again, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
@@ -0,0 +1,127 @@
+package lobby
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+)
+
+// MuteMemberArgs represents the expected structure of the request body for muting a lobby member.
+//
+// @Description Structure for the lobby member mute request payload.
+type MuteMemberArgs struct {
+	// The lobby ID for the lobby the member is in.
+	LobbyId int64 `json:"lobby_id" validate:"required"`
+	// The account ID of the member who will be muted or unmuted.
+	AccountId int64 `json:"account_id" validate:"required"`
+	// Whether the member should be muted.
+	Muted *bool `json:"muted" validate:"required"`
+}
+
+// MuteMemberBody represents the expected structure of the request body for muting a member through /v2,
+// which takes the lobby and account IDs from the path.
+//
+// @Description Structure for the v2 member mute request payload.
+type MuteMemberBody struct {
+	// Whether the member should be muted.
+	Muted *bool `json:"muted"`
+}
+
+// MuteMember mutes or unmutes a single lobby member. Only the lobby owner can mute members; to mute
+// the whole lobby, the owner sets is_muted through UpdateLobby.
+//
+// @Summary Mutes a lobby member
+// @Description This endpoint lets the lobby owner stop (or allow again) a single member sending chat messages.
+// @Tags lobby
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Param body body MuteMemberArgs true "lobby member mute request body"
+// @Success 200 {object} httpapi.MessageResponse "Successfully muted member!"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 404 {object} httpapi.ErrorResponse "Not Found"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /lobby/mute_member [post]
+func MuteMember(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) error {
+
+	if r.Method != http.MethodPost {
+		return httpapi.MethodNotAllowed(http.MethodPost)
+	}
+
+	args := MuteMemberArgs{}
+	err := httpapi.DecodeJSON(r, &args)
+	if err != nil {
+		return err
+	}
+
+	return muteMember(w, r, args, lobbies, store)
+}
+
+// MuteMemberV2 mutes or unmutes the member with the account ID in the path.
+//
+// @Summary Mutes or unmutes a lobby member
+// @Description This endpoint lets the lobby owner stop (or allow again) a single member sending chat messages.
+// @Tags lobby
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Param id path int true "lobby ID"
+// @Param account_id path int true "account ID of the member"
+// @Param body body MuteMemberBody true "member mute request body"
+// @Success 200 {object} httpapi.MessageResponse "Successfully muted member!"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 404 {object} httpapi.ErrorResponse "Not Found"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /v2/lobbies/{id}/members/{account_id}/muted [put]
+func MuteMemberV2(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) error {
+	lobbyId, err := httpapi.PathID(r, "id")
+	if err != nil {
+		return err
+	}
+
+	accountId, err := httpapi.PathID(r, "account_id")
+	if err != nil {
+		return err
+	}
+
+	body := MuteMemberBody{}
+	if err := httpapi.DecodeJSON(r, &body); err != nil {
+		return err
+	}
+
+	return muteMember(w, r, MuteMemberArgs{LobbyId: lobbyId, AccountId: accountId, Muted: body.Muted}, lobbies, store)
+}
+
+// muteMember mutes or unmutes the member in args. Only the lobby owner can do so.
+func muteMember(w http.ResponseWriter, r *http.Request, args MuteMemberArgs, lobbies LobbyRepository, store *auth.SessionStore) error {
+	if err := httpapi.Validate(&args); err != nil {
+		return err
+	}
+
+	if _, err := authorizeLobbyOwner(r, lobbies, store, args.LobbyId); err != nil {
+		return err
+	}
+
+	err := lobbies.SetMuted(args.LobbyId, args.AccountId, *args.Muted)
+	if err == ErrNotLobbyMember {
+		return httpapi.NotFound(fmt.Sprintf("account %d is not a member of the lobby with the ID %d", args.AccountId, args.LobbyId)).WithCode(CodeNotLobbyMember)
+	}
+	if err != nil {
+		return fmt.Errorf("an error occurred while muting account %d in the lobby with the ID %d: %v", args.AccountId, args.LobbyId, err)
+	}
+
+	Events.Publish(Event{Type: EventMemberMuted, LobbyId: args.LobbyId, AccountId: args.AccountId, Muted: args.Muted})
+
+	if *args.Muted {
+		httpapi.WriteMessage(w, http.StatusOK, "Successfully muted member!")
+	} else {
+		httpapi.WriteMessage(w, http.StatusOK, "Successfully unmuted member!")
+	}
+	return nil
+}
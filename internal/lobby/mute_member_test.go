@@ -0,0 +1,102 @@
+package lobby
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi/httpapitest"
+)
+
+func TestMuteMember_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	expectLobbyOwner(mock, 5, "1")
+	mock.ExpectExec("UPDATE lobby_member SET is_muted = \\$1 WHERE lobby_id = \\$2 AND account_id = \\$3").
+		WithArgs(true, int64(5), int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	events, unsubscribe := Events.Subscribe(5, 0)
+	defer unsubscribe()
+
+	req, err := http.NewRequest("POST", "/lobby/mute_member", strings.NewReader(`{"lobby_id": 5, "account_id": 2, "muted": true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = withSession(req, 1)
+
+	rr := httptest.NewRecorder()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		MuteMemberHandler(w, r, NewPostgresLobbyRepository(sqlxDB), &auth.SessionStore{DB: sqlxDB})
+	})
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	event, ok := receiveEvent(t, events)
+	if !ok || event.Type != EventMemberMuted || event.AccountId != 2 || event.Muted == nil || !*event.Muted {
+		t.Errorf("unexpected event: %+v", event)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestMuteMember_NotOwner(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	expectLobbyOwner(mock, 5, "1")
+
+	req, err := http.NewRequest("POST", "/lobby/mute_member", strings.NewReader(`{"lobby_id": 5, "account_id": 3, "muted": true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = withSession(req, 2)
+
+	rr := httptest.NewRecorder()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	MuteMemberHandler(rr, req, NewPostgresLobbyRepository(sqlxDB), &auth.SessionStore{DB: sqlxDB})
+
+	if status := rr.Code; status != http.StatusForbidden {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusForbidden)
+	}
+}
+
+func TestMuteMember_MissingMuted(t *testing.T) {
+	req, err := http.NewRequest("POST", "/lobby/mute_member", strings.NewReader(`{"lobby_id": 5, "account_id": 2}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	err = MuteMember(rr, req, nil, nil)
+
+	expectedError := "muted must be specified"
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("MuteMember() error = %v, wantErr %v", err, expectedError)
+	}
+
+	if status := httpapitest.Status(err); status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,39 @@
+package lobby
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+)
+
+// authorizeLobbyOwner checks that the caller's session belongs to the owner of the lobby with the given ID
+// and returns the session.
+func authorizeLobbyOwner(r *http.Request, lobbies LobbyRepository, store *auth.SessionStore, lobbyID int64) (*auth.Session, error) {
+	session, err := store.Authenticate(r, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ownerAccountID, err := lobbies.LobbyOwner(lobbyID)
+	if err == ErrLobbyNotFound {
+		return nil, httpapi.NotFound(fmt.Sprintf("no lobby exists with the ID %d", lobbyID))
+	}
+	if err != nil {
+		return nil, errors.New("an error occurred while retrieving the lobby owner: " + err.Error())
+	}
+
+	ownerID, err := strconv.ParseInt(ownerAccountID, 10, 64)
+	if err != nil {
+		return nil, auth.ErrForbidden
+	}
+
+	if err := auth.RequireAccount(session, ownerID); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
@@ -0,0 +1,149 @@
+package lobby
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi/httpapitest"
+)
+
+const lobbyOwnerQuery = "SELECT owner_account_id FROM lobby WHERE id = $1"
+
+// withSession returns a copy of req which carries a valid session for the given account, as though it had been through auth.Middleware.
+func withSession(req *http.Request, accountID int) *http.Request {
+	session := &auth.Session{
+		AccountID: accountID,
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	return req.WithContext(auth.ContextWithSession(req.Context(), session))
+}
+
+func expectLobbyOwner(mock sqlmock.Sqlmock, lobbyID int64, ownerAccountID string) {
+	mock.ExpectQuery(regexp.QuoteMeta(lobbyOwnerQuery)).
+		WithArgs(lobbyID).
+		WillReturnRows(sqlmock.NewRows([]string{"owner_account_id"}).AddRow(ownerAccountID))
+}
+
+func TestDeleteLobby_NotOwner(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	expectLobbyOwner(mock, 1, "2")
+
+	req, err := http.NewRequest("DELETE", "/lobby/delete_lobby", strings.NewReader(`{"lobby_id": 1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = withSession(req, 1)
+
+	rr := httptest.NewRecorder()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	store := &auth.SessionStore{DB: sqlxDB}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		DeleteLobbyHandler(w, r, NewPostgresLobbyRepository(sqlxDB), store)
+	})
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusForbidden {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusForbidden)
+	}
+
+	if httpapitest.Message(rr) != auth.ErrForbidden.Error() {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), auth.ErrForbidden.Error())
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDeleteLobby_MissingSession(t *testing.T) {
+	req, err := http.NewRequest("DELETE", "/lobby/delete_lobby", strings.NewReader(`{"lobby_id": 1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	store := &auth.SessionStore{}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		DeleteLobbyHandler(w, r, nil, store)
+	})
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusUnauthorized {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusUnauthorized)
+	}
+}
+
+func TestUpdateLobby_LobbyDoesNotExist(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(lobbyOwnerQuery)).
+		WithArgs(int64(1)).
+		WillReturnError(sql.ErrNoRows)
+
+	req, err := http.NewRequest("PUT", "/lobby/update_lobby", strings.NewReader(`{"lobby_id": 1, "lobby": {"name": "New Name"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = withSession(req, 1)
+
+	rr := httptest.NewRecorder()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	store := &auth.SessionStore{DB: sqlxDB}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		UpdateLobbyHandler(w, r, NewPostgresLobbyRepository(sqlxDB), store)
+	})
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusNotFound)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCreateLobby_OwnerMismatch(t *testing.T) {
+	req, err := http.NewRequest("POST", "/lobby/create_lobby", strings.NewReader(`{"lobby": {"name": "Test Lobby", "owner_name": "Owner", "owner_account_id": "2"}, "password": "password123"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = withSession(req, 1)
+
+	rr := httptest.NewRecorder()
+	store := &auth.SessionStore{}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		CreateLobbyHandler(w, r, nil, store)
+	})
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusForbidden {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusForbidden)
+	}
+}
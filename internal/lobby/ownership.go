@@ -0,0 +1,89 @@
+package lobby
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+)
+
+const ERROR_OWNER_CHANGED_BY_UPDATE = "the lobby owner can only be changed through /lobby/transfer_ownership"
+const ERROR_ALREADY_OWNER = "the account already owns the lobby"
+
+// CodeAlreadyOwner is the code of the error returned when a lobby is handed to its current owner.
+const CodeAlreadyOwner httpapi.Code = "already_owner"
+
+// accountName looks up the name of an account, which is stored as the owner name of the lobbies it owns.
+func accountName(q sqlx.Queryer, accountID int64) (string, error) {
+	var name string
+	if err := q.QueryRowx("SELECT name FROM account WHERE id = $1", accountID).Scan(&name); err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
+
+// setLobbyOwner makes the account the owner of the lobby, keeping the owner name in step with the account name.
+func setLobbyOwner(tx *sqlx.Tx, lobbyID int64, accountID int64) error {
+	name, err := accountName(tx, accountID)
+	if err != nil {
+		return fmt.Errorf("an error occurred while getting the name of account %d: %v", accountID, err)
+	}
+
+	_, err = tx.Exec("UPDATE lobby SET owner_account_id = $1, owner_name = $2 WHERE id = $3", fmt.Sprint(accountID), name, lobbyID)
+	if err != nil {
+		return fmt.Errorf("an error occurred while changing the owner of the lobby with the ID %d: %v", lobbyID, err)
+	}
+
+	return nil
+}
+
+// longestPresentMember returns the member who joined the lobby first, other than the given account.
+// ok is false when there is no such member.
+func longestPresentMember(tx *sqlx.Tx, lobbyID int64, excludeAccountID int64) (accountID int64, ok bool, err error) {
+	err = tx.QueryRow(
+		"SELECT account_id FROM lobby_member WHERE lobby_id = $1 AND account_id <> $2 ORDER BY joined_at, account_id LIMIT 1",
+		lobbyID, excludeAccountID,
+	).Scan(&accountID)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("an error occurred while finding the longest present member of the lobby with the ID %d: %v", lobbyID, err)
+	}
+
+	return accountID, true, nil
+}
+
+// ReleaseOwnedLobbies hands every lobby the account owns to its longest present member, or deletes the lobby
+// when nobody else is in it. It is an account.PostgresDeleteHook, so it runs in the transaction deleting the account.
+func ReleaseOwnedLobbies(tx *sqlx.Tx, accountID int64) error {
+	var lobbyIDs []int64
+	err := tx.Select(&lobbyIDs, "SELECT id FROM lobby WHERE owner_account_id = $1 ORDER BY id FOR UPDATE", strconv.FormatInt(accountID, 10))
+	if err != nil {
+		return fmt.Errorf("an error occurred while finding the lobbies owned by account %d: %v", accountID, err)
+	}
+
+	for _, lobbyID := range lobbyIDs {
+		newOwnerID, ok, err := longestPresentMember(tx, lobbyID, accountID)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			// Nobody is left to hand the lobby to
+			if _, err := tx.Exec("DELETE FROM lobby WHERE id = $1", lobbyID); err != nil {
+				return fmt.Errorf("an error occurred while deleting the lobby with the ID %d: %v", lobbyID, err)
+			}
+			continue
+		}
+
+		if err := setLobbyOwner(tx, lobbyID, newOwnerID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
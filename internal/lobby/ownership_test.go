@@ -0,0 +1,61 @@
+package lobby
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestReleaseOwnedLobbies(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM lobby WHERE owner_account_id = $1 ORDER BY id FOR UPDATE")).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4).AddRow(5))
+
+	// Lobby 4 has another member, who takes it over
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT account_id FROM lobby_member WHERE lobby_id = $1 AND account_id <> $2 ORDER BY joined_at, account_id LIMIT 1")).
+		WithArgs(int64(4), int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"account_id"}).AddRow(2))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name FROM account WHERE id = $1")).
+		WithArgs(int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("New Owner"))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE lobby SET owner_account_id = $1, owner_name = $2 WHERE id = $3")).
+		WithArgs("2", "New Owner", int64(4)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Lobby 5 is empty, so it is deleted
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT account_id FROM lobby_member WHERE lobby_id = $1 AND account_id <> $2 ORDER BY joined_at, account_id LIMIT 1")).
+		WithArgs(int64(5), int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"account_id"}))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM lobby WHERE id = $1")).
+		WithArgs(int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	tx, err := sqlxDB.Beginx()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ReleaseOwnedLobbies(tx, 1); err != nil {
+		t.Fatalf("ReleaseOwnedLobbies() error = %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,39 @@
+package lobby
+
+import (
+	"database/sql"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+)
+
+const ERROR_LOBBY_PASSWORD_INCORRECT = "the lobby password is incorrect"
+
+// CodeLobbyPasswordIncorrect is the code of the error returned when a lobby's password is wrong.
+const CodeLobbyPasswordIncorrect httpapi.Code = "lobby_password_incorrect"
+
+var ErrLobbyPasswordIncorrect = httpapi.Forbidden(ERROR_LOBBY_PASSWORD_INCORRECT).WithCode(CodeLobbyPasswordIncorrect)
+
+// LobbyAccess holds the parts of a lobby which decide whether an account may join it.
+type LobbyAccess struct {
+	OwnerAccountId string         `db:"owner_account_id"`
+	IsClosed       bool           `db:"is_closed"`
+	IsPublic       bool           `db:"is_public"`
+	PasswordHash   sql.NullString `db:"password_hash"`
+	PasswordSalt   sql.NullString `db:"password_salt"`
+}
+
+// checkPassword returns ErrLobbyPasswordIncorrect unless the lobby is public or the password
+// matches the one stored for it. Lobbies created before passwords were stored have no hash and are
+// treated as having no password. PasswordSalt is only set for hashes stored before the PHC string format was used.
+func (a *LobbyAccess) checkPassword(password string) error {
+	if a.IsPublic || !a.PasswordHash.Valid {
+		return nil
+	}
+
+	if err := auth.ComparePassword(a.PasswordHash.String, a.PasswordSalt.String, password); err != nil {
+		return ErrLobbyPasswordIncorrect
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package lobby
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi/httpapitest"
+)
+
+func TestLobbyAccess_CheckPassword(t *testing.T) {
+	hash, err := auth.HashPassword("password123")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		isPublic bool
+		hash     string
+		password string
+		wantErr  error
+	}{
+		{name: "private lobby with the correct password", hash: hash, password: "password123"},
+		{name: "private lobby with the wrong password", hash: hash, password: "wrongpassword", wantErr: ErrLobbyPasswordIncorrect},
+		{name: "public lobby ignores the password", isPublic: true, hash: hash, password: ""},
+		{name: "private lobby without a stored password", password: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			access := &LobbyAccess{IsPublic: tt.isPublic}
+			if tt.hash != "" {
+				access.PasswordHash = sql.NullString{String: tt.hash, Valid: true}
+			}
+
+			err := access.checkPassword(tt.password)
+			if err != tt.wantErr {
+				t.Errorf("checkPassword() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUpdateLobby_PasswordOnly(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	expectLobbyOwner(mock, 1, "1")
+
+	mock.ExpectExec("UPDATE lobby SET password_hash = \\$1, password_salt = NULL WHERE id = \\$2").
+		WithArgs(sqlmock.AnyArg(), int64(1)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	req, err := http.NewRequest("PUT", "/lobby/update_lobby", strings.NewReader(`{"lobby_id": 1, "password": "newpassword"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = withSession(req, 1)
+
+	rr := httptest.NewRecorder()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	store := &auth.SessionStore{DB: sqlxDB}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		UpdateLobbyHandler(w, r, NewPostgresLobbyRepository(sqlxDB), store)
+	})
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUpdateLobby_PasswordTooShort(t *testing.T) {
+	req, err := http.NewRequest("PUT", "/lobby/update_lobby", strings.NewReader(`{"lobby_id": 1, "password": "123"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = withSession(req, 1)
+
+	rr := httptest.NewRecorder()
+	store := &auth.SessionStore{}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		UpdateLobbyHandler(w, r, nil, store)
+	})
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+	}
+
+	if httpapitest.Message(rr) != "password must be at least 6 characters" {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), "password must be at least 6 characters")
+	}
+}
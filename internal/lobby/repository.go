@@ -0,0 +1,552 @@
+package lobby
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/justinfarrelldev/open-ctp-server/internal/account"
+)
+
+// ErrLobbyNotFound is returned when no lobby exists with the requested ID.
+var ErrLobbyNotFound = errors.New("no lobby exists with the given ID")
+
+// ErrLobbyFull is returned when a lobby has as many members as it has room for.
+var ErrLobbyFull = errors.New("the lobby is full")
+
+// ErrNotLobbyMember is returned when an account is not a member of the lobby being acted on.
+var ErrNotLobbyMember = errors.New("the account is not a member of the lobby")
+
+// LobbyPassword is a lobby password as returned by auth.HashPassword.
+type LobbyPassword struct {
+	Hash string
+}
+
+// LobbyPosition is the position of a lobby in the lobby browser: the value of the sort column and the lobby ID.
+type LobbyPosition struct {
+	Value interface{}
+	ID    int64
+}
+
+// LobbyListOptions filters, orders and pages the lobbies listed in the lobby browser.
+type LobbyListOptions struct {
+	// Name only keeps lobbies whose name contains it, ignoring case.
+	Name                 string
+	HasFreeSlots         *bool
+	IsMuted              *bool
+	OwnerExperienceLevel *account.ExperienceLevel
+	Sort                 LobbySort
+	// After, when set, only keeps lobbies which come after the position in the sort order.
+	After *LobbyPosition
+	Limit int
+}
+
+// Chatter holds what decides whether an account may chat in a lobby.
+type Chatter struct {
+	OwnerAccountId string `db:"owner_account_id"`
+	LobbyMuted     bool   `db:"lobby_muted"`
+	// MemberMuted is NULL when the account is not a member of the lobby.
+	MemberMuted sql.NullBool `db:"member_muted"`
+}
+
+// LeaveResult describes what happened to a lobby when an account left it.
+type LeaveResult struct {
+	// NewOwnerID is the member who became the owner, if HasNewOwner is set.
+	NewOwnerID   int64
+	HasNewOwner  bool
+	LobbyDeleted bool
+}
+
+// LobbyRepository stores lobbies, their members, bans and chat messages. Lookups of a single lobby
+// return ErrLobbyNotFound when the lobby does not exist.
+type LobbyRepository interface {
+	// CreateLobby stores a new lobby with its owner as its first member, and sets its ID.
+	CreateLobby(lobby *Lobby, password LobbyPassword) error
+	GetLobby(lobbyID int64) (*Lobby, error)
+	ListLobbies(options LobbyListOptions) ([]LobbySummary, error)
+	// UpdateLobby sets the name, is_closed, is_muted and is_public fields which are not nil, and the password if given.
+	UpdateLobby(lobbyID int64, update *LobbyParam, password *LobbyPassword) error
+	DeleteLobby(lobbyID int64) error
+
+	LobbyOwner(lobbyID int64) (string, error)
+	LobbyAccess(lobbyID int64) (*LobbyAccess, error)
+	// AccountName returns the name of an account, which is stored as the owner name of the lobbies it owns.
+	AccountName(accountID int64) (string, error)
+
+	// AddMember reports whether the account was added, rather than already being a member. It returns
+	// ErrLobbyFull when the lobby has no room for another member.
+	AddMember(lobbyID int64, accountID int64) (bool, error)
+	// RemoveMember reports whether the account was a member.
+	RemoveMember(lobbyID int64, accountID int64) (bool, error)
+	ListMembers(lobbyID int64) ([]Member, error)
+	IsMemberOrOwner(lobbyID int64, accountID int64) (bool, error)
+	// SetReady and SetMuted return ErrNotLobbyMember when the account is not a member.
+	SetReady(lobbyID int64, accountID int64, ready bool) error
+	SetMuted(lobbyID int64, accountID int64, muted bool) error
+	// Leave removes the account from the lobby, handing the lobby to the longest present member or
+	// deleting it when the owner leaves. It returns ErrNotLobbyMember when the account is neither a member nor the owner.
+	Leave(lobbyID int64, accountID int64) (*LeaveResult, error)
+	// TransferOwnership makes a member the owner, keeping the previous owner in the lobby as a member.
+	// It returns ErrNotLobbyMember when the new owner is not a member.
+	TransferOwnership(lobbyID int64, fromAccountID int64, toAccountID int64) error
+
+	IsBanned(lobbyID int64, accountID int64) (bool, error)
+	Ban(lobbyID int64, accountID int64) error
+
+	Chatter(lobbyID int64, accountID int64) (*Chatter, error)
+	// AddMessage stores a chat message and sets its ID and creation time.
+	AddMessage(message *Message) error
+	// ListMessages returns up to limit messages of the lobby, newest first, optionally only those before a message ID.
+	ListMessages(lobbyID int64, before *int64, limit int) ([]Message, error)
+}
+
+// PostgresLobbyRepository stores lobbies in the lobby, lobby_member, lobby_ban and lobby_message tables.
+type PostgresLobbyRepository struct {
+	DB *sqlx.DB
+}
+
+// NewPostgresLobbyRepository creates a LobbyRepository backed by Postgres.
+func NewPostgresLobbyRepository(db *sqlx.DB) *PostgresLobbyRepository {
+	return &PostgresLobbyRepository{DB: db}
+}
+
+func (p *PostgresLobbyRepository) CreateLobby(lobby *Lobby, password LobbyPassword) error {
+	ownerAccountID, err := strconv.ParseInt(lobby.OwnerAccountId, 10, 64)
+	if err != nil {
+		return fmt.Errorf("the owner account ID %q is not a number", lobby.OwnerAccountId)
+	}
+
+	tx, err := p.DB.Beginx()
+	if err != nil {
+		return fmt.Errorf("an error occurred while starting a transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(
+		"INSERT INTO lobby (name, owner_name, owner_account_id, is_closed, is_muted, is_public, password_hash) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id",
+		lobby.Name, lobby.OwnerName, lobby.OwnerAccountId, lobby.IsClosed, lobby.IsMuted, lobby.IsPublic, password.Hash,
+	).Scan(&lobby.ID)
+	if err != nil {
+		return errors.New("an error occurred while inserting a lobby into the database: " + err.Error())
+	}
+
+	if _, err := tx.Exec("INSERT INTO lobby_member (lobby_id, account_id) VALUES ($1, $2)", lobby.ID, ownerAccountID); err != nil {
+		return fmt.Errorf("an error occurred while adding the owner to the lobby: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("an error occurred while committing the lobby: %v", err)
+	}
+
+	return nil
+}
+
+func (p *PostgresLobbyRepository) GetLobby(lobbyID int64) (*Lobby, error) {
+	var lobby Lobby
+
+	query := "SELECT id, name, owner_name, owner_account_id, is_closed, is_muted, is_public FROM lobby WHERE id = $1"
+	if err := p.DB.Get(&lobby, query, lobbyID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrLobbyNotFound
+		}
+		return nil, err
+	}
+
+	return &lobby, nil
+}
+
+func (p *PostgresLobbyRepository) ListLobbies(options LobbyListOptions) ([]LobbySummary, error) {
+	order := lobbySortOrders[options.Sort]
+
+	conditions := []string{}
+	params := []interface{}{}
+	addParam := func(value interface{}) string {
+		params = append(params, value)
+		return fmt.Sprintf("$%d", len(params))
+	}
+
+	if options.Name != "" {
+		conditions = append(conditions, "name ILIKE '%' || "+addParam(escapeLike(options.Name))+" || '%'")
+	}
+
+	if options.HasFreeSlots != nil {
+		if *options.HasFreeSlots {
+			conditions = append(conditions, "member_count < max_members")
+		} else {
+			conditions = append(conditions, "member_count >= max_members")
+		}
+	}
+
+	if options.IsMuted != nil {
+		conditions = append(conditions, "is_muted = "+addParam(*options.IsMuted))
+	}
+
+	if options.OwnerExperienceLevel != nil {
+		conditions = append(conditions, "owner_experience_level = "+addParam(int(*options.OwnerExperienceLevel)))
+	}
+
+	comparison, direction := "<", "DESC"
+	if order.ascending {
+		comparison, direction = ">", "ASC"
+	}
+
+	if options.After != nil {
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s (%s, %s)", order.column, comparison, addParam(options.After.Value), addParam(options.After.ID)))
+	}
+
+	query := "SELECT * FROM (" +
+		"SELECT lobby.id, lobby.name, lobby.owner_name, lobby.owner_account_id, lobby.is_closed, lobby.is_muted, lobby.is_public, lobby.created_at, lobby.max_members, " +
+		"COALESCE(account.experience_level, 0) AS owner_experience_level, " +
+		"(SELECT COUNT(*) FROM lobby_member WHERE lobby_member.lobby_id = lobby.id) AS member_count " +
+		"FROM lobby LEFT JOIN account ON account.id::text = lobby.owner_account_id " +
+		"WHERE lobby.is_public AND NOT lobby.is_closed" +
+		") AS lobbies"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY %s %s, id %s LIMIT %s", order.column, direction, direction, addParam(options.Limit))
+
+	lobbies := []LobbySummary{}
+	if err := p.DB.Select(&lobbies, query, params...); err != nil {
+		return nil, err
+	}
+
+	return lobbies, nil
+}
+
+func (p *PostgresLobbyRepository) UpdateLobby(lobbyID int64, update *LobbyParam, password *LobbyPassword) error {
+	query := "UPDATE lobby SET "
+	params := []interface{}{}
+	paramIndex := 1
+
+	if update.Name != nil {
+		query += fmt.Sprintf("name = $%d, ", paramIndex)
+		params = append(params, update.Name)
+		paramIndex++
+	}
+	if update.IsClosed != nil {
+		query += fmt.Sprintf("is_closed = $%d, ", paramIndex)
+		params = append(params, update.IsClosed)
+		paramIndex++
+	}
+	if update.IsMuted != nil {
+		query += fmt.Sprintf("is_muted = $%d, ", paramIndex)
+		params = append(params, update.IsMuted)
+		paramIndex++
+	}
+	if update.IsPublic != nil {
+		query += fmt.Sprintf("is_public = $%d, ", paramIndex)
+		params = append(params, update.IsPublic)
+		paramIndex++
+	}
+	if password != nil {
+		// The salt column is only used by hashes stored before the PHC string format was used
+		query += fmt.Sprintf("password_hash = $%d, password_salt = NULL, ", paramIndex)
+		params = append(params, password.Hash)
+		paramIndex++
+	}
+
+	if len(params) == 0 {
+		return nil
+	}
+
+	// Remove the trailing comma and space
+	query = query[:len(query)-2]
+	query += fmt.Sprintf(" WHERE id = $%d", paramIndex)
+	params = append(params, lobbyID)
+
+	_, err := p.DB.Exec(query, params...)
+	return err
+}
+
+func (p *PostgresLobbyRepository) DeleteLobby(lobbyID int64) error {
+	result, err := p.DB.Exec("DELETE FROM lobby WHERE id = $1", lobbyID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("an error occurred while checking the affected rows: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrLobbyNotFound
+	}
+
+	return nil
+}
+
+func (p *PostgresLobbyRepository) LobbyOwner(lobbyID int64) (string, error) {
+	var ownerAccountID string
+	err := p.DB.QueryRow("SELECT owner_account_id FROM lobby WHERE id = $1", lobbyID).Scan(&ownerAccountID)
+	if err == sql.ErrNoRows {
+		return "", ErrLobbyNotFound
+	}
+
+	return ownerAccountID, err
+}
+
+func (p *PostgresLobbyRepository) LobbyAccess(lobbyID int64) (*LobbyAccess, error) {
+	var access LobbyAccess
+	err := p.DB.Get(&access, "SELECT owner_account_id, is_closed, is_public, password_hash, password_salt FROM lobby WHERE id = $1", lobbyID)
+	if err == sql.ErrNoRows {
+		return nil, ErrLobbyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &access, nil
+}
+
+func (p *PostgresLobbyRepository) AccountName(accountID int64) (string, error) {
+	return accountName(p.DB, accountID)
+}
+
+func (p *PostgresLobbyRepository) AddMember(lobbyID int64, accountID int64) (bool, error) {
+	tx, err := p.DB.Beginx()
+	if err != nil {
+		return false, fmt.Errorf("an error occurred while starting a transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	// Lock the lobby so that two accounts joining at once cannot both take its last place
+	var room struct {
+		MaxMembers  int  `db:"max_members"`
+		MemberCount int  `db:"member_count"`
+		IsMember    bool `db:"is_member"`
+	}
+	err = tx.Get(&room, "SELECT max_members, "+
+		"(SELECT COUNT(*) FROM lobby_member WHERE lobby_id = $1) AS member_count, "+
+		"EXISTS (SELECT 1 FROM lobby_member WHERE lobby_id = $1 AND account_id = $2) AS is_member "+
+		"FROM lobby WHERE id = $1 FOR UPDATE", lobbyID, accountID)
+	if err == sql.ErrNoRows {
+		return false, ErrLobbyNotFound
+	}
+	if err != nil {
+		return false, fmt.Errorf("an error occurred while getting the lobby with the ID %d: %v", lobbyID, err)
+	}
+
+	if room.IsMember {
+		return false, nil
+	}
+	if room.MemberCount >= room.MaxMembers {
+		return false, ErrLobbyFull
+	}
+
+	if _, err := tx.Exec("INSERT INTO lobby_member (lobby_id, account_id) VALUES ($1, $2)", lobbyID, accountID); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("an error occurred while committing the lobby join: %v", err)
+	}
+
+	return true, nil
+}
+
+func (p *PostgresLobbyRepository) RemoveMember(lobbyID int64, accountID int64) (bool, error) {
+	result, err := p.DB.Exec("DELETE FROM lobby_member WHERE lobby_id = $1 AND account_id = $2", lobbyID, accountID)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("an error occurred while checking the affected rows: %v", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+func (p *PostgresLobbyRepository) ListMembers(lobbyID int64) ([]Member, error) {
+	members := []Member{}
+	query := "SELECT lobby_member.account_id, account.name, account.experience_level, lobby_member.is_ready, lobby_member.is_muted, lobby_member.joined_at FROM lobby_member JOIN account ON account.id = lobby_member.account_id WHERE lobby_member.lobby_id = $1 ORDER BY lobby_member.joined_at"
+	if err := p.DB.Select(&members, query, lobbyID); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+func (p *PostgresLobbyRepository) IsMemberOrOwner(lobbyID int64, accountID int64) (bool, error) {
+	var isMember bool
+	err := p.DB.QueryRow(
+		"SELECT EXISTS (SELECT 1 FROM lobby_member WHERE lobby_id = $1 AND account_id = $2) OR EXISTS (SELECT 1 FROM lobby WHERE id = $1 AND owner_account_id = $3)",
+		lobbyID, accountID, strconv.FormatInt(accountID, 10),
+	).Scan(&isMember)
+	return isMember, err
+}
+
+func (p *PostgresLobbyRepository) SetReady(lobbyID int64, accountID int64, ready bool) error {
+	return p.updateMember("UPDATE lobby_member SET is_ready = $1 WHERE lobby_id = $2 AND account_id = $3", ready, lobbyID, accountID)
+}
+
+func (p *PostgresLobbyRepository) SetMuted(lobbyID int64, accountID int64, muted bool) error {
+	return p.updateMember("UPDATE lobby_member SET is_muted = $1 WHERE lobby_id = $2 AND account_id = $3", muted, lobbyID, accountID)
+}
+
+func (p *PostgresLobbyRepository) updateMember(query string, value bool, lobbyID int64, accountID int64) error {
+	result, err := p.DB.Exec(query, value, lobbyID, accountID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("an error occurred while checking the affected rows: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotLobbyMember
+	}
+
+	return nil
+}
+
+func (p *PostgresLobbyRepository) Leave(lobbyID int64, accountID int64) (*LeaveResult, error) {
+	tx, err := p.DB.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while starting a transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	// Lock the lobby so that two owners leaving at once cannot both promote someone
+	var ownerAccountID string
+	err = tx.QueryRow("SELECT owner_account_id FROM lobby WHERE id = $1 FOR UPDATE", lobbyID).Scan(&ownerAccountID)
+	if err == sql.ErrNoRows {
+		return nil, ErrLobbyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while getting the lobby with the ID %d: %v", lobbyID, err)
+	}
+	isOwner := ownerAccountID == strconv.FormatInt(accountID, 10)
+
+	result, err := tx.Exec("DELETE FROM lobby_member WHERE lobby_id = $1 AND account_id = $2", lobbyID, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while leaving the lobby with the ID %d: %v", lobbyID, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while checking the affected rows: %v", err)
+	}
+
+	// The owner is in the lobby whether or not they joined it
+	if rowsAffected == 0 && !isOwner {
+		return nil, ErrNotLobbyMember
+	}
+
+	leave := &LeaveResult{}
+	if isOwner {
+		leave.NewOwnerID, leave.HasNewOwner, err = longestPresentMember(tx, lobbyID, accountID)
+		if err != nil {
+			return nil, err
+		}
+
+		if leave.HasNewOwner {
+			if err := setLobbyOwner(tx, lobbyID, leave.NewOwnerID); err != nil {
+				return nil, err
+			}
+		} else {
+			// Nobody is left to hand the lobby to
+			if _, err := tx.Exec("DELETE FROM lobby WHERE id = $1", lobbyID); err != nil {
+				return nil, fmt.Errorf("an error occurred while deleting the empty lobby with the ID %d: %v", lobbyID, err)
+			}
+			leave.LobbyDeleted = true
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("an error occurred while committing the lobby leave: %v", err)
+	}
+
+	return leave, nil
+}
+
+func (p *PostgresLobbyRepository) TransferOwnership(lobbyID int64, fromAccountID int64, toAccountID int64) error {
+	tx, err := p.DB.Beginx()
+	if err != nil {
+		return fmt.Errorf("an error occurred while starting a transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var isMember bool
+	err = tx.QueryRow("SELECT EXISTS (SELECT 1 FROM lobby_member WHERE lobby_id = $1 AND account_id = $2)", lobbyID, toAccountID).Scan(&isMember)
+	if err != nil {
+		return fmt.Errorf("an error occurred while checking the lobby membership: %v", err)
+	}
+
+	if !isMember {
+		return ErrNotLobbyMember
+	}
+
+	if err := setLobbyOwner(tx, lobbyID, toAccountID); err != nil {
+		return err
+	}
+
+	// The previous owner may never have joined, but should not be shut out of the lobby they handed over
+	_, err = tx.Exec("INSERT INTO lobby_member (lobby_id, account_id) VALUES ($1, $2) ON CONFLICT (lobby_id, account_id) DO NOTHING", lobbyID, fromAccountID)
+	if err != nil {
+		return fmt.Errorf("an error occurred while keeping the previous owner in the lobby with the ID %d: %v", lobbyID, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("an error occurred while committing the ownership transfer: %v", err)
+	}
+
+	return nil
+}
+
+func (p *PostgresLobbyRepository) IsBanned(lobbyID int64, accountID int64) (bool, error) {
+	var banned bool
+	err := p.DB.QueryRow("SELECT EXISTS (SELECT 1 FROM lobby_ban WHERE lobby_id = $1 AND account_id = $2)", lobbyID, accountID).Scan(&banned)
+	return banned, err
+}
+
+func (p *PostgresLobbyRepository) Ban(lobbyID int64, accountID int64) error {
+	_, err := p.DB.Exec("INSERT INTO lobby_ban (lobby_id, account_id) VALUES ($1, $2) ON CONFLICT (lobby_id, account_id) DO NOTHING", lobbyID, accountID)
+	return err
+}
+
+func (p *PostgresLobbyRepository) Chatter(lobbyID int64, accountID int64) (*Chatter, error) {
+	var chatter Chatter
+	err := p.DB.Get(&chatter,
+		"SELECT lobby.owner_account_id, lobby.is_muted AS lobby_muted, lobby_member.is_muted AS member_muted FROM lobby LEFT JOIN lobby_member ON lobby_member.lobby_id = lobby.id AND lobby_member.account_id = $2 WHERE lobby.id = $1",
+		lobbyID, accountID,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrLobbyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &chatter, nil
+}
+
+func (p *PostgresLobbyRepository) AddMessage(message *Message) error {
+	return p.DB.QueryRow(
+		"INSERT INTO lobby_message (lobby_id, account_id, message) VALUES ($1, $2, $3) RETURNING id, created_at",
+		message.LobbyId, message.AccountId, message.Message,
+	).Scan(&message.ID, &message.CreatedAt)
+}
+
+func (p *PostgresLobbyRepository) ListMessages(lobbyID int64, before *int64, limit int) ([]Message, error) {
+	var err error
+	messages := []Message{}
+	if before != nil {
+		err = p.DB.Select(&messages, "SELECT id, lobby_id, account_id, message, created_at FROM lobby_message WHERE lobby_id = $1 AND id < $2 ORDER BY id DESC LIMIT $3", lobbyID, *before, limit)
+	} else {
+		err = p.DB.Select(&messages, "SELECT id, lobby_id, account_id, message, created_at FROM lobby_message WHERE lobby_id = $1 ORDER BY id DESC LIMIT $2", lobbyID, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
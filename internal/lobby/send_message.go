@@ -0,0 +1,112 @@
+package lobby
+
+import (
+	"net/http"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+)
+
+// SendMessageArgs represents the expected structure of the request body for sending a chat message.
+//
+// @Description Structure for the lobby chat message request payload.
+type SendMessageArgs struct {
+	// The lobby ID for the lobby the message will be sent to.
+	LobbyId int64 `json:"lobby_id" validate:"required"`
+	// The text of the message, at most 500 characters.
+	Message string `json:"message"`
+}
+
+// SendMessageBody represents the expected structure of the request body for sending a chat message through
+// /v2, which takes the lobby ID from the path.
+//
+// @Description Structure for the v2 chat message request payload.
+type SendMessageBody struct {
+	// The text of the message, at most 500 characters.
+	Message string `json:"message"`
+}
+
+// SendMessage sends a chat message to a lobby. This is the fallback for clients which are not
+// connected to the lobby's WebSocket; connected clients can send chat_message events instead.
+//
+// @Summary Sends a lobby chat message
+// @Description This endpoint sends a chat message to a lobby the caller is a member of. Members cannot chat while the lobby or they are muted, and can send at most 5 messages every 10 seconds.
+// @Tags lobby
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Param body body SendMessageArgs true "lobby chat message request body"
+// @Success 201 {object} lobby.Message "Message successfully sent"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 404 {object} httpapi.ErrorResponse "Not Found"
+// @Failure 429 {object} httpapi.ErrorResponse "Too Many Requests"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /lobby/send_message [post]
+func SendMessage(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) error {
+
+	if r.Method != http.MethodPost {
+		return httpapi.MethodNotAllowed(http.MethodPost)
+	}
+
+	args := SendMessageArgs{}
+	err := httpapi.DecodeJSON(r, &args)
+	if err != nil {
+		return err
+	}
+
+	return sendMessage(w, r, args, lobbies, store)
+}
+
+// SendMessageV2 sends a chat message to the lobby with the ID in the path.
+//
+// @Summary Sends a lobby chat message
+// @Description This endpoint sends a chat message to a lobby the caller is a member of. Members cannot chat while the lobby or they are muted, and can send at most 5 messages every 10 seconds.
+// @Tags lobby
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Param id path int true "lobby ID"
+// @Param body body SendMessageBody true "chat message request body"
+// @Success 201 {object} lobby.Message "Message successfully sent"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 404 {object} httpapi.ErrorResponse "Not Found"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Failure 429 {object} httpapi.ErrorResponse "Too Many Requests"
+// @Router /v2/lobbies/{id}/messages [post]
+func SendMessageV2(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) error {
+	lobbyId, err := httpapi.PathID(r, "id")
+	if err != nil {
+		return err
+	}
+
+	body := SendMessageBody{}
+	if err := httpapi.DecodeJSON(r, &body); err != nil {
+		return err
+	}
+
+	return sendMessage(w, r, SendMessageArgs{LobbyId: lobbyId, Message: body.Message}, lobbies, store)
+}
+
+// sendMessage sends the chat message in args on behalf of the caller.
+func sendMessage(w http.ResponseWriter, r *http.Request, args SendMessageArgs, lobbies LobbyRepository, store *auth.SessionStore) error {
+	if err := httpapi.Validate(&args); err != nil {
+		return err
+	}
+
+	session, err := store.Authenticate(r, nil)
+	if err != nil {
+		return err
+	}
+
+	message, err := sendChatMessage(lobbies, args.LobbyId, int64(session.AccountID), args.Message)
+	if err != nil {
+		return err
+	}
+
+	httpapi.WriteJSON(w, http.StatusCreated, message)
+	return nil
+}
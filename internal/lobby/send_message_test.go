@@ -0,0 +1,112 @@
+package lobby
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi/httpapitest"
+)
+
+func TestSendMessage_Success(t *testing.T) {
+	chatLimiter = newChatRateLimiter()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	expectChatter(mock, 3, 2, "1", false, false)
+	mock.ExpectQuery(regexp.QuoteMeta(insertMessageQuery)).
+		WithArgs(int64(3), int64(2), "ready when you are").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(11, time.Now()))
+
+	req, err := http.NewRequest("POST", "/lobby/send_message", strings.NewReader(`{"lobby_id": 3, "message": "ready when you are"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = withSession(req, 2)
+
+	rr := httptest.NewRecorder()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		SendMessageHandler(w, r, NewPostgresLobbyRepository(sqlxDB), &auth.SessionStore{DB: sqlxDB})
+	})
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusCreated {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusCreated)
+	}
+
+	var message Message
+	if err := json.Unmarshal(rr.Body.Bytes(), &message); err != nil {
+		t.Fatalf("could not decode the response: %v", err)
+	}
+
+	if message.ID != 11 || message.AccountId != 2 || message.Message != "ready when you are" {
+		t.Errorf("unexpected message: %+v", message)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSendMessage_LobbyMuted(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	expectChatter(mock, 3, 2, "1", true, false)
+
+	req, err := http.NewRequest("POST", "/lobby/send_message", strings.NewReader(`{"lobby_id": 3, "message": "hello?"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = withSession(req, 2)
+
+	rr := httptest.NewRecorder()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	err = SendMessage(rr, req, NewPostgresLobbyRepository(sqlxDB), &auth.SessionStore{DB: sqlxDB})
+
+	if err == nil || err.Error() != ERROR_LOBBY_MUTED {
+		t.Errorf("SendMessage() error = %v, wantErr %v", err, ERROR_LOBBY_MUTED)
+	}
+
+	if status := httpapitest.Status(err); status != http.StatusForbidden {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusForbidden)
+	}
+}
+
+func TestSendMessage_MissingLobbyID(t *testing.T) {
+	req, err := http.NewRequest("POST", "/lobby/send_message", strings.NewReader(`{"message": "hello"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	err = SendMessage(rr, req, nil, nil)
+
+	expectedError := "lobby_id must be specified"
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("SendMessage() error = %v, wantErr %v", err, expectedError)
+	}
+
+	if status := httpapitest.Status(err); status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,116 @@
+package lobby
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+)
+
+// SetReadyArgs represents the expected structure of the request body for changing a member's ready state.
+//
+// @Description Structure for the lobby ready state request payload.
+type SetReadyArgs struct {
+	// The lobby ID for the lobby the caller is a member of.
+	LobbyId int64 `json:"lobby_id" validate:"required"`
+	// Whether the caller is ready for the game to start.
+	Ready *bool `json:"ready" validate:"required"`
+}
+
+// SetReadyBody represents the expected structure of the request body for changing whether the caller is
+// ready through /v2, which takes the lobby ID from the path.
+//
+// @Description Structure for the v2 ready state request payload.
+type SetReadyBody struct {
+	// Whether the caller is ready for the game to start.
+	Ready *bool `json:"ready"`
+}
+
+// SetReady changes whether the caller is ready for the game to start.
+//
+// @Summary Sets the caller's ready state
+// @Description This endpoint marks the caller as ready (or not ready) in a lobby they have joined.
+// @Tags lobby
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Param body body SetReadyArgs true "lobby ready state request body"
+// @Success 200 {object} httpapi.MessageResponse "Successfully changed ready state!"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 404 {object} httpapi.ErrorResponse "Not Found"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /lobby/set_ready [post]
+func SetReady(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) error {
+
+	if r.Method != http.MethodPost {
+		return httpapi.MethodNotAllowed(http.MethodPost)
+	}
+
+	args := SetReadyArgs{}
+	err := httpapi.DecodeJSON(r, &args)
+	if err != nil {
+		return err
+	}
+
+	return setReady(w, r, args, lobbies, store)
+}
+
+// SetReadyV2 changes whether the caller is ready in the lobby with the ID in the path.
+//
+// @Summary Sets the caller's ready state
+// @Description This endpoint marks the caller as ready (or not ready) in a lobby they have joined.
+// @Tags lobby
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Param id path int true "lobby ID"
+// @Param body body SetReadyBody true "ready state request body"
+// @Success 200 {object} httpapi.MessageResponse "Successfully changed ready state!"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 404 {object} httpapi.ErrorResponse "Not Found"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /v2/lobbies/{id}/members/me/ready [put]
+func SetReadyV2(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) error {
+	lobbyId, err := httpapi.PathID(r, "id")
+	if err != nil {
+		return err
+	}
+
+	body := SetReadyBody{}
+	if err := httpapi.DecodeJSON(r, &body); err != nil {
+		return err
+	}
+
+	return setReady(w, r, SetReadyArgs{LobbyId: lobbyId, Ready: body.Ready}, lobbies, store)
+}
+
+// setReady changes whether the caller is ready in the lobby in args.
+func setReady(w http.ResponseWriter, r *http.Request, args SetReadyArgs, lobbies LobbyRepository, store *auth.SessionStore) error {
+	if err := httpapi.Validate(&args); err != nil {
+		return err
+	}
+
+	session, err := store.Authenticate(r, nil)
+	if err != nil {
+		return err
+	}
+	accountID := int64(session.AccountID)
+
+	err = lobbies.SetReady(args.LobbyId, accountID, *args.Ready)
+	if err == ErrNotLobbyMember {
+		return httpapi.NotFound(fmt.Sprintf("you are not a member of the lobby with the ID %d", args.LobbyId))
+	}
+	if err != nil {
+		return fmt.Errorf("an error occurred while changing the ready state in the lobby with the ID %d: %v", args.LobbyId, err)
+	}
+
+	Events.Publish(Event{Type: EventReadyChanged, LobbyId: args.LobbyId, AccountId: accountID, Ready: args.Ready})
+
+	httpapi.WriteMessage(w, http.StatusOK, "Successfully changed ready state!")
+	return nil
+}
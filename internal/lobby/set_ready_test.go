@@ -0,0 +1,76 @@
+package lobby
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi/httpapitest"
+)
+
+func TestSetReady_PublishesEvent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE lobby_member SET is_ready = \\$1 WHERE lobby_id = \\$2 AND account_id = \\$3").
+		WithArgs(true, int64(7), int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	events, unsubscribe := Events.Subscribe(7, 0)
+	defer unsubscribe()
+
+	req, err := http.NewRequest("POST", "/lobby/set_ready", strings.NewReader(`{"lobby_id": 7, "ready": true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = withSession(req, 2)
+
+	rr := httptest.NewRecorder()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		SetReadyHandler(w, r, NewPostgresLobbyRepository(sqlxDB), &auth.SessionStore{DB: sqlxDB})
+	})
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	event, ok := receiveEvent(t, events)
+	if !ok || event.Type != EventReadyChanged || event.AccountId != 2 || event.Ready == nil || !*event.Ready {
+		t.Errorf("unexpected event: %+v", event)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSetReady_MissingReady(t *testing.T) {
+	req, err := http.NewRequest("POST", "/lobby/set_ready", strings.NewReader(`{"lobby_id": 7}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	err = SetReady(rr, req, nil, nil)
+
+	expectedError := "ready must be specified"
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("SetReady() error = %v, wantErr %v", err, expectedError)
+	}
+
+	if status := httpapitest.Status(err); status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,119 @@
+package lobby
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+)
+
+// TransferOwnershipArgs represents the expected structure of the request body for transferring lobby ownership.
+//
+// @Description Structure for the lobby ownership transfer request payload.
+type TransferOwnershipArgs struct {
+	// The lobby ID for the lobby whose ownership will be transferred.
+	LobbyId int64 `json:"lobby_id" validate:"required"`
+	// The account ID of the member who will become the owner.
+	AccountId int64 `json:"account_id" validate:"required"`
+}
+
+// TransferOwnershipBody represents the expected structure of the request body for transferring ownership
+// through /v2, which takes the lobby ID from the path.
+//
+// @Description Structure for the v2 ownership transfer request payload.
+type TransferOwnershipBody struct {
+	// The account ID of the member who will become the owner.
+	AccountId int64 `json:"account_id"`
+}
+
+// TransferOwnership hands a lobby over to one of its members. The previous owner stays in the lobby as a member.
+//
+// @Summary Transfers lobby ownership
+// @Description This endpoint lets the lobby owner make another member of the lobby its owner.
+// @Tags lobby
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Param body body TransferOwnershipArgs true "lobby ownership transfer request body"
+// @Success 200 {object} httpapi.MessageResponse "Successfully transferred ownership!"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 404 {object} httpapi.ErrorResponse "Not Found"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /lobby/transfer_ownership [post]
+func TransferOwnership(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) error {
+
+	if r.Method != http.MethodPost {
+		return httpapi.MethodNotAllowed(http.MethodPost)
+	}
+
+	args := TransferOwnershipArgs{}
+	err := httpapi.DecodeJSON(r, &args)
+	if err != nil {
+		return err
+	}
+
+	return transferOwnership(w, r, args, lobbies, store)
+}
+
+// TransferOwnershipV2 hands the lobby with the ID in the path over to another member.
+//
+// @Summary Transfers lobby ownership
+// @Description This endpoint lets the lobby owner make another member of the lobby its owner.
+// @Tags lobby
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Param id path int true "lobby ID"
+// @Param body body TransferOwnershipBody true "ownership transfer request body"
+// @Success 200 {object} httpapi.MessageResponse "Successfully transferred ownership!"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 404 {object} httpapi.ErrorResponse "Not Found"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /v2/lobbies/{id}/owner [put]
+func TransferOwnershipV2(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) error {
+	lobbyId, err := httpapi.PathID(r, "id")
+	if err != nil {
+		return err
+	}
+
+	body := TransferOwnershipBody{}
+	if err := httpapi.DecodeJSON(r, &body); err != nil {
+		return err
+	}
+
+	return transferOwnership(w, r, TransferOwnershipArgs{LobbyId: lobbyId, AccountId: body.AccountId}, lobbies, store)
+}
+
+// transferOwnership hands the lobby in args over to another member. Only the lobby owner can do so.
+func transferOwnership(w http.ResponseWriter, r *http.Request, args TransferOwnershipArgs, lobbies LobbyRepository, store *auth.SessionStore) error {
+	if err := httpapi.Validate(&args); err != nil {
+		return err
+	}
+
+	session, err := authorizeLobbyOwner(r, lobbies, store, args.LobbyId)
+	if err != nil {
+		return err
+	}
+
+	if int64(session.AccountID) == args.AccountId {
+		return httpapi.Validation(ERROR_ALREADY_OWNER).WithCode(CodeAlreadyOwner)
+	}
+
+	err = lobbies.TransferOwnership(args.LobbyId, int64(session.AccountID), args.AccountId)
+	if err == ErrNotLobbyMember {
+		return httpapi.NotFound(fmt.Sprintf("account %d is not a member of the lobby with the ID %d", args.AccountId, args.LobbyId)).WithCode(CodeNotLobbyMember)
+	}
+	if err != nil {
+		return err
+	}
+
+	Events.Publish(Event{Type: EventOwnerChanged, LobbyId: args.LobbyId, AccountId: args.AccountId})
+
+	httpapi.WriteMessage(w, http.StatusOK, "Successfully transferred ownership!")
+	return nil
+}
@@ -0,0 +1,129 @@
+package lobby
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+)
+
+const transferMembershipQuery = "SELECT EXISTS (SELECT 1 FROM lobby_member WHERE lobby_id = $1 AND account_id = $2)"
+
+func TestTransferOwnership_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	expectLobbyOwner(mock, 4, "1")
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(transferMembershipQuery)).
+		WithArgs(int64(4), int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name FROM account WHERE id = $1")).
+		WithArgs(int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("New Owner"))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE lobby SET owner_account_id = $1, owner_name = $2 WHERE id = $3")).
+		WithArgs("2", "New Owner", int64(4)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO lobby_member (lobby_id, account_id) VALUES ($1, $2) ON CONFLICT (lobby_id, account_id) DO NOTHING")).
+		WithArgs(int64(4), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	events, unsubscribe := Events.Subscribe(4, 0)
+	defer unsubscribe()
+
+	req, err := http.NewRequest("POST", "/lobby/transfer_ownership", strings.NewReader(`{"lobby_id": 4, "account_id": 2}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = withSession(req, 1)
+
+	rr := httptest.NewRecorder()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		TransferOwnershipHandler(w, r, NewPostgresLobbyRepository(sqlxDB), &auth.SessionStore{DB: sqlxDB})
+	})
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	event, ok := receiveEvent(t, events)
+	if !ok || event.Type != EventOwnerChanged || event.AccountId != 2 {
+		t.Errorf("unexpected event: %+v", event)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestTransferOwnership_NotAMember(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	expectLobbyOwner(mock, 4, "1")
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(transferMembershipQuery)).
+		WithArgs(int64(4), int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectRollback()
+
+	req, err := http.NewRequest("POST", "/lobby/transfer_ownership", strings.NewReader(`{"lobby_id": 4, "account_id": 2}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = withSession(req, 1)
+
+	rr := httptest.NewRecorder()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	TransferOwnershipHandler(rr, req, NewPostgresLobbyRepository(sqlxDB), &auth.SessionStore{DB: sqlxDB})
+
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusNotFound)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestTransferOwnership_NotOwner(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	expectLobbyOwner(mock, 4, "1")
+
+	req, err := http.NewRequest("POST", "/lobby/transfer_ownership", strings.NewReader(`{"lobby_id": 4, "account_id": 3}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = withSession(req, 2)
+
+	rr := httptest.NewRecorder()
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	TransferOwnershipHandler(rr, req, NewPostgresLobbyRepository(sqlxDB), &auth.SessionStore{DB: sqlxDB})
+
+	if status := rr.Code; status != http.StatusForbidden {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusForbidden)
+	}
+}
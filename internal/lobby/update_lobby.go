@@ -1,14 +1,13 @@
 package lobby
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"net/http"
-	"reflect"
 
-	"github.com/jmoiron/sqlx"
 	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
 )
 
 // UpdateLobbyArgs represents the expected structure of the request body for updating a lobby.
@@ -16,109 +15,126 @@ import (
 // @Description Structure for the lobby update request payload.
 type UpdateLobbyArgs struct {
 	// The lobby to update.
-	Lobby *LobbyParam `json:"lobby"`
+	Lobby *LobbyParam `json:"lobby" validate:"required_without=Password,nonempty"`
 	// The lobby ID for the lobby that will be updated.
-	LobbyId *int64 `json:"lobby_id"`
+	LobbyId *int64 `json:"lobby_id" validate:"required"`
+	// A new password for the lobby. Only the lobby owner can change it.
+	Password *string `json:"password,omitempty" validate:"password"`
+}
+
+// UpdateLobbyBody represents the expected structure of the request body for updating a lobby through /v2,
+// which takes the lobby ID from the path.
+//
+// @Description Structure for the v2 lobby update request payload.
+type UpdateLobbyBody struct {
+	// The fields of the lobby to change. Fields which are left out keep their current value.
+	Lobby *LobbyParam `json:"lobby,omitempty"`
+	// A new password for the lobby.
+	Password *string `json:"password,omitempty"`
 }
 
 // UpdateLobby updates a lobby by the lobby ID.
 //
 // @Summary Updates a lobby
-// @Description This endpoint updates a lobby's info.
+// @Description This endpoint updates a lobby's info. Only the lobby owner can update it, and the owner cannot be changed here.
 // @Tags lobby
 // @Accept json
 // @Produce json
+// @Param Authorization header string true "Bearer session token"
 // @Param body body UpdateLobbyArgs true "lobby update request body"
-// @Success 200 {string} string "Successfully updated lobby!"
-// @Failure 400 {string} string "lobby_id must be specified"
-// @Failure 500 {string} string "an error occurred while decoding the request body: <error message>"
+// @Success 200 {object} httpapi.MessageResponse "Successfully updated lobby!"
+// @Failure 400 {object} httpapi.ErrorResponse "lobby_id must be specified"
+// @Failure 401 {object} httpapi.ErrorResponse "a session token must be provided in the Authorization header"
+// @Failure 403 {object} httpapi.ErrorResponse "the session does not belong to the account being acted on"
+// @Failure 404 {object} httpapi.ErrorResponse "no lobby exists with the ID <id>"
+// @Failure 500 {object} httpapi.ErrorResponse "an error occurred while decoding the request body: <error message>"
 // @Router /lobby/update_lobby [put]
-func UpdateLobby(w http.ResponseWriter, r *http.Request, db *sqlx.DB, store *auth.SessionStore) error {
+func UpdateLobby(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) error {
 
 	if r.Method != http.MethodPut {
-		return errors.New("invalid request; request must be a PUT request")
+		return httpapi.MethodNotAllowed(http.MethodPut)
 	}
 
-	decoder := json.NewDecoder(r.Body)
-	decoder.DisallowUnknownFields()
-
 	args := UpdateLobbyArgs{}
-	err := decoder.Decode(&args)
-
+	err := httpapi.DecodeJSON(r, &args)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		return errors.New("an error occurred while decoding the request body:" + err.Error())
+		return err
 	}
 
-	if args.LobbyId == nil {
-		w.WriteHeader(http.StatusBadRequest)
-		return errors.New("lobby_id must be specified")
+	return updateLobby(w, r, args, lobbies, store)
+}
+
+// UpdateLobbyV2 updates the lobby with the ID in the path.
+//
+// @Summary Updates a lobby
+// @Description This endpoint updates a lobby's info. Only the lobby owner can update it, and the owner cannot be changed here.
+// @Tags lobby
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer session token"
+// @Param id path int true "lobby ID"
+// @Param body body UpdateLobbyBody true "lobby update request body"
+// @Success 200 {object} httpapi.MessageResponse "Successfully updated lobby!"
+// @Failure 400 {object} httpapi.ErrorResponse "Bad Request"
+// @Failure 401 {object} httpapi.ErrorResponse "Unauthorized"
+// @Failure 403 {object} httpapi.ErrorResponse "Forbidden"
+// @Failure 404 {object} httpapi.ErrorResponse "Not Found"
+// @Failure 500 {object} httpapi.ErrorResponse "Internal Server Error"
+// @Router /v2/lobbies/{id} [patch]
+func UpdateLobbyV2(w http.ResponseWriter, r *http.Request, lobbies LobbyRepository, store *auth.SessionStore) error {
+	lobbyId, err := httpapi.PathID(r, "id")
+	if err != nil {
+		return err
 	}
 
-	if args.Lobby == nil {
-		w.WriteHeader(http.StatusBadRequest)
-		return errors.New("lobby must be specified")
+	body := UpdateLobbyBody{}
+	if err := httpapi.DecodeJSON(r, &body); err != nil {
+		return err
 	}
 
-	// Use reflection to check if at least one field other than LobbyId is set
-	v := reflect.ValueOf(args)
-	numFields := v.NumField()
-	anyFieldSet := false
+	return updateLobby(w, r, UpdateLobbyArgs{Lobby: body.Lobby, LobbyId: &lobbyId, Password: body.Password}, lobbies, store)
+}
 
-	for i := 0; i < numFields; i++ {
-		field := v.Field(i)
-		if field.Kind() == reflect.Ptr && !field.IsNil() && v.Type().Field(i).Name != "LobbyId" {
-			anyFieldSet = true
-			break
-		}
+// updateLobby updates the lobby in args. Only its owner can update it.
+func updateLobby(w http.ResponseWriter, r *http.Request, args UpdateLobbyArgs, lobbies LobbyRepository, store *auth.SessionStore) error {
+	if err := httpapi.Validate(&args); err != nil {
+		return err
 	}
 
-	if !anyFieldSet {
-		w.WriteHeader(http.StatusBadRequest)
-		return errors.New("at least one field to update must be specified")
+	if args.Lobby != nil && (args.Lobby.OwnerName != nil || args.Lobby.OwnerAccountId != nil) {
+		return httpapi.Validation(ERROR_OWNER_CHANGED_BY_UPDATE)
 	}
 
-	query := "UPDATE lobby SET "
-	params := []interface{}{}
-	paramIndex := 1
-
-	if args.Lobby.Name != nil {
-		query += fmt.Sprintf("name = $%d, ", paramIndex)
-		params = append(params, args.Lobby.Name)
-		paramIndex++
+	if _, err := authorizeLobbyOwner(r, lobbies, store, *args.LobbyId); err != nil {
+		return err
 	}
-	if args.Lobby.OwnerName != nil {
-		query += fmt.Sprintf("owner_name = $%d, ", paramIndex)
-		params = append(params, args.Lobby.OwnerName)
-		paramIndex++
-	}
-	if args.Lobby.IsClosed != nil {
-		query += fmt.Sprintf("is_closed = $%d, ", paramIndex)
-		params = append(params, args.Lobby.IsClosed)
-		paramIndex++
-	}
-	if args.Lobby.IsMuted != nil {
-		query += fmt.Sprintf("is_muted = $%d, ", paramIndex)
-		params = append(params, args.Lobby.IsMuted)
-		paramIndex++
+
+	if args.Lobby == nil {
+		args.Lobby = &LobbyParam{}
 	}
-	if args.Lobby.IsPublic != nil {
-		query += fmt.Sprintf("is_public = $%d, ", paramIndex)
-		params = append(params, args.Lobby.IsPublic)
-		paramIndex++
+
+	var password *LobbyPassword
+	if args.Password != nil {
+		passwordHash, err := auth.HashPassword(*args.Password)
+		if err != nil {
+			log.Println("error hashing a lobby password: ", err.Error())
+			return errors.New("an error occurred while saving the password. Please try again later")
+		}
+
+		password = &LobbyPassword{Hash: passwordHash}
 	}
 
-	// Remove the trailing comma and space
-	query = query[:len(query)-2]
-	query += fmt.Sprintf(" WHERE id = $%d", paramIndex)
-	params = append(params, args.LobbyId)
+	if args.Lobby.Name == nil && args.Lobby.IsClosed == nil && args.Lobby.IsMuted == nil && args.Lobby.IsPublic == nil && password == nil {
+		return httpapi.Validation("at least one field to update must be specified")
+	}
 
-	_, err = db.Exec(query, params...)
+	err := lobbies.UpdateLobby(*args.LobbyId, args.Lobby, password)
 	if err != nil {
 		return fmt.Errorf("an error occurred while updating the lobby with the ID %d: %v", args.LobbyId, err)
 	}
 
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("Successfully updated lobby!"))
+	Events.Publish(Event{Type: EventLobbyUpdated, LobbyId: *args.LobbyId, Lobby: args.Lobby})
+
+	httpapi.WriteMessage(w, http.StatusOK, "Successfully updated lobby!")
 	return nil
 }
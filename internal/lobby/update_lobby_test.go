@@ -11,6 +11,8 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
 	auth "github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi/httpapitest"
 )
 
 func TestUpdateLobby_InvalidMethod(t *testing.T) {
@@ -32,21 +34,21 @@ func TestUpdateLobby_InvalidMethod(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := UpdateLobby(w, r, sqlxDB, mockStore)
+		err := UpdateLobby(w, r, NewPostgresLobbyRepository(sqlxDB), mockStore)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
 	handler.ServeHTTP(rr, req)
 
-	if status := rr.Code; status != http.StatusInternalServerError {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+	if status := rr.Code; status != http.StatusMethodNotAllowed {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusMethodNotAllowed)
 	}
 
 	expectedError := "invalid request; request must be a PUT request"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
 
@@ -69,21 +71,21 @@ func TestUpdateLobby_DecodeError(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := UpdateLobby(w, r, sqlxDB, mockStore)
+		err := UpdateLobby(w, r, NewPostgresLobbyRepository(sqlxDB), mockStore)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
 	handler.ServeHTTP(rr, req)
 
-	if status := rr.Code; status != http.StatusInternalServerError {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
 	}
 
-	expectedError := "an error occurred while decoding the request body:invalid character 'i' looking for beginning of value"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	expectedError := "an error occurred while decoding the request body: invalid character 'i' looking for beginning of value"
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
 
@@ -114,9 +116,9 @@ func TestUpdateLobby_MissingLobbyID(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := UpdateLobby(w, r, sqlxDB, mockStore)
+		err := UpdateLobby(w, r, NewPostgresLobbyRepository(sqlxDB), mockStore)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
@@ -127,8 +129,8 @@ func TestUpdateLobby_MissingLobbyID(t *testing.T) {
 	}
 
 	expectedError := "lobby_id must be specified"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
 
@@ -157,9 +159,9 @@ func TestUpdateLobby_MissingLobby(t *testing.T) {
 	}
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := UpdateLobby(w, r, sqlxDB, mockStore)
+		err := UpdateLobby(w, r, NewPostgresLobbyRepository(sqlxDB), mockStore)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
@@ -169,9 +171,9 @@ func TestUpdateLobby_MissingLobby(t *testing.T) {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
 	}
 
-	expectedError := "lobby must be specified"
-	if strings.TrimSpace(rr.Body.String()) != expectedError {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedError)
+	expectedError := "lobby must be specified unless password is"
+	if httpapitest.Message(rr) != expectedError {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedError)
 	}
 }
 
@@ -184,7 +186,6 @@ func TestUpdateLobby_Success(t *testing.T) {
 
 	lobbyID := int64(1)
 	name := "Updated Lobby"
-	ownerName := "Updated Owner"
 	isClosed := true
 	isMuted := false
 	isPublic := true
@@ -192,11 +193,10 @@ func TestUpdateLobby_Success(t *testing.T) {
 	updateArgs := UpdateLobbyArgs{
 		LobbyId: &lobbyID,
 		Lobby: &LobbyParam{
-			Name:      &name,
-			OwnerName: &ownerName,
-			IsClosed:  &isClosed,
-			IsMuted:   &isMuted,
-			IsPublic:  &isPublic,
+			Name:     &name,
+			IsClosed: &isClosed,
+			IsMuted:  &isMuted,
+			IsPublic: &isPublic,
 		},
 	}
 
@@ -205,6 +205,7 @@ func TestUpdateLobby_Success(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	req = withSession(req, 1)
 
 	rr := httptest.NewRecorder()
 	sqlxDB := sqlx.NewDb(db, "sqlmock")
@@ -212,14 +213,16 @@ func TestUpdateLobby_Success(t *testing.T) {
 		DB: sqlxDB,
 	}
 
-	mock.ExpectExec("UPDATE lobby SET name = \\$1, owner_name = \\$2, is_closed = \\$3, is_muted = \\$4, is_public = \\$5 WHERE id = \\$6").
-		WithArgs(name, ownerName, isClosed, isMuted, isPublic, lobbyID).
+	expectLobbyOwner(mock, 1, "1")
+
+	mock.ExpectExec("UPDATE lobby SET name = \\$1, is_closed = \\$2, is_muted = \\$3, is_public = \\$4 WHERE id = \\$5").
+		WithArgs(name, isClosed, isMuted, isPublic, lobbyID).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := UpdateLobby(w, r, sqlxDB, mockStore)
+		err := UpdateLobby(w, r, NewPostgresLobbyRepository(sqlxDB), mockStore)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httpapi.WriteError(w, err)
 		}
 	})
 
@@ -230,11 +233,31 @@ func TestUpdateLobby_Success(t *testing.T) {
 	}
 
 	expectedResponse := "Successfully updated lobby!"
-	if strings.TrimSpace(rr.Body.String()) != expectedResponse {
-		t.Errorf("handler returned unexpected body: got %v want %v", strings.TrimSpace(rr.Body.String()), expectedResponse)
+	if httpapitest.Message(rr) != expectedResponse {
+		t.Errorf("handler returned unexpected body: got %v want %v", httpapitest.Message(rr), expectedResponse)
 	}
 
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("there were unfulfilled expectations: %s", err)
 	}
 }
+
+func TestUpdateLobby_CannotChangeOwner(t *testing.T) {
+	req, err := http.NewRequest("PUT", "/lobby/update_lobby", strings.NewReader(`{"lobby_id": 1, "lobby": {"owner_account_id": "2"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = withSession(req, 1)
+
+	rr := httptest.NewRecorder()
+
+	err = UpdateLobby(rr, req, nil, &auth.SessionStore{})
+
+	if err == nil || err.Error() != ERROR_OWNER_CHANGED_BY_UPDATE {
+		t.Errorf("UpdateLobby() error = %v, wantErr %v", err, ERROR_OWNER_CHANGED_BY_UPDATE)
+	}
+
+	if status := httpapitest.Status(err); status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,54 @@
+package mail
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+)
+
+// Message is an email to a single recipient.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers emails, such as password reset tokens.
+type Sender interface {
+	Send(message Message) error
+}
+
+// LogSender writes emails to the log instead of sending them. It is meant for local development.
+type LogSender struct{}
+
+func (LogSender) Send(message Message) error {
+	log.Printf("Email to %s: %s\n%s", message.To, message.Subject, message.Body)
+	return nil
+}
+
+// FileSender appends emails to a file instead of sending them, so that they can be read while
+// developing a client without digging through the server's log.
+type FileSender struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+func (f *FileSender) Send(message Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.OpenFile(f.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("an error occurred while opening the mail file: %v", err)
+	}
+	defer file.Close()
+
+	_, err = fmt.Fprintf(file, "To: %s\nSubject: %s\n\n%s\n\n", message.To, message.Subject, message.Body)
+	if err != nil {
+		return fmt.Errorf("an error occurred while writing to the mail file: %v", err)
+	}
+
+	return nil
+}
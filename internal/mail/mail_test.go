@@ -0,0 +1,43 @@
+package mail
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileSender_AppendsMessages(t *testing.T) {
+	sender := &FileSender{Path: filepath.Join(t.TempDir(), "mail.txt")}
+
+	for _, subject := range []string{"First", "Second"} {
+		if err := sender.Send(Message{To: "player@example.com", Subject: subject, Body: "Hello"}); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	contents, err := os.ReadFile(sender.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "To: player@example.com\nSubject: First\n\nHello\n\nTo: player@example.com\nSubject: Second\n\nHello\n\n"
+	if string(contents) != expected {
+		t.Errorf("unexpected mail file:\n%s", contents)
+	}
+}
+
+func TestFormatMessage(t *testing.T) {
+	raw, err := formatMessage("server@example.com", Message{To: "player@example.com", Subject: "Hello", Body: "Line one\nLine two"})
+	if err != nil {
+		t.Fatalf("formatMessage() error = %v", err)
+	}
+
+	expected := "From: server@example.com\r\nTo: player@example.com\r\nSubject: Hello\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\nLine one\r\nLine two\r\n"
+	if string(raw) != expected {
+		t.Errorf("unexpected message:\n%q", raw)
+	}
+
+	if _, err := formatMessage("server@example.com", Message{To: "player@example.com", Subject: "Hello\r\nBcc: victim@example.com"}); err == nil {
+		t.Error("expected a subject containing a line break to be refused")
+	}
+}
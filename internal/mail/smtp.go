@@ -0,0 +1,60 @@
+package mail
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+// SMTPSender sends emails through an SMTP server, such as the one provided by a transactional email service.
+type SMTPSender struct {
+	// Addr is the host and port of the SMTP server, e.g. smtp.example.com:587.
+	Addr string
+	// Username and Password authenticate with the server using PLAIN auth. They can be left empty for
+	// servers which do not require authentication.
+	Username string
+	Password string
+	// From is the address emails are sent from.
+	From string
+}
+
+func (s *SMTPSender) Send(message Message) error {
+	body, err := formatMessage(s.From, message)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if s.Username != "" {
+		host, _, err := net.SplitHostPort(s.Addr)
+		if err != nil {
+			return fmt.Errorf("an error occurred while parsing the SMTP address: %v", err)
+		}
+		auth = smtp.PlainAuth("", s.Username, s.Password, host)
+	}
+
+	if err := smtp.SendMail(s.Addr, auth, s.From, []string{message.To}, body); err != nil {
+		return fmt.Errorf("an error occurred while sending the email: %v", err)
+	}
+
+	return nil
+}
+
+// formatMessage builds the raw email sent over SMTP. Headers are refused if they contain line breaks, since
+// those could be used to inject extra headers or recipients.
+func formatMessage(from string, message Message) ([]byte, error) {
+	for _, header := range []string{from, message.To, message.Subject} {
+		if strings.ContainsAny(header, "\r\n") {
+			return nil, errors.New("email headers must not contain line breaks")
+		}
+	}
+
+	body := strings.ReplaceAll(message.Body, "\n", "\r\n")
+
+	return []byte(fmt.Sprintf(
+		"From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		from, message.To, message.Subject, body,
+	)), nil
+}
@@ -0,0 +1,278 @@
+// Package migrate applies the versioned SQL migrations which create every table the server uses.
+package migrate
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+//go:embed migrations/*.sql
+var embedded embed.FS
+
+// lockID is the Postgres advisory lock key which stops two servers migrating the same database at once.
+const lockID = 7_302_468_411
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// ErrNoMigrationsApplied is returned by Down when there is nothing to roll back.
+var ErrNoMigrationsApplied = errors.New("no migrations have been applied")
+
+// Migration is a single schema change and the SQL which undoes it.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status is a migration and when it was applied to the database, if it has been.
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Migrator applies migrations to a database, recording them in the schema_migrations table.
+type Migrator struct {
+	DB         *sqlx.DB
+	Migrations []Migration
+}
+
+// New creates a Migrator for the migrations embedded in the server.
+func New(db *sqlx.DB) (*Migrator, error) {
+	fsys, err := fs.Sub(embedded, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{DB: db, Migrations: migrations}, nil
+}
+
+// Load reads migrations named like 0001_create_account.up.sql and 0001_create_account.down.sql
+// from the root of fsys, sorted by version. Every migration must have both files.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration file %s is not named like 0001_name.up.sql or 0001_name.down.sql", entry.Name())
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration file %s has an invalid version: %v", entry.Name(), err)
+		}
+
+		contents, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d is named both %s and %s", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(contents)
+		} else {
+			migration.Down = string(contents)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both an up and a down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Up applies every migration which has not been applied yet, in order, and returns the ones it applied.
+// Each migration runs in its own transaction, so a failure leaves the earlier ones in place.
+func (m *Migrator) Up() ([]Migration, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+
+	applied := []Migration{}
+	for _, migration := range m.Migrations {
+		ran := false
+		err := m.inLockedTx(func(tx *sqlx.Tx) error {
+			var done bool
+			if err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)", migration.Version).Scan(&done); err != nil {
+				return err
+			}
+
+			// Another server may have applied it while we waited for the lock
+			if done {
+				return nil
+			}
+
+			if _, err := tx.Exec(migration.Up); err != nil {
+				return err
+			}
+
+			if _, err := tx.Exec("INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", migration.Version, migration.Name); err != nil {
+				return err
+			}
+
+			ran = true
+			return nil
+		})
+		if err != nil {
+			return applied, fmt.Errorf("an error occurred while applying migration %d_%s: %v", migration.Version, migration.Name, err)
+		}
+
+		if ran {
+			applied = append(applied, migration)
+		}
+	}
+
+	return applied, nil
+}
+
+// Down rolls back the most recently applied migration and returns it.
+func (m *Migrator) Down() (*Migration, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+
+	var rolledBack *Migration
+	err := m.inLockedTx(func(tx *sqlx.Tx) error {
+		var version *int64
+		if err := tx.QueryRow("SELECT MAX(version) FROM schema_migrations").Scan(&version); err != nil {
+			return err
+		}
+
+		if version == nil {
+			return ErrNoMigrationsApplied
+		}
+
+		migration := m.find(*version)
+		if migration == nil {
+			return fmt.Errorf("migration %d has been applied but is not known to this server", *version)
+		}
+
+		if _, err := tx.Exec(migration.Down); err != nil {
+			return fmt.Errorf("an error occurred while rolling back migration %d_%s: %v", migration.Version, migration.Name, err)
+		}
+
+		if _, err := tx.Exec("DELETE FROM schema_migrations WHERE version = $1", migration.Version); err != nil {
+			return err
+		}
+
+		rolledBack = migration
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rolledBack, nil
+}
+
+// Status lists every known migration and when it was applied.
+func (m *Migrator) Status() ([]Status, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+
+	rows := []struct {
+		Version   int64     `db:"version"`
+		AppliedAt time.Time `db:"applied_at"`
+	}{}
+	if err := m.DB.Select(&rows, "SELECT version, applied_at FROM schema_migrations ORDER BY version"); err != nil {
+		return nil, fmt.Errorf("an error occurred while reading the applied migrations: %v", err)
+	}
+
+	appliedAt := make(map[int64]time.Time, len(rows))
+	for _, row := range rows {
+		appliedAt[row.Version] = row.AppliedAt
+	}
+
+	statuses := make([]Status, 0, len(m.Migrations))
+	for _, migration := range m.Migrations {
+		status := Status{Migration: migration}
+		if at, ok := appliedAt[migration.Version]; ok {
+			status.AppliedAt = &at
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+func (m *Migrator) ensureTable() error {
+	err := m.inLockedTx(func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+    version bigint PRIMARY KEY,
+    name text NOT NULL,
+    applied_at timestamp with time zone NOT NULL DEFAULT now()
+)`)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("an error occurred while creating the schema_migrations table: %v", err)
+	}
+
+	return nil
+}
+
+// inLockedTx runs fn in a transaction which holds the migration lock, committing if fn succeeds.
+func (m *Migrator) inLockedTx(fn func(tx *sqlx.Tx) error) error {
+	tx, err := m.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("SELECT pg_advisory_xact_lock($1)", lockID); err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (m *Migrator) find(version int64) *Migration {
+	for i := range m.Migrations {
+		if m.Migrations[i].Version == version {
+			return &m.Migrations[i]
+		}
+	}
+	return nil
+}
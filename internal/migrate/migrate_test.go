@@ -0,0 +1,264 @@
+package migrate
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+var testMigrations = []Migration{
+	{Version: 1, Name: "create_account", Up: "CREATE TABLE account (id bigint)", Down: "DROP TABLE account"},
+	{Version: 2, Name: "create_lobby", Up: "CREATE TABLE lobby (id bigint)", Down: "DROP TABLE lobby"},
+}
+
+// expectLockedTx expects a transaction to be started and the migration lock taken.
+func expectLockedTx(mock sqlmock.Sqlmock) {
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1)")).
+		WithArgs(lockID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func expectEnsureTable(mock sqlmock.Sqlmock) {
+	expectLockedTx(mock)
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+}
+
+func TestNew_LoadsEmbeddedMigrations(t *testing.T) {
+	migrator, err := New(nil)
+	if err != nil {
+		t.Fatalf("could not load the embedded migrations: %v", err)
+	}
+
+	if len(migrator.Migrations) == 0 {
+		t.Fatal("expected at least one embedded migration")
+	}
+
+	for i := 1; i < len(migrator.Migrations); i++ {
+		if migrator.Migrations[i].Version <= migrator.Migrations[i-1].Version {
+			t.Errorf("migration versions are not strictly increasing: %d follows %d", migrator.Migrations[i].Version, migrator.Migrations[i-1].Version)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0002_create_lobby.up.sql":     {Data: []byte("CREATE TABLE lobby (id bigint)")},
+		"0002_create_lobby.down.sql":   {Data: []byte("DROP TABLE lobby")},
+		"0001_create_account.up.sql":   {Data: []byte("CREATE TABLE account (id bigint)")},
+		"0001_create_account.down.sql": {Data: []byte("DROP TABLE account")},
+	}
+
+	migrations, err := Load(fsys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(migrations) != 2 {
+		t.Fatalf("got %d migrations want 2", len(migrations))
+	}
+
+	for i, want := range testMigrations {
+		if migrations[i] != want {
+			t.Errorf("migration %d = %+v, want %+v", i, migrations[i], want)
+		}
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{"MissingDown", fstest.MapFS{
+			"0001_create_account.up.sql": {Data: []byte("CREATE TABLE account (id bigint)")},
+		}},
+		{"BadFileName", fstest.MapFS{
+			"create_account.sql": {Data: []byte("CREATE TABLE account (id bigint)")},
+		}},
+		{"MismatchedNames", fstest.MapFS{
+			"0001_create_account.up.sql": {Data: []byte("CREATE TABLE account (id bigint)")},
+			"0001_create_lobby.down.sql": {Data: []byte("DROP TABLE lobby")},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Load(tt.fsys); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestUp_AppliesPendingMigrations(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	expectEnsureTable(mock)
+
+	// The first migration has already been applied
+	expectLockedTx(mock)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)")).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectCommit()
+
+	expectLockedTx(mock)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)")).
+		WithArgs(int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE lobby (id bigint)")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO schema_migrations (version, name) VALUES ($1, $2)")).
+		WithArgs(int64(2), "create_lobby").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	migrator := &Migrator{DB: sqlx.NewDb(db, "sqlmock"), Migrations: testMigrations}
+
+	applied, err := migrator.Up()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(applied) != 1 || applied[0].Version != 2 {
+		t.Errorf("unexpected applied migrations: %+v", applied)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUp_FailedMigrationIsRolledBack(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	expectEnsureTable(mock)
+
+	expectLockedTx(mock)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)")).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE account (id bigint)")).
+		WillReturnError(errors.New("syntax error"))
+	mock.ExpectRollback()
+
+	migrator := &Migrator{DB: sqlx.NewDb(db, "sqlmock"), Migrations: testMigrations}
+
+	applied, err := migrator.Up()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if len(applied) != 0 {
+		t.Errorf("unexpected applied migrations: %+v", applied)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDown_RollsBackLatestMigration(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	expectEnsureTable(mock)
+
+	expectLockedTx(mock)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT MAX(version) FROM schema_migrations")).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(2))
+	mock.ExpectExec(regexp.QuoteMeta("DROP TABLE lobby")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM schema_migrations WHERE version = $1")).
+		WithArgs(int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	migrator := &Migrator{DB: sqlx.NewDb(db, "sqlmock"), Migrations: testMigrations}
+
+	rolledBack, err := migrator.Down()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if rolledBack.Version != 2 {
+		t.Errorf("rolled back migration %d, want 2", rolledBack.Version)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDown_NothingApplied(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	expectEnsureTable(mock)
+
+	expectLockedTx(mock)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT MAX(version) FROM schema_migrations")).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(nil))
+	mock.ExpectRollback()
+
+	migrator := &Migrator{DB: sqlx.NewDb(db, "sqlmock"), Migrations: testMigrations}
+
+	if _, err := migrator.Down(); !errors.Is(err, ErrNoMigrationsApplied) {
+		t.Errorf("Down() error = %v, want %v", err, ErrNoMigrationsApplied)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	expectEnsureTable(mock)
+
+	appliedAt := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT version, applied_at FROM schema_migrations ORDER BY version")).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, appliedAt))
+
+	migrator := &Migrator{DB: sqlx.NewDb(db, "sqlmock"), Migrations: testMigrations}
+
+	statuses, err := migrator.Status()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(statuses) != 2 {
+		t.Fatalf("got %d statuses want 2", len(statuses))
+	}
+
+	if statuses[0].AppliedAt == nil || !statuses[0].AppliedAt.Equal(appliedAt) {
+		t.Errorf("expected migration 1 to be applied at %v, got %v", appliedAt, statuses[0].AppliedAt)
+	}
+
+	if statuses[1].AppliedAt != nil {
+		t.Errorf("expected migration 2 to be pending, got %v", statuses[1].AppliedAt)
+	}
+}
@@ -0,0 +1,3 @@
+drop table if exists "public"."passwords";
+
+drop table if exists "public"."account";
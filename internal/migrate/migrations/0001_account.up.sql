@@ -0,0 +1,29 @@
+-- Accounts and their password hashes. Passwords are keyed by the account email, not the account ID.
+create table if not exists "public"."account" (
+    "id" bigint generated by default as identity not null,
+    "created_at" timestamp with time zone not null default now(),
+    "name" text not null,
+    "info" text not null,
+    "location" text not null,
+    "email" text not null,
+    "experience_level" smallint not null default '0'::smallint,
+    constraint "account_pkey" primary key ("id"),
+    constraint "account_email_key" unique ("email")
+);
+
+alter table "public"."account" enable row level security;
+
+create table if not exists "public"."passwords" (
+    "id" bigint generated by default as identity not null,
+    "created_at" timestamp with time zone not null default now(),
+    "account_email" text not null,
+    "hash" text not null,
+    "salt" text not null,
+    constraint "pass_pkey" primary key ("id"),
+    constraint "pass_account_id_key" unique ("account_email"),
+    constraint "passwords_hash_key" unique ("hash"),
+    constraint "passwords_salt_key" unique ("salt"),
+    constraint "passwords_account_email_fkey" foreign key ("account_email") references "public"."account" ("email") on update cascade on delete cascade
+);
+
+alter table "public"."passwords" enable row level security;
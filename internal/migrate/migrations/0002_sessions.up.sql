@@ -0,0 +1,15 @@
+create table if not exists "public"."sessions" (
+    "id" bigint not null,
+    "account_id" bigint not null,
+    "created_at" timestamp with time zone not null default now(),
+    "expires_at" timestamp with time zone not null,
+    constraint "sessions_pkey" primary key ("id")
+);
+
+alter table "public"."sessions" enable row level security;
+
+alter table "public"."sessions" add column if not exists "token_hash" text;
+
+CREATE UNIQUE INDEX IF NOT EXISTS sessions_token_hash_key ON public.sessions USING btree (token_hash);
+
+CREATE INDEX IF NOT EXISTS sessions_expires_at_idx ON public.sessions USING btree (expires_at);
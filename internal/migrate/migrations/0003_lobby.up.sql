@@ -0,0 +1,22 @@
+create table if not exists "public"."lobby" (
+    "id" bigint generated by default as identity not null,
+    "created_at" timestamp with time zone not null default now(),
+    "name" text not null,
+    "owner_name" text not null,
+    "owner_account_id" text not null,
+    "is_closed" boolean not null default false,
+    "is_muted" boolean not null default false,
+    "is_public" boolean not null default false,
+    constraint "lobby_pkey" primary key ("id")
+);
+
+alter table "public"."lobby" enable row level security;
+
+-- Lobby passwords are hashed with the same argon2id parameters and base64 encoding as account passwords.
+alter table "public"."lobby" add column if not exists "password_hash" text;
+
+alter table "public"."lobby" add column if not exists "password_salt" text;
+
+alter table "public"."lobby" add column if not exists "max_members" smallint not null default 8;
+
+CREATE INDEX IF NOT EXISTS lobby_public_open_created_at_idx ON public.lobby USING btree (created_at DESC, id DESC) WHERE (is_public AND NOT is_closed);
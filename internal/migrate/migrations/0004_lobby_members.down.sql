@@ -0,0 +1,3 @@
+drop table if exists "public"."lobby_ban";
+
+drop table if exists "public"."lobby_member";
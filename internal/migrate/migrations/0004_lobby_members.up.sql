@@ -0,0 +1,27 @@
+create table if not exists "public"."lobby_member" (
+    "lobby_id" bigint not null,
+    "account_id" bigint not null,
+    "joined_at" timestamp with time zone not null default now(),
+    constraint "lobby_member_pkey" primary key ("lobby_id", "account_id"),
+    constraint "lobby_member_lobby_id_fkey" foreign key ("lobby_id") references "public"."lobby" ("id") on delete cascade,
+    constraint "lobby_member_account_id_fkey" foreign key ("account_id") references "public"."account" ("id") on delete cascade
+);
+
+alter table "public"."lobby_member" enable row level security;
+
+alter table "public"."lobby_member" add column if not exists "is_ready" boolean not null default false;
+
+alter table "public"."lobby_member" add column if not exists "is_muted" boolean not null default false;
+
+CREATE INDEX IF NOT EXISTS lobby_member_account_id_idx ON public.lobby_member USING btree (account_id);
+
+create table if not exists "public"."lobby_ban" (
+    "lobby_id" bigint not null,
+    "account_id" bigint not null,
+    "banned_at" timestamp with time zone not null default now(),
+    constraint "lobby_ban_pkey" primary key ("lobby_id", "account_id"),
+    constraint "lobby_ban_lobby_id_fkey" foreign key ("lobby_id") references "public"."lobby" ("id") on delete cascade,
+    constraint "lobby_ban_account_id_fkey" foreign key ("account_id") references "public"."account" ("id") on delete cascade
+);
+
+alter table "public"."lobby_ban" enable row level security;
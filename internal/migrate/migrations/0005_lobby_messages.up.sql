@@ -0,0 +1,14 @@
+create table if not exists "public"."lobby_message" (
+    "id" bigint generated by default as identity not null,
+    "lobby_id" bigint not null,
+    "account_id" bigint not null,
+    "message" text not null,
+    "created_at" timestamp with time zone not null default now(),
+    constraint "lobby_message_pkey" primary key ("id"),
+    constraint "lobby_message_lobby_id_fkey" foreign key ("lobby_id") references "public"."lobby" ("id") on delete cascade,
+    constraint "lobby_message_account_id_fkey" foreign key ("account_id") references "public"."account" ("id") on delete cascade
+);
+
+alter table "public"."lobby_message" enable row level security;
+
+CREATE INDEX IF NOT EXISTS lobby_message_lobby_id_id_idx ON public.lobby_message USING btree (lobby_id, id DESC);
@@ -0,0 +1,20 @@
+create table if not exists "public"."game" (
+    "id" bigint generated by default as identity not null,
+    "created_at" timestamp with time zone not null default now(),
+    "name" text not null,
+    "host_account_id" bigint not null,
+    "ruleset" text not null default 'ctp2',
+    "map_size" text not null default 'medium',
+    "max_players" smallint not null default 8,
+    "status" text not null default 'open',
+    "password_protected" boolean not null default false,
+    "password_hash" text,
+    "password_salt" text,
+    constraint "game_pkey" primary key ("id"),
+    constraint "game_host_account_id_fkey" foreign key ("host_account_id") references "public"."account" ("id") on delete cascade,
+    constraint "game_max_players_check" check (max_players BETWEEN 2 AND 8)
+);
+
+alter table "public"."game" enable row level security;
+
+CREATE INDEX IF NOT EXISTS game_status_created_at_idx ON public.game USING btree (status, created_at DESC);
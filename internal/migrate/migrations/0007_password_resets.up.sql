@@ -0,0 +1,13 @@
+-- Single-use password reset tokens. Only the SHA-256 hash of each token is stored, like session tokens.
+create table if not exists "public"."password_resets" (
+    "token_hash" text not null,
+    "account_id" bigint not null,
+    "created_at" timestamp with time zone not null default now(),
+    "expires_at" timestamp with time zone not null,
+    constraint "password_resets_pkey" primary key ("token_hash"),
+    constraint "password_resets_account_id_fkey" foreign key ("account_id") references "public"."account" ("id") on delete cascade
+);
+
+alter table "public"."password_resets" enable row level security;
+
+CREATE INDEX IF NOT EXISTS password_resets_account_id_idx ON public.password_resets USING btree (account_id);
@@ -0,0 +1,3 @@
+drop table if exists "public"."email_verifications";
+
+alter table "public"."account" drop column if exists "email_verified";
@@ -0,0 +1,19 @@
+-- Accounts start unverified. Accounts which existed before verification was introduced are treated as verified.
+alter table "public"."account" add column if not exists "email_verified" boolean not null default false;
+
+update "public"."account" set "email_verified" = true;
+
+-- Outstanding verification tokens. The email the token was sent to is kept, so that changing the email makes the token useless.
+create table if not exists "public"."email_verifications" (
+    "token_hash" text not null,
+    "account_id" bigint not null,
+    "email" text not null,
+    "created_at" timestamp with time zone not null default now(),
+    "expires_at" timestamp with time zone not null,
+    constraint "email_verifications_pkey" primary key ("token_hash"),
+    constraint "email_verifications_account_id_fkey" foreign key ("account_id") references "public"."account" ("id") on delete cascade
+);
+
+alter table "public"."email_verifications" enable row level security;
+
+CREATE INDEX IF NOT EXISTS email_verifications_account_id_idx ON public.email_verifications USING btree (account_id);
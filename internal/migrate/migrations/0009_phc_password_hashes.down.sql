@@ -0,0 +1,5 @@
+-- Hashes in the PHC string format cannot be checked without their parameters, so accounts which were
+-- rehashed will have to reset their password. Their salt is copied out so the constraints hold again.
+update "public"."passwords" set "salt" = split_part("hash", '$', 5) where "salt" is null;
+alter table "public"."passwords" alter column "salt" set not null;
+alter table "public"."passwords" add constraint "passwords_salt_key" unique ("salt");
@@ -0,0 +1,5 @@
+-- Password hashes are stored in the PHC string format, which carries the salt and the Argon2id
+-- parameters. The salt column is only kept for hashes stored before then, which are replaced the next
+-- time the account logs in.
+alter table "public"."passwords" drop constraint if exists "passwords_salt_key";
+alter table "public"."passwords" alter column "salt" drop not null;
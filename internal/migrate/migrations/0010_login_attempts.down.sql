@@ -0,0 +1,2 @@
+drop table if exists "public"."login_failures";
+drop table if exists "public"."login_throttles";
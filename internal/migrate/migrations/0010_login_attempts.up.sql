@@ -0,0 +1,24 @@
+-- Counters of recent failed password attempts, keyed by "account:<id>", "email:<email>" or "ip:<address>"
+create table if not exists "public"."login_throttles" (
+    "key" text not null,
+    "failures" integer not null,
+    "last_failure_at" timestamp with time zone not null,
+    constraint "login_throttles_pkey" primary key ("key")
+);
+
+alter table "public"."login_throttles" enable row level security;
+
+-- The audit trail of failed password attempts. It outlives the account, so the account is only nulled out.
+create table if not exists "public"."login_failures" (
+    "id" bigint generated by default as identity not null,
+    "account_id" bigint,
+    "ip" text not null,
+    "reason" text not null,
+    "created_at" timestamp with time zone not null default now(),
+    constraint "login_failures_pkey" primary key ("id"),
+    constraint "login_failures_account_id_fkey" foreign key ("account_id") references "public"."account" ("id") on delete set null
+);
+
+alter table "public"."login_failures" enable row level security;
+
+CREATE INDEX IF NOT EXISTS login_failures_account_id_idx ON public.login_failures USING btree (account_id, created_at);
@@ -0,0 +1,5 @@
+alter table "public"."account" drop constraint if exists "account_info_visibility_check";
+alter table "public"."account" drop constraint if exists "account_location_visibility_check";
+
+alter table "public"."account" drop column if exists "info_visibility";
+alter table "public"."account" drop column if exists "location_visibility";
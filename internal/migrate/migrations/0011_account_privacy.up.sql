@@ -0,0 +1,10 @@
+-- Who can see the optional parts of an account's public profile. The email is never public.
+-- Locations start out private, since before public profiles only the account itself could see them.
+alter table "public"."account" add column if not exists "location_visibility" text not null default 'private';
+alter table "public"."account" add column if not exists "info_visibility" text not null default 'public';
+
+alter table "public"."account" drop constraint if exists "account_location_visibility_check";
+alter table "public"."account" add constraint "account_location_visibility_check" check (location_visibility IN ('public', 'private'));
+
+alter table "public"."account" drop constraint if exists "account_info_visibility_check";
+alter table "public"."account" add constraint "account_info_visibility_check" check (info_visibility IN ('public', 'private'));
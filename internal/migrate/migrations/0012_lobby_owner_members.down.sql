@@ -0,0 +1,3 @@
+-- Owners added by the up migration cannot be told apart from owners who joined their lobby, so they are
+-- left as members.
+select 1;
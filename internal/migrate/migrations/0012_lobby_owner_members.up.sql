@@ -0,0 +1,7 @@
+-- Lobby owners are members of their lobbies. Lobbies created before that was the case get their owner
+-- added, so that member counts and the lobby's capacity include them.
+insert into "public"."lobby_member" ("lobby_id", "account_id", "joined_at")
+select "lobby"."id", "account"."id", "lobby"."created_at"
+from "public"."lobby"
+join "public"."account" on "account"."id"::text = "lobby"."owner_account_id"
+on conflict ("lobby_id", "account_id") do nothing;
@@ -0,0 +1 @@
+drop index if exists "public"."game_host_account_id_idx";
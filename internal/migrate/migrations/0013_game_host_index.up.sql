@@ -0,0 +1,2 @@
+-- Profiles count the games each account hosts.
+CREATE INDEX IF NOT EXISTS game_host_account_id_idx ON public.game USING btree (host_account_id);
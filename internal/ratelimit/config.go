@@ -0,0 +1,162 @@
+// Package ratelimit limits how often clients can call each route. The limits are grouped into named
+// policies, which routes are given in code and which the server's configuration file can change, and
+// are counted per IP address or per account.
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+// Key says what a limit counts requests by.
+type Key string
+
+const (
+	// KeyIP counts the requests from each IP address.
+	KeyIP Key = "ip"
+	// KeyAccount counts the requests made with each account's sessions. Requests without a session are
+	// counted by IP address instead.
+	KeyAccount Key = "account"
+)
+
+// The policies every configuration has. The configuration file may change their limits but not remove them.
+const (
+	// PolicyDefault is for reads and cheap updates.
+	PolicyDefault = "default"
+	// PolicyStrict is for requests which create things or send emails, such as creating an account.
+	PolicyStrict = "strict"
+	// PolicyHealth is for the health check.
+	PolicyHealth = "health"
+)
+
+// Limit allows Requests requests every Per, counted by By. Up to Requests may be made at once, after
+// which they are let through at an even pace.
+type Limit struct {
+	Requests int      `yaml:"requests"`
+	Per      Duration `yaml:"per"`
+	By       Key      `yaml:"by"`
+}
+
+// Policy is a set of limits which all apply to the routes using it.
+type Policy []Limit
+
+// Config holds the rate limits of every route.
+type Config struct {
+	// Policies holds the limits of each policy by name.
+	Policies map[string]Policy `yaml:"policies"`
+	// Routes gives routes another policy than the one they have in code. Routes are named by the
+	// pattern of their original route, such as "/account/create_account", which their /v2 route shares.
+	Routes map[string]string `yaml:"routes"`
+	// TrustedNetworks lists addresses and CIDR ranges which are never limited by IP address, such as the
+	// public address of a LAN event where every player shares one. Limits by account still apply.
+	TrustedNetworks []string `yaml:"trusted_networks"`
+}
+
+// Duration is a time.Duration written in YAML as a string such as "1m" or "30s".
+type Duration time.Duration
+
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value string
+	if err := unmarshal(&value); err != nil {
+		return errors.New(`durations must be strings such as "1m"`)
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return time.Duration(d).String(), nil
+}
+
+// DefaultConfig returns the built-in policies: 5 requests a second by IP address for PolicyDefault and
+// PolicyHealth, and 1 a minute for PolicyStrict.
+func DefaultConfig() Config {
+	return Config{
+		Policies: map[string]Policy{
+			PolicyDefault: {{Requests: 5, Per: Duration(time.Second), By: KeyIP}},
+			PolicyStrict:  {{Requests: 1, Per: Duration(time.Minute), By: KeyIP}},
+			PolicyHealth:  {{Requests: 5, Per: Duration(time.Second), By: KeyIP}},
+		},
+		Routes: map[string]string{},
+	}
+}
+
+// WithDefaults returns c with the built-in policies which it does not replace, so that a configuration
+// only needs to list the policies it changes.
+func (c Config) WithDefaults() Config {
+	defaults := DefaultConfig()
+
+	policies := defaults.Policies
+	for name, policy := range c.Policies {
+		policies[name] = policy
+	}
+	c.Policies = policies
+
+	if c.Routes == nil {
+		c.Routes = defaults.Routes
+	}
+	return c
+}
+
+// Validate checks that every limit can be enforced, every route names a policy which exists and every
+// trusted network can be parsed.
+func (c Config) Validate() error {
+	for _, name := range []string{PolicyDefault, PolicyStrict, PolicyHealth} {
+		if _, ok := c.Policies[name]; !ok {
+			return fmt.Errorf("the %s policy must be kept", name)
+		}
+	}
+
+	for name, policy := range c.Policies {
+		for _, limit := range policy {
+			if limit.Requests < 1 {
+				return fmt.Errorf("the limits of the %s policy must allow at least 1 request", name)
+			}
+			if limit.Per <= 0 {
+				return fmt.Errorf("the limits of the %s policy must have a positive per", name)
+			}
+			if limit.By != KeyIP && limit.By != KeyAccount {
+				return fmt.Errorf("the limits of the %s policy must be by %s or %s, not %q", name, KeyIP, KeyAccount, limit.By)
+			}
+		}
+	}
+
+	for route, name := range c.Routes {
+		if _, ok := c.Policies[name]; !ok {
+			return fmt.Errorf("the route %s uses the %s policy, which does not exist", route, name)
+		}
+	}
+
+	_, err := parseNetworks(c.TrustedNetworks)
+	return err
+}
+
+// parseNetworks parses addresses and CIDR ranges. Addresses become ranges holding only themselves.
+func parseNetworks(networks []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(networks))
+	for _, network := range networks {
+		if !strings.Contains(network, "/") {
+			addr, err := netip.ParseAddr(network)
+			if err != nil {
+				return nil, fmt.Errorf("the trusted network %q is neither an address nor a CIDR range", network)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(network)
+		if err != nil {
+			return nil, fmt.Errorf("the trusted network %q is neither an address nor a CIDR range", network)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
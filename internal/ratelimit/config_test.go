@@ -0,0 +1,42 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Errorf("expected the built-in policies to be valid, got %v", err)
+	}
+
+	// strict replaces the limits of the strict policy
+	strict := func(limit Limit) func(c *Config) {
+		return func(c *Config) { c.Policies[PolicyStrict] = Policy{limit} }
+	}
+
+	tests := []struct {
+		name    string
+		change  func(config *Config)
+		message string
+	}{
+		{"RemovedPolicy", func(c *Config) { delete(c.Policies, PolicyHealth) }, "the health policy must be kept"},
+		{"NoRequests", strict(Limit{Requests: 0, Per: Duration(time.Minute), By: KeyIP}), "the limits of the strict policy must allow at least 1 request"},
+		{"NoPer", strict(Limit{Requests: 1, By: KeyIP}), "the limits of the strict policy must have a positive per"},
+		{"By", strict(Limit{Requests: 1, Per: Duration(time.Minute), By: "email"}), `the limits of the strict policy must be by ip or account, not "email"`},
+		{"UnknownPolicy", func(c *Config) { c.Routes["/auth/login"] = "login" }, "the route /auth/login uses the login policy, which does not exist"},
+		{"TrustedNetwork", func(c *Config) { c.TrustedNetworks = []string{"lan"} }, `the trusted network "lan" is neither an address nor a CIDR range`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			tt.change(&config)
+
+			err := config.Validate()
+			if err == nil || err.Error() != tt.message {
+				t.Errorf("got %v want %q", err, tt.message)
+			}
+		})
+	}
+}
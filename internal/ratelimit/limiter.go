@@ -0,0 +1,222 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
+)
+
+const ERROR_RATE_LIMITED = "too many requests; please slow down"
+
+// CodeRateLimited is the code of the error returned when a route's rate limit is used up.
+const CodeRateLimited httpapi.Code = "rate_limited"
+
+// sweepInterval is how often buckets which have filled up again are forgotten.
+const sweepInterval = time.Minute
+
+// Limiter enforces a Config. Each route name has its own buckets, so using up the limit of one route does
+// not slow down the others.
+type Limiter struct {
+	// ClientIPHeader names a header holding the client's IP address, such as Fly-Client-IP. It must only
+	// be set behind a proxy which overwrites the header. When empty, the connection's address is used.
+	ClientIPHeader string
+
+	config  Config
+	trusted []netip.Prefix
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// bucket holds the tokens left of one limit for one client. A request takes a token, and tokens come
+// back at the limit's pace up to its number of requests.
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	// full is when the bucket will have every token back, after which it can be forgotten.
+	full time.Time
+}
+
+// New creates a Limiter enforcing config.
+func New(config Config) (*Limiter, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	trusted, err := parseNetworks(config.TrustedNetworks)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Limiter{
+		config:  config,
+		trusted: trusted,
+		buckets: map[string]*bucket{},
+		now:     time.Now,
+	}, nil
+}
+
+// Route returns the limits of the route named name, usually the pattern it is registered under. The route
+// uses policy unless the configuration gives it another one. Routes with the same name share their
+// buckets, so handlers serving one endpoint at several patterns can be given the limits of one name.
+func (l *Limiter) Route(name string, policy string) *Route {
+	if configured, ok := l.config.Routes[name]; ok {
+		policy = configured
+	}
+
+	limits, ok := l.config.Policies[policy]
+	if !ok {
+		panic(fmt.Sprintf("ratelimit: unknown policy %q", policy))
+	}
+	return &Route{limiter: l, name: name, limits: limits}
+}
+
+// Route applies the limits of one route to its handler.
+type Route struct {
+	limiter *Limiter
+	name    string
+	limits  Policy
+}
+
+// Handler applies every limit of the route to next, which does not look up the caller's session.
+func (rt *Route) Handler(next http.Handler) http.Handler {
+	return rt.LimitIP(rt.LimitAccount(next))
+}
+
+// LimitIP applies the route's limits by IP address to next. Routes which need a session should have
+// these checked before the session is looked up, so that floods of requests do not reach the database.
+func (rt *Route) LimitIP(next http.Handler) http.Handler {
+	return rt.limit(KeyIP, next)
+}
+
+// LimitAccount applies the route's limits by account to next. They need the session found by
+// auth.SessionStore.Middleware, so they should be checked after it; without a session, the client's IP
+// address is counted instead.
+func (rt *Route) LimitAccount(next http.Handler) http.Handler {
+	return rt.limit(KeyAccount, next)
+}
+
+func (rt *Route) limit(by Key, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := rt.take(w, r, by); err != nil {
+			httpapi.WriteError(w, err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// take takes a token from each of the route's limits by the given key, and sets the RateLimit headers
+// of the one with the fewest left. It returns an error when a limit has none left.
+func (rt *Route) take(w http.ResponseWriter, r *http.Request, by Key) error {
+	ip := httpapi.ClientIP(r, rt.limiter.ClientIPHeader)
+	trusted := rt.limiter.isTrusted(ip)
+
+	var denied *httpapi.Error
+	for i, limit := range rt.limits {
+		if limit.By != by {
+			continue
+		}
+
+		client := "ip:" + ip
+		if accountID, ok := auth.AccountIDFromContext(r.Context()); ok && by == KeyAccount {
+			client = fmt.Sprintf("account:%d", accountID)
+		} else if trusted {
+			continue
+		}
+
+		allowed, remaining, reset, retryAfter := rt.limiter.takeToken(fmt.Sprintf("%s|%d|%s", rt.name, i, client), limit)
+		setHeaders(w, limit, remaining, reset)
+		if !allowed && denied == nil {
+			denied = httpapi.TooManyRequests(ERROR_RATE_LIMITED, retryAfter).WithCode(CodeRateLimited)
+		}
+	}
+
+	if denied != nil {
+		return denied
+	}
+	return nil
+}
+
+func (l *Limiter) isTrusted(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+
+	addr = addr.Unmap()
+	for _, prefix := range l.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// takeToken takes a token from the bucket with the given key. It returns whether there was one, how many
+// are left, how long until the bucket is full again and, when there was none, how long until there is.
+func (l *Limiter) takeToken(key string, limit Limit) (allowed bool, remaining int, reset time.Duration, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	capacity := float64(limit.Requests)
+	perToken := time.Duration(limit.Per) / time.Duration(limit.Requests)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updated: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(capacity, b.tokens+float64(now.Sub(b.updated))/float64(perToken))
+	b.updated = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		allowed = true
+	} else {
+		retryAfter = time.Duration((1 - b.tokens) * float64(perToken))
+	}
+
+	reset = time.Duration((capacity - b.tokens) * float64(perToken))
+	b.full = now.Add(reset)
+	return allowed, int(b.tokens), reset, retryAfter
+}
+
+// sweep forgets the buckets which have every token back, since a new bucket would be the same.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if !now.Before(b.full) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// setHeaders sets the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers of the IETF
+// draft for the limit, unless the response already has those of a limit with fewer requests remaining.
+func setHeaders(w http.ResponseWriter, limit Limit, remaining int, reset time.Duration) {
+	if current, err := strconv.Atoi(w.Header().Get("RateLimit-Remaining")); err == nil && current <= remaining {
+		return
+	}
+
+	w.Header().Set("RateLimit-Limit", strconv.Itoa(limit.Requests))
+	w.Header().Set("RateLimit-Remaining", strconv.Itoa(remaining))
+	w.Header().Set("RateLimit-Reset", strconv.Itoa(int(math.Ceil(reset.Seconds()))))
+}
@@ -0,0 +1,204 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	"github.com/justinfarrelldev/open-ctp-server/internal/httpapi/httpapitest"
+)
+
+// newTestLimiter creates a Limiter with the given policy as PolicyDefault, whose clock only moves when
+// the returned function is called.
+func newTestLimiter(t *testing.T, policy Policy, trusted ...string) (*Limiter, func(time.Duration)) {
+	config := DefaultConfig()
+	config.Policies[PolicyDefault] = policy
+	config.TrustedNetworks = trusted
+
+	limiter, err := New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+	return limiter, func(d time.Duration) { now = now.Add(d) }
+}
+
+var ok = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+})
+
+func serve(handler http.Handler, ip string, accountID int) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/account/create_account", nil)
+	req.RemoteAddr = ip + ":1234"
+	if accountID != 0 {
+		req = req.WithContext(auth.ContextWithSession(req.Context(), &auth.Session{AccountID: accountID}))
+	}
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestLimiter_ByIP(t *testing.T) {
+	limiter, advance := newTestLimiter(t, Policy{{Requests: 2, Per: Duration(time.Minute), By: KeyIP}})
+	handler := limiter.Route("/account/create_account", PolicyDefault).Handler(ok)
+
+	rr := serve(handler, "192.0.2.1", 0)
+	if rr.Code != http.StatusOK || rr.Header().Get("RateLimit-Limit") != "2" || rr.Header().Get("RateLimit-Remaining") != "1" || rr.Header().Get("RateLimit-Reset") != "30" {
+		t.Errorf("got status %d and headers %v", rr.Code, rr.Header())
+	}
+
+	serve(handler, "192.0.2.1", 0)
+	rr = serve(handler, "192.0.2.1", 0)
+	if rr.Code != http.StatusTooManyRequests || httpapitest.Code(rr) != CodeRateLimited || httpapitest.Message(rr) != ERROR_RATE_LIMITED {
+		t.Fatalf("expected the third request to be limited, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr.Header().Get("Retry-After") != "30" || rr.Header().Get("RateLimit-Remaining") != "0" || rr.Header().Get("RateLimit-Reset") != "60" {
+		t.Errorf("got headers %v", rr.Header())
+	}
+
+	if rr := serve(handler, "192.0.2.2", 0); rr.Code != http.StatusOK {
+		t.Errorf("expected another address to have its own limit, got %d", rr.Code)
+	}
+
+	advance(30 * time.Second)
+	if rr := serve(handler, "192.0.2.1", 0); rr.Code != http.StatusOK {
+		t.Errorf("expected a token to have come back, got %d", rr.Code)
+	}
+}
+
+func TestLimiter_RoutesHaveTheirOwnBuckets(t *testing.T) {
+	limiter, _ := newTestLimiter(t, Policy{{Requests: 1, Per: Duration(time.Minute), By: KeyIP}})
+	createAccount := limiter.Route("/account/create_account", PolicyDefault).Handler(ok)
+	createLobby := limiter.Route("/lobby/create_lobby", PolicyDefault).Handler(ok)
+
+	if rr := serve(createAccount, "192.0.2.1", 0); rr.Code != http.StatusOK {
+		t.Fatalf("got %d", rr.Code)
+	}
+	if rr := serve(createLobby, "192.0.2.1", 0); rr.Code != http.StatusOK {
+		t.Errorf("expected creating an account not to use up the limit of creating lobbies, got %d", rr.Code)
+	}
+}
+
+func TestLimiter_RoutesWithOneNameShareBuckets(t *testing.T) {
+	limiter, _ := newTestLimiter(t, Policy{{Requests: 1, Per: Duration(time.Minute), By: KeyIP}})
+	createAccount := limiter.Route("/account/create_account", PolicyDefault).Handler(ok)
+	createAccountV2 := limiter.Route("/account/create_account", PolicyDefault).Handler(ok)
+
+	if rr := serve(createAccount, "192.0.2.1", 0); rr.Code != http.StatusOK {
+		t.Fatalf("got %d", rr.Code)
+	}
+	if rr := serve(createAccountV2, "192.0.2.1", 0); rr.Code != http.StatusTooManyRequests {
+		t.Errorf("expected the /v2 route to share the limit of the original route, got %d", rr.Code)
+	}
+}
+
+func TestLimiter_ByAccount(t *testing.T) {
+	limiter, _ := newTestLimiter(t, Policy{
+		{Requests: 10, Per: Duration(time.Minute), By: KeyIP},
+		{Requests: 1, Per: Duration(time.Minute), By: KeyAccount},
+	})
+	route := limiter.Route("/lobby/send_message", PolicyDefault)
+	handler := route.Handler(ok)
+
+	if rr := serve(handler, "192.0.2.1", 1); rr.Code != http.StatusOK || rr.Header().Get("RateLimit-Remaining") != "0" {
+		t.Fatalf("expected the headers of the account limit, which has fewer left, got %d and %v", rr.Code, rr.Header())
+	}
+	if rr := serve(handler, "192.0.2.1", 1); rr.Code != http.StatusTooManyRequests {
+		t.Errorf("expected the account to be limited, got %d", rr.Code)
+	}
+
+	// Another account behind the same address has its own limit
+	if rr := serve(handler, "192.0.2.1", 2); rr.Code != http.StatusOK {
+		t.Errorf("expected another account to have its own limit, got %d", rr.Code)
+	}
+
+	// Without a session, the account limit counts the address
+	if rr := serve(handler, "192.0.2.1", 0); rr.Code != http.StatusOK {
+		t.Fatalf("got %d", rr.Code)
+	}
+	if rr := serve(handler, "192.0.2.1", 0); rr.Code != http.StatusTooManyRequests {
+		t.Errorf("expected requests without a session to be limited by address, got %d", rr.Code)
+	}
+
+	// LimitIP alone leaves the account limits to LimitAccount
+	if rr := serve(route.LimitIP(ok), "192.0.2.1", 1); rr.Code != http.StatusOK || rr.Header().Get("RateLimit-Limit") != "10" {
+		t.Errorf("expected only the IP limit, got %d and %v", rr.Code, rr.Header())
+	}
+}
+
+func TestLimiter_TrustedNetworks(t *testing.T) {
+	limiter, _ := newTestLimiter(t, Policy{
+		{Requests: 1, Per: Duration(time.Minute), By: KeyIP},
+		{Requests: 1, Per: Duration(time.Minute), By: KeyAccount},
+	}, "203.0.113.7", "10.0.0.0/8")
+	handler := limiter.Route("/account/create_account", PolicyDefault).Handler(ok)
+
+	for i := 0; i < 3; i++ {
+		if rr := serve(handler, "203.0.113.7", 0); rr.Code != http.StatusOK {
+			t.Fatalf("expected a trusted address never to be limited, got %d", rr.Code)
+		}
+		if rr := serve(handler, "10.1.2.3", 0); rr.Code != http.StatusOK {
+			t.Fatalf("expected a trusted network never to be limited, got %d", rr.Code)
+		}
+	}
+
+	serve(handler, "203.0.113.7", 1)
+	if rr := serve(handler, "203.0.113.7", 1); rr.Code != http.StatusTooManyRequests {
+		t.Errorf("expected limits by account to apply from trusted addresses, got %d", rr.Code)
+	}
+
+	serve(handler, "198.51.100.1", 0)
+	if rr := serve(handler, "198.51.100.1", 0); rr.Code != http.StatusTooManyRequests {
+		t.Errorf("expected other addresses to be limited, got %d", rr.Code)
+	}
+}
+
+func TestLimiter_ClientIPHeader(t *testing.T) {
+	limiter, _ := newTestLimiter(t, Policy{{Requests: 1, Per: Duration(time.Minute), By: KeyIP}})
+	limiter.ClientIPHeader = "Fly-Client-IP"
+	handler := limiter.Route("/account/create_account", PolicyDefault).Handler(ok)
+
+	for _, ip := range []string{"192.0.2.1", "192.0.2.2"} {
+		req := httptest.NewRequest(http.MethodPost, "/account/create_account", nil)
+		req.Header.Set("Fly-Client-IP", ip)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("expected clients behind the proxy to be told apart, got %d for %s", rr.Code, ip)
+		}
+	}
+}
+
+func TestLimiter_Sweep(t *testing.T) {
+	limiter, advance := newTestLimiter(t, Policy{{Requests: 1, Per: Duration(time.Second), By: KeyIP}})
+	handler := limiter.Route("/account/create_account", PolicyDefault).Handler(ok)
+
+	serve(handler, "192.0.2.1", 0)
+	advance(sweepInterval)
+	serve(handler, "192.0.2.2", 0)
+
+	if len(limiter.buckets) != 1 {
+		t.Errorf("expected the full bucket to be forgotten, got %d buckets", len(limiter.buckets))
+	}
+}
+
+func TestLimiter_RouteOverride(t *testing.T) {
+	config := DefaultConfig()
+	config.Routes["/account/create_account"] = PolicyDefault
+
+	limiter, err := New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	route := limiter.Route("/account/create_account", PolicyStrict)
+	if len(route.limits) != 1 || route.limits[0].Per != Duration(time.Second) {
+		t.Errorf("expected the configured policy, got %+v", route.limits)
+	}
+}
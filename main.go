@@ -1,13 +1,15 @@
 package main
 
 import (
+	"context"
 	_ "embed"
-	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"net/http"
@@ -15,19 +17,20 @@ import (
 	"github.com/jmoiron/sqlx"
 	account "github.com/justinfarrelldev/open-ctp-server/internal/account"
 	auth "github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	config "github.com/justinfarrelldev/open-ctp-server/internal/config"
 	game "github.com/justinfarrelldev/open-ctp-server/internal/game"
 	health "github.com/justinfarrelldev/open-ctp-server/internal/health"
+	httpapi "github.com/justinfarrelldev/open-ctp-server/internal/httpapi"
 	lobby "github.com/justinfarrelldev/open-ctp-server/internal/lobby"
+	mail "github.com/justinfarrelldev/open-ctp-server/internal/mail"
+	migrate "github.com/justinfarrelldev/open-ctp-server/internal/migrate"
+	ratelimit "github.com/justinfarrelldev/open-ctp-server/internal/ratelimit"
 
 	_ "github.com/justinfarrelldev/open-ctp-server/docs"
 
 	"github.com/flowchartsman/swaggerui"
 
-	"github.com/didip/tollbooth/v7"
-
 	_ "github.com/lib/pq"
-
-	"github.com/joho/godotenv"
 )
 
 //	@title			Open Call to Power Server
@@ -39,14 +42,10 @@ import (
 type Server struct {
 }
 
-type Message struct {
-	Status string `json:"status"`
-	Body   string `json:"body"`
-}
-
 var (
-	port  = 9000
-	sleep = flag.Duration("sleep", time.Second*5, "duration between changes in health")
+	configFile  = flag.String("config", "", "the YAML file to read settings from, like CONFIG_FILE")
+	printConfig = flag.Bool("print-config", false, "print the settings the server would run with, secrets redacted, and exit")
+	settings    = config.RegisterFlags(flag.CommandLine)
 
 	system = "" // empty string represents the health of the system
 )
@@ -55,105 +54,549 @@ var (
 var spec []byte
 
 func main() {
-	if os.Getenv("SUPABASE_DB_URL") == "" {
-		// Load .env file
-		err := godotenv.Load()
+	flag.Parse()
+
+	// Settings come from, in increasing precedence, their defaults, the --config file, the environment
+	// (and .env) and flags
+	cfg, err := config.Load(*configFile, settings)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *printConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("invalid configuration:\n%v", err)
+	}
+	if *printConfig {
+		return
+	}
+
+	var repos repositories
+	switch cfg.Storage {
+	case config.STORAGE_POSTGRES:
+		db, err := sqlx.Open("postgres", cfg.DatabaseURL)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Println("opened connection to database successfully")
+
+		migrator, err := migrate.New(db)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// "migrate up|down|status" manages the schema without starting the server
+		if flag.Arg(0) == "migrate" {
+			if err := runMigrateCommand(migrator, flag.Args()[1:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+
+		applied, err := migrator.Up()
 		if err != nil {
-			log.Fatalf("Error loading .env file")
-		}
-	}
-
-	// Tollbooth
-	message := Message{
-		Status: "Request Failed",
-		Body:   "The API is at capacity, try again later.",
-	}
-	jsonMessage, _ := json.Marshal(message)
-
-	// TODO move these tollbooth initializations to its own module to clean up the code path
-	// Limiter for standard operations
-	tollboothLimiter := tollbooth.NewLimiter(5, nil)
-	tollboothLimiter.SetMessageContentType("application/json")
-	tollboothLimiter.SetMessage(string(jsonMessage))
-	tollboothLimiter.SetTokenBucketExpirationTTL(time.Hour)
-	tollboothLimiter.SetBasicAuthExpirationTTL(time.Hour)
-	tollboothLimiter.SetHeaderEntryExpirationTTL(time.Hour)
-
-	// Limiter for DB operations
-	tollboothLimiterMinute := tollbooth.NewLimiter(1.0/60.0, nil)
-	tollboothLimiterMinute.SetMessageContentType("application/json")
-	tollboothLimiterMinute.SetMessage(string(jsonMessage))
-	tollboothLimiterMinute.SetTokenBucketExpirationTTL(time.Hour)
-	tollboothLimiterMinute.SetBasicAuthExpirationTTL(time.Hour)
-	tollboothLimiterMinute.SetHeaderEntryExpirationTTL(time.Hour)
-
-	// Limiter for the health check
-	tollboothLimiterHealth := tollbooth.NewLimiter(5, nil)
-	tollboothLimiterHealth.SetMessageContentType("application/json")
-	tollboothLimiterHealth.SetMessage(string(jsonMessage))
-	tollboothLimiterHealth.SetTokenBucketExpirationTTL(time.Hour)
-	tollboothLimiterHealth.SetBasicAuthExpirationTTL(time.Hour)
-	tollboothLimiterHealth.SetHeaderEntryExpirationTTL(time.Hour)
-
-	// Postgres
-	db, err := sqlx.Open("postgres", os.Getenv("SUPABASE_DB_URL"))
+			log.Fatalf("error migrating the database: %v", err)
+		}
+		for _, migration := range applied {
+			fmt.Printf("applied migration %d_%s\n", migration.Version, migration.Name)
+		}
+
+		repos = newPostgresRepositories(db)
+	case config.STORAGE_MEMORY:
+		if flag.Arg(0) == "migrate" {
+			log.Fatalf("there is nothing to migrate with --storage=%s", config.STORAGE_MEMORY)
+		}
+
+		fmt.Println("using in-memory storage, everything will be lost when the server stops")
+		repos = newMemoryRepositories()
+	}
+
+	accounts := repos.accounts
+	lobbies := repos.lobbies
+	games := repos.games
+	sessionStore := auth.NewSessionStoreWithRepository(repos.sessions)
+
+	sessionStore.IdleTimeout = cfg.Session.IdleTimeout
+	sessionStore.MaxLifetime = cfg.Session.MaxLifetime
+	// Numeric session IDs are deprecated in favor of bearer tokens
+	sessionStore.LegacyIDCutoff = cfg.Session.LegacyIDCutoff
+
+	// Passwords hashed with other parameters are rehashed the next time they are used to log in
+	auth.Hasher = auth.NewArgon2idHash(cfg.Argon2.Time, auth.DefaultArgon2SaltLen, cfg.Argon2.Memory, cfg.Argon2.Threads, auth.DefaultArgon2KeyLen)
+
+	// Every password set on the server, for accounts, lobbies and games, is checked against the same policy
+	auth.Passwords = auth.NewPasswordPolicy(int(cfg.Passwords.MinLength), int(cfg.Passwords.MaxLength))
+	if cfg.Passwords.DenylistFile != "" {
+		if err := auth.Passwords.LoadDenylist(cfg.Passwords.DenylistFile); err != nil {
+			log.Fatalf("error loading the password denylist: %v", err)
+		}
+	}
+
+	// Failed password attempts lock out the account and the IP address they came from
+	lockout := auth.NewLockout(repos.loginAttempts)
+	lockout.ClientIPHeader = cfg.ClientIPHeader
+
+	// Each route is rate limited by the policy it is given below, unless the configuration's rate_limits
+	// give it another. They can also change the policies' limits and list trusted networks which are not limited by IP
+	limiter, err := ratelimit.New(cfg.RateLimits)
 	if err != nil {
 		log.Fatal(err)
 	}
+	limiter.ClientIPHeader = cfg.ClientIPHeader
+
+	// Browsers may open lobby event WebSockets from pages on the server's own origin and on the allowed origins
+	lobby.AllowedOrigins = cfg.AllowedOrigins
+
+	// The /admin routes are only served when an admin token is set
+	adminToken := cfg.AdminToken
+
+	// Emails are sent through the SMTP server when one is set. Otherwise they are written to the log,
+	// unless a mail file is set to collect them in
+	var mailSender mail.Sender = mail.LogSender{}
+	if cfg.Mail.SMTPAddr != "" {
+		mailSender = &mail.SMTPSender{
+			Addr:     cfg.Mail.SMTPAddr,
+			Username: cfg.Mail.SMTPUsername,
+			Password: cfg.Mail.SMTPPassword,
+			From:     cfg.Mail.From,
+		}
+	} else if cfg.Mail.File != "" {
+		mailSender = &mail.FileSender{Path: cfg.Mail.File}
+	}
+
+	// Accounts can be made to verify their email before they can create lobbies or games
+	requireVerifiedEmail := func(next http.Handler) http.Handler { return next }
+	if cfg.RequireVerifiedEmail {
+		requireVerifiedEmail = func(next http.Handler) http.Handler {
+			return account.RequireVerifiedEmail(accounts, next)
+		}
+	}
 
-	fmt.Println("opened connection to database successfully")
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	sessionStore := auth.NewSessionStore(db)
+	// Expired sessions are never read again, so clean them up in the background
+	reaperDone := make(chan struct{})
+	go func() {
+		sessionStore.RunSessionReaper(ctx, auth.DefaultSessionReaperInterval, auth.DefaultSessionReaperBatchSize)
+		close(reaperDone)
+	}()
 
 	// Handlers
 	mux := http.NewServeMux()
 
-	mux.Handle("/game/create_game", tollbooth.LimitFuncHandler(tollboothLimiter, func(w http.ResponseWriter, r *http.Request) {
-		game.GameHandler(w, r, db)
+	// routes holds the rate limits of the original routes by pattern. Each /v2 route shares the limits of
+	// its original route, so that switching between them does not give a client twice the budget and one
+	// rate_limits.routes entry, such as "/lobby/create_lobby", applies to both.
+	routes := map[string]*ratelimit.Route{}
+	original := func(pattern string) *ratelimit.Route {
+		route, ok := routes[pattern]
+		if !ok {
+			panic(fmt.Sprintf("the original route %s must be registered before its /v2 route", pattern))
+		}
+		return route
+	}
+
+	serve := func(pattern string, route *ratelimit.Route, handler http.Handler) {
+		mux.Handle(pattern, route.Handler(handler))
+	}
+
+	// Limits by IP address are checked before the session is looked up, and limits by account after
+	serveSession := func(pattern string, route *ratelimit.Route, handler http.Handler) {
+		mux.Handle(pattern, route.LimitIP(sessionStore.Middleware(route.LimitAccount(handler))))
+	}
+
+	// handle serves a route which does not need a session
+	handle := func(pattern string, policy string, handler http.HandlerFunc) {
+		routes[pattern] = limiter.Route(pattern, policy)
+		serve(pattern, routes[pattern], handler)
+	}
+
+	// handleSession serves a route which acts on behalf of an account. The caller's session is resolved once
+	// by sessionStore.Middleware and available from the request context.
+	handleSession := func(pattern string, policy string, handler http.Handler) {
+		routes[pattern] = limiter.Route(pattern, policy)
+		serveSession(pattern, routes[pattern], handler)
+	}
+
+	// handleV2 and handleSessionV2 serve a /v2 route with the limits of its original route
+	handleV2 := func(pattern string, originalPattern string, handler http.HandlerFunc) {
+		serve(pattern, original(originalPattern), handler)
+	}
+
+	handleSessionV2 := func(pattern string, originalPattern string, handler http.Handler) {
+		serveSession(pattern, original(originalPattern), handler)
+	}
+
+	handleSession("/game/create_game", ratelimit.PolicyDefault, requireVerifiedEmail(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		game.GameHandler(w, r, games, sessionStore)
+	})))
+
+	handle("/game/get_game", ratelimit.PolicyDefault, func(w http.ResponseWriter, r *http.Request) {
+		game.GetGameHandler(w, r, games, sessionStore)
+	})
+
+	handle("/game/list_games", ratelimit.PolicyDefault, func(w http.ResponseWriter, r *http.Request) {
+		game.ListGamesHandler(w, r, games, sessionStore)
+	})
+
+	handleSession("/game/delete_game", ratelimit.PolicyDefault, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		game.DeleteGameHandler(w, r, games, sessionStore)
+	}))
+
+	handle("/account/create_account", ratelimit.PolicyStrict, func(w http.ResponseWriter, r *http.Request) {
+		account.CreateAccountHandler(w, r, accounts, sessionStore, mailSender)
+	})
+
+	handle("/account/verify_email", ratelimit.PolicyDefault, func(w http.ResponseWriter, r *http.Request) {
+		account.VerifyEmailHandler(w, r, accounts)
+	})
+
+	handleSession("/account/resend_verification", ratelimit.PolicyStrict, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		account.ResendVerificationHandler(w, r, accounts, sessionStore, mailSender)
+	}))
+
+	handleSession("/account/get_account", ratelimit.PolicyDefault, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		account.GetAccountHandler(w, r, accounts, sessionStore)
+	}))
+
+	handleSession("/account/my_account", ratelimit.PolicyDefault, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		account.MyAccountHandler(w, r, accounts, sessionStore)
+	}))
+
+	handle("/account/get_profile", ratelimit.PolicyDefault, func(w http.ResponseWriter, r *http.Request) {
+		account.GetProfileHandler(w, r, accounts)
+	})
+
+	handleSession("/account/update_privacy", ratelimit.PolicyDefault, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		account.UpdatePrivacyHandler(w, r, accounts, sessionStore)
+	}))
+
+	handleSession("/account/update_account", ratelimit.PolicyDefault, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		account.UpdateAccountHandler(w, r, accounts, sessionStore, lockout)
+	}))
+
+	handleSession("/account/delete_account", ratelimit.PolicyDefault, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		account.DeleteAccountHandler(w, r, accounts, sessionStore)
+	}))
+
+	handleSession("/account/change_password", ratelimit.PolicyDefault, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		account.ChangePasswordHandler(w, r, accounts, sessionStore, lockout)
+	}))
+
+	handle("/auth/login", ratelimit.PolicyDefault, func(w http.ResponseWriter, r *http.Request) {
+		auth.LoginHandler(w, r, accounts, sessionStore, lockout)
+	})
+
+	handle("/auth/logout", ratelimit.PolicyDefault, func(w http.ResponseWriter, r *http.Request) {
+		auth.LogoutHandler(w, r, sessionStore)
+	})
+
+	handle("/auth/forgot_password", ratelimit.PolicyStrict, func(w http.ResponseWriter, r *http.Request) {
+		auth.ForgotPasswordHandler(w, r, accounts, repos.passwordResets, mailSender)
+	})
+
+	handle("/auth/reset_password", ratelimit.PolicyDefault, func(w http.ResponseWriter, r *http.Request) {
+		auth.ResetPasswordHandler(w, r, repos.passwordResets, sessionStore, lockout)
+	})
+
+	handleSession("/lobby/create_lobby", ratelimit.PolicyStrict, requireVerifiedEmail(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lobby.CreateLobbyHandler(w, r, lobbies, sessionStore)
+	})))
+
+	handle("/lobby/get_lobby", ratelimit.PolicyDefault, func(w http.ResponseWriter, r *http.Request) {
+		lobby.GetLobbyHandler(w, r, lobbies, sessionStore)
+	})
+
+	handle("/lobby/list_lobbies", ratelimit.PolicyDefault, func(w http.ResponseWriter, r *http.Request) {
+		lobby.ListLobbiesHandler(w, r, lobbies, sessionStore)
+	})
+
+	handleSession("/lobby/update_lobby", ratelimit.PolicyDefault, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lobby.UpdateLobbyHandler(w, r, lobbies, sessionStore)
+	}))
+
+	handleSession("/lobby/delete_lobby", ratelimit.PolicyDefault, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lobby.DeleteLobbyHandler(w, r, lobbies, sessionStore)
+	}))
+
+	handleSession("/lobby/join_lobby", ratelimit.PolicyDefault, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lobby.JoinLobbyHandler(w, r, lobbies, sessionStore)
+	}))
+
+	handleSession("/lobby/leave_lobby", ratelimit.PolicyDefault, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lobby.LeaveLobbyHandler(w, r, lobbies, sessionStore)
+	}))
+
+	handleSession("/lobby/kick_member", ratelimit.PolicyDefault, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lobby.KickMemberHandler(w, r, lobbies, sessionStore)
+	}))
+
+	handleSession("/lobby/set_ready", ratelimit.PolicyDefault, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lobby.SetReadyHandler(w, r, lobbies, sessionStore)
+	}))
+
+	handleSession("/lobby/send_message", ratelimit.PolicyDefault, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lobby.SendMessageHandler(w, r, lobbies, sessionStore)
+	}))
+
+	handleSession("/lobby/list_messages", ratelimit.PolicyDefault, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lobby.ListMessagesHandler(w, r, lobbies, sessionStore)
+	}))
+
+	handleSession("/lobby/mute_member", ratelimit.PolicyDefault, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lobby.MuteMemberHandler(w, r, lobbies, sessionStore)
+	}))
+
+	handleSession("/lobby/transfer_ownership", ratelimit.PolicyDefault, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lobby.TransferOwnershipHandler(w, r, lobbies, sessionStore)
+	}))
+
+	// Not wrapped in sessionStore.Middleware, since browsers cannot set the Authorization header on WebSockets
+	// and pass the token in the bearer.<token> subprotocol instead
+	handle("/lobby/events", ratelimit.PolicyDefault, func(w http.ResponseWriter, r *http.Request) {
+		lobby.LobbyEventsHandler(w, r, lobbies, sessionStore)
+	})
+
+	handle("/lobby/list_members", ratelimit.PolicyDefault, func(w http.ResponseWriter, r *http.Request) {
+		lobby.ListMembersHandler(w, r, lobbies, sessionStore)
+	})
+
+	// The /v2 API names resources in the path and chooses what to do with them by the method, so that
+	// standard HTTP clients and caches can be used with it. It shares its handlers' logic and rate limits with the routes above.
+	handleSessionV2("POST /v2/games", "/game/create_game", requireVerifiedEmail(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		game.GameHandler(w, r, games, sessionStore)
+	})))
+
+	handleV2("GET /v2/games", "/game/list_games", func(w http.ResponseWriter, r *http.Request) {
+		game.ListGamesHandler(w, r, games, sessionStore)
+	})
+
+	handleV2("GET /v2/games/{id}", "/game/get_game", func(w http.ResponseWriter, r *http.Request) {
+		game.GetGameV2Handler(w, r, games, sessionStore)
+	})
+
+	handleSessionV2("DELETE /v2/games/{id}", "/game/delete_game", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		game.DeleteGameV2Handler(w, r, games, sessionStore)
+	}))
+
+	handleV2("POST /v2/accounts", "/account/create_account", func(w http.ResponseWriter, r *http.Request) {
+		account.CreateAccountHandler(w, r, accounts, sessionStore, mailSender)
+	})
+
+	handleSessionV2("GET /v2/accounts/me", "/account/my_account", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		account.MyAccountHandler(w, r, accounts, sessionStore)
+	}))
+
+	handleSessionV2("GET /v2/accounts/{id}", "/account/get_account", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		account.GetAccountV2Handler(w, r, accounts, sessionStore)
+	}))
+
+	handleSessionV2("PATCH /v2/accounts/{id}", "/account/update_account", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		account.UpdateAccountV2Handler(w, r, accounts, sessionStore, lockout)
+	}))
+
+	handleSessionV2("DELETE /v2/accounts/{id}", "/account/delete_account", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		account.DeleteAccountV2Handler(w, r, accounts, sessionStore)
+	}))
+
+	handleSessionV2("PUT /v2/accounts/{id}/password", "/account/change_password", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		account.ChangePasswordV2Handler(w, r, accounts, sessionStore, lockout)
+	}))
+
+	handleV2("GET /v2/accounts/{id}/profile", "/account/get_profile", func(w http.ResponseWriter, r *http.Request) {
+		account.GetProfileV2Handler(w, r, accounts)
+	})
+
+	handleSessionV2("PATCH /v2/accounts/{id}/privacy", "/account/update_privacy", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		account.UpdatePrivacyV2Handler(w, r, accounts, sessionStore)
+	}))
+
+	handleSessionV2("POST /v2/accounts/{id}/verification-emails", "/account/resend_verification", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		account.ResendVerificationV2Handler(w, r, accounts, sessionStore, mailSender)
 	}))
 
-	mux.Handle("/account/create_account", tollbooth.LimitFuncHandler(tollboothLimiterMinute, func(w http.ResponseWriter, r *http.Request) {
-		account.CreateAccountHandler(w, r, db, sessionStore)
+	handleV2("POST /v2/email-verifications", "/account/verify_email", func(w http.ResponseWriter, r *http.Request) {
+		account.VerifyEmailHandler(w, r, accounts)
+	})
+
+	handleV2("POST /v2/sessions", "/auth/login", func(w http.ResponseWriter, r *http.Request) {
+		auth.LoginHandler(w, r, accounts, sessionStore, lockout)
+	})
+
+	handleV2("DELETE /v2/sessions/current", "/auth/logout", func(w http.ResponseWriter, r *http.Request) {
+		auth.LogoutV2Handler(w, r, sessionStore)
+	})
+
+	handleV2("POST /v2/password-reset-requests", "/auth/forgot_password", func(w http.ResponseWriter, r *http.Request) {
+		auth.ForgotPasswordHandler(w, r, accounts, repos.passwordResets, mailSender)
+	})
+
+	handleV2("POST /v2/password-resets", "/auth/reset_password", func(w http.ResponseWriter, r *http.Request) {
+		auth.ResetPasswordHandler(w, r, repos.passwordResets, sessionStore, lockout)
+	})
+
+	handleSessionV2("POST /v2/lobbies", "/lobby/create_lobby", requireVerifiedEmail(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lobby.CreateLobbyV2Handler(w, r, lobbies, sessionStore)
+	})))
+
+	handleV2("GET /v2/lobbies", "/lobby/list_lobbies", func(w http.ResponseWriter, r *http.Request) {
+		lobby.ListLobbiesHandler(w, r, lobbies, sessionStore)
+	})
+
+	handleV2("GET /v2/lobbies/{id}", "/lobby/get_lobby", func(w http.ResponseWriter, r *http.Request) {
+		lobby.GetLobbyV2Handler(w, r, lobbies, sessionStore)
+	})
+
+	handleSessionV2("PATCH /v2/lobbies/{id}", "/lobby/update_lobby", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lobby.UpdateLobbyV2Handler(w, r, lobbies, sessionStore)
 	}))
 
-	mux.Handle("/account/get_account", tollbooth.LimitFuncHandler(tollboothLimiter, func(w http.ResponseWriter, r *http.Request) {
-		account.GetAccountHandler(w, r, db, sessionStore)
+	handleSessionV2("DELETE /v2/lobbies/{id}", "/lobby/delete_lobby", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lobby.DeleteLobbyV2Handler(w, r, lobbies, sessionStore)
 	}))
 
-	mux.Handle("/account/update_account", tollbooth.LimitFuncHandler(tollboothLimiter, func(w http.ResponseWriter, r *http.Request) {
-		account.UpdateAccountHandler(w, r, db, sessionStore)
+	handleV2("GET /v2/lobbies/{id}/members", "/lobby/list_members", func(w http.ResponseWriter, r *http.Request) {
+		lobby.ListMembersV2Handler(w, r, lobbies, sessionStore)
+	})
+
+	handleSessionV2("POST /v2/lobbies/{id}/members", "/lobby/join_lobby", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lobby.JoinLobbyV2Handler(w, r, lobbies, sessionStore)
 	}))
 
-	mux.Handle("/account/delete_account", tollbooth.LimitFuncHandler(tollboothLimiter, func(w http.ResponseWriter, r *http.Request) {
-		account.DeleteAccountHandler(w, r, db, sessionStore)
+	handleSessionV2("DELETE /v2/lobbies/{id}/members/me", "/lobby/leave_lobby", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lobby.LeaveLobbyV2Handler(w, r, lobbies, sessionStore)
 	}))
 
-	mux.Handle("/lobby/create_lobby", tollbooth.LimitFuncHandler(tollboothLimiterMinute, func(w http.ResponseWriter, r *http.Request) {
-		lobby.CreateLobbyHandler(w, r, db, sessionStore)
+	handleSessionV2("PUT /v2/lobbies/{id}/members/me/ready", "/lobby/set_ready", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lobby.SetReadyV2Handler(w, r, lobbies, sessionStore)
 	}))
 
-	mux.Handle("/lobby/get_lobby", tollbooth.LimitFuncHandler(tollboothLimiter, func(w http.ResponseWriter, r *http.Request) {
-		lobby.GetLobbyHandler(w, r, db, sessionStore)
+	handleSessionV2("DELETE /v2/lobbies/{id}/members/{account_id}", "/lobby/kick_member", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lobby.KickMemberV2Handler(w, r, lobbies, sessionStore)
 	}))
 
-	mux.Handle("/lobby/update_lobby", tollbooth.LimitFuncHandler(tollboothLimiter, func(w http.ResponseWriter, r *http.Request) {
-		lobby.UpdateLobbyHandler(w, r, db, sessionStore)
+	handleSessionV2("PUT /v2/lobbies/{id}/members/{account_id}/muted", "/lobby/mute_member", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lobby.MuteMemberV2Handler(w, r, lobbies, sessionStore)
 	}))
 
-	mux.Handle("/lobby/delete_lobby", tollbooth.LimitFuncHandler(tollboothLimiter, func(w http.ResponseWriter, r *http.Request) {
-		lobby.DeleteLobbyHandler(w, r, db, sessionStore)
+	handleSessionV2("GET /v2/lobbies/{id}/messages", "/lobby/list_messages", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lobby.ListMessagesV2Handler(w, r, lobbies, sessionStore)
 	}))
 
-	mux.Handle("/health", tollbooth.LimitFuncHandler(tollboothLimiterHealth, health.HealthCheckHandler))
+	handleSessionV2("POST /v2/lobbies/{id}/messages", "/lobby/send_message", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lobby.SendMessageV2Handler(w, r, lobbies, sessionStore)
+	}))
+
+	handleSessionV2("PUT /v2/lobbies/{id}/owner", "/lobby/transfer_ownership", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lobby.TransferOwnershipV2Handler(w, r, lobbies, sessionStore)
+	}))
+
+	// Not wrapped in sessionStore.Middleware, since browsers cannot set the Authorization header on WebSockets
+	// and pass the token in the bearer.<token> subprotocol instead
+	handleV2("GET /v2/lobbies/{id}/events", "/lobby/events", func(w http.ResponseWriter, r *http.Request) {
+		lobby.LobbyEventsV2Handler(w, r, lobbies, sessionStore)
+	})
+
+	if adminToken != "" {
+		handle("/admin/unlock_account", ratelimit.PolicyDefault, func(w http.ResponseWriter, r *http.Request) {
+			auth.UnlockAccountHandler(w, r, lockout, adminToken)
+		})
+
+		handle("/admin/login_failures", ratelimit.PolicyDefault, func(w http.ResponseWriter, r *http.Request) {
+			auth.ListLoginFailuresHandler(w, r, lockout, adminToken)
+		})
+
+		handleV2("DELETE /v2/admin/accounts/{id}/lockout", "/admin/unlock_account", func(w http.ResponseWriter, r *http.Request) {
+			auth.UnlockAccountV2Handler(w, r, lockout, adminToken)
+		})
+
+		handleV2("GET /v2/admin/accounts/{id}/login-failures", "/admin/login_failures", func(w http.ResponseWriter, r *http.Request) {
+			auth.ListLoginFailuresV2Handler(w, r, lockout, adminToken)
+		})
+	}
+
+	handle("/health", ratelimit.PolicyHealth, health.HealthCheckHandler)
 	mux.Handle("/docs/", http.StripPrefix("/docs", swaggerui.Handler(spec)))
 
-	fmt.Printf("\nNow serving on port %d\n", port)
-	err = http.ListenAndServe(fmt.Sprintf(":%d", port), mux)
+	server := &http.Server{
+		Addr:    fmt.Sprintf(":%d", cfg.Port),
+		Handler: httpapi.JSONErrors(mux),
+	}
+
+	// Let in-flight requests finish when we are asked to stop
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			fmt.Printf("error shutting down server: %s\n", err)
+		}
+	}()
+
+	fmt.Printf("\nNow serving on port %d\n", cfg.Port)
+	err = server.ListenAndServe()
 
 	if errors.Is(err, http.ErrServerClosed) {
+		<-shutdownDone
 		fmt.Printf("server closed\n")
 	} else if err != nil {
 		fmt.Printf("error starting server: %s\n", err)
 		os.Exit(1)
 	}
+
+	stop()
+	<-reaperDone
+	auth.WaitForPasswordResets()
+}
+
+func runMigrateCommand(migrator *migrate.Migrator, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: open-ctp-server migrate up|down|status")
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up()
+		for _, migration := range applied {
+			fmt.Printf("applied migration %d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("the database is up to date")
+		}
+	case "down":
+		migration, err := migrator.Down()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("rolled back migration %d_%s\n", migration.Version, migration.Name)
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			applied := "pending"
+			if status.AppliedAt != nil {
+				applied = "applied " + status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%04d_%s\t%s\n", status.Version, status.Name, applied)
+		}
+	default:
+		return fmt.Errorf("unknown migrate command %q; usage: open-ctp-server migrate up|down|status", args[0])
+	}
+
+	return nil
 }
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"github.com/jmoiron/sqlx"
+	account "github.com/justinfarrelldev/open-ctp-server/internal/account"
+	auth "github.com/justinfarrelldev/open-ctp-server/internal/auth"
+	game "github.com/justinfarrelldev/open-ctp-server/internal/game"
+	lobby "github.com/justinfarrelldev/open-ctp-server/internal/lobby"
+)
+
+// repositories holds everything the handlers read from and write to, whichever storage backs it.
+type repositories struct {
+	accounts account.AccountRepository
+	lobbies  lobby.LobbyRepository
+	games    game.GameRepository
+	sessions auth.SessionRepository
+
+	passwordResets auth.PasswordResetRepository
+	loginAttempts  auth.LoginAttemptRepository
+}
+
+func newPostgresRepositories(db *sqlx.DB) repositories {
+	accounts := account.NewPostgresAccountRepository(db)
+	accounts.DeleteHooks = append(accounts.DeleteHooks, lobby.ReleaseOwnedLobbies)
+
+	return repositories{
+		accounts: accounts,
+		lobbies:  lobby.NewPostgresLobbyRepository(db),
+		games:    game.NewPostgresGameRepository(db),
+		sessions: auth.NewPostgresSessionRepository(db),
+
+		passwordResets: auth.NewPostgresPasswordResetRepository(db),
+		loginAttempts:  auth.NewPostgresLoginAttemptRepository(db),
+	}
+}
+
+// newMemoryRepositories keeps all data in memory, so nothing survives a restart.
+// This is meant for LAN parties and client development, where setting up Postgres is not worth it.
+func newMemoryRepositories() repositories {
+	accounts := account.NewMemoryAccountRepository()
+	lobbies := lobby.NewMemoryLobbyRepository(accounts)
+	games := game.NewMemoryGameRepository()
+	sessions := auth.NewMemorySessionRepository()
+	accounts.DeleteHooks = append(accounts.DeleteHooks, deleteMemorySessions(sessions), lobbies.ReleaseOwnedLobbies, games.DeleteHostedGames)
+	accounts.HostedGames = games.CountHostedGames
+
+	return repositories{
+		accounts: accounts,
+		lobbies:  lobbies,
+		games:    games,
+		sessions: sessions,
+
+		passwordResets: auth.NewMemoryPasswordResetRepository(accounts),
+		loginAttempts:  auth.NewMemoryLoginAttemptRepository(),
+	}
+}
+
+// deleteMemorySessions deletes the account's sessions when the account is deleted. Deleting them from
+// memory cannot fail.
+func deleteMemorySessions(sessions *auth.MemorySessionRepository) account.MemoryDeleteHook {
+	return func(accountID int64) (func(), error) {
+		return func() { sessions.DeleteAccountSessions(accountID) }, nil
+	}
+}